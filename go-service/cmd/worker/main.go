@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"os/signal"
@@ -9,6 +10,8 @@ import (
 	"time"
 
 	"tubexxi/video-api/internal/dependencies"
+	"tubexxi/video-api/internal/worker"
+	"tubexxi/video-api/pkg/smtpd"
 
 	"go.uber.org/zap"
 )
@@ -30,11 +33,35 @@ func main() {
 		log.Fatalf("Asynq client is not initialized")
 	}
 
+	worker.RegisterHandlers(cont)
+	if err := worker.RegisterSchedules(cont); err != nil {
+		log.Fatalf("Failed to register periodic tasks: %v", err)
+	}
+	if err := cont.AsynqClient.StartScheduler(); err != nil {
+		log.Fatalf("Failed to start scheduler: %v", err)
+	}
+	defer cont.AsynqClient.ShutdownScheduler()
+
 	workerErrCh := make(chan error, 1)
 	go func() {
 		workerErrCh <- cont.AsynqClient.StartServer()
 	}()
 
+	mailServer := worker.NewInboundMailServer(cont)
+	if mailServer != nil {
+		go func() {
+			cont.Logger.Info("Inbound ticket mail listening", zap.String("addr", mailServer.Addr))
+			if err := mailServer.ListenAndServe(); err != nil && !errors.Is(err, smtpd.ErrServerClosed) {
+				cont.Logger.Error("Inbound ticket mail stopped with error", zap.Error(err))
+			}
+		}()
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			mailServer.Shutdown(shutdownCtx)
+		}()
+	}
+
 	select {
 	case <-ctx.Done():
 		stop()
@@ -10,16 +10,22 @@ import (
 )
 
 type Config struct {
-	App        AppConfig
-	Database   DatabaseConfig
-	Redis      RedisConfig
-	JWT        JWTConfig
-	Centrifugo CentrifugoConfig
-	MinIO      MinIOConfig
-	Bycrypt    BycryptConfig
-	Telegram   TelegramConfig
-	Email      EmailConfig
-	Scraper    ScraperConfig
+	App         AppConfig
+	Database    DatabaseConfig
+	Redis       RedisConfig
+	JWT         JWTConfig
+	Centrifugo  CentrifugoConfig
+	MinIO       MinIOConfig
+	Bycrypt     BycryptConfig
+	Telegram    TelegramConfig
+	Email       EmailConfig
+	Scraper     ScraperConfig
+	Comment     CommentConfig
+	Report      ReportConfig
+	Chat        ChatConfig
+	Ticket      TicketConfig
+	ViewHistory ViewHistoryConfig
+	Playback    PlaybackConfig
 }
 type AppConfig struct {
 	AppName           string
@@ -66,6 +72,8 @@ type CentrifugoConfig struct {
 	CentrifugoUrl         string
 	CentrifugoApiKey      string
 	CentrifugoTokenSecret string
+	CentrifugoTokenTTL    time.Duration
+	CentrifugoProxySecret string
 }
 
 type MinIOConfig struct {
@@ -101,6 +109,65 @@ type ScraperConfig struct {
 	Port string
 }
 
+type CommentConfig struct {
+	ModerationQueueAlertThreshold int
+	ModerationAlertCooldown       time.Duration
+	ReputationPendingScore        int
+	ReputationRejectScore         int
+	MaxLinksPerComment            int
+	MaxMentionsPerComment         int
+	StatsCacheTTL                 time.Duration
+	GuestVerificationTTL          time.Duration
+	GuestRateWindow               time.Duration
+	GuestLimitPerEmail            int
+	GuestLimitPerIP               int
+}
+
+type ReportConfig struct {
+	AutoHideThreshold int
+}
+
+// ChatConfig caps chat uploads per media kind. Every cap has to stay below the server's request
+// body limit with room for the multipart framing, or the upload is refused before it is read.
+type ChatConfig struct {
+	MaxImageSize     int64
+	MaxVideoSize     int64
+	MaxAudioSize     int64
+	MaxFileSize      int64
+	ThumbnailMaxSide int
+}
+
+// TicketConfig limits ticket attachments and sets up emailed replies. InboundAddress is the
+// support mailbox; replies go to its plus-addressed form carrying the ticket's reply token.
+// InboundSMTPAddr, when set, is where the worker listens for that mail. SLA breaches are
+// checked every SLACheckInterval and mailed to the assignee, or to SLAEscalationEmail for
+// unassigned tickets. The contact form takes ContactLimitPerEmail messages per sender address
+// every ContactRateWindow.
+type TicketConfig struct {
+	MaxAttachments       int
+	MaxAttachmentSize    int64
+	InboundAddress       string
+	InboundSMTPAddr      string
+	InboundMaxSize       int64
+	SLACheckInterval     time.Duration
+	SLAEscalationEmail   string
+	ContactLimitPerEmail int
+	ContactRateWindow    time.Duration
+}
+
+// ViewHistoryConfig controls view recording: a user's repeated views of the same title within
+// DedupWindow count once.
+type ViewHistoryConfig struct {
+	DedupWindow time.Duration
+}
+
+// PlaybackConfig controls playback progress sync. Heartbeats are held in Redis for ProgressTTL
+// and written to Postgres by the worker every FlushInterval.
+type PlaybackConfig struct {
+	FlushInterval time.Duration
+	ProgressTTL   time.Duration
+}
+
 func Load() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		fmt.Println("No .env file found, using environment variables")
@@ -149,6 +216,8 @@ func Load() (*Config, error) {
 			CentrifugoUrl:         getEnv("CENTRIFUGE_URL", "http://localhost:8000"),
 			CentrifugoApiKey:      getEnv("CENTRIFUGO_API_KEY", "1234567890"),
 			CentrifugoTokenSecret: getEnv("CENTRIFUGO_TOKEN_SECRET", "1234567890"),
+			CentrifugoTokenTTL:    getAsTime("CENTRIFUGO_TOKEN_TTL", 1*time.Hour),
+			CentrifugoProxySecret: getEnv("CENTRIFUGO_PROXY_SECRET", ""),
 		},
 		MinIO: MinIOConfig{
 			MinioEndpoint:        getEnv("MINIO_ENDPOINT", "localhost:9000"),
@@ -178,6 +247,47 @@ func Load() (*Config, error) {
 			Host: getEnv("SCRAPER_HOST", "localhost"),
 			Port: getEnv("SCRAPER_PORT", "50051"),
 		},
+		Comment: CommentConfig{
+			ModerationQueueAlertThreshold: getEnvAsInt("COMMENT_MODERATION_QUEUE_ALERT_THRESHOLD", 50),
+			ModerationAlertCooldown:       getAsTime("COMMENT_MODERATION_ALERT_COOLDOWN", 30*time.Minute),
+			ReputationPendingScore:        getEnvAsInt("COMMENT_REPUTATION_PENDING_SCORE", -5),
+			ReputationRejectScore:         getEnvAsInt("COMMENT_REPUTATION_REJECT_SCORE", -20),
+			MaxLinksPerComment:            getEnvAsInt("COMMENT_MAX_LINKS", 2),
+			MaxMentionsPerComment:         getEnvAsInt("COMMENT_MAX_MENTIONS", 10),
+			StatsCacheTTL:                 getAsTime("COMMENT_STATS_CACHE_TTL", 5*time.Minute),
+			GuestVerificationTTL:          getAsTime("COMMENT_GUEST_VERIFICATION_TTL", 48*time.Hour),
+			GuestRateWindow:               getAsTime("COMMENT_GUEST_RATE_WINDOW", time.Hour),
+			GuestLimitPerEmail:            getEnvAsInt("COMMENT_GUEST_LIMIT_PER_EMAIL", 5),
+			GuestLimitPerIP:               getEnvAsInt("COMMENT_GUEST_LIMIT_PER_IP", 10),
+		},
+		Report: ReportConfig{
+			AutoHideThreshold: getEnvAsInt("REPORT_AUTO_HIDE_THRESHOLD", 3),
+		},
+		Chat: ChatConfig{
+			MaxImageSize:     int64(getEnvAsInt("CHAT_MAX_IMAGE_SIZE_MB", 5)) << 20,
+			MaxVideoSize:     int64(getEnvAsInt("CHAT_MAX_VIDEO_SIZE_MB", 8)) << 20,
+			MaxAudioSize:     int64(getEnvAsInt("CHAT_MAX_AUDIO_SIZE_MB", 8)) << 20,
+			MaxFileSize:      int64(getEnvAsInt("CHAT_MAX_FILE_SIZE_MB", 8)) << 20,
+			ThumbnailMaxSide: getEnvAsInt("CHAT_THUMBNAIL_MAX_SIDE", 320),
+		},
+		Ticket: TicketConfig{
+			MaxAttachments:       getEnvAsInt("TICKET_MAX_ATTACHMENTS", 5),
+			MaxAttachmentSize:    int64(getEnvAsInt("TICKET_MAX_ATTACHMENT_SIZE_MB", 10)) << 20,
+			InboundAddress:       getEnv("TICKET_INBOUND_ADDRESS", ""),
+			InboundSMTPAddr:      getEnv("TICKET_INBOUND_SMTP_ADDR", ""),
+			InboundMaxSize:       int64(getEnvAsInt("TICKET_INBOUND_MAX_SIZE_MB", 10)) << 20,
+			SLACheckInterval:     time.Duration(getEnvAsInt("TICKET_SLA_CHECK_INTERVAL_MINUTES", 5)) * time.Minute,
+			SLAEscalationEmail:   getEnv("TICKET_SLA_ESCALATION_EMAIL", getEnv("APP_ADMIN_EMAIL", "premiumwatchdevice@gmail.com")),
+			ContactLimitPerEmail: getEnvAsInt("TICKET_CONTACT_LIMIT_PER_EMAIL", 3),
+			ContactRateWindow:    time.Duration(getEnvAsInt("TICKET_CONTACT_RATE_WINDOW_MINUTES", 60)) * time.Minute,
+		},
+		ViewHistory: ViewHistoryConfig{
+			DedupWindow: time.Duration(getEnvAsInt("VIEW_HISTORY_DEDUP_WINDOW_MINUTES", 30)) * time.Minute,
+		},
+		Playback: PlaybackConfig{
+			FlushInterval: time.Duration(getEnvAsInt("PLAYBACK_FLUSH_INTERVAL_SECONDS", 30)) * time.Second,
+			ProgressTTL:   time.Duration(getEnvAsInt("PLAYBACK_PROGRESS_TTL_HOURS", 24)) * time.Hour,
+		},
 	}
 
 	return config, nil
@@ -0,0 +1,18 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS update_comment_likes_count ON comment_likes;
+DROP FUNCTION IF EXISTS update_comment_likes_count();
+
+CREATE OR REPLACE FUNCTION update_comments_modtime()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_comments_page_url_likes;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS likes_count;
+
+COMMIT;
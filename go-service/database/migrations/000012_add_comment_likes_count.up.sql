@@ -0,0 +1,47 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS likes_count BIGINT NOT NULL DEFAULT 0;
+
+UPDATE comments c
+SET likes_count = l.total
+FROM (
+    SELECT comment_id, COUNT(*) AS total
+    FROM comment_likes
+    GROUP BY comment_id
+) l
+WHERE l.comment_id = c.id;
+
+CREATE INDEX IF NOT EXISTS idx_comments_page_url_likes ON comments (page_url, likes_count DESC);
+
+-- Keep likes_count in sync with comment_likes
+CREATE OR REPLACE FUNCTION update_comment_likes_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE comments SET likes_count = likes_count + 1 WHERE id = NEW.comment_id;
+        RETURN NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        UPDATE comments SET likes_count = GREATEST(likes_count - 1, 0) WHERE id = OLD.comment_id;
+        RETURN OLD;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS update_comment_likes_count ON comment_likes;
+CREATE TRIGGER update_comment_likes_count
+    AFTER INSERT OR DELETE ON comment_likes
+    FOR EACH ROW
+    EXECUTE FUNCTION update_comment_likes_count();
+
+-- Counter updates should not bump updated_at
+CREATE OR REPLACE FUNCTION update_comments_modtime()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.likes_count IS DISTINCT FROM OLD.likes_count
+        AND (NEW.comment, NEW.media_url, NEW.is_edited, NEW.is_deleted)
+            IS NOT DISTINCT FROM (OLD.comment, OLD.media_url, OLD.is_edited, OLD.is_deleted) THEN
+        RETURN NEW;
+    END IF;
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS update_comment_author_reputations_modtime ON comment_author_reputations;
+DROP TABLE IF EXISTS comment_author_reputations;
+
+DROP INDEX IF EXISTS idx_comments_status_created;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS moderated_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS moderated_by;
+ALTER TABLE comments DROP COLUMN IF EXISTS moderation_reason;
+ALTER TABLE comments DROP COLUMN IF EXISTS status;
+
+COMMIT;
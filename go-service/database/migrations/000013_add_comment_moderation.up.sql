@@ -0,0 +1,28 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'approved'
+    CHECK (status IN ('approved', 'pending', 'rejected'));
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS moderation_reason TEXT;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS moderated_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_comments_status_created ON comments (status, created_at);
+
+-- Per-author moderation history used by the reputation rule and shadow bans
+CREATE TABLE IF NOT EXISTS comment_author_reputations (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    score INTEGER NOT NULL DEFAULT 0,
+    approved_count INTEGER NOT NULL DEFAULT 0,
+    rejected_count INTEGER NOT NULL DEFAULT 0,
+    is_shadow_banned BOOLEAN NOT NULL DEFAULT FALSE,
+    shadow_banned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    shadow_banned_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_author_reputations_shadow_banned
+    ON comment_author_reputations (user_id) WHERE is_shadow_banned = TRUE;
+
+CREATE TRIGGER update_comment_author_reputations_modtime
+    BEFORE UPDATE ON comment_author_reputations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_modified_column();
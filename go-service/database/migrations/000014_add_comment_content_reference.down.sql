@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_comments_page_url_created;
+DROP INDEX IF EXISTS idx_comments_content_created;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS content_title;
+ALTER TABLE comments DROP COLUMN IF EXISTS content_id;
+ALTER TABLE comments DROP COLUMN IF EXISTS content_type;
+
+COMMIT;
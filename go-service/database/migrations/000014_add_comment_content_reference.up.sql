@@ -0,0 +1,10 @@
+-- Identify the title a comment belongs to so statistics can roll up across episode pages
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS content_type VARCHAR(20)
+    CHECK (content_type IN ('movies', 'series', 'anime'));
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS content_id TEXT;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS content_title TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_comments_content_created
+    ON comments (content_type, content_id, created_at DESC)
+    WHERE content_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_page_url_created ON comments (page_url, created_at DESC);
@@ -0,0 +1,19 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS attach_guest_comments_on_user_verify ON users;
+DROP TRIGGER IF EXISTS attach_guest_comments_on_user_insert ON users;
+DROP FUNCTION IF EXISTS attach_guest_comments();
+
+DROP INDEX IF EXISTS idx_comments_guest_email;
+
+DELETE FROM comments WHERE user_id IS NULL;
+
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_author_check;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_status_check;
+ALTER TABLE comments ADD CONSTRAINT comments_status_check
+    CHECK (status IN ('approved', 'pending', 'rejected'));
+
+ALTER TABLE comments DROP COLUMN IF EXISTS guest_verified_at;
+ALTER TABLE comments ALTER COLUMN user_id SET NOT NULL;
+
+COMMIT;
@@ -0,0 +1,38 @@
+-- Guests comment with a name and email instead of an account
+ALTER TABLE comments ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS guest_verified_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_status_check;
+ALTER TABLE comments ADD CONSTRAINT comments_status_check
+    CHECK (status IN ('approved', 'pending', 'rejected', 'unverified'));
+ALTER TABLE comments ADD CONSTRAINT comments_author_check
+    CHECK (user_id IS NOT NULL OR (name IS NOT NULL AND email IS NOT NULL));
+
+CREATE INDEX IF NOT EXISTS idx_comments_guest_email
+    ON comments (LOWER(email)) WHERE user_id IS NULL;
+
+-- Verified guest comments move to the account once a user verifies the same email
+CREATE OR REPLACE FUNCTION attach_guest_comments()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.is_verified AND NEW.deleted_at IS NULL THEN
+        UPDATE comments
+        SET user_id = NEW.id
+        WHERE user_id IS NULL
+          AND guest_verified_at IS NOT NULL
+          AND LOWER(email) = LOWER(NEW.email);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attach_guest_comments_on_user_insert
+    AFTER INSERT ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION attach_guest_comments();
+
+CREATE TRIGGER attach_guest_comments_on_user_verify
+    AFTER UPDATE OF is_verified, email ON users
+    FOR EACH ROW
+    WHEN (NEW.is_verified AND (NOT OLD.is_verified OR OLD.email IS DISTINCT FROM NEW.email))
+    EXECUTE FUNCTION attach_guest_comments();
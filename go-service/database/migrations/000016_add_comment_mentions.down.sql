@@ -0,0 +1,15 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS update_comment_notification_settings_modtime ON comment_notification_settings;
+DROP TABLE IF EXISTS comment_notification_settings;
+DROP TABLE IF EXISTS comment_notifications;
+DROP TABLE IF EXISTS comment_mentions;
+
+DROP TRIGGER IF EXISTS set_default_username_on_insert ON users;
+DROP FUNCTION IF EXISTS set_default_username();
+DROP FUNCTION IF EXISTS generate_username(TEXT);
+
+DROP INDEX IF EXISTS idx_users_username_lower;
+ALTER TABLE users DROP COLUMN IF EXISTS username;
+
+COMMIT;
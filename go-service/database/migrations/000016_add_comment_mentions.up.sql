@@ -0,0 +1,84 @@
+-- Handles used for @mentions
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username VARCHAR(32);
+
+CREATE OR REPLACE FUNCTION generate_username(source_email TEXT)
+RETURNS TEXT AS $$
+DECLARE
+    base TEXT;
+    candidate TEXT;
+BEGIN
+    base := LEFT(REGEXP_REPLACE(LOWER(SPLIT_PART(source_email, '@', 1)), '[^a-z0-9_]', '', 'g'), 24);
+    IF LENGTH(base) < 3 THEN
+        base := 'user' || base;
+    END IF;
+
+    candidate := base;
+    WHILE EXISTS (SELECT 1 FROM users WHERE LOWER(username) = candidate) LOOP
+        candidate := base || '_' || SUBSTR(MD5(RANDOM()::TEXT), 1, 6);
+    END LOOP;
+    RETURN candidate;
+END;
+$$ LANGUAGE plpgsql;
+
+DO $$
+DECLARE
+    u RECORD;
+BEGIN
+    FOR u IN SELECT id, email FROM users WHERE username IS NULL ORDER BY created_at LOOP
+        UPDATE users SET username = generate_username(u.email) WHERE id = u.id;
+    END LOOP;
+END;
+$$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (LOWER(username));
+
+CREATE OR REPLACE FUNCTION set_default_username()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.username IS NULL OR NEW.username = '' THEN
+        NEW.username := generate_username(NEW.email);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER set_default_username_on_insert
+    BEFORE INSERT ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION set_default_username();
+
+-- Users resolved from @mentions in a comment
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_mentions_user ON comment_mentions (user_id, created_at DESC);
+
+-- One row per recipient and comment, so a comment never notifies the same user twice
+CREATE TABLE IF NOT EXISTS comment_notifications (
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('mention', 'reply')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_notifications_user ON comment_notifications (user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS comment_notification_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    mute_mentions BOOLEAN NOT NULL DEFAULT FALSE,
+    mute_replies BOOLEAN NOT NULL DEFAULT FALSE,
+    email_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    muted_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER update_comment_notification_settings_modtime
+    BEFORE UPDATE ON comment_notification_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_modified_column();
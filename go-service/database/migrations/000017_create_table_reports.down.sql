@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE chat_messages DROP COLUMN IF EXISTS is_hidden;
+
+DROP TRIGGER IF EXISTS update_reports_modtime ON reports;
+DROP FUNCTION IF EXISTS update_reports_modtime();
+DROP TABLE IF EXISTS reports;
+
+COMMIT;
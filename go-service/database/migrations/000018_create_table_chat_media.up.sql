@@ -0,0 +1,24 @@
+-- Up Migration
+-- Uploaded chat attachments. Rows stay pending (message_id IS NULL) until a message claims them.
+CREATE TABLE IF NOT EXISTS chat_media (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    room_id UUID NOT NULL REFERENCES chat_rooms(id) ON DELETE CASCADE,
+    uploader_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message_id UUID REFERENCES chat_messages(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('image', 'video', 'audio', 'file')),
+    object_key TEXT NOT NULL,
+    url TEXT NOT NULL,
+    name TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    width INTEGER,
+    height INTEGER,
+    duration INTEGER,
+    thumbnail_key TEXT,
+    thumbnail TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_media_room ON chat_media(room_id);
+CREATE INDEX IF NOT EXISTS idx_chat_media_pending ON chat_media(created_at) WHERE message_id IS NULL;
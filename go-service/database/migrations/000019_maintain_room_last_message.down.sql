@@ -0,0 +1,20 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS update_room_last_message_delete ON chat_messages;
+DROP TRIGGER IF EXISTS update_room_last_message_visibility ON chat_messages;
+
+CREATE OR REPLACE FUNCTION update_room_last_message()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE chat_rooms 
+        SET last_message_id = NEW.id,
+            last_message_at = NEW.created_at,
+            updated_at = NOW()
+        WHERE id = NEW.room_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,60 @@
+-- Up Migration
+-- Keep chat_rooms.last_message_id pointing at the newest visible message. Inserts still move it
+-- forward; deleting, hiding or restoring a message recomputes it for the room.
+CREATE OR REPLACE FUNCTION update_room_last_message()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.is_deleted IS NOT TRUE AND NEW.is_hidden IS NOT TRUE THEN
+            UPDATE chat_rooms
+            SET last_message_id = NEW.id,
+                last_message_at = NEW.created_at,
+                updated_at = NOW()
+            WHERE id = NEW.room_id;
+        END IF;
+        RETURN NEW;
+    END IF;
+
+    UPDATE chat_rooms cr
+    SET (last_message_id, last_message_at) = (
+        SELECT m.id, m.created_at
+        FROM chat_messages m
+        WHERE m.room_id = cr.id AND m.is_deleted IS NOT TRUE AND m.is_hidden IS NOT TRUE
+        ORDER BY m.created_at DESC, m.id DESC
+        LIMIT 1
+    )
+    WHERE cr.id = OLD.room_id;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS update_room_last_message_visibility ON chat_messages;
+CREATE TRIGGER update_room_last_message_visibility
+    AFTER UPDATE OF is_deleted, is_hidden ON chat_messages
+    FOR EACH ROW
+    WHEN (OLD.is_deleted IS DISTINCT FROM NEW.is_deleted OR OLD.is_hidden IS DISTINCT FROM NEW.is_hidden)
+    EXECUTE FUNCTION update_room_last_message();
+
+DROP TRIGGER IF EXISTS update_room_last_message_delete ON chat_messages;
+CREATE TRIGGER update_room_last_message_delete
+    AFTER DELETE ON chat_messages
+    FOR EACH ROW
+    EXECUTE FUNCTION update_room_last_message();
+
+-- Rooms whose last message was already deleted or hidden before this migration.
+UPDATE chat_rooms cr
+SET (last_message_id, last_message_at) = (
+    SELECT m.id, m.created_at
+    FROM chat_messages m
+    WHERE m.room_id = cr.id AND m.is_deleted IS NOT TRUE AND m.is_hidden IS NOT TRUE
+    ORDER BY m.created_at DESC, m.id DESC
+    LIMIT 1
+)
+WHERE EXISTS (
+    SELECT 1 FROM chat_messages lm
+    WHERE lm.id = cr.last_message_id AND (lm.is_deleted IS TRUE OR lm.is_hidden IS TRUE)
+);
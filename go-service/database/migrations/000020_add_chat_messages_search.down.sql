@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_chat_messages_message_fts;
+
+COMMIT;
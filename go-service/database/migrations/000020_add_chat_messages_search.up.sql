@@ -0,0 +1,6 @@
+-- Up Migration
+-- Full-text search over chat messages. The 'simple' configuration avoids language-specific
+-- stemming because rooms mix languages.
+CREATE INDEX IF NOT EXISTS idx_chat_messages_message_fts
+    ON chat_messages USING gin (to_tsvector('simple', COALESCE(message, '')))
+    WHERE is_deleted IS NOT TRUE;
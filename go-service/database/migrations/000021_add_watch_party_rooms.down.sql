@@ -0,0 +1,9 @@
+BEGIN;
+
+DELETE FROM chat_rooms WHERE type = 'watch_party';
+
+ALTER TABLE chat_rooms DROP CONSTRAINT IF EXISTS chat_rooms_type_check;
+ALTER TABLE chat_rooms
+    ADD CONSTRAINT chat_rooms_type_check CHECK (type IN ('personal', 'group', 'channel'));
+
+COMMIT;
@@ -0,0 +1,6 @@
+-- Up Migration
+-- Watch parties are chat rooms tied to a movie or episode. The content they play is kept in
+-- chat_rooms.metadata; live playback state lives in Redis.
+ALTER TABLE chat_rooms DROP CONSTRAINT IF EXISTS chat_rooms_type_check;
+ALTER TABLE chat_rooms
+    ADD CONSTRAINT chat_rooms_type_check CHECK (type IN ('personal', 'group', 'channel', 'watch_party'));
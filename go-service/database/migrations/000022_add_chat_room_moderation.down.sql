@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS chat_room_bans;
+
+ALTER TABLE chat_room_participants DROP COLUMN IF EXISTS muted_until;
+ALTER TABLE chat_rooms DROP COLUMN IF EXISTS slow_mode_seconds;
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- Up Migration
+-- Room moderation: per-room slow mode, timed member mutes and bans. A ban with a NULL
+-- expires_at is permanent.
+ALTER TABLE chat_rooms
+    ADD COLUMN IF NOT EXISTS slow_mode_seconds INTEGER NOT NULL DEFAULT 0
+        CHECK (slow_mode_seconds BETWEEN 0 AND 21600);
+
+ALTER TABLE chat_room_participants ADD COLUMN IF NOT EXISTS muted_until TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS chat_room_bans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    room_id UUID NOT NULL REFERENCES chat_rooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    banned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(room_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_room_bans_user_id ON chat_room_bans(user_id);
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS subscribe_user_to_channels ON users;
+DROP FUNCTION IF EXISTS subscribe_user_to_channels();
+
+DROP TABLE IF EXISTS user_devices;
+DROP TABLE IF EXISTS chat_announcements;
+
+COMMIT;
@@ -0,0 +1,61 @@
+-- Up Migration
+-- Channel rooms are admin-run broadcast channels. Every user is a participant, so the
+-- regular chat history, unread counts and mute preferences apply to them unchanged.
+CREATE TABLE IF NOT EXISTS chat_announcements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    room_id UUID NOT NULL REFERENCES chat_rooms(id) ON DELETE CASCADE,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    link TEXT,
+    send_push BOOLEAN NOT NULL DEFAULT FALSE,
+    send_email BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'sending', 'sent', 'cancelled', 'failed')),
+    scheduled_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    message_id UUID REFERENCES chat_messages(id) ON DELETE SET NULL,
+    push_count INT NOT NULL DEFAULT 0,
+    email_count INT NOT NULL DEFAULT 0,
+    failure_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_announcements_room ON chat_announcements(room_id, scheduled_at DESC);
+CREATE INDEX IF NOT EXISTS idx_chat_announcements_status ON chat_announcements(status, scheduled_at);
+
+CREATE TRIGGER update_chat_announcements_modtime
+    BEFORE UPDATE ON chat_announcements
+    FOR EACH ROW
+    EXECUTE FUNCTION update_modified_column();
+
+-- Push tokens, tagged with the platform and settings scope they registered from.
+CREATE TABLE IF NOT EXISTS user_devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token TEXT NOT NULL UNIQUE,
+    platform VARCHAR(20) NOT NULL CHECK (platform IN ('web', 'mobile')),
+    scope VARCHAR(100) NOT NULL DEFAULT 'default',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_devices_user_id ON user_devices(user_id);
+
+-- New users join every live broadcast channel.
+CREATE OR REPLACE FUNCTION subscribe_user_to_channels()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO chat_room_participants (room_id, user_id, role)
+    SELECT id, NEW.id, 'member'
+    FROM chat_rooms
+    WHERE type = 'channel' AND is_archived IS NOT TRUE
+    ON CONFLICT (room_id, user_id) DO NOTHING;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER subscribe_user_to_channels
+    AFTER INSERT ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION subscribe_user_to_channels();
@@ -0,0 +1,21 @@
+BEGIN;
+
+DROP TABLE IF EXISTS ticket_attachments;
+DROP TABLE IF EXISTS ticket_replies;
+
+DROP INDEX IF EXISTS idx_tickets_status_priority;
+DROP INDEX IF EXISTS idx_tickets_assignee;
+
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_priority_check;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_status_check;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_number_key;
+
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS closed_at,
+    DROP COLUMN IF EXISTS resolved_at,
+    DROP COLUMN IF EXISTS last_reply_at,
+    DROP COLUMN IF EXISTS assignee_id,
+    DROP COLUMN IF EXISTS priority,
+    DROP COLUMN IF EXISTS number;
+
+COMMIT;
@@ -0,0 +1,50 @@
+-- Up Migration
+-- Ticket numbers, priority, assignment and workflow timestamps
+ALTER TABLE tickets
+    ADD COLUMN IF NOT EXISTS number VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS priority VARCHAR(20) NOT NULL DEFAULT 'normal',
+    ADD COLUMN IF NOT EXISTS assignee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS last_reply_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP WITH TIME ZONE;
+
+-- Backfill numbers in the same shape as utils.GenerateTaskNumber
+UPDATE tickets
+SET number = 'task-' || LEFT(id::text, 24) || TO_HEX((EXTRACT(EPOCH FROM created_at) * 1000000000)::BIGINT)
+WHERE number IS NULL;
+
+ALTER TABLE tickets ALTER COLUMN number SET NOT NULL;
+ALTER TABLE tickets ADD CONSTRAINT tickets_number_key UNIQUE (number);
+ALTER TABLE tickets ADD CONSTRAINT tickets_status_check CHECK (status IN ('open', 'resolved', 'closed'));
+ALTER TABLE tickets ADD CONSTRAINT tickets_priority_check CHECK (priority IN ('low', 'normal', 'high', 'urgent'));
+
+CREATE INDEX IF NOT EXISTS idx_tickets_assignee ON tickets(assignee_id) WHERE assignee_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tickets_status_priority ON tickets(status, priority, created_at);
+
+-- Threaded replies from the ticket owner and from staff
+CREATE TABLE IF NOT EXISTS ticket_replies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticket_id UUID NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    is_staff BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_replies_ticket ON ticket_replies(ticket_id, created_at);
+
+-- Files attached to the opening message (reply_id IS NULL) or to a reply
+CREATE TABLE IF NOT EXISTS ticket_attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticket_id UUID NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    reply_id UUID REFERENCES ticket_replies(id) ON DELETE CASCADE,
+    uploader_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    object_key TEXT NOT NULL,
+    url TEXT NOT NULL,
+    name TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_attachments_ticket ON ticket_attachments(ticket_id);
@@ -0,0 +1,25 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_ticket_replies_email_message;
+ALTER TABLE ticket_replies DROP CONSTRAINT IF EXISTS ticket_replies_via_check;
+ALTER TABLE ticket_replies
+    DROP COLUMN IF EXISTS email_message_id,
+    DROP COLUMN IF EXISTS via;
+
+DROP INDEX IF EXISTS idx_tickets_contact_email;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_requester_check;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_source_check;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_reply_token_key;
+
+-- Tickets from visitors without an account cannot be kept once user_id is required again
+DELETE FROM tickets WHERE user_id IS NULL;
+
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS reply_token,
+    DROP COLUMN IF EXISTS contact_email,
+    DROP COLUMN IF EXISTS contact_name,
+    DROP COLUMN IF EXISTS source;
+
+ALTER TABLE tickets ALTER COLUMN user_id SET NOT NULL;
+
+COMMIT;
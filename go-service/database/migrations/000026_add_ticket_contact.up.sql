@@ -0,0 +1,29 @@
+-- Up Migration
+-- Contact form tickets may come from visitors without an account; they are tied to the
+-- email address they were sent from instead. Every ticket gets a reply token that threads
+-- emailed replies sent to support+<token>@<inbound domain>.
+ALTER TABLE tickets ALTER COLUMN user_id DROP NOT NULL;
+
+ALTER TABLE tickets
+    ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'app',
+    ADD COLUMN IF NOT EXISTS contact_name VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS contact_email VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS reply_token VARCHAR(64);
+
+UPDATE tickets SET reply_token = REPLACE(gen_random_uuid()::text, '-', '') WHERE reply_token IS NULL;
+
+ALTER TABLE tickets ALTER COLUMN reply_token SET NOT NULL;
+ALTER TABLE tickets ADD CONSTRAINT tickets_reply_token_key UNIQUE (reply_token);
+ALTER TABLE tickets ADD CONSTRAINT tickets_source_check CHECK (source IN ('app', 'contact'));
+ALTER TABLE tickets ADD CONSTRAINT tickets_requester_check CHECK (user_id IS NOT NULL OR contact_email IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_tickets_contact_email ON tickets(LOWER(contact_email)) WHERE contact_email IS NOT NULL;
+
+-- Replies that arrived by email keep their Message-ID so redelivered mail is threaded once
+ALTER TABLE ticket_replies
+    ADD COLUMN IF NOT EXISTS via VARCHAR(20) NOT NULL DEFAULT 'app',
+    ADD COLUMN IF NOT EXISTS email_message_id VARCHAR(998);
+
+ALTER TABLE ticket_replies ADD CONSTRAINT ticket_replies_via_check CHECK (via IN ('app', 'email'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ticket_replies_email_message ON ticket_replies(email_message_id) WHERE email_message_id IS NOT NULL;
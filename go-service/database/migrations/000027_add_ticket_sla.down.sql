@@ -0,0 +1,15 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS update_ticket_sla_policies_modtime ON ticket_sla_policies;
+DROP TABLE IF EXISTS ticket_sla_policies;
+
+DROP INDEX IF EXISTS idx_tickets_open_created;
+DROP INDEX IF EXISTS idx_tickets_category;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_category_check;
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS sla_resolution_escalated_at,
+    DROP COLUMN IF EXISTS sla_response_escalated_at,
+    DROP COLUMN IF EXISTS first_response_at,
+    DROP COLUMN IF EXISTS category;
+
+COMMIT;
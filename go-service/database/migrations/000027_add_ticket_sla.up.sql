@@ -0,0 +1,51 @@
+-- Up Migration
+-- Ticket categories and SLA policies. A policy sets the first-response and resolution targets
+-- for a category and priority; a policy without a category is the default for its priority.
+ALTER TABLE tickets
+    ADD COLUMN IF NOT EXISTS category VARCHAR(30) NOT NULL DEFAULT 'general',
+    ADD COLUMN IF NOT EXISTS first_response_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS sla_response_escalated_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS sla_resolution_escalated_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE tickets ADD CONSTRAINT tickets_category_check
+    CHECK (category IN ('general', 'account', 'billing', 'playback', 'content', 'technical'));
+
+-- The first staff reply on existing tickets counts as their first response
+UPDATE tickets t SET first_response_at = r.first_reply
+FROM (
+    SELECT ticket_id, MIN(created_at) AS first_reply
+    FROM ticket_replies
+    WHERE is_staff
+    GROUP BY ticket_id
+) r
+WHERE r.ticket_id = t.id AND t.first_response_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tickets_category ON tickets(category);
+CREATE INDEX IF NOT EXISTS idx_tickets_open_created ON tickets(created_at) WHERE status = 'open';
+
+CREATE TABLE IF NOT EXISTS ticket_sla_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    category VARCHAR(30),
+    priority VARCHAR(20) NOT NULL CHECK (priority IN ('low', 'normal', 'high', 'urgent')),
+    first_response_minutes INTEGER NOT NULL CHECK (first_response_minutes > 0),
+    resolution_minutes INTEGER NOT NULL CHECK (resolution_minutes > 0),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT ticket_sla_policies_category_check
+        CHECK (category IS NULL OR category IN ('general', 'account', 'billing', 'playback', 'content', 'technical'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ticket_sla_policies_scope ON ticket_sla_policies(COALESCE(category, ''), priority);
+
+CREATE TRIGGER update_ticket_sla_policies_modtime
+    BEFORE UPDATE ON ticket_sla_policies
+    FOR EACH ROW
+    EXECUTE FUNCTION update_modified_column();
+
+INSERT INTO ticket_sla_policies (category, priority, first_response_minutes, resolution_minutes) VALUES
+    (NULL, 'urgent', 60, 480),
+    (NULL, 'high', 240, 1440),
+    (NULL, 'normal', 480, 4320),
+    (NULL, 'low', 1440, 10080)
+ON CONFLICT DO NOTHING;
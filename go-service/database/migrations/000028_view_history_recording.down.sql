@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_view_history_user_title;
+ALTER TABLE view_histories
+    DROP COLUMN IF EXISTS browser,
+    DROP COLUMN IF EXISTS slug;
+
+ALTER TABLE view_histories DROP CONSTRAINT IF EXISTS view_histories_type_check;
+UPDATE view_histories SET type = 'animes' WHERE type = 'anime';
+ALTER TABLE view_histories ADD CONSTRAINT view_histories_type_check
+    CHECK (type IN ('movies', 'series', 'animes'));
+
+COMMIT;
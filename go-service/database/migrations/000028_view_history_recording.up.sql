@@ -0,0 +1,16 @@
+-- Up Migration
+-- Views are recorded per title, so each row keeps the title slug next to the page it was
+-- watched on, plus the browser parsed from the user agent. The type values now match the
+-- application ('anime' instead of 'animes').
+UPDATE view_histories SET type = 'anime' WHERE type = 'animes';
+
+ALTER TABLE view_histories DROP CONSTRAINT IF EXISTS view_histories_type_check;
+ALTER TABLE view_histories ADD CONSTRAINT view_histories_type_check
+    CHECK (type IN ('movies', 'series', 'anime'));
+
+ALTER TABLE view_histories
+    ADD COLUMN IF NOT EXISTS slug TEXT,
+    ADD COLUMN IF NOT EXISTS browser TEXT;
+
+-- Backs the dedup lookup for a user's latest view of a title
+CREATE INDEX IF NOT EXISTS idx_view_history_user_title ON view_histories (user_id, type, slug, view_time DESC);
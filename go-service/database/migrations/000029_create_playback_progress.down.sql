@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS update_playback_progress_modtime ON playback_progress;
+DROP INDEX IF EXISTS idx_playback_progress_user_watched;
+DROP TABLE IF EXISTS playback_progress;
+
+COMMIT;
@@ -0,0 +1,29 @@
+-- Up Migration
+-- Where each user is in each title, for continue-watching and resuming on another device. One
+-- row per user and title holds the episode being watched; next_episode_* is resolved by the
+-- worker when it flushes the progress the player reported.
+CREATE TABLE IF NOT EXISTS playback_progress (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL CHECK (type IN ('movies', 'series', 'anime')),
+    slug TEXT NOT NULL,
+    name TEXT,
+    episode_number INTEGER CHECK (episode_number > 0),
+    episode_url TEXT,
+    position_seconds INTEGER NOT NULL DEFAULT 0 CHECK (position_seconds >= 0),
+    duration_seconds INTEGER NOT NULL DEFAULT 0 CHECK (duration_seconds >= 0),
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    next_episode_number INTEGER,
+    next_episode_url TEXT,
+    watched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (user_id, type, slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_playback_progress_user_watched ON playback_progress (user_id, watched_at DESC);
+
+CREATE TRIGGER update_playback_progress_modtime
+    BEFORE UPDATE ON playback_progress
+    FOR EACH ROW
+    EXECUTE FUNCTION update_modified_column();
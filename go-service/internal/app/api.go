@@ -38,4 +38,25 @@ func RegisterApiRoutes(router fiber.Router, cont *dependencies.Container, mw *fa
 
 	clientFactory := factory.NewClientFactory(cont, mw)
 	clientFactory.GetRoutes(router)
+
+	realtimeFactory := factory.NewRealtimeFactory(cont, mw)
+	realtimeFactory.GetRoutes(router)
+
+	commentFactory := factory.NewCommentFactory(cont, mw)
+	commentFactory.GetRoutes(router)
+
+	chatFactory := factory.NewChatFactory(cont, mw)
+	chatFactory.GetRoutes(router)
+
+	watchPartyFactory := factory.NewWatchPartyFactory(cont, mw)
+	watchPartyFactory.GetRoutes(router)
+
+	broadcastFactory := factory.NewBroadcastFactory(cont, mw)
+	broadcastFactory.GetRoutes(router)
+
+	reportFactory := factory.NewReportFactory(cont, mw)
+	reportFactory.GetRoutes(router)
+
+	ticketFactory := factory.NewTicketFactory(cont, mw)
+	ticketFactory.GetRoutes(router)
 }
@@ -15,9 +15,27 @@ import (
 
 var App *fiber.App
 
+const (
+	bodyLimit = 10 * 1024 * 1024 // 10MB global limit
+	// multipartHeadroom is what a single-file multipart request adds on top of the file itself.
+	multipartHeadroom = 512 * 1024
+)
+
 func Start(cont *dependencies.Container) {
+	if cont.AppConfig.Centrifugo.CentrifugoProxySecret == "" {
+		cont.Logger.Warn("CENTRIFUGO_PROXY_SECRET is not set, Centrifugo proxy calls will be rejected")
+	}
+
+	chat := cont.AppConfig.Chat
+	if maxUpload := max(chat.MaxImageSize, chat.MaxVideoSize, chat.MaxAudioSize, chat.MaxFileSize); maxUpload+multipartHeadroom > bodyLimit {
+		cont.Logger.Warn("Chat upload limit does not fit in the request body limit, the largest uploads will be refused",
+			zap.Int64("chat_max_upload", maxUpload),
+			zap.Int64("body_limit", bodyLimit),
+		)
+	}
+
 	App = fiber.New(fiber.Config{
-		BodyLimit:    10 * 1024 * 1024, // 10MB global limit
+		BodyLimit:    bodyLimit,
 		AppName:      cont.AppConfig.App.AppName,
 		ProxyHeader:  fiber.HeaderXForwardedFor,
 		WriteTimeout: 10 * time.Second,
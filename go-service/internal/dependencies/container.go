@@ -40,6 +40,14 @@ type Container struct {
 	UserRepo         repository.UserRepository
 	SettingRepo      repository.SettingRepository
 	ApplicationRepo  repository.ApplicationRepository
+	ChatRoomRepo     repository.ChatRoomRepository
+	CommentRepo      repository.CommentRepository
+	ReportRepo       repository.ReportRepository
+	BroadcastRepo    repository.BroadcastRepository
+	TicketRepo       repository.TicketRepository
+	TicketSLARepo    repository.TicketSLARepository
+	ViewHistoryRepo  repository.ViewHistoryRepository
+	PlaybackRepo     repository.PlaybackProgressRepository
 	CacheHelper      *helpers.CacheHelper
 	UserHelper       *helpers.UserHelper
 	SessionHelper    *helpers.SessionHelper
@@ -101,6 +109,14 @@ func NewContainer(ctx context.Context) (*Container, error) {
 	userRepo := repository.NewUserRepository(dbPool.Pool, logger)
 	settingRepo := repository.NewSettingRepository(dbPool.Pool, logger)
 	applicationRepo := repository.NewApplicationRepository(dbPool.Pool, logger)
+	chatRoomRepo := repository.NewChatRoomRepository(dbPool.Pool, logger)
+	commentRepo := repository.NewCommentRepository(dbPool.Pool, logger)
+	reportRepo := repository.NewReportRepository(dbPool.Pool, logger)
+	broadcastRepo := repository.NewBroadcastRepository(dbPool.Pool, logger)
+	ticketRepo := repository.NewTicketRepository(dbPool.Pool, logger)
+	ticketSLARepo := repository.NewTicketSLARepository(dbPool.Pool, logger)
+	viewHistoryRepo := repository.NewViewHistoryRepository(dbPool.Pool, logger)
+	playbackRepo := repository.NewPlaybackProgressRepository(dbPool.Pool, logger)
 
 	// Initialize helper
 	cacheHelper := helpers.NewCacheHelper(logger, redis, applicationRepo, settingRepo)
@@ -128,6 +144,14 @@ func NewContainer(ctx context.Context) (*Container, error) {
 		UserRepo:         userRepo,
 		SettingRepo:      settingRepo,
 		ApplicationRepo:  applicationRepo,
+		ChatRoomRepo:     chatRoomRepo,
+		CommentRepo:      commentRepo,
+		ReportRepo:       reportRepo,
+		BroadcastRepo:    broadcastRepo,
+		TicketRepo:       ticketRepo,
+		TicketSLARepo:    ticketSLARepo,
+		ViewHistoryRepo:  viewHistoryRepo,
+		PlaybackRepo:     playbackRepo,
 		CacheHelper:      cacheHelper,
 		UserHelper:       userHelper,
 		SessionHelper:    sessionHelper,
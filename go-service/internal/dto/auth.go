@@ -16,6 +16,18 @@ var (
 	ResetPassword     TypeVerify = "reset_password"
 	EmailVerification TypeVerify = "email_verification"
 	RegistrationInfo  TypeVerify = "registration_info"
+
+	GuestCommentVerification TypeVerify = "guest_comment_verification"
+	CommentMentionNotice     TypeVerify = "comment_mention"
+	CommentReplyNotice       TypeVerify = "comment_reply"
+
+	BroadcastAnnouncementNotice TypeVerify = "broadcast_announcement"
+
+	TicketAcknowledgement TypeVerify = "ticket_acknowledgement"
+	TicketReplyNotice     TypeVerify = "ticket_reply"
+	TicketStatusNotice    TypeVerify = "ticket_status"
+	TicketAssignedNotice  TypeVerify = "ticket_assigned"
+	TicketSLABreachNotice TypeVerify = "ticket_sla_breach"
 )
 
 var (
@@ -82,12 +94,19 @@ type VerifyTwoFactorRequest struct {
 }
 
 type SendMailMetaData struct {
-	Token     string       `json:"token"`
-	Type      TypeVerify   `json:"type"`
-	To        string       `json:"to"`
-	User      *entity.User `json:"user,omitempty"`
-	Password  string       `json:"password,omitempty"`
-	ExpiredAt time.Time    `json:"expired_at"`
+	Token        string                   `json:"token"`
+	Type         TypeVerify               `json:"type"`
+	To           string                   `json:"to"`
+	User         *entity.User             `json:"user,omitempty"`
+	Password     string                   `json:"password,omitempty"`
+	Comment      *entity.Comment          `json:"comment,omitempty"`
+	Announcement *entity.ChatAnnouncement `json:"announcement,omitempty"`
+	Ticket       *entity.Ticket           `json:"ticket,omitempty"`
+	TicketReply  *entity.TicketReply      `json:"ticket_reply,omitempty"`
+	Link         string                   `json:"link,omitempty"`
+	ReplyTo      string                   `json:"reply_to,omitempty"`
+	SLABreach    *entity.TicketSLABreach  `json:"sla_breach,omitempty"`
+	ExpiredAt    time.Time                `json:"expired_at"`
 }
 
 func (m *SendMailMetaData) GetURL(origin string) string {
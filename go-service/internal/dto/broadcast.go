@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBroadcastChannelNotFound = errors.New("broadcast channel not found")
+	ErrAnnouncementNotFound     = errors.New("announcement not found")
+	ErrAnnouncementNotScheduled = errors.New("only scheduled announcements can be cancelled")
+	ErrAnnouncementInPast       = errors.New("scheduled_at must not be in the past")
+	ErrAnnouncementTooFar       = errors.New("scheduled_at must be within 90 days")
+	ErrDevicePlatformRequired   = errors.New("X-Platform header is required to register a device")
+)
+
+// TaskBroadcastAnnouncement delivers a scheduled announcement. It is enqueued with
+// EnqueueTaskAt for the announcement's scheduled_at and handled by the worker.
+const TaskBroadcastAnnouncement = "broadcast:announcement"
+
+type BroadcastAnnouncementTask struct {
+	AnnouncementID uuid.UUID `json:"announcement_id"`
+}
+
+// BroadcastChannelCreateRequest opens a broadcast channel. Scope and Platforms narrow who sees
+// the channel and who receives its push notifications; leave them empty to reach everyone.
+type BroadcastChannelCreateRequest struct {
+	Name        string   `json:"name" validate:"required,max=255"`
+	Description string   `json:"description,omitempty" validate:"omitempty,max=1000"`
+	AvatarURL   string   `json:"avatar_url,omitempty" validate:"omitempty,url,max=2048"`
+	Scope       string   `json:"scope,omitempty" validate:"omitempty,max=100"`
+	Platforms   []string `json:"platforms,omitempty" validate:"omitempty,max=2,dive,oneof=web mobile"`
+}
+
+// AnnouncementCreateRequest schedules an announcement. ScheduledAt defaults to now.
+type AnnouncementCreateRequest struct {
+	Title       string     `json:"title" validate:"required,max=255"`
+	Body        string     `json:"body" validate:"required,max=4000"`
+	Link        string     `json:"link,omitempty" validate:"omitempty,url,max=2048"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	SendPush    bool       `json:"send_push"`
+	SendEmail   bool       `json:"send_email"`
+}
+
+type DeviceRegisterRequest struct {
+	Token string `json:"token" validate:"required,max=4096"`
+}
@@ -0,0 +1,150 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"tubexxi/video-api/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrChatMessageNotFound    = errors.New("chat message not found")
+	ErrChatRoomNotFound       = errors.New("chat room not found")
+	ErrChatNotParticipant     = errors.New("you are not a participant of this room")
+	ErrChatRoomRequired       = errors.New("exactly one of receiver_id or group_id is required")
+	ErrChatInvalidRecipient   = errors.New("recipient not found or unavailable")
+	ErrChatPersonalRoomSize   = errors.New("personal rooms take exactly one other participant")
+	ErrChatRoomNameRequired   = errors.New("name is required for group and channel rooms")
+	ErrChatPersonalRoomFixed  = errors.New("participants of a personal room cannot be changed")
+	ErrChatChannelMembership  = errors.New("broadcast channel subscriptions are managed automatically")
+	ErrChatPostForbidden      = errors.New("only admins and moderators can post in this channel")
+	ErrChatMessageEmpty       = errors.New("message text is required")
+	ErrChatMessageTooLong     = errors.New("message is too long")
+	ErrChatPayloadRequired    = errors.New("location or contact details are required for this message type")
+	ErrChatMediaRequired      = errors.New("media is required for this message type")
+	ErrChatMediaNotFound      = errors.New("media not found or already attached")
+	ErrChatMediaTypeMismatch  = errors.New("message type does not match the uploaded media")
+	ErrChatMediaTooLarge      = errors.New("file exceeds the size limit for its type")
+	ErrChatMediaEmpty         = errors.New("file is empty")
+	ErrChatInvalidReply       = errors.New("reply target does not belong to this room")
+	ErrChatInvalidForward     = errors.New("message cannot be forwarded")
+	ErrChatMessageDeleted     = errors.New("message has been deleted")
+	ErrChatEditForbidden      = errors.New("only the sender can edit text messages")
+	ErrChatInvalidCursor      = errors.New("invalid cursor")
+	ErrChatRoleNotAllowed     = errors.New("you cannot grant this role")
+	ErrChatParticipantMissing = errors.New("user is not a participant of this room")
+	ErrChatNoPreferences      = errors.New("is_pinned or is_muted is required")
+	ErrChatInvalidDateRange   = errors.New("start_date must be before end_date")
+	ErrChatInvalidReaction    = errors.New("reaction must be one of " + strings.Join(entity.ChatReactionTypes, " "))
+	ErrChatNotModeratable     = errors.New("personal rooms cannot be moderated")
+	ErrChatSelfModeration     = errors.New("you cannot moderate yourself")
+	ErrChatBanned             = errors.New("you are banned from this room")
+	ErrChatBanNotFound        = errors.New("user is not banned from this room")
+	ErrChatMemberMuted        = errors.New("you are muted in this room")
+	ErrChatSlowMode           = errors.New("slow mode is on, wait before sending another message")
+	ErrChatBlocked            = errors.New("you cannot message this user")
+)
+
+const (
+	ChatEventMessageUpdated      = "message_updated"
+	ChatEventMessageDeleted      = "message_deleted"
+	ChatEventParticipantsAdded   = "participants_added"
+	ChatEventParticipantRemoved  = "participant_removed"
+	ChatEventRoomJoined          = "room_joined"
+	ChatEventRoomLeft            = "room_left"
+	ChatEventUnreadUpdated       = "unread_updated"
+	ChatEventPreferencesUpdated  = "preferences_updated"
+	ChatEventReactionAdded       = "reaction_added"
+	ChatEventReactionRemoved     = "reaction_removed"
+	ChatEventParticipantKicked   = "participant_kicked"
+	ChatEventParticipantBanned   = "participant_banned"
+	ChatEventParticipantUnbanned = "participant_unbanned"
+	ChatEventParticipantMuted    = "participant_muted"
+	ChatEventParticipantUnmuted  = "participant_unmuted"
+	ChatEventRoleChanged         = "role_changed"
+	ChatEventSlowModeChanged     = "slow_mode_changed"
+)
+
+type ChatRoomCreateRequest struct {
+	Type           string      `json:"type" validate:"required,oneof=personal group"`
+	Name           string      `json:"name,omitempty" validate:"omitempty,max=255"`
+	Description    string      `json:"description,omitempty" validate:"omitempty,max=1000"`
+	AvatarURL      string      `json:"avatar_url,omitempty" validate:"omitempty,url,max=2048"`
+	ParticipantIDs []uuid.UUID `json:"participant_ids" validate:"omitempty,max=200"`
+}
+
+type ChatParticipantsRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids" validate:"required,min=1,max=100"`
+	Role    string      `json:"role,omitempty" validate:"omitempty,oneof=admin moderator member"`
+}
+
+type ChatHistoryResponse struct {
+	Messages   []*entity.Chat `json:"messages"`
+	NextCursor *string        `json:"next_cursor,omitempty"`
+	HasMore    bool           `json:"has_more"`
+}
+
+type ChatParticipantsResult struct {
+	RoomID uuid.UUID   `json:"room_id"`
+	Added  []uuid.UUID `json:"added"`
+}
+
+// ChatReceiptRequest acknowledges messages in a room. An empty list covers every message
+// after the caller's last read position.
+type ChatReceiptRequest struct {
+	MessageIDs []uuid.UUID `json:"message_ids" validate:"omitempty,max=500"`
+}
+
+type ChatReceiptResult struct {
+	RoomID      uuid.UUID   `json:"room_id"`
+	MessageIDs  []uuid.UUID `json:"message_ids"`
+	UnreadCount int64       `json:"unread_count"`
+	LastReadAt  *time.Time  `json:"last_read_at,omitempty"`
+}
+
+type ChatTypingRequest struct {
+	IsTyping *bool `json:"is_typing" validate:"required"`
+}
+
+// ChatRoomPreferencesRequest updates the caller's own pin and mute flags for a room.
+type ChatRoomPreferencesRequest struct {
+	IsPinned *bool `json:"is_pinned,omitempty"`
+	IsMuted  *bool `json:"is_muted,omitempty"`
+}
+
+type ChatUnreadResponse struct {
+	Total int64               `json:"total"`
+	Rooms map[uuid.UUID]int64 `json:"rooms"`
+}
+
+type ChatReactionRequest struct {
+	Reaction string `json:"reaction" validate:"required,max=16"`
+}
+
+type ChatReactionResult struct {
+	MessageID uuid.UUID                     `json:"message_id"`
+	Reaction  string                        `json:"reaction"`
+	Added     bool                          `json:"added"`
+	Summary   []*entity.ChatReactionSummary `json:"summary"`
+}
+
+// ChatBanRequest bans a user. Without a duration the ban is permanent.
+type ChatBanRequest struct {
+	Reason          string `json:"reason,omitempty" validate:"omitempty,max=500"`
+	DurationMinutes *int   `json:"duration_minutes,omitempty" validate:"omitempty,min=1,max=525600"`
+}
+
+type ChatMuteRequest struct {
+	DurationMinutes int `json:"duration_minutes" validate:"required,min=1,max=43200"`
+}
+
+type ChatRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin moderator member"`
+}
+
+// ChatSlowModeRequest sets the minimum gap between one member's messages; 0 turns it off.
+type ChatSlowModeRequest struct {
+	Seconds *int `json:"seconds" validate:"required,min=0,max=21600"`
+}
@@ -1,8 +1,8 @@
 package dto
 
 type ContactRequest struct {
-	Name    string `json:"name" validate:"required"`
-	Email   string `json:"email" validate:"required,email"`
-	Subject string `json:"subject" validate:"required"`
-	Message string `json:"message" validate:"required"`
+	Name    string `json:"name" validate:"required,max=255"`
+	Email   string `json:"email" validate:"required,email,max=255"`
+	Subject string `json:"subject" validate:"required,max=255"`
+	Message string `json:"message" validate:"required,max=10000"`
 }
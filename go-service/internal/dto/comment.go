@@ -0,0 +1,143 @@
+package dto
+
+import (
+	"errors"
+	"time"
+	"tubexxi/video-api/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCommentNotFound       = errors.New("comment not found")
+	ErrCommentDeleted        = errors.New("comment has been deleted")
+	ErrInvalidReplyTarget    = errors.New("reply target does not belong to this page")
+	ErrCommentDepthExceeded  = errors.New("maximum reply depth reached")
+	ErrCommentPageURLMissing = errors.New("page_url is required")
+	ErrCommentStreamReset    = errors.New("comment stream position is no longer available, reload comments")
+	ErrInvalidStatsRange     = errors.New("invalid date range, use YYYY-MM-DD or RFC3339 and at most 366 days")
+	ErrStatsContentType      = errors.New("content_type is required with content_id")
+	ErrGuestIdentityRequired = errors.New("name and email are required to comment as a guest")
+	ErrGuestEmailRegistered  = errors.New("this email belongs to an account, sign in to comment")
+	ErrGuestRateLimited      = errors.New("too many guest comments, try again later")
+	ErrGuestTokenInvalid     = errors.New("invalid or expired verification link")
+)
+
+const (
+	CommentStatsScopePage        = "page"
+	CommentStatsScopeContent     = "content"
+	CommentStatsScopeContentType = "content_type"
+	CommentStatsScopeSite        = "site"
+)
+
+const (
+	CommentEventCreated = "comment_created"
+	CommentEventUpdated = "comment_updated"
+	CommentEventDeleted = "comment_deleted"
+	CommentEventLiked   = "comment_liked"
+	CommentEventUnliked = "comment_unliked"
+
+	CommentEventMentioned = "comment_mentioned"
+	CommentEventReplied   = "comment_replied"
+)
+
+type CommentLikeResponse struct {
+	CommentID  uuid.UUID `json:"comment_id"`
+	Liked      bool      `json:"liked"`
+	Changed    bool      `json:"changed"`
+	LikesCount int64     `json:"likes_count"`
+}
+
+type CommentGuestResponse struct {
+	Comment              *entity.Comment `json:"comment"`
+	VerificationRequired bool            `json:"verification_required"`
+	ExpiresAt            time.Time       `json:"expires_at"`
+}
+
+// CommentNotification is pushed to user:<id> when someone mentions or replies to the user.
+type CommentNotification struct {
+	Type         string                `json:"type"`
+	CommentID    uuid.UUID             `json:"comment_id"`
+	ReplyToID    *uuid.UUID            `json:"reply_to_id,omitempty"`
+	PageURL      string                `json:"page_url"`
+	ContentTitle *string               `json:"content_title,omitempty"`
+	Excerpt      string                `json:"excerpt"`
+	Actor        *entity.CommentAuthor `json:"actor,omitempty"`
+	OccurredAt   time.Time             `json:"occurred_at"`
+}
+
+type CommentNotificationSettingsRequest struct {
+	MuteMentions *bool      `json:"mute_mentions" validate:"required"`
+	MuteReplies  *bool      `json:"mute_replies" validate:"required"`
+	EmailEnabled *bool      `json:"email_enabled" validate:"required"`
+	MutedUntil   *time.Time `json:"muted_until,omitempty"`
+}
+
+type CommentModerationRequest struct {
+	CommentIDs []uuid.UUID `json:"comment_ids" validate:"required,min=1,max=100"`
+	Reason     string      `json:"reason,omitempty" validate:"omitempty,max=500"`
+}
+
+type CommentShadowBanRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids" validate:"required,min=1,max=100"`
+	Banned  *bool       `json:"banned" validate:"required"`
+}
+
+type CommentModerationResult struct {
+	Status  string `json:"status,omitempty"`
+	Updated int64  `json:"updated"`
+	Pending int64  `json:"pending"`
+}
+
+// CommentEvent is the payload published to a page's comment channel. Comment is set for
+// created and updated events, Like for liked and unliked events.
+type CommentEvent struct {
+	Type       string            `json:"type"`
+	Offset     uint64            `json:"offset,omitempty"`
+	PageURL    string            `json:"page_url"`
+	CommentID  uuid.UUID         `json:"comment_id"`
+	ReplyToID  *uuid.UUID        `json:"reply_to_id,omitempty"`
+	Comment    *entity.Comment   `json:"comment,omitempty"`
+	Like       *CommentLikeEvent `json:"like,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+type CommentLikeEvent struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Liked      bool      `json:"liked"`
+	LikesCount int64     `json:"likes_count"`
+}
+
+type CommentStreamRequest struct {
+	PageURL string `json:"page_url" query:"page_url" validate:"required,max=2048"`
+	Since   uint64 `json:"since" query:"since"`
+	Epoch   string `json:"epoch" query:"epoch" validate:"omitempty,max=64"`
+	Limit   int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type CommentStreamResponse struct {
+	Channel string          `json:"channel"`
+	Epoch   string          `json:"epoch"`
+	Offset  uint64          `json:"offset"`
+	Events  []*CommentEvent `json:"events"`
+}
+
+type CommentStatsResponse struct {
+	Scope       string                            `json:"scope"`
+	PageURL     string                            `json:"page_url,omitempty"`
+	ContentType string                            `json:"content_type,omitempty"`
+	ContentID   string                            `json:"content_id,omitempty"`
+	From        time.Time                         `json:"from"`
+	To          time.Time                         `json:"to"`
+	Stats       *entity.CommentStats              `json:"stats"`
+	TopTitles   []*entity.CommentLeaderboardEntry `json:"top_titles,omitempty"`
+	GeneratedAt time.Time                         `json:"generated_at"`
+}
+
+type CommentLeaderboardResponse struct {
+	ContentType string                            `json:"content_type,omitempty"`
+	From        time.Time                         `json:"from"`
+	To          time.Time                         `json:"to"`
+	Entries     []*entity.CommentLeaderboardEntry `json:"entries"`
+	GeneratedAt time.Time                         `json:"generated_at"`
+}
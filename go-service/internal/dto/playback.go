@@ -0,0 +1,27 @@
+package dto
+
+import "errors"
+
+var ErrPlaybackProgressNotFound = errors.New("no playback progress for this title")
+
+// TaskPlaybackFlush writes the playback progress held in Redis to Postgres. It is scheduled by
+// the worker every PlaybackConfig.FlushInterval.
+const TaskPlaybackFlush = "playback:flush"
+
+// PlaybackHeartbeatRequest is sent by the player every few seconds while a title plays, and once
+// more when it stops. EpisodeNumber and EpisodeURL identify the episode for series and anime;
+// EpisodeURL is what the next episode is resolved from.
+type PlaybackHeartbeatRequest struct {
+	Type            string `json:"type" validate:"required,oneof=movies series anime"`
+	Slug            string `json:"slug" validate:"required,max=255"`
+	Name            string `json:"name,omitempty" validate:"omitempty,max=255"`
+	EpisodeNumber   *int   `json:"episode_number,omitempty" validate:"omitempty,min=1,max=100000"`
+	EpisodeURL      string `json:"episode_url,omitempty" validate:"omitempty,url,max=2048"`
+	PositionSeconds int    `json:"position_seconds" validate:"min=0,max=86400"`
+	DurationSeconds int    `json:"duration_seconds" validate:"min=0,max=86400"`
+	Completed       bool   `json:"completed"`
+}
+
+type ContinueWatchingQuery struct {
+	Limit int `query:"limit" validate:"omitempty,min=1,max=50"`
+}
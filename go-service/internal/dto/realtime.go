@@ -0,0 +1,113 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidChannel   = errors.New("invalid channel")
+	ErrPublishForbidden = errors.New("publishing to this channel is not allowed")
+	ErrUnknownRPCMethod = errors.New("unknown rpc method")
+	ErrInvalidPayload   = errors.New("invalid payload")
+)
+
+type SubscriptionTokenRequest struct {
+	Channel string `json:"channel" validate:"required,max=255"`
+}
+
+type RealtimeTokenResponse struct {
+	Token     string    `json:"token"`
+	Channel   string    `json:"channel,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type CentrifugoConnectRequest struct {
+	Client    string          `json:"client"`
+	Transport string          `json:"transport"`
+	Protocol  string          `json:"protocol"`
+	Encoding  string          `json:"encoding"`
+	Name      string          `json:"name,omitempty"`
+	Version   string          `json:"version,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Channels  []string        `json:"channels,omitempty"`
+}
+
+type CentrifugoSubscribeRequest struct {
+	Client    string          `json:"client"`
+	Transport string          `json:"transport"`
+	Protocol  string          `json:"protocol"`
+	Encoding  string          `json:"encoding"`
+	User      string          `json:"user"`
+	Channel   string          `json:"channel"`
+	Token     string          `json:"token,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+type CentrifugoPublishRequest struct {
+	Client    string          `json:"client"`
+	Transport string          `json:"transport"`
+	Protocol  string          `json:"protocol"`
+	Encoding  string          `json:"encoding"`
+	User      string          `json:"user"`
+	Channel   string          `json:"channel"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type CentrifugoRPCRequest struct {
+	Client    string          `json:"client"`
+	Transport string          `json:"transport"`
+	Protocol  string          `json:"protocol"`
+	Encoding  string          `json:"encoding"`
+	User      string          `json:"user"`
+	Method    string          `json:"method"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+type CentrifugoConnectData struct {
+	Token string `json:"token"`
+}
+
+type CentrifugoProxyResponse struct {
+	Result     interface{}                `json:"result,omitempty"`
+	Error      *CentrifugoProxyError      `json:"error,omitempty"`
+	Disconnect *CentrifugoProxyDisconnect `json:"disconnect,omitempty"`
+}
+
+type CentrifugoProxyError struct {
+	Code    uint32 `json:"code"`
+	Message string `json:"message"`
+}
+
+type CentrifugoProxyDisconnect struct {
+	Code   uint32 `json:"code"`
+	Reason string `json:"reason"`
+}
+
+type CentrifugoConnectResult struct {
+	User     string          `json:"user"`
+	ExpireAt int64           `json:"expire_at,omitempty"`
+	Info     json.RawMessage `json:"info,omitempty"`
+	Channels []string        `json:"channels,omitempty"`
+}
+
+type CentrifugoPublishResult struct {
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type CentrifugoRPCResult struct {
+	Data interface{} `json:"data"`
+}
+
+type ConversationPublishRequest struct {
+	Type      string     `json:"type" validate:"omitempty,oneof=text"`
+	Message   string     `json:"message" validate:"required,max=4000"`
+	ReplyToID *uuid.UUID `json:"reply_to_id,omitempty"`
+}
+
+type ChannelRPCRequest struct {
+	Channel string `json:"channel" validate:"required,max=255"`
+}
@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrReportNotFound      = errors.New("no open reports for this content")
+	ErrReportDuplicate     = errors.New("you have already reported this content")
+	ErrReportOwnContent    = errors.New("you cannot report your own content")
+	ErrReportTargetDeleted = errors.New("reported content has already been deleted")
+	ErrReportNoAuthor      = errors.New("reported content has no registered author to act on")
+)
+
+const (
+	ReportEventMessageHidden   = "message_hidden"
+	ReportEventMessageRestored = "message_restored"
+	ReportEventWarning         = "moderation_warning"
+)
+
+type ReportRequest struct {
+	TargetType string    `json:"target_type" validate:"required,oneof=comment chat_message"`
+	TargetID   uuid.UUID `json:"target_id" validate:"required"`
+	Reason     string    `json:"reason" validate:"required,oneof=spam abuse spoiler illegal"`
+	Details    string    `json:"details,omitempty" validate:"omitempty,max=1000"`
+}
+
+type ReportResolveRequest struct {
+	TargetType string    `json:"target_type" validate:"required,oneof=comment chat_message"`
+	TargetID   uuid.UUID `json:"target_id" validate:"required"`
+	Action     string    `json:"action" validate:"required,oneof=dismiss delete warn suspend"`
+	Note       string    `json:"note,omitempty" validate:"omitempty,max=1000"`
+}
+
+type ReportResolveResult struct {
+	TargetType string    `json:"target_type"`
+	TargetID   uuid.UUID `json:"target_id"`
+	Action     string    `json:"action"`
+	Status     string    `json:"status"`
+	Resolved   int64     `json:"resolved"`
+}
+
+// ReportWarning is pushed to user:<id> when an admin resolves reports against the user with a warning.
+type ReportWarning struct {
+	Type       string    `json:"type"`
+	TargetType string    `json:"target_type"`
+	TargetID   uuid.UUID `json:"target_id"`
+	Reasons    []string  `json:"reasons"`
+	Note       *string   `json:"note,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTicketNotFound           = errors.New("ticket not found")
+	ErrTicketClosed             = errors.New("ticket is closed")
+	ErrTicketTransition         = errors.New("ticket cannot move to that status")
+	ErrTicketAssigneeInvalid    = errors.New("assignee must be an active staff member")
+	ErrTicketTooManyAttachments = errors.New("too many attachments")
+	ErrTicketAttachmentTooLarge = errors.New("attachment exceeds the size limit")
+	ErrTicketAttachmentEmpty    = errors.New("attachment is empty")
+	ErrTicketReplyDuplicate     = errors.New("reply was already threaded")
+	ErrInboundMailRejected      = errors.New("inbound email cannot be threaded")
+	ErrTicketSLAPolicyNotFound  = errors.New("SLA policy not found")
+	ErrTicketSLAPolicyInvalid   = errors.New("resolution target cannot be shorter than the first response target")
+	ErrContactRateLimited       = errors.New("too many messages from this address, try again later")
+)
+
+const TaskTicketSLACheck = "ticket:sla_check"
+
+// TicketCreateRequest opens a ticket. It is read from JSON or from a multipart form whose
+// "attachments" files are stored with the ticket.
+type TicketCreateRequest struct {
+	Title       string `json:"title" form:"title" validate:"required,max=255"`
+	Description string `json:"description" form:"description" validate:"required,max=10000"`
+	Category    string `json:"category,omitempty" form:"category" validate:"omitempty,oneof=general account billing playback content technical"`
+	Priority    string `json:"priority,omitempty" form:"priority" validate:"omitempty,oneof=low normal high urgent"`
+}
+
+type TicketReplyRequest struct {
+	Body string `json:"body" form:"body" validate:"required,max=10000"`
+}
+
+// TicketAssignRequest assigns a ticket; a nil AssigneeID unassigns it.
+type TicketAssignRequest struct {
+	AssigneeID *uuid.UUID `json:"assignee_id"`
+}
+
+type TicketPriorityRequest struct {
+	Priority string `json:"priority" validate:"required,oneof=low normal high urgent"`
+}
+
+// TicketStatusRequest moves a ticket through the workflow. Note, when given, is posted as a
+// staff reply alongside the change.
+type TicketStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=open resolved closed"`
+	Note   string `json:"note,omitempty" validate:"omitempty,max=10000"`
+}
+
+type TicketCategoryRequest struct {
+	Category string `json:"category" validate:"required,oneof=general account billing playback content technical"`
+}
+
+// TicketSLAPolicyRequest sets the targets for a priority, within one category or, without a
+// category, as the default. Targets are in minutes; IsActive defaults to true.
+type TicketSLAPolicyRequest struct {
+	Category             *string `json:"category,omitempty" validate:"omitempty,oneof=general account billing playback content technical"`
+	Priority             string  `json:"priority" validate:"required,oneof=low normal high urgent"`
+	FirstResponseMinutes int     `json:"first_response_minutes" validate:"required,min=1,max=525600"`
+	ResolutionMinutes    int     `json:"resolution_minutes" validate:"required,min=1,max=525600"`
+	IsActive             *bool   `json:"is_active,omitempty"`
+}
+
+// TicketSLAReportQuery selects the window of the SLA dashboard: tickets opened in the last
+// Days days, optionally of one category.
+type TicketSLAReportQuery struct {
+	Days     int    `query:"days" validate:"omitempty,min=1,max=365"`
+	Category string `query:"category" validate:"omitempty,oneof=general account billing playback content technical"`
+}
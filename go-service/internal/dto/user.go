@@ -7,12 +7,15 @@ var (
 	ErrUsernameAlreadyExists = errors.New("username already exists")
 	ErrPhoneAlreadyExists    = errors.New("phone already exists")
 	ErrWeakPassword          = errors.New("password too weak")
+	ErrCannotBlockSelf       = errors.New("you cannot block yourself")
+	ErrUserNotBlocked        = errors.New("user is not blocked")
 )
 
 type UpdateProfileRequest struct {
-	FullName string `json:"full_name" validate:"required"`
-	Email    string `json:"email" validate:"email"`
-	Phone    string `json:"phone" validate:"omitempty,e164"`
+	FullName string  `json:"full_name" validate:"required"`
+	Email    string  `json:"email" validate:"email"`
+	Phone    string  `json:"phone" validate:"omitempty,e164"`
+	Username *string `json:"username,omitempty" validate:"omitempty,username"`
 }
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" validate:"required"`
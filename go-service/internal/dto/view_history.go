@@ -0,0 +1,24 @@
+package dto
+
+// TaskViewHistoryRecord carries one recorded view. The tasks are enqueued into the
+// TaskViewHistoryBatch group, so the worker never sees them one by one: asynq hands it a
+// TaskViewHistoryBatch task whose payload is the JSON array of the grouped views.
+const (
+	TaskViewHistoryRecord = "view_history:record"
+	TaskViewHistoryBatch  = "view_history:batch"
+)
+
+// ViewHistoryRecordRequest is the player's ping when a title starts playing. Slug identifies the
+// title within its type; PageURL is the page it was watched on. The remaining fields are
+// filled in by the handler from the request itself.
+type ViewHistoryRecordRequest struct {
+	Type    string `json:"type" validate:"required,oneof=movies series anime"`
+	Slug    string `json:"slug" validate:"required,max=255"`
+	Name    string `json:"name,omitempty" validate:"omitempty,max=255"`
+	PageURL string `json:"page_url" validate:"required,max=2048"`
+
+	IPAddress      string `json:"-"`
+	UserAgent      string `json:"-"`
+	AcceptLanguage string `json:"-"`
+	Platform       string `json:"-"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"errors"
+	"time"
+	"tubexxi/video-api/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWatchPartyNotFound           = errors.New("watch party not found")
+	ErrWatchPartyHostOnly           = errors.New("only the host can control playback")
+	ErrWatchPartyFull               = errors.New("watch party is full")
+	ErrWatchPartyContentUnavailable = errors.New("content not found or has no playable source")
+	ErrWatchPartyInvalidPlayer      = errors.New("player_index is out of range")
+	ErrWatchPartyPositionRequired   = errors.New("position is required for seek")
+	ErrWatchPartyPlayerRequired     = errors.New("player_index is required to change the source")
+)
+
+const WatchPartyEventPlayback = "playback"
+
+// WatchPartyCreateRequest starts a party for a movie slug or a series episode URL.
+type WatchPartyCreateRequest struct {
+	ContentType    string      `json:"content_type" validate:"required,oneof=movie episode"`
+	Source         string      `json:"source" validate:"required,max=2048"`
+	Title          string      `json:"title,omitempty" validate:"omitempty,max=255"`
+	Name           string      `json:"name,omitempty" validate:"omitempty,max=255"`
+	PlayerIndex    *int        `json:"player_index,omitempty" validate:"omitempty,min=0"`
+	ParticipantIDs []uuid.UUID `json:"participant_ids" validate:"omitempty,max=49"`
+}
+
+// WatchPartyPlaybackRequest is a host control. Position defaults to the extrapolated current
+// position for play, pause and source changes.
+type WatchPartyPlaybackRequest struct {
+	Action      string   `json:"action" validate:"required,oneof=play pause seek source"`
+	Position    *float64 `json:"position,omitempty" validate:"omitempty,min=0,max=86400"`
+	PlayerIndex *int     `json:"player_index,omitempty" validate:"omitempty,min=0"`
+}
+
+type WatchPartySyncResponse struct {
+	State      *entity.WatchPartyState `json:"state"`
+	Position   float64                 `json:"position"`
+	ServerTime time.Time               `json:"server_time"`
+}
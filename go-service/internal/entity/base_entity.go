@@ -245,6 +245,13 @@ func NewNullStringPtr(s *string) NullString {
 	return NullString{NullString: sql.NullString{String: *s, Valid: true}}
 }
 
+func (ns NullString) Ptr() *string {
+	if !ns.Valid {
+		return nil
+	}
+	s := ns.String
+	return &s
+}
 func NewNullTime(t time.Time) NullTime {
 	if t.IsZero() {
 		return NullTime{NullTime: sql.NullTime{Valid: false}}
@@ -0,0 +1,123 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AnnouncementStatusScheduled = "scheduled"
+	AnnouncementStatusSending   = "sending"
+	AnnouncementStatusSent      = "sent"
+	AnnouncementStatusCancelled = "cancelled"
+	AnnouncementStatusFailed    = "failed"
+)
+
+const (
+	DevicePlatformWeb    = "web"
+	DevicePlatformMobile = "mobile"
+)
+
+// BroadcastTarget limits a broadcast channel to one settings scope and a set of client
+// platforms. Empty fields match everything. It is stored in the room metadata.
+type BroadcastTarget struct {
+	Scope     string   `json:"scope,omitempty"`
+	Platforms []string `json:"platforms,omitempty"`
+}
+
+// IsTargeted reports whether the channel is limited to a scope or to some platforms.
+func (t *BroadcastTarget) IsTargeted() bool {
+	return t.Scope != "" || len(t.Platforms) > 0
+}
+
+type BroadcastChannel struct {
+	ID            uuid.UUID       `json:"id" db:"id"`
+	Name          string          `json:"name" db:"name"`
+	Description   *string         `json:"description,omitempty" db:"description"`
+	AvatarURL     *string         `json:"avatar_url,omitempty" db:"avatar_url"`
+	Target        BroadcastTarget `json:"target" db:"metadata"`
+	Subscribers   int64           `json:"subscribers" db:"subscribers"`
+	IsMuted       bool            `json:"is_muted" db:"is_muted"`
+	LastMessageAt *time.Time      `json:"last_message_at,omitempty" db:"last_message_at"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+}
+
+// ChatAnnouncement is a message scheduled into a broadcast channel. The worker posts it at
+// ScheduledAt and optionally fans it out as push notifications and emails.
+type ChatAnnouncement struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	RoomID        uuid.UUID  `json:"room_id" db:"room_id"`
+	CreatedBy     uuid.UUID  `json:"created_by" db:"created_by"`
+	Title         string     `json:"title" db:"title"`
+	Body          string     `json:"body" db:"body"`
+	Link          *string    `json:"link,omitempty" db:"link"`
+	SendPush      bool       `json:"send_push" db:"send_push"`
+	SendEmail     bool       `json:"send_email" db:"send_email"`
+	Status        string     `json:"status" db:"status"`
+	ScheduledAt   time.Time  `json:"scheduled_at" db:"scheduled_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty" db:"sent_at"`
+	MessageID     *uuid.UUID `json:"message_id,omitempty" db:"message_id"`
+	PushCount     int        `json:"push_count" db:"push_count"`
+	EmailCount    int        `json:"email_count" db:"email_count"`
+	FailureReason *string    `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// UserDevice is a push token registered by a signed-in client.
+type UserDevice struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Token      string    `json:"-" db:"token"`
+	Platform   string    `json:"platform" db:"platform"`
+	Scope      string    `json:"scope" db:"scope"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
+
+// BroadcastRecipient is a channel subscriber an announcement email goes to.
+type BroadcastRecipient struct {
+	UserID   uuid.UUID `db:"user_id"`
+	Email    string    `db:"email"`
+	FullName string    `db:"full_name"`
+}
+
+// BroadcastChannelFilter pages through broadcast channels. Scope and Platform come from the
+// request, not the query string; when empty every channel is listed.
+type BroadcastChannelFilter struct {
+	Scope    string `json:"-" query:"-"`
+	Platform string `json:"-" query:"-"`
+	Limit    int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset   int    `json:"offset" query:"offset" validate:"omitempty,min=0"`
+}
+
+func (f *BroadcastChannelFilter) SetDefaults() {
+	if f.Limit <= 0 {
+		f.Limit = 30
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+}
+
+type AnnouncementFilter struct {
+	Status string `json:"status,omitempty" query:"status" validate:"omitempty,oneof=scheduled sending sent cancelled failed"`
+	Limit  int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int    `json:"offset" query:"offset" validate:"omitempty,min=0"`
+}
+
+func (f *AnnouncementFilter) SetDefaults() {
+	if f.Limit <= 0 {
+		f.Limit = 30
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+}
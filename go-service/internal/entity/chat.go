@@ -2,69 +2,115 @@ package entity
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Chat struct {
-	ID              uuid.UUID       `json:"id" db:"id"`
-	SenderID        uuid.UUID       `json:"sender_id" db:"sender_id"`
-	ReceiverID      uuid.UUID       `json:"receiver_id" db:"receiver_id"`
-	Message         *string         `json:"message,omitempty" db:"message"`
-	Type            string          `json:"type" db:"type"` // text, image, video, audio, file, location, contact
-	FileURL         *string         `json:"file_url,omitempty" db:"file_url"`
-	FileName        *string         `json:"file_name,omitempty" db:"file_name"`
-	FileSize        *int64          `json:"file_size,omitempty" db:"file_size"`
-	MimeType        *string         `json:"mime_type,omitempty" db:"mime_type"`
-	IsRead          bool            `json:"is_read" db:"is_read"`
-	IsDelivered     bool            `json:"is_delivered" db:"is_delivered"`
-	IsEdited        bool            `json:"is_edited" db:"is_edited"`
-	IsDeleted       bool            `json:"is_deleted" db:"is_deleted"`
-	ReplyToID       *uuid.UUID      `json:"reply_to_id,omitempty" db:"reply_to_id"`
-	ForwardedFromID *uuid.UUID      `json:"forwarded_from_id,omitempty" db:"forwarded_from_id"`
-	Metadata        json.RawMessage `json:"metadata,omitempty" db:"metadata"` // For location, contact, etc.
-	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
-	ReplyTo         *Chat           `json:"reply_to,omitempty" db:"-"`
-	ForwardedFrom   *Chat           `json:"forwarded_from,omitempty" db:"-"`
-	Replies         []*Chat         `json:"replies,omitempty" db:"-"`
-	Sender          *User           `json:"sender,omitempty" db:"-"`
-	Receiver        *User           `json:"receiver,omitempty" db:"-"`
-	Reactions       []*ChatReaction `json:"reactions,omitempty" db:"-"`
-	ReactionsCount  int64           `json:"reactions_count" db:"reactions_count"`
-	RepliesCount    int64           `json:"replies_count" db:"replies_count"`
-	IsSentByMe      bool            `json:"is_sent_by_me" db:"-"`
-	IsForwarded     bool            `json:"is_forwarded" db:"-"`
-	SenderName      string          `json:"sender_name,omitempty" db:"sender_name"`
-	SenderAvatar    *string         `json:"sender_avatar,omitempty" db:"sender_avatar"`
-	ReceiverName    string          `json:"receiver_name,omitempty" db:"receiver_name"`
-	ReceiverAvatar  *string         `json:"receiver_avatar,omitempty" db:"receiver_avatar"`
+	ID              uuid.UUID              `json:"id" db:"id"`
+	RoomID          uuid.UUID              `json:"room_id" db:"room_id"`
+	SenderID        uuid.UUID              `json:"sender_id" db:"sender_id"`
+	ReceiverID      *uuid.UUID             `json:"receiver_id,omitempty" db:"-"`
+	Message         *string                `json:"message,omitempty" db:"message"`
+	Type            string                 `json:"type" db:"type"` // text, image, video, audio, file, location, contact
+	FileURL         *string                `json:"file_url,omitempty" db:"file_url"`
+	FileName        *string                `json:"file_name,omitempty" db:"file_name"`
+	FileSize        *int64                 `json:"file_size,omitempty" db:"file_size"`
+	MimeType        *string                `json:"mime_type,omitempty" db:"mime_type"`
+	IsRead          bool                   `json:"is_read" db:"is_read"`
+	IsDelivered     bool                   `json:"is_delivered" db:"is_delivered"`
+	IsEdited        bool                   `json:"is_edited" db:"is_edited"`
+	IsDeleted       bool                   `json:"is_deleted" db:"is_deleted"`
+	IsHidden        bool                   `json:"is_hidden" db:"is_hidden"`
+	ReplyToID       *uuid.UUID             `json:"reply_to_id,omitempty" db:"reply_to_id"`
+	ForwardedFromID *uuid.UUID             `json:"forwarded_from_id,omitempty" db:"forwarded_from_id"`
+	Metadata        json.RawMessage        `json:"metadata,omitempty" db:"metadata"` // For location, contact, etc.
+	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at" db:"updated_at"`
+	ReplyTo         *Chat                  `json:"reply_to,omitempty" db:"-"`
+	ForwardedFrom   *Chat                  `json:"forwarded_from,omitempty" db:"-"`
+	Replies         []*Chat                `json:"replies,omitempty" db:"-"`
+	Sender          *User                  `json:"sender,omitempty" db:"-"`
+	Receiver        *User                  `json:"receiver,omitempty" db:"-"`
+	Reactions       []*ChatReaction        `json:"reactions,omitempty" db:"-"`
+	ReactionSummary []*ChatReactionSummary `json:"reaction_summary,omitempty" db:"-"`
+	ReactionsCount  int64                  `json:"reactions_count" db:"reactions_count"`
+	RepliesCount    int64                  `json:"replies_count" db:"replies_count"`
+	IsSentByMe      bool                   `json:"is_sent_by_me" db:"-"`
+	IsForwarded     bool                   `json:"is_forwarded" db:"-"`
+	SenderName      string                 `json:"sender_name,omitempty" db:"sender_name"`
+	SenderAvatar    *string                `json:"sender_avatar,omitempty" db:"sender_avatar"`
+	ReceiverName    string                 `json:"receiver_name,omitempty" db:"receiver_name"`
+	ReceiverAvatar  *string                `json:"receiver_avatar,omitempty" db:"receiver_avatar"`
 }
 
 type ChatReaction struct {
 	ID           uuid.UUID `json:"id" db:"id"`
-	ChatID       uuid.UUID `json:"chat_id" db:"chat_id"`
+	MessageID    uuid.UUID `json:"message_id" db:"message_id"`
 	UserID       uuid.UUID `json:"user_id" db:"user_id"`
-	ReactionType string    `json:"reaction_type" db:"reaction_type"` // like, love, haha, wow, sad, angry
+	ReactionType string    `json:"reaction_type" db:"reaction_type"` // one of ChatReactionTypes
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	User         *User     `json:"user,omitempty" db:"-"`
 	Chat         *Chat     `json:"chat,omitempty" db:"-"`
 }
 
+// ChatReactionSummary aggregates one emoji's reactions on a message for a viewer.
+type ChatReactionSummary struct {
+	ReactionType string `json:"reaction_type" db:"reaction_type"`
+	Count        int64  `json:"count" db:"count"`
+	ReactedByMe  bool   `json:"reacted_by_me" db:"reacted_by_me"`
+}
+
+// ChatReactor is one user's reaction on a message, for the "who reacted" list.
+type ChatReactor struct {
+	UserID       uuid.UUID `json:"user_id" db:"user_id"`
+	FullName     string    `json:"full_name" db:"full_name"`
+	Username     *string   `json:"username,omitempty" db:"username"`
+	AvatarURL    *string   `json:"avatar_url,omitempty" db:"avatar_url"`
+	ReactionType string    `json:"reaction_type" db:"reaction_type"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// ChatReactionTypes mirrors the reaction_type CHECK constraint on chat_message_reactions
+// (migration 000010); keep the two in sync.
+var ChatReactionTypes = []string{"👍", "❤️", "😂", "😮", "😢", "😡", "👏", "🎉"}
+
+// NormalizeChatReaction maps a reaction to its stored form, accepting the heart without the
+// emoji variation selector that some keyboards omit. ok is false for unsupported reactions.
+func NormalizeChatReaction(reaction string) (string, bool) {
+	reaction = strings.TrimSpace(reaction)
+	if reaction == "❤" {
+		reaction = "❤️"
+	}
+	for _, allowed := range ChatReactionTypes {
+		if reaction == allowed {
+			return reaction, true
+		}
+	}
+	return "", false
+}
+
+// ChatMedia is an uploaded attachment. Everything but the name is derived server-side from the
+// stored bytes; MessageID stays nil until a message claims the upload.
 type ChatMedia struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	ChatID    uuid.UUID `json:"chat_id" db:"chat_id"`
-	URL       string    `json:"url" db:"url"`
-	Type      string    `json:"type" db:"type"`
-	Name      string    `json:"name" db:"name"`
-	Size      int64     `json:"size" db:"size"`
-	MimeType  string    `json:"mime_type" db:"mime_type"`
-	Width     *int      `json:"width,omitempty" db:"width"`
-	Height    *int      `json:"height,omitempty" db:"height"`
-	Duration  *int      `json:"duration,omitempty" db:"duration"` // for video/audio
-	Thumbnail *string   `json:"thumbnail,omitempty" db:"thumbnail"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID           uuid.UUID  `json:"id" db:"id"`
+	RoomID       uuid.UUID  `json:"room_id" db:"room_id"`
+	UploaderID   uuid.UUID  `json:"uploader_id" db:"uploader_id"`
+	MessageID    *uuid.UUID `json:"message_id,omitempty" db:"message_id"`
+	ObjectKey    string     `json:"-" db:"object_key"`
+	URL          string     `json:"url" db:"url"`
+	Type         string     `json:"type" db:"type"`
+	Name         string     `json:"name" db:"name"`
+	Size         int64      `json:"size" db:"size"`
+	MimeType     string     `json:"mime_type" db:"mime_type"`
+	Width        *int       `json:"width,omitempty" db:"width"`
+	Height       *int       `json:"height,omitempty" db:"height"`
+	Duration     *int       `json:"duration,omitempty" db:"duration"` // for video/audio
+	ThumbnailKey *string    `json:"-" db:"thumbnail_key"`
+	Thumbnail    *string    `json:"thumbnail,omitempty" db:"thumbnail"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
 }
 
 type ChatCreateRequest struct {
@@ -80,15 +126,10 @@ type ChatCreateRequest struct {
 	Metadata        json.RawMessage   `json:"metadata,omitempty"`
 }
 
+// ChatMediaRequest references an attachment uploaded beforehand; its metadata is taken from the
+// stored upload, never from the client.
 type ChatMediaRequest struct {
-	URL       string  `json:"url"`
-	Name      string  `json:"name"`
-	Size      int64   `json:"size"`
-	MimeType  string  `json:"mime_type"`
-	Width     *int    `json:"width,omitempty"`
-	Height    *int    `json:"height,omitempty"`
-	Duration  *int    `json:"duration,omitempty"`
-	Thumbnail *string `json:"thumbnail,omitempty"`
+	ID uuid.UUID `json:"id" validate:"required"`
 }
 
 type LocationData struct {
@@ -110,19 +151,54 @@ type ChatUpdateRequest struct {
 }
 
 type ChatFilter struct {
-	UserID      uuid.UUID  `json:"user_id" validate:"required"`
-	OtherUserID *uuid.UUID `json:"other_user_id,omitempty"`
-	GroupID     *uuid.UUID `json:"group_id,omitempty"`
-	Type        string     `json:"type,omitempty"`
-	IsRead      *bool      `json:"is_read,omitempty"`
-	IsDelivered *bool      `json:"is_delivered,omitempty"`
-	StartDate   *time.Time `json:"start_date,omitempty"`
-	EndDate     *time.Time `json:"end_date,omitempty"`
-	Search      *string    `json:"search,omitempty"`
-	Limit       int        `json:"limit" validate:"omitempty,min=1,max=100"`
-	Offset      int        `json:"offset" validate:"omitempty,min=0"`
-	SortBy      string     `json:"sort_by" validate:"omitempty,oneof=created_at is_read"`
-	SortOrder   string     `json:"sort_order" validate:"omitempty,oneof=asc desc"`
+	UserID      uuid.UUID  `json:"user_id" query:"-" validate:"required"`
+	OtherUserID *uuid.UUID `json:"other_user_id,omitempty" query:"other_user_id"`
+	GroupID     *uuid.UUID `json:"group_id,omitempty" query:"group_id"`
+	Type        string     `json:"type,omitempty" query:"type" validate:"omitempty,oneof=text image video audio file location contact"`
+	IsRead      *bool      `json:"is_read,omitempty" query:"is_read"`
+	IsDelivered *bool      `json:"is_delivered,omitempty" query:"is_delivered"`
+	StartDate   *time.Time `json:"start_date,omitempty" query:"start_date"`
+	EndDate     *time.Time `json:"end_date,omitempty" query:"end_date"`
+	Search      *string    `json:"search,omitempty" query:"search" validate:"omitempty,min=2,max=200"`
+	Limit       int        `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset      int        `json:"offset" query:"offset" validate:"omitempty,min=0"`
+	SortBy      string     `json:"sort_by" query:"sort_by" validate:"omitempty,oneof=created_at is_read rank"`
+	SortOrder   string     `json:"sort_order" query:"sort_order" validate:"omitempty,oneof=asc desc"`
+}
+
+// ChatSearchHit is one message matched by a search, with the matching terms wrapped in <mark>.
+// The highlight is HTML-escaped apart from those tags.
+type ChatSearchHit struct {
+	Message   *Chat   `json:"message"`
+	RoomType  string  `json:"room_type"`
+	RoomName  *string `json:"room_name,omitempty"`
+	Highlight *string `json:"highlight,omitempty"`
+	Rank      float64 `json:"rank"`
+}
+
+// SetDefaults orders by relevance when searching and by recency otherwise.
+func (f *ChatFilter) SetDefaults() {
+	if f.Search != nil && strings.TrimSpace(*f.Search) == "" {
+		f.Search = nil
+	}
+	if f.SortBy == "" || (f.SortBy == "rank" && f.Search == nil) {
+		f.SortBy = "created_at"
+		if f.Search != nil {
+			f.SortBy = "rank"
+		}
+	}
+	if f.SortOrder == "" {
+		f.SortOrder = "desc"
+	}
+	if f.Limit <= 0 {
+		f.Limit = 20
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
 }
 
 type ChatResponse struct {
@@ -133,14 +209,48 @@ type ChatResponse struct {
 	Media         *ChatMedia      `json:"media,omitempty"`
 }
 
+// Conversation is one row of a user's inbox. The other user fields are set for personal rooms.
 type Conversation struct {
-	OtherUserID     uuid.UUID `json:"other_user_id" db:"other_user_id"`
-	OtherUserName   string    `json:"other_user_name" db:"other_user_name"`
-	OtherUserAvatar *string   `json:"other_user_avatar,omitempty" db:"other_user_avatar"`
-	LastMessage     *Chat     `json:"last_message" db:"-"`
-	UnreadCount     int64     `json:"unread_count" db:"unread_count"`
-	TotalMessages   int64     `json:"total_messages" db:"total_messages"`
-	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+	RoomID          uuid.UUID  `json:"room_id" db:"room_id"`
+	Type            string     `json:"type" db:"type"`
+	Name            *string    `json:"name,omitempty" db:"name"`
+	AvatarURL       *string    `json:"avatar_url,omitempty" db:"avatar_url"`
+	Role            string     `json:"role" db:"role"`
+	IsPinned        bool       `json:"is_pinned" db:"is_pinned"`
+	IsMuted         bool       `json:"is_muted" db:"is_muted"`
+	LastReadAt      time.Time  `json:"last_read_at" db:"last_read_at"`
+	OtherUserID     *uuid.UUID `json:"other_user_id,omitempty" db:"other_user_id"`
+	OtherUserName   string     `json:"other_user_name,omitempty" db:"other_user_name"`
+	OtherUserAvatar *string    `json:"other_user_avatar,omitempty" db:"other_user_avatar"`
+	LastMessageID   *uuid.UUID `json:"-" db:"last_message_id"`
+	LastMessage     *Chat      `json:"last_message" db:"-"`
+	LastMessageAt   *time.Time `json:"last_message_at,omitempty" db:"last_message_at"`
+	UnreadCount     int64      `json:"unread_count" db:"unread_count"`
+	TotalMessages   int64      `json:"total_messages" db:"total_messages"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ConversationFilter pages through the caller's rooms. Scope and Platform come from the request
+// and hide broadcast channels targeted elsewhere.
+type ConversationFilter struct {
+	Type       string `json:"type,omitempty" query:"type" validate:"omitempty,oneof=personal group channel watch_party"`
+	UnreadOnly bool   `json:"unread_only,omitempty" query:"unread_only"`
+	Scope      string `json:"-" query:"-"`
+	Platform   string `json:"-" query:"-"`
+	Limit      int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset     int    `json:"offset" query:"offset" validate:"omitempty,min=0"`
+}
+
+func (f *ConversationFilter) SetDefaults() {
+	if f.Limit <= 0 {
+		f.Limit = 30
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
 }
 
 type ChatThread struct {
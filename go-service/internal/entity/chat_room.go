@@ -0,0 +1,128 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ChatRoomTypePersonal   = "personal"
+	ChatRoomTypeGroup      = "group"
+	ChatRoomTypeChannel    = "channel"
+	ChatRoomTypeWatchParty = "watch_party"
+)
+
+const (
+	ChatRoleAdmin     = "admin"
+	ChatRoleModerator = "moderator"
+	ChatRoleMember    = "member"
+)
+
+const (
+	ChatMessageTypeText     = "text"
+	ChatMessageTypeImage    = "image"
+	ChatMessageTypeVideo    = "video"
+	ChatMessageTypeAudio    = "audio"
+	ChatMessageTypeFile     = "file"
+	ChatMessageTypeLocation = "location"
+	ChatMessageTypeContact  = "contact"
+	ChatMessageTypeSystem   = "system"
+)
+
+type ChatRoom struct {
+	ID              uuid.UUID          `json:"id" db:"id"`
+	Name            *string            `json:"name,omitempty" db:"name"`
+	Type            string             `json:"type" db:"type"`
+	CreatedBy       uuid.UUID          `json:"created_by" db:"created_by"`
+	AvatarURL       *string            `json:"avatar_url,omitempty" db:"avatar_url"`
+	Description     *string            `json:"description,omitempty" db:"description"`
+	IsArchived      bool               `json:"is_archived" db:"is_archived"`
+	IsMuted         bool               `json:"is_muted" db:"is_muted"`
+	LastMessageID   *uuid.UUID         `json:"last_message_id,omitempty" db:"last_message_id"`
+	LastMessageAt   *time.Time         `json:"last_message_at,omitempty" db:"last_message_at"`
+	Metadata        json.RawMessage    `json:"metadata,omitempty" db:"metadata"`
+	SlowModeSeconds int                `json:"slow_mode_seconds" db:"slow_mode_seconds"`
+	CreatedAt       time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" db:"updated_at"`
+	Participants    []*ChatParticipant `json:"participants,omitempty" db:"-"`
+}
+
+type ChatParticipant struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	RoomID     uuid.UUID  `json:"room_id" db:"room_id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	Role       string     `json:"role" db:"role"`
+	JoinedAt   time.Time  `json:"joined_at" db:"joined_at"`
+	LastReadAt time.Time  `json:"last_read_at" db:"last_read_at"`
+	IsMuted    bool       `json:"is_muted" db:"is_muted"`
+	IsPinned   bool       `json:"is_pinned" db:"is_pinned"`
+	Nickname   *string    `json:"nickname,omitempty" db:"nickname"`
+	MutedUntil *time.Time `json:"muted_until,omitempty" db:"muted_until"`
+	FullName   string     `json:"full_name" db:"full_name"`
+	Username   *string    `json:"username,omitempty" db:"username"`
+	AvatarURL  *string    `json:"avatar_url,omitempty" db:"avatar_url"`
+}
+
+// ChatRoomBan keeps a user out of a room until ExpiresAt, or for good when it is nil.
+type ChatRoomBan struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	RoomID    uuid.UUID  `json:"room_id" db:"room_id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	BannedBy  *uuid.UUID `json:"banned_by,omitempty" db:"banned_by"`
+	Reason    *string    `json:"reason,omitempty" db:"reason"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	FullName  string     `json:"full_name" db:"full_name"`
+	Username  *string    `json:"username,omitempty" db:"username"`
+	AvatarURL *string    `json:"avatar_url,omitempty" db:"avatar_url"`
+}
+
+// ChatHistoryFilter pages through a room newest first. Cursor is the opaque next_cursor
+// of the previous page.
+type ChatHistoryFilter struct {
+	Cursor string `json:"cursor,omitempty" query:"cursor"`
+	Limit  int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// ChatCursor is the position of the oldest message on a history page.
+type ChatCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (f *ChatHistoryFilter) SetDefaults() {
+	if f.Limit <= 0 {
+		f.Limit = 50
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+}
+
+func (r *ChatRoom) IsPersonal() bool {
+	return r.Type == ChatRoomTypePersonal
+}
+
+func (r *ChatRoom) IsChannel() bool {
+	return r.Type == ChatRoomTypeChannel
+}
+
+func (r *ChatRoom) IsWatchParty() bool {
+	return r.Type == ChatRoomTypeWatchParty
+}
+
+func (p *ChatParticipant) IsAdmin() bool {
+	return p.Role == ChatRoleAdmin
+}
+
+// IsSilenced reports whether a moderator's mute on the participant is still running.
+func (p *ChatParticipant) IsSilenced(now time.Time) bool {
+	return p.MutedUntil != nil && p.MutedUntil.After(now)
+}
+
+// CanModerate reports whether the participant may manage other members' messages.
+func (p *ChatParticipant) CanModerate() bool {
+	return p.Role == ChatRoleAdmin || p.Role == ChatRoleModerator
+}
@@ -6,29 +6,91 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	CommentStatusApproved = "approved"
+	CommentStatusPending  = "pending"
+	CommentStatusRejected = "rejected"
+	// CommentStatusUnverified marks guest comments waiting on their email verification link.
+	CommentStatusUnverified = "unverified"
+)
+
+const (
+	CommentNotificationMention = "mention"
+	CommentNotificationReply   = "reply"
+)
+
 type Comment struct {
-	ID           uuid.UUID      `json:"id" db:"id"`
-	UserID       uuid.UUID      `json:"user_id" db:"user_id"`
-	PageURL      string         `json:"page_url" db:"page_url"`
-	Name         *string        `json:"name,omitempty" db:"name"`
-	Email        *string        `json:"email,omitempty" db:"email"`
-	Comment      string         `json:"comment" db:"comment"`
-	Type         string         `json:"type" db:"type"`
-	ReplyToID    *uuid.UUID     `json:"reply_to_id,omitempty" db:"reply_to_id"`
-	IsEdited     bool           `json:"is_edited" db:"is_edited"`
-	IsDeleted    bool           `json:"is_deleted" db:"is_deleted"`
-	MediaURL     *string        `json:"media_url,omitempty" db:"media_url"`
-	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at" db:"updated_at"`
-	Parent       *Comment       `json:"parent,omitempty" db:"-"`
-	Replies      []*Comment     `json:"replies,omitempty" db:"-"`
-	User         *User          `json:"user,omitempty" db:"-"`
-	Likes        []*LikeComment `json:"likes,omitempty" db:"-"`
-	LikesCount   int64          `json:"likes_count" db:"likes_count"`
-	RepliesCount int64          `json:"replies_count" db:"replies_count"`
-	IsLikedByMe  bool           `json:"is_liked_by_me" db:"-"`
-	Depth        int            `json:"depth,omitempty" db:"depth"`
-	Path         string         `json:"path,omitempty" db:"path"`
+	ID               uuid.UUID         `json:"id" db:"id"`
+	UserID           *uuid.UUID        `json:"user_id,omitempty" db:"user_id"`
+	PageURL          string            `json:"page_url" db:"page_url"`
+	Name             *string           `json:"name,omitempty" db:"name"`
+	Email            *string           `json:"-" db:"email"`
+	Comment          string            `json:"comment" db:"comment"`
+	Type             string            `json:"type" db:"type"`
+	ReplyToID        *uuid.UUID        `json:"reply_to_id,omitempty" db:"reply_to_id"`
+	IsEdited         bool              `json:"is_edited" db:"is_edited"`
+	IsDeleted        bool              `json:"is_deleted" db:"is_deleted"`
+	MediaURL         *string           `json:"media_url,omitempty" db:"media_url"`
+	Status           string            `json:"status" db:"status"`
+	ModerationReason *string           `json:"moderation_reason,omitempty" db:"moderation_reason"`
+	ModeratedBy      *uuid.UUID        `json:"moderated_by,omitempty" db:"moderated_by"`
+	ModeratedAt      *time.Time        `json:"moderated_at,omitempty" db:"moderated_at"`
+	ContentType      *string           `json:"content_type,omitempty" db:"content_type"`
+	ContentID        *string           `json:"content_id,omitempty" db:"content_id"`
+	ContentTitle     *string           `json:"content_title,omitempty" db:"content_title"`
+	GuestVerifiedAt  *time.Time        `json:"guest_verified_at,omitempty" db:"guest_verified_at"`
+	CreatedAt        time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at" db:"updated_at"`
+	Parent           *Comment          `json:"parent,omitempty" db:"-"`
+	Replies          []*Comment        `json:"replies,omitempty" db:"-"`
+	User             *User             `json:"user,omitempty" db:"-"`
+	Author           *CommentAuthor    `json:"author,omitempty" db:"-"`
+	Likes            []*LikeComment    `json:"likes,omitempty" db:"-"`
+	Mentions         []*CommentMention `json:"mentions,omitempty" db:"-"`
+	LikesCount       int64             `json:"likes_count" db:"likes_count"`
+	RepliesCount     int64             `json:"replies_count" db:"replies_count"`
+	IsLikedByMe      bool              `json:"is_liked_by_me" db:"-"`
+	Depth            int               `json:"depth,omitempty" db:"depth"`
+	Path             string            `json:"path,omitempty" db:"path"`
+}
+
+type CommentAuthor struct {
+	ID        *uuid.UUID `json:"id,omitempty"`
+	Name      string     `json:"name"`
+	AvatarURL *string    `json:"avatar_url,omitempty"`
+	IsGuest   bool       `json:"is_guest"`
+}
+
+// CommentMention is a resolved @username. Offset and Length are in runes of the comment body.
+type CommentMention struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	AvatarURL *string   `json:"avatar_url,omitempty"`
+	Offset    int       `json:"offset"`
+	Length    int       `json:"length"`
+}
+
+type CommentNotificationSettings struct {
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	MuteMentions bool       `json:"mute_mentions" db:"mute_mentions"`
+	MuteReplies  bool       `json:"mute_replies" db:"mute_replies"`
+	EmailEnabled bool       `json:"email_enabled" db:"email_enabled"`
+	MutedUntil   *time.Time `json:"muted_until,omitempty" db:"muted_until"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type CommentAuthorReputation struct {
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	Score          int        `json:"score" db:"score"`
+	ApprovedCount  int        `json:"approved_count" db:"approved_count"`
+	RejectedCount  int        `json:"rejected_count" db:"rejected_count"`
+	IsShadowBanned bool       `json:"is_shadow_banned" db:"is_shadow_banned"`
+	ShadowBannedBy *uuid.UUID `json:"shadow_banned_by,omitempty" db:"shadow_banned_by"`
+	ShadowBannedAt *time.Time `json:"shadow_banned_at,omitempty" db:"shadow_banned_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 type LikeComment struct {
@@ -41,17 +103,21 @@ type LikeComment struct {
 }
 
 type CommentCreateRequest struct {
-	PageURL   string     `json:"page_url" validate:"required"`
-	Comment   string     `json:"comment" validate:"required"`
+	PageURL   string     `json:"page_url" validate:"required,max=2048"`
+	Comment   string     `json:"comment" validate:"required,max=5000"`
 	Type      string     `json:"type" validate:"omitempty,oneof=text image video link document other"`
 	ReplyToID *uuid.UUID `json:"reply_to_id,omitempty"`
 	MediaURL  *string    `json:"media_url,omitempty"`
-	Name      *string    `json:"name,omitempty"`
-	Email     *string    `json:"email,omitempty" validate:"omitempty,email"`
+	Name      *string    `json:"name,omitempty" validate:"omitempty,max=100"`
+	Email     *string    `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	// Optional title reference; derived from page_url when omitted.
+	ContentType  string `json:"content_type,omitempty" validate:"omitempty,oneof=movies series anime"`
+	ContentID    string `json:"content_id,omitempty" validate:"omitempty,max=255"`
+	ContentTitle string `json:"content_title,omitempty" validate:"omitempty,max=255"`
 }
 type CommentUpdateRequest struct {
-	Comment  *string `json:"comment,omitempty"`
-	MediaURL *string `json:"media_url,omitempty"`
+	Comment  *string `json:"comment,omitempty" validate:"omitempty,min=1,max=5000"`
+	MediaURL *string `json:"media_url,omitempty" validate:"omitempty,url"`
 }
 type CommentResponse struct {
 	*Comment
@@ -59,16 +125,25 @@ type CommentResponse struct {
 	Parent  *CommentResponse   `json:"parent,omitempty"`
 }
 type CommentFilter struct {
-	PageURL        string     `json:"page_url"`
-	UserID         *uuid.UUID `json:"user_id,omitempty"`
-	ParentID       *uuid.UUID `json:"parent_id,omitempty"`
-	Type           string     `json:"type,omitempty"`
-	SortBy         string     `json:"sort_by" validate:"omitempty,oneof=created_at likes_count replies_count"`
-	SortOrder      string     `json:"sort_order" validate:"omitempty,oneof=asc desc"`
-	Limit          int        `json:"limit" validate:"omitempty,min=1,max=100"`
-	Offset         int        `json:"offset" validate:"omitempty,min=0"`
-	IncludeReplies bool       `json:"include_replies"`
-	Depth          int        `json:"depth" validate:"omitempty,min=1,max=10"`
+	PageURL        string     `json:"page_url" query:"page_url"`
+	UserID         *uuid.UUID `json:"user_id,omitempty" query:"user_id"`
+	ParentID       *uuid.UUID `json:"parent_id,omitempty" query:"parent_id"`
+	Type           string     `json:"type,omitempty" query:"type"`
+	SortBy         string     `json:"sort_by" query:"sort_by" validate:"omitempty,oneof=created_at likes_count replies_count"`
+	SortOrder      string     `json:"sort_order" query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Limit          int        `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset         int        `json:"offset" query:"offset" validate:"omitempty,min=0"`
+	IncludeReplies bool       `json:"include_replies" query:"include_replies"`
+	Depth          int        `json:"depth" query:"depth" validate:"omitempty,min=1,max=10"`
+	ViewerID       *uuid.UUID `json:"-" query:"-"`
+}
+type CommentModerationFilter struct {
+	Status    string     `json:"status" query:"status" validate:"omitempty,oneof=approved pending rejected unverified"`
+	PageURL   string     `json:"page_url,omitempty" query:"page_url"`
+	UserID    *uuid.UUID `json:"user_id,omitempty" query:"user_id"`
+	SortOrder string     `json:"sort_order" query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Limit     int        `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset    int        `json:"offset" query:"offset" validate:"omitempty,min=0"`
 }
 type CommentTree struct {
 	Comment  *Comment       `json:"comment"`
@@ -83,6 +158,25 @@ type CommentStats struct {
 	CommentsByType map[string]int64 `json:"comments_by_type"`
 	CommentsByHour map[int]int64    `json:"comments_by_hour"`
 }
+type CommentStatsFilter struct {
+	PageURL     string    `json:"page_url,omitempty" query:"page_url" validate:"omitempty,max=2048"`
+	ContentType string    `json:"content_type,omitempty" query:"content_type" validate:"omitempty,oneof=movies series anime"`
+	ContentID   string    `json:"content_id,omitempty" query:"content_id" validate:"omitempty,max=255"`
+	From        string    `json:"from,omitempty" query:"from"`
+	To          string    `json:"to,omitempty" query:"to"`
+	Limit       int       `json:"limit,omitempty" query:"limit" validate:"omitempty,min=1,max=50"`
+	DateFrom    time.Time `json:"-" query:"-"`
+	DateTo      time.Time `json:"-" query:"-"`
+}
+type CommentLeaderboardEntry struct {
+	ContentType       string    `json:"content_type" db:"content_type"`
+	ContentID         string    `json:"content_id" db:"content_id"`
+	ContentTitle      *string   `json:"content_title,omitempty" db:"content_title"`
+	CommentsCount     int64     `json:"comments_count" db:"comments_count"`
+	ParticipantsCount int64     `json:"participants_count" db:"participants_count"`
+	LikesCount        int64     `json:"likes_count" db:"likes_count"`
+	LastCommentAt     time.Time `json:"last_comment_at" db:"last_comment_at"`
+}
 type CommentWithUserInfo struct {
 	Comment
 	UserName      string  `json:"user_name" db:"user_name"`
@@ -92,6 +186,77 @@ type CommentWithUserInfo struct {
 	ParentContent *string `json:"parent_content,omitempty" db:"parent_content"`
 }
 
+func (f *CommentFilter) SetDefaults() {
+	if f.Limit <= 0 {
+		f.Limit = 20
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	if f.SortBy == "" {
+		f.SortBy = "created_at"
+	}
+	if f.SortOrder == "" {
+		f.SortOrder = "desc"
+	}
+	if f.Depth <= 0 {
+		f.Depth = 3
+	}
+	if f.Depth > 10 {
+		f.Depth = 10
+	}
+}
+
+func (f *CommentModerationFilter) SetDefaults() {
+	if f.Status == "" {
+		f.Status = CommentStatusPending
+	}
+	if f.SortOrder == "" {
+		f.SortOrder = "asc"
+	}
+	if f.Limit <= 0 {
+		f.Limit = 50
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+}
+
+func (c *Comment) IsGuest() bool {
+	return c.UserID == nil
+}
+
+func (c *Comment) IsAuthoredBy(userID *uuid.UUID) bool {
+	return userID != nil && c.UserID != nil && *userID == *c.UserID
+}
+
+func (c *Comment) IsVisibleTo(viewerID *uuid.UUID) bool {
+	if c.IsAuthoredBy(viewerID) {
+		return true
+	}
+	return c.Status == CommentStatusApproved
+}
+
+// Allows reports whether a notification for reason (mention or reply) may be delivered.
+func (s *CommentNotificationSettings) Allows(reason string, now time.Time) bool {
+	if s.MutedUntil != nil && s.MutedUntil.After(now) {
+		return false
+	}
+	switch reason {
+	case CommentNotificationMention:
+		return !s.MuteMentions
+	case CommentNotificationReply:
+		return !s.MuteReplies
+	}
+	return true
+}
+
 func (c *Comment) IsRoot() bool {
 	return c.ReplyToID == nil
 }
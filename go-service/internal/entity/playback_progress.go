@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlaybackProgress is how far a user got in a title. For series and anime it tracks the episode
+// being watched, and NextEpisodeNumber/NextEpisodeURL point at the one after it when known.
+// WatchedAt is the time of the latest heartbeat.
+type PlaybackProgress struct {
+	ID                uuid.UUID       `json:"id" db:"id"`
+	UserID            uuid.UUID       `json:"user_id" db:"user_id"`
+	Type              ViewHistoryType `json:"type" db:"type"`
+	Slug              string          `json:"slug" db:"slug"`
+	Name              *string         `json:"name,omitempty" db:"name"`
+	EpisodeNumber     *int            `json:"episode_number,omitempty" db:"episode_number"`
+	EpisodeURL        *string         `json:"episode_url,omitempty" db:"episode_url"`
+	PositionSeconds   int             `json:"position_seconds" db:"position_seconds"`
+	DurationSeconds   int             `json:"duration_seconds" db:"duration_seconds"`
+	Completed         bool            `json:"completed" db:"completed"`
+	NextEpisodeNumber *int            `json:"next_episode_number,omitempty" db:"next_episode_number"`
+	NextEpisodeURL    *string         `json:"next_episode_url,omitempty" db:"next_episode_url"`
+	WatchedAt         time.Time       `json:"watched_at" db:"watched_at"`
+	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// SameEpisode reports whether p and other are at the same episode of their title.
+func (p *PlaybackProgress) SameEpisode(other *PlaybackProgress) bool {
+	return equalIntPtr(p.EpisodeNumber, other.EpisodeNumber) && equalStringPtr(p.EpisodeURL, other.EpisodeURL)
+}
+
+// InProgress reports whether the title belongs in continue-watching: it was left part way,
+// or the finished episode has a next one to go on to.
+func (p *PlaybackProgress) InProgress() bool {
+	if p.Completed {
+		return p.NextEpisodeURL != nil || p.NextEpisodeNumber != nil
+	}
+	return p.PositionSeconds > 0
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReportTargetComment     = "comment"
+	ReportTargetChatMessage = "chat_message"
+)
+
+const (
+	ReportReasonSpam    = "spam"
+	ReportReasonAbuse   = "abuse"
+	ReportReasonSpoiler = "spoiler"
+	ReportReasonIllegal = "illegal"
+)
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+const (
+	ReportActionDismiss = "dismiss"
+	ReportActionDelete  = "delete"
+	ReportActionWarn    = "warn"
+	ReportActionSuspend = "suspend"
+)
+
+// ReportHiddenReasonPrefix marks comments moved back to pending by the report threshold,
+// so dismissing the reports only restores comments that reports hid.
+const ReportHiddenReasonPrefix = "reports: "
+
+type Report struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	TargetType     string     `json:"target_type" db:"target_type"`
+	TargetID       uuid.UUID  `json:"target_id" db:"target_id"`
+	TargetAuthorID *uuid.UUID `json:"target_author_id,omitempty" db:"target_author_id"`
+	ReporterID     uuid.UUID  `json:"reporter_id" db:"reporter_id"`
+	Reason         string     `json:"reason" db:"reason"`
+	Details        *string    `json:"details,omitempty" db:"details"`
+	Status         string     `json:"status" db:"status"`
+	Action         *string    `json:"action,omitempty" db:"action"`
+	ResolutionNote *string    `json:"resolution_note,omitempty" db:"resolution_note"`
+	ResolvedBy     *uuid.UUID `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	ReporterName   string     `json:"reporter_name,omitempty" db:"reporter_name"`
+}
+
+// ReportCase groups every report filed against one comment or chat message.
+type ReportCase struct {
+	TargetType      string           `json:"target_type"`
+	TargetID        uuid.UUID        `json:"target_id"`
+	TargetAuthorID  *uuid.UUID       `json:"target_author_id,omitempty"`
+	AuthorName      *string          `json:"author_name,omitempty"`
+	Excerpt         *string          `json:"excerpt,omitempty"`
+	IsHidden        bool             `json:"is_hidden"`
+	IsDeleted       bool             `json:"is_deleted"`
+	ReportsCount    int64            `json:"reports_count"`
+	Reasons         map[string]int64 `json:"reasons"`
+	FirstReportedAt time.Time        `json:"first_reported_at"`
+	LastReportedAt  time.Time        `json:"last_reported_at"`
+	Reports         []*Report        `json:"reports,omitempty"`
+}
+
+type ReportFilter struct {
+	Status     string `json:"status" query:"status" validate:"omitempty,oneof=open resolved dismissed"`
+	TargetType string `json:"target_type,omitempty" query:"target_type" validate:"omitempty,oneof=comment chat_message"`
+	Reason     string `json:"reason,omitempty" query:"reason" validate:"omitempty,oneof=spam abuse spoiler illegal"`
+	SortBy     string `json:"sort_by" query:"sort_by" validate:"omitempty,oneof=reports_count last_reported_at"`
+	SortOrder  string `json:"sort_order" query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Limit      int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset     int    `json:"offset" query:"offset" validate:"omitempty,min=0"`
+}
+
+func (f *ReportFilter) SetDefaults() {
+	if f.Status == "" {
+		f.Status = ReportStatusOpen
+	}
+	if f.SortBy == "" {
+		f.SortBy = "reports_count"
+	}
+	if f.SortOrder == "" {
+		f.SortOrder = "desc"
+	}
+	if f.Limit <= 0 {
+		f.Limit = 50
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+}
@@ -12,21 +12,134 @@ const (
 	TicketStatusClosed   = "closed"
 )
 
+const (
+	TicketSourceApp     = "app"
+	TicketSourceContact = "contact"
+)
+
+const (
+	TicketReplyViaApp   = "app"
+	TicketReplyViaEmail = "email"
+)
+
+const (
+	TicketCategoryGeneral   = "general"
+	TicketCategoryAccount   = "account"
+	TicketCategoryBilling   = "billing"
+	TicketCategoryPlayback  = "playback"
+	TicketCategoryContent   = "content"
+	TicketCategoryTechnical = "technical"
+)
+
+const (
+	TicketPriorityLow    = "low"
+	TicketPriorityNormal = "normal"
+	TicketPriorityHigh   = "high"
+	TicketPriorityUrgent = "urgent"
+)
+
 type TicketStatus string
 
+// ticketTransitions lists the statuses each status may move to. Closed is final; a resolved
+// ticket may be reopened.
+var ticketTransitions = map[TicketStatus][]TicketStatus{
+	TicketStatusOpen:     {TicketStatusResolved},
+	TicketStatusResolved: {TicketStatusOpen, TicketStatusClosed},
+}
+
+// Ticket is a support request. Contact form tickets from visitors without an account have no
+// UserID and are tied to ContactEmail instead. The SLA due times come from the policy matching
+// the ticket's category and priority, and are empty when no policy applies.
 type Ticket struct {
-	ID          uuid.UUID    `json:"id" gorm:"primaryKey"`
-	UserID      uuid.UUID    `json:"user_id" gorm:"not null"`
-	Title       string       `json:"title" gorm:"not null"`
-	Description string       `json:"description" gorm:"not null"`
-	Status      TicketStatus `json:"status" gorm:"not null;default:'open'"`
-	CreatedAt   time.Time    `json:"created_at" gorm:"not null;default:now()"`
-	UpdatedAt   time.Time    `json:"updated_at" gorm:"not null;default:now()"`
+	ID                 uuid.UUID           `json:"id" db:"id"`
+	Number             string              `json:"number" db:"number"`
+	UserID             *uuid.UUID          `json:"user_id,omitempty" db:"user_id"`
+	Source             string              `json:"source" db:"source"`
+	ContactName        *string             `json:"contact_name,omitempty" db:"contact_name"`
+	ContactEmail       *string             `json:"contact_email,omitempty" db:"contact_email"`
+	ReplyToken         string              `json:"-" db:"reply_token"`
+	Title              string              `json:"title" db:"title"`
+	Description        string              `json:"description" db:"description"`
+	Category           string              `json:"category" db:"category"`
+	Status             TicketStatus        `json:"status" db:"status"`
+	Priority           string              `json:"priority" db:"priority"`
+	AssigneeID         *uuid.UUID          `json:"assignee_id,omitempty" db:"assignee_id"`
+	LastReplyAt        *time.Time          `json:"last_reply_at,omitempty" db:"last_reply_at"`
+	FirstResponseAt    *time.Time          `json:"first_response_at,omitempty" db:"first_response_at"`
+	ResolvedAt         *time.Time          `json:"resolved_at,omitempty" db:"resolved_at"`
+	ClosedAt           *time.Time          `json:"closed_at,omitempty" db:"closed_at"`
+	CreatedAt          time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at" db:"updated_at"`
+	FirstResponseDueAt *time.Time          `json:"first_response_due_at,omitempty" db:"first_response_due_at"`
+	ResolutionDueAt    *time.Time          `json:"resolution_due_at,omitempty" db:"resolution_due_at"`
+	UserName           string              `json:"user_name,omitempty" db:"user_name"`
+	AssigneeName       *string             `json:"assignee_name,omitempty" db:"assignee_name"`
+	Attachments        []*TicketAttachment `json:"attachments,omitempty" db:"-"`
+	Replies            []*TicketReply      `json:"replies,omitempty" db:"-"`
+}
+
+type TicketReply struct {
+	ID          uuid.UUID           `json:"id" db:"id"`
+	TicketID    uuid.UUID           `json:"ticket_id" db:"ticket_id"`
+	AuthorID    *uuid.UUID          `json:"author_id,omitempty" db:"author_id"`
+	Body        string              `json:"body" db:"body"`
+	IsStaff     bool                `json:"is_staff" db:"is_staff"`
+	Via         string              `json:"via" db:"via"`
+	MessageID   *string             `json:"-" db:"email_message_id"`
+	CreatedAt   time.Time           `json:"created_at" db:"created_at"`
+	AuthorName  string              `json:"author_name,omitempty" db:"author_name"`
+	Attachments []*TicketAttachment `json:"attachments,omitempty" db:"-"`
+}
+
+// TicketAttachment is a file on the opening message (ReplyID nil) or on a reply.
+type TicketAttachment struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	TicketID   uuid.UUID  `json:"ticket_id" db:"ticket_id"`
+	ReplyID    *uuid.UUID `json:"reply_id,omitempty" db:"reply_id"`
+	UploaderID *uuid.UUID `json:"uploader_id,omitempty" db:"uploader_id"`
+	ObjectKey  string     `json:"-" db:"object_key"`
+	URL        string     `json:"url" db:"url"`
+	Name       string     `json:"name" db:"name"`
+	Size       int64      `json:"size" db:"size"`
+	MimeType   string     `json:"mime_type" db:"mime_type"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
 }
 
-func (t *Ticket) TableName() string {
-	return "tickets"
+// TicketFilter pages through tickets. UserID is set from the session for a user's own
+// listing and is never read from the query string.
+type TicketFilter struct {
+	UserID     *uuid.UUID `json:"-" query:"-"`
+	Status     string     `json:"status,omitempty" query:"status" validate:"omitempty,oneof=open resolved closed"`
+	Source     string     `json:"source,omitempty" query:"source" validate:"omitempty,oneof=app contact"`
+	Category   string     `json:"category,omitempty" query:"category" validate:"omitempty,oneof=general account billing playback content technical"`
+	Priority   string     `json:"priority,omitempty" query:"priority" validate:"omitempty,oneof=low normal high urgent"`
+	AssigneeID string     `json:"assignee_id,omitempty" query:"assignee_id" validate:"omitempty,uuid"`
+	Unassigned bool       `json:"unassigned,omitempty" query:"unassigned"`
+	Search     string     `json:"search,omitempty" query:"search" validate:"omitempty,max=255"`
+	SortBy     string     `json:"sort_by" query:"sort_by" validate:"omitempty,oneof=created_at updated_at priority"`
+	SortOrder  string     `json:"sort_order" query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Limit      int        `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset     int        `json:"offset" query:"offset" validate:"omitempty,min=0"`
 }
+
+func (f *TicketFilter) SetDefaults() {
+	if f.SortBy == "" {
+		f.SortBy = "updated_at"
+	}
+	if f.SortOrder == "" {
+		f.SortOrder = "desc"
+	}
+	if f.Limit <= 0 {
+		f.Limit = 20
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+}
+
 func (t *TicketStatus) String() string {
 	return string(*t)
 }
@@ -39,3 +152,13 @@ func (t *TicketStatus) IsResolved() bool {
 func (t *TicketStatus) IsClosed() bool {
 	return *t == TicketStatusClosed
 }
+
+// CanTransitionTo reports whether the workflow allows moving from t to next.
+func (t *TicketStatus) CanTransitionTo(next TicketStatus) bool {
+	for _, allowed := range ticketTransitions[*t] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
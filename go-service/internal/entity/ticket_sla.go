@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TicketSLAFirstResponse = "first_response"
+	TicketSLAResolution    = "resolution"
+)
+
+// TicketSLAPolicy sets response targets for tickets of one priority. A policy with a category
+// applies to that category only and takes precedence over the default policy, which has none.
+type TicketSLAPolicy struct {
+	ID                   uuid.UUID `json:"id" db:"id"`
+	Category             *string   `json:"category,omitempty" db:"category"`
+	Priority             string    `json:"priority" db:"priority"`
+	FirstResponseMinutes int       `json:"first_response_minutes" db:"first_response_minutes"`
+	ResolutionMinutes    int       `json:"resolution_minutes" db:"resolution_minutes"`
+	IsActive             bool      `json:"is_active" db:"is_active"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TicketSLABreach is an open ticket that has missed one of its targets and was not yet
+// escalated for it.
+type TicketSLABreach struct {
+	TicketID     uuid.UUID  `json:"ticket_id" db:"ticket_id"`
+	Number       string     `json:"number" db:"number"`
+	Title        string     `json:"title" db:"title"`
+	Category     string     `json:"category" db:"category"`
+	Priority     string     `json:"priority" db:"priority"`
+	AssigneeID   *uuid.UUID `json:"assignee_id,omitempty" db:"assignee_id"`
+	AssigneeName *string    `json:"assignee_name,omitempty" db:"assignee_name"`
+	Kind         string     `json:"kind" db:"kind"`
+	DueAt        time.Time  `json:"due_at" db:"due_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TicketSLAMetric summarises one target over the tickets opened in the report window. Tickets
+// still inside their target count as pending and are left out of the compliance rate.
+type TicketSLAMetric struct {
+	Met            int64    `json:"met"`
+	Breached       int64    `json:"breached"`
+	Pending        int64    `json:"pending"`
+	ComplianceRate *float64 `json:"compliance_rate"`
+	MedianMinutes  *float64 `json:"median_minutes"`
+}
+
+type TicketBacklogBucket struct {
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// TicketBacklog describes the tickets currently waiting on staff, by age.
+type TicketBacklog struct {
+	Open            int64                 `json:"open"`
+	Breached        int64                 `json:"breached"`
+	OldestAgeHours  *float64              `json:"oldest_age_hours"`
+	MedianAgeHours  *float64              `json:"median_age_hours"`
+	AverageAgeHours *float64              `json:"average_age_hours"`
+	Buckets         []TicketBacklogBucket `json:"buckets"`
+}
+
+type TicketSLAReport struct {
+	Since         time.Time       `json:"since"`
+	Category      string          `json:"category,omitempty"`
+	Tickets       int64           `json:"tickets"`
+	FirstResponse TicketSLAMetric `json:"first_response"`
+	Resolution    TicketSLAMetric `json:"resolution"`
+	Backlog       TicketBacklog   `json:"backlog"`
+}
@@ -10,6 +10,7 @@ import (
 type User struct {
 	ID              uuid.UUID  `json:"id" db:"id"`
 	Email           string     `json:"email" db:"email" validate:"required,email,max=255"`
+	Username        NullString `json:"username,omitempty" db:"username"`
 	PasswordHash    string     `json:"-" db:"password_hash"`
 	FullName        string     `json:"full_name" db:"full_name" validate:"required,max=255"`
 	RoleID          uuid.UUID  `json:"role_id" db:"role_id"`
@@ -39,6 +40,7 @@ func (u *User) ToResponse() *User {
 	return &User{
 		ID:              u.ID,
 		Email:           u.Email,
+		Username:        u.Username,
 		FullName:        u.FullName,
 		Phone:           u.Phone,
 		AvatarURL:       u.AvatarURL,
@@ -81,3 +83,29 @@ func (u *User) UpdateLastLogin() {
 func (u *User) IsTwoFaActive() bool {
 	return u.TwoFaSecret.Valid
 }
+
+// UserBlock is a user the blocker has blocked, with enough profile to list them.
+type UserBlock struct {
+	UserID    uuid.UUID  `json:"user_id" db:"blocked_id"`
+	FullName  string     `json:"full_name" db:"full_name"`
+	Username  NullString `json:"username,omitempty" db:"username"`
+	AvatarURL NullString `json:"avatar_url,omitempty" db:"avatar_url"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+type UserBlockFilter struct {
+	Limit  int `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int `json:"offset" query:"offset" validate:"omitempty,min=0"`
+}
+
+func (f *UserBlockFilter) SetDefaults() {
+	if f.Limit <= 0 {
+		f.Limit = 50
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+}
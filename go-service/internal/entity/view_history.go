@@ -17,12 +17,14 @@ type ViewHistoryType string
 type ViewHistory struct {
 	ID              uuid.UUID       `json:"id" db:"id"`
 	UserID          uuid.UUID       `json:"user_id" db:"user_id"`
+	Slug            *string         `json:"slug,omitempty" db:"slug"`
 	Name            *string         `json:"name,omitempty" db:"name"`
 	PageURL         *string         `json:"page_url,omitempty" db:"page_url"`
 	IPAddress       *string         `json:"ip_address,omitempty" db:"ip_address"`
 	UserAgent       *string         `json:"user_agent,omitempty" db:"user_agent"`
 	BrowserLanguage *string         `json:"browser_language,omitempty" db:"browser_language"`
 	DeviceType      *string         `json:"device_type,omitempty" db:"device_type"`
+	Browser         *string         `json:"browser,omitempty" db:"browser"`
 	Platform        string          `json:"platform" db:"platform"`
 	ViewTime        time.Time       `json:"view_time" db:"view_time"`
 	Type            ViewHistoryType `json:"type" db:"type"`
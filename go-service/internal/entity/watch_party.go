@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WatchPartyContentMovie   = "movie"
+	WatchPartyContentEpisode = "episode"
+)
+
+const (
+	WatchPartyActionPlay   = "play"
+	WatchPartyActionPause  = "pause"
+	WatchPartyActionSeek   = "seek"
+	WatchPartyActionSource = "source"
+)
+
+// WatchPartyContent is the movie or episode a party watches. It is stored in the room metadata.
+type WatchPartyContent struct {
+	ContentType string      `json:"content_type"`
+	Source      string      `json:"source"`
+	Title       string      `json:"title"`
+	Thumbnail   *string     `json:"thumbnail,omitempty"`
+	PlayerUrls  []PlayerUrl `json:"player_urls"`
+}
+
+// WatchPartyState is the shared playback position. Position is in seconds and was current at
+// UpdatedAt; while playing, clients extrapolate from there to correct drift.
+type WatchPartyState struct {
+	Position    float64    `json:"position"`
+	Playing     bool       `json:"playing"`
+	PlayerIndex int        `json:"player_index"`
+	PlayerUrl   *PlayerUrl `json:"player_url,omitempty"`
+	Seq         int64      `json:"seq"`
+	UpdatedBy   *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type WatchParty struct {
+	Room       *ChatRoom          `json:"room"`
+	Content    *WatchPartyContent `json:"content"`
+	State      *WatchPartyState   `json:"state"`
+	ServerTime time.Time          `json:"server_time"`
+}
+
+// PositionAt extrapolates the playback position to the given time.
+func (s *WatchPartyState) PositionAt(now time.Time) float64 {
+	if !s.Playing || now.Before(s.UpdatedAt) {
+		return s.Position
+	}
+	return s.Position + now.Sub(s.UpdatedAt).Seconds()
+}
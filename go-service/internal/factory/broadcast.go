@@ -0,0 +1,60 @@
+package factory
+
+import (
+	"tubexxi/video-api/internal/dependencies"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/routes"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BroadcastFactory struct {
+	service *service.BroadcastService
+	handler *handler.BroadcastHandler
+	routes  *routes.BroadcastRoutes
+}
+
+func NewBroadcastFactory(cont *dependencies.Container, mw *MiddlewareFactory) *BroadcastFactory {
+	chatService := service.NewChatService(
+		cont.ChatRoomRepo,
+		cont.UserRepo,
+		cont.CentrifugoClient,
+		cont.RedisClient,
+		cont.MinioClient,
+		cont.AppConfig,
+		cont.Logger,
+	)
+	service := service.NewBroadcastService(
+		cont.BroadcastRepo,
+		cont.ChatRoomRepo,
+		chatService,
+		cont.AsynqClient,
+		cont.FirebaseClient,
+		cont.EmailHelper,
+		&cont.AppConfig.App,
+		cont.Logger,
+	)
+	handler := handler.NewBroadcastHandler(
+		mw.ContextMiddleware,
+		mw.ScopeMiddleware,
+		service,
+		cont.Logger,
+	)
+	return &BroadcastFactory{
+		service: service,
+		handler: handler,
+		routes: routes.NewBroadcastRoutes(
+			handler,
+			mw.ContextMiddleware,
+			mw.RateLimiter,
+			mw.AuthMiddleware,
+			mw.AdminMiddleware,
+			mw.CSRFMiddleware,
+			mw.ScopeMiddleware,
+		),
+	}
+}
+func (f *BroadcastFactory) GetRoutes(router fiber.Router) {
+	f.routes.RegisterRoutes(router)
+}
@@ -0,0 +1,48 @@
+package factory
+
+import (
+	"tubexxi/video-api/internal/dependencies"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/routes"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ChatFactory struct {
+	service *service.ChatService
+	handler *handler.ChatHandler
+	routes  *routes.ChatRoutes
+}
+
+func NewChatFactory(cont *dependencies.Container, mw *MiddlewareFactory) *ChatFactory {
+	service := service.NewChatService(
+		cont.ChatRoomRepo,
+		cont.UserRepo,
+		cont.CentrifugoClient,
+		cont.RedisClient,
+		cont.MinioClient,
+		cont.AppConfig,
+		cont.Logger,
+	)
+	handler := handler.NewChatHandler(
+		mw.ContextMiddleware,
+		mw.ScopeMiddleware,
+		service,
+		cont.Logger,
+	)
+	return &ChatFactory{
+		service: service,
+		handler: handler,
+		routes: routes.NewChatRoutes(
+			handler,
+			mw.ContextMiddleware,
+			mw.RateLimiter,
+			mw.AuthMiddleware,
+			mw.ScopeMiddleware,
+		),
+	}
+}
+func (f *ChatFactory) GetRoutes(router fiber.Router) {
+	f.routes.RegisterRoutes(router)
+}
@@ -20,7 +20,16 @@ func NewClientFactory(
 	mw *MiddlewareFactory,
 ) *ClientFactory {
 	s := service.NewClientService(
-		cont.EmailHelper,
+		service.NewTicketService(
+			cont.TicketRepo,
+			cont.UserRepo,
+			cont.MinioClient,
+			cont.RedisClient,
+			cont.EmailHelper,
+			cont.Notifier,
+			cont.AppConfig,
+			cont.Logger,
+		),
 		cont.Logger,
 	)
 	h := handler.NewClientHandler(
@@ -35,6 +44,7 @@ func NewClientFactory(
 		routes: routes.NewClientRoutes(
 			h,
 			mw.RateLimiter,
+			mw.AuthMiddleware,
 			mw.CSRFMiddleware,
 		),
 	}
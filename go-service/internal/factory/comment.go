@@ -0,0 +1,51 @@
+package factory
+
+import (
+	"tubexxi/video-api/internal/dependencies"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/routes"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CommentFactory struct {
+	service *service.CommentService
+	handler *handler.CommentHandler
+	routes  *routes.CommentRoutes
+}
+
+func NewCommentFactory(cont *dependencies.Container, mw *MiddlewareFactory) *CommentFactory {
+	service := service.NewCommentService(
+		cont.CommentRepo,
+		cont.UserRepo,
+		service.NewDefaultModerationChain(&cont.AppConfig.Comment),
+		cont.CentrifugoClient,
+		cont.Notifier,
+		cont.RedisClient,
+		cont.EmailHelper,
+		&cont.AppConfig.App,
+		&cont.AppConfig.Comment,
+		cont.Logger,
+	)
+	handler := handler.NewCommentHandler(
+		mw.ContextMiddleware,
+		service,
+		cont.Logger,
+	)
+	return &CommentFactory{
+		service: service,
+		handler: handler,
+		routes: routes.NewCommentRoutes(
+			handler,
+			mw.ContextMiddleware,
+			mw.RateLimiter,
+			mw.AuthMiddleware,
+			mw.AdminMiddleware,
+			mw.CSRFMiddleware,
+		),
+	}
+}
+func (f *CommentFactory) GetRoutes(router fiber.Router) {
+	f.routes.RegisterRoutes(router)
+}
@@ -0,0 +1,62 @@
+package factory
+
+import (
+	"tubexxi/video-api/internal/dependencies"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/routes"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RealtimeFactory struct {
+	service      *service.RealtimeService
+	handler      *handler.RealtimeHandler
+	proxyHandler *handler.CentrifugoProxyHandler
+	routes       *routes.RealtimeRoutes
+}
+
+func NewRealtimeFactory(cont *dependencies.Container, mw *MiddlewareFactory) *RealtimeFactory {
+	chatService := service.NewChatService(
+		cont.ChatRoomRepo,
+		cont.UserRepo,
+		cont.CentrifugoClient,
+		cont.RedisClient,
+		cont.MinioClient,
+		cont.AppConfig,
+		cont.Logger,
+	)
+	service := service.NewRealtimeService(
+		cont.CentrifugoClient,
+		cont.ChatRoomRepo,
+		chatService,
+		cont.Logger,
+	)
+	proxyHandler := handler.NewCentrifugoProxyHandler(
+		mw.ContextMiddleware,
+		mw.AuthMiddleware,
+		service,
+		&cont.AppConfig.Centrifugo,
+		cont.Logger,
+	)
+	handler := handler.NewRealtimeHandler(
+		mw.ContextMiddleware,
+		service,
+		cont.Logger,
+	)
+	return &RealtimeFactory{
+		service:      service,
+		handler:      handler,
+		proxyHandler: proxyHandler,
+		routes: routes.NewRealtimeRoutes(
+			handler,
+			proxyHandler,
+			mw.ContextMiddleware,
+			mw.RateLimiter,
+			mw.AuthMiddleware,
+		),
+	}
+}
+func (f *RealtimeFactory) GetRoutes(router fiber.Router) {
+	f.routes.RegisterRoutes(router)
+}
@@ -0,0 +1,49 @@
+package factory
+
+import (
+	"tubexxi/video-api/internal/dependencies"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/routes"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReportFactory struct {
+	service *service.ReportService
+	handler *handler.ReportHandler
+	routes  *routes.ReportRoutes
+}
+
+func NewReportFactory(cont *dependencies.Container, mw *MiddlewareFactory) *ReportFactory {
+	service := service.NewReportService(
+		cont.ReportRepo,
+		cont.CommentRepo,
+		cont.ChatRoomRepo,
+		cont.UserRepo,
+		cont.CentrifugoClient,
+		cont.Notifier,
+		&cont.AppConfig.Report,
+		cont.Logger,
+	)
+	handler := handler.NewReportHandler(
+		mw.ContextMiddleware,
+		service,
+		cont.Logger,
+	)
+	return &ReportFactory{
+		service: service,
+		handler: handler,
+		routes: routes.NewReportRoutes(
+			handler,
+			mw.ContextMiddleware,
+			mw.RateLimiter,
+			mw.AuthMiddleware,
+			mw.AdminMiddleware,
+			mw.CSRFMiddleware,
+		),
+	}
+}
+func (f *ReportFactory) GetRoutes(router fiber.Router) {
+	f.routes.RegisterRoutes(router)
+}
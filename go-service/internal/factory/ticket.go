@@ -0,0 +1,58 @@
+package factory
+
+import (
+	"tubexxi/video-api/internal/dependencies"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/routes"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TicketFactory struct {
+	service *service.TicketService
+	handler *handler.TicketHandler
+	routes  *routes.TicketRoutes
+}
+
+func NewTicketFactory(cont *dependencies.Container, mw *MiddlewareFactory) *TicketFactory {
+	slaService := service.NewTicketSLAService(
+		cont.TicketSLARepo,
+		cont.UserRepo,
+		cont.EmailHelper,
+		cont.Notifier,
+		cont.AppConfig,
+		cont.Logger,
+	)
+	service := service.NewTicketService(
+		cont.TicketRepo,
+		cont.UserRepo,
+		cont.MinioClient,
+		cont.RedisClient,
+		cont.EmailHelper,
+		cont.Notifier,
+		cont.AppConfig,
+		cont.Logger,
+	)
+	handler := handler.NewTicketHandler(
+		mw.ContextMiddleware,
+		service,
+		slaService,
+		cont.Logger,
+	)
+	return &TicketFactory{
+		service: service,
+		handler: handler,
+		routes: routes.NewTicketRoutes(
+			handler,
+			mw.ContextMiddleware,
+			mw.RateLimiter,
+			mw.AuthMiddleware,
+			mw.AdminMiddleware,
+			mw.CSRFMiddleware,
+		),
+	}
+}
+func (f *TicketFactory) GetRoutes(router fiber.Router) {
+	f.routes.RegisterRoutes(router)
+}
@@ -16,6 +16,21 @@ type UserFactory struct {
 }
 
 func NewUserFactory(cont *dependencies.Container, mw *MiddlewareFactory) *UserFactory {
+	viewHistoryService := service.NewViewHistoryService(
+		cont.ViewHistoryRepo,
+		cont.RedisClient,
+		cont.AsynqClient,
+		&cont.AppConfig.ViewHistory,
+		cont.Logger,
+	)
+	playbackService := service.NewPlaybackService(
+		cont.PlaybackRepo,
+		service.NewMovieService(cont.Logger, cont.ScraperClient),
+		service.NewAnimeService(cont.Logger, cont.ScraperClient),
+		cont.RedisClient,
+		&cont.AppConfig.Playback,
+		cont.Logger,
+	)
 	service := service.NewUserService(
 		cont.UserRepo,
 		cont.UserHelper,
@@ -27,6 +42,8 @@ func NewUserFactory(cont *dependencies.Container, mw *MiddlewareFactory) *UserFa
 	handler := handler.NewUserHandler(
 		mw.ContextMiddleware,
 		service,
+		viewHistoryService,
+		playbackService,
 		mw.RateLimiter,
 		cont.Logger,
 	)
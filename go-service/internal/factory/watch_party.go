@@ -0,0 +1,58 @@
+package factory
+
+import (
+	"tubexxi/video-api/internal/dependencies"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/routes"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WatchPartyFactory struct {
+	service *service.WatchPartyService
+	handler *handler.WatchPartyHandler
+	routes  *routes.WatchPartyRoutes
+}
+
+func NewWatchPartyFactory(cont *dependencies.Container, mw *MiddlewareFactory) *WatchPartyFactory {
+	chatService := service.NewChatService(
+		cont.ChatRoomRepo,
+		cont.UserRepo,
+		cont.CentrifugoClient,
+		cont.RedisClient,
+		cont.MinioClient,
+		cont.AppConfig,
+		cont.Logger,
+	)
+	movieService := service.NewMovieService(
+		cont.Logger,
+		cont.ScraperClient,
+	)
+	service := service.NewWatchPartyService(
+		cont.ChatRoomRepo,
+		chatService,
+		movieService,
+		cont.CentrifugoClient,
+		cont.RedisClient,
+		cont.Logger,
+	)
+	handler := handler.NewWatchPartyHandler(
+		mw.ContextMiddleware,
+		service,
+		cont.Logger,
+	)
+	return &WatchPartyFactory{
+		service: service,
+		handler: handler,
+		routes: routes.NewWatchPartyRoutes(
+			handler,
+			mw.ContextMiddleware,
+			mw.RateLimiter,
+			mw.AuthMiddleware,
+		),
+	}
+}
+func (f *WatchPartyFactory) GetRoutes(router fiber.Router) {
+	f.routes.RegisterRoutes(router)
+}
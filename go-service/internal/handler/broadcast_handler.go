@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"errors"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/middleware"
+	"tubexxi/video-api/internal/service"
+	"tubexxi/video-api/pkg/response"
+	"tubexxi/video-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type BroadcastHandler struct {
+	ctxinject        *middleware.ContextMiddleware
+	scopeMiddleware  *middleware.ScopeMiddleware
+	broadcastService *service.BroadcastService
+	logger           *zap.Logger
+}
+
+func NewBroadcastHandler(
+	ctxinject *middleware.ContextMiddleware,
+	scopeMiddleware *middleware.ScopeMiddleware,
+	broadcastService *service.BroadcastService,
+	logger *zap.Logger,
+) *BroadcastHandler {
+	return &BroadcastHandler{
+		ctxinject:        ctxinject,
+		scopeMiddleware:  scopeMiddleware,
+		broadcastService: broadcastService,
+		logger:           logger,
+	}
+}
+func (h *BroadcastHandler) ListChannels(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var filter entity.BroadcastChannelFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	filter.Scope = h.scopeMiddleware.GetSettingsScope(c)
+	filter.Platform, _ = c.Locals("platform").(string)
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	channels, pagination, err := h.broadcastService.ListChannels(ctx, userID, filter)
+	if err != nil {
+		return response.Error(c, broadcastErrorStatus(err), err.Error(), nil)
+	}
+	return response.SuccessWithMeta(c, "Broadcast channels retrieved", channels, pagination)
+}
+func (h *BroadcastHandler) RegisterDevice(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.DeviceRegisterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	platform, _ := c.Locals("platform").(string)
+	scope := h.scopeMiddleware.GetSettingsScope(c)
+
+	device, err := h.broadcastService.RegisterDevice(ctx, userID, platform, scope, &req)
+	if err != nil {
+		return response.Error(c, broadcastErrorStatus(err), err.Error(), nil)
+	}
+	return response.Created(c, "Device registered", device)
+}
+func (h *BroadcastHandler) UnregisterDevice(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.DeviceRegisterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	if err := h.broadcastService.UnregisterDevice(ctx, userID, &req); err != nil {
+		return response.Error(c, broadcastErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Device unregistered", nil)
+}
+func (h *BroadcastHandler) CreateChannel(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.BroadcastChannelCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	channel, err := h.broadcastService.CreateChannel(ctx, userID, &req)
+	if err != nil {
+		return response.Error(c, broadcastErrorStatus(err), err.Error(), nil)
+	}
+	return response.Created(c, "Broadcast channel created", channel)
+}
+func (h *BroadcastHandler) ListAllChannels(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var filter entity.BroadcastChannelFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	channels, pagination, err := h.broadcastService.ListChannels(ctx, userID, filter)
+	if err != nil {
+		return response.Error(c, broadcastErrorStatus(err), err.Error(), nil)
+	}
+	return response.SuccessWithMeta(c, "Broadcast channels retrieved", channels, pagination)
+}
+func (h *BroadcastHandler) ScheduleAnnouncement(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid channel ID", nil)
+	}
+
+	var req dto.AnnouncementCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	announcement, err := h.broadcastService.ScheduleAnnouncement(ctx, userID, roomID, &req)
+	if err != nil {
+		return response.Error(c, broadcastErrorStatus(err), err.Error(), nil)
+	}
+	return response.Created(c, "Announcement scheduled", announcement)
+}
+func (h *BroadcastHandler) ListAnnouncements(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid channel ID", nil)
+	}
+
+	var filter entity.AnnouncementFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	announcements, pagination, err := h.broadcastService.ListAnnouncements(ctx, roomID, filter)
+	if err != nil {
+		return response.Error(c, broadcastErrorStatus(err), err.Error(), nil)
+	}
+	return response.SuccessWithMeta(c, "Announcements retrieved", announcements, pagination)
+}
+func (h *BroadcastHandler) CancelAnnouncement(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid announcement ID", nil)
+	}
+
+	announcement, err := h.broadcastService.CancelAnnouncement(ctx, id)
+	if err != nil {
+		return response.Error(c, broadcastErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Announcement cancelled", announcement)
+}
+
+func broadcastErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrBroadcastChannelNotFound), errors.Is(err, dto.ErrAnnouncementNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, dto.ErrAnnouncementNotScheduled):
+		return fiber.StatusConflict
+	case errors.Is(err, dto.ErrAnnouncementInPast), errors.Is(err, dto.ErrAnnouncementTooFar),
+		errors.Is(err, dto.ErrDevicePlatformRequired), errors.Is(err, dto.ErrChatRoomNameRequired),
+		errors.Is(err, dto.ErrChatMessageEmpty):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"strings"
+	"tubexxi/video-api/config"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/middleware"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	proxySecretHeader = "X-Centrifugo-Proxy-Secret"
+
+	proxyErrorBadRequest        uint32 = 400
+	proxyErrorPermissionDenied  uint32 = 403
+	proxyErrorNotFound          uint32 = 404
+	proxyErrorInternal          uint32 = 500
+	proxyDisconnectUnauthorized uint32 = 4501
+)
+
+// CentrifugoProxyHandler answers Centrifugo's HTTP proxy calls. Responses always use
+// HTTP 200 with a result, error or disconnect object, as Centrifugo expects.
+type CentrifugoProxyHandler struct {
+	ctxinject       *middleware.ContextMiddleware
+	auth            *middleware.AuthMiddleware
+	realtimeService *service.RealtimeService
+	cfg             *config.CentrifugoConfig
+	logger          *zap.Logger
+}
+
+func NewCentrifugoProxyHandler(
+	ctxinject *middleware.ContextMiddleware,
+	auth *middleware.AuthMiddleware,
+	realtimeService *service.RealtimeService,
+	cfg *config.CentrifugoConfig,
+	logger *zap.Logger,
+) *CentrifugoProxyHandler {
+	return &CentrifugoProxyHandler{
+		ctxinject:       ctxinject,
+		auth:            auth,
+		realtimeService: realtimeService,
+		cfg:             cfg,
+		logger:          logger,
+	}
+}
+
+// VerifySecret rejects proxy calls that do not carry the shared secret configured on Centrifugo.
+// Without a configured secret every call is rejected, since the handlers trust the user the
+// request names.
+func (h *CentrifugoProxyHandler) VerifySecret() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.cfg.CentrifugoProxySecret == "" {
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+		secret := c.Get(proxySecretHeader)
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(h.cfg.CentrifugoProxySecret)) != 1 {
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+		return c.Next()
+	}
+}
+func (h *CentrifugoProxyHandler) Connect(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var req dto.CentrifugoConnectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.disconnect(c, "invalid connect request")
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
+	if token == "" && len(req.Data) > 0 {
+		var data dto.CentrifugoConnectData
+		if err := json.Unmarshal(req.Data, &data); err == nil {
+			token = data.Token
+		}
+	}
+	if token == "" {
+		return h.disconnect(c, "unauthorized")
+	}
+
+	identity, err := h.auth.Authenticate(ctx, token)
+	if err != nil {
+		h.logger.Warn("[CentrifugoProxyHandler.Connect] authentication failed", zap.Error(err))
+		return h.disconnect(c, "unauthorized")
+	}
+	if !identity.User.IsActive {
+		return h.disconnect(c, "account inactive")
+	}
+
+	result, err := h.realtimeService.ConnectResult(ctx, identity.User)
+	if err != nil {
+		return h.proxyError(c, err)
+	}
+	return c.JSON(dto.CentrifugoProxyResponse{Result: result})
+}
+func (h *CentrifugoProxyHandler) Subscribe(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var req dto.CentrifugoSubscribeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.proxyError(c, dto.ErrInvalidPayload)
+	}
+	if req.User == "" {
+		return h.proxyError(c, dto.ErrPermissionDenied)
+	}
+
+	if err := h.realtimeService.AuthorizeChannel(ctx, req.User, req.Channel); err != nil {
+		return h.proxyError(c, err)
+	}
+	return c.JSON(dto.CentrifugoProxyResponse{Result: fiber.Map{}})
+}
+func (h *CentrifugoProxyHandler) Publish(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var req dto.CentrifugoPublishRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.proxyError(c, dto.ErrInvalidPayload)
+	}
+	if req.User == "" {
+		return h.proxyError(c, dto.ErrPermissionDenied)
+	}
+
+	data, err := h.realtimeService.HandlePublish(ctx, req.User, req.Channel, req.Data)
+	if err != nil {
+		return h.proxyError(c, err)
+	}
+	return c.JSON(dto.CentrifugoProxyResponse{Result: dto.CentrifugoPublishResult{Data: data}})
+}
+func (h *CentrifugoProxyHandler) RPC(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var req dto.CentrifugoRPCRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.proxyError(c, dto.ErrInvalidPayload)
+	}
+	if req.User == "" {
+		return h.proxyError(c, dto.ErrPermissionDenied)
+	}
+
+	data, err := h.realtimeService.HandleRPC(ctx, req.User, req.Method, req.Data)
+	if err != nil {
+		return h.proxyError(c, err)
+	}
+	return c.JSON(dto.CentrifugoProxyResponse{Result: dto.CentrifugoRPCResult{Data: data}})
+}
+func (h *CentrifugoProxyHandler) disconnect(c *fiber.Ctx, reason string) error {
+	return c.JSON(dto.CentrifugoProxyResponse{
+		Disconnect: &dto.CentrifugoProxyDisconnect{
+			Code:   proxyDisconnectUnauthorized,
+			Reason: reason,
+		},
+	})
+}
+func (h *CentrifugoProxyHandler) proxyError(c *fiber.Ctx, err error) error {
+	code := proxyErrorInternal
+	message := "internal error"
+
+	switch {
+	case errors.Is(err, dto.ErrPermissionDenied), errors.Is(err, dto.ErrPublishForbidden),
+		errors.Is(err, dto.ErrChatNotParticipant), errors.Is(err, dto.ErrChatPostForbidden):
+		code, message = proxyErrorPermissionDenied, err.Error()
+	case errors.Is(err, dto.ErrInvalidChannel), errors.Is(err, dto.ErrInvalidPayload),
+		errors.Is(err, dto.ErrChatMessageEmpty), errors.Is(err, dto.ErrChatMessageTooLong),
+		errors.Is(err, dto.ErrChatInvalidReply):
+		code, message = proxyErrorBadRequest, err.Error()
+	case errors.Is(err, dto.ErrUnknownRPCMethod):
+		code, message = proxyErrorNotFound, err.Error()
+	default:
+		h.logger.Error("[CentrifugoProxyHandler]", zap.String("path", c.Path()), zap.Error(err))
+	}
+
+	return c.JSON(dto.CentrifugoProxyResponse{
+		Error: &dto.CentrifugoProxyError{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
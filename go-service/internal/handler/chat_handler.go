@@ -0,0 +1,695 @@
+package handler
+
+import (
+	"errors"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/middleware"
+	"tubexxi/video-api/internal/service"
+	"tubexxi/video-api/pkg/response"
+	"tubexxi/video-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ChatHandler struct {
+	ctxinject       *middleware.ContextMiddleware
+	scopeMiddleware *middleware.ScopeMiddleware
+	chatService     *service.ChatService
+	logger          *zap.Logger
+}
+
+func NewChatHandler(
+	ctxinject *middleware.ContextMiddleware,
+	scopeMiddleware *middleware.ScopeMiddleware,
+	chatService *service.ChatService,
+	logger *zap.Logger,
+) *ChatHandler {
+	return &ChatHandler{
+		ctxinject:       ctxinject,
+		scopeMiddleware: scopeMiddleware,
+		chatService:     chatService,
+		logger:          logger,
+	}
+}
+func (h *ChatHandler) CreateRoom(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.ChatRoomCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	room, err := h.chatService.CreateRoom(ctx, userID, &req)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Created(c, "Chat room created", room)
+}
+func (h *ChatHandler) ListConversations(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var filter entity.ConversationFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	filter.Scope = h.scopeMiddleware.GetSettingsScope(c)
+	filter.Platform, _ = c.Locals("platform").(string)
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	conversations, pagination, err := h.chatService.ListConversations(ctx, userID, filter)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.SuccessWithMeta(c, "Conversations retrieved", conversations, pagination)
+}
+func (h *ChatHandler) SearchMessages(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var filter entity.ChatFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	filter.UserID = userUUID
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	hits, pagination, err := h.chatService.SearchMessages(ctx, userID, filter)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.SuccessWithMeta(c, "Messages retrieved", hits, pagination)
+}
+func (h *ChatHandler) UpdatePreferences(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+
+	var req dto.ChatRoomPreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+
+	participant, err := h.chatService.UpdatePreferences(ctx, userID, roomID, &req)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Chat preferences updated", participant)
+}
+func (h *ChatHandler) GetRoom(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+
+	room, err := h.chatService.GetRoom(ctx, userID, roomID)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Chat room retrieved", room)
+}
+func (h *ChatHandler) GetHistory(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+
+	var filter entity.ChatHistoryFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	history, err := h.chatService.GetHistory(ctx, userID, roomID, filter)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Chat history retrieved", history)
+}
+func (h *ChatHandler) AddParticipants(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+
+	var req dto.ChatParticipantsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	result, err := h.chatService.AddParticipants(ctx, userID, roomID, &req)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Participants added", result)
+}
+func (h *ChatHandler) RemoveParticipant(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+	targetID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	if err := h.chatService.RemoveParticipant(ctx, userID, roomID, targetID); err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Participant removed", nil)
+}
+func (h *ChatHandler) KickParticipant(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+	targetID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	if err := h.chatService.KickParticipant(ctx, userID, roomID, targetID); err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Participant kicked", nil)
+}
+func (h *ChatHandler) MuteParticipant(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+	targetID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	var req dto.ChatMuteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	participant, err := h.chatService.MuteParticipant(ctx, userID, roomID, targetID, &req)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Participant muted", participant)
+}
+func (h *ChatHandler) UnmuteParticipant(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+	targetID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	if err := h.chatService.UnmuteParticipant(ctx, userID, roomID, targetID); err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Participant unmuted", nil)
+}
+func (h *ChatHandler) UpdateParticipantRole(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+	targetID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	var req dto.ChatRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	participant, err := h.chatService.UpdateParticipantRole(ctx, userID, roomID, targetID, &req)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Participant role updated", participant)
+}
+func (h *ChatHandler) ListBans(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+
+	bans, err := h.chatService.ListBans(ctx, userID, roomID)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Bans retrieved", bans)
+}
+func (h *ChatHandler) BanUser(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+	targetID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	var req dto.ChatBanRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+		}
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	ban, err := h.chatService.BanUser(ctx, userID, roomID, targetID, &req)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "User banned", ban)
+}
+func (h *ChatHandler) UnbanUser(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+	targetID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	if err := h.chatService.UnbanUser(ctx, userID, roomID, targetID); err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "User unbanned", nil)
+}
+func (h *ChatHandler) SetSlowMode(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+
+	var req dto.ChatSlowModeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	room, err := h.chatService.SetSlowMode(ctx, userID, roomID, &req)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Slow mode updated", room)
+}
+func (h *ChatHandler) SendMessage(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req entity.ChatCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	message, err := h.chatService.SendMessage(ctx, userID, &req)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Created(c, "Message sent", message)
+}
+func (h *ChatHandler) EditMessage(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	messageID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid message ID", nil)
+	}
+
+	var req entity.ChatUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	message, err := h.chatService.EditMessage(ctx, userID, messageID, &req)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Message updated", message)
+}
+func (h *ChatHandler) DeleteMessage(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	messageID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid message ID", nil)
+	}
+
+	if err := h.chatService.DeleteMessage(ctx, userID, messageID); err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Message deleted", nil)
+}
+func (h *ChatHandler) ToggleReaction(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	messageID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid message ID", nil)
+	}
+
+	var req dto.ChatReactionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	result, err := h.chatService.ToggleReaction(ctx, userID, messageID, &req)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	message := "Reaction removed"
+	if result.Added {
+		message = "Reaction added"
+	}
+	return response.Success(c, message, result)
+}
+func (h *ChatHandler) GetReactions(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	messageID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid message ID", nil)
+	}
+
+	reactors, err := h.chatService.GetReactions(ctx, userID, messageID)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Reactions retrieved", reactors)
+}
+func (h *ChatHandler) UploadMedia(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid form data", nil)
+	}
+	defer form.RemoveAll()
+
+	files, exists := form.File["file"]
+	if !exists || len(files) == 0 {
+		return response.Error(c, fiber.StatusBadRequest, "File is required", nil)
+	}
+
+	media, err := h.chatService.UploadMedia(ctx, userID, roomID, files[0])
+	if err != nil {
+		h.logger.Error("Failed to upload chat media",
+			zap.String("user_id", userID),
+			zap.Error(err))
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Created(c, "Media uploaded", media)
+}
+func (h *ChatHandler) MarkDelivered(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+
+	var req dto.ChatReceiptRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+		}
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	result, err := h.chatService.MarkDelivered(ctx, userID, roomID, &req)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Messages marked as delivered", result)
+}
+func (h *ChatHandler) MarkRead(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+
+	var req dto.ChatReceiptRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+		}
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	result, err := h.chatService.MarkRead(ctx, userID, roomID, &req)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Messages marked as read", result)
+}
+func (h *ChatHandler) SetTyping(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid room ID", nil)
+	}
+
+	var req dto.ChatTypingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	if err := h.chatService.SetTyping(ctx, userID, roomID, *req.IsTyping); err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Typing state updated", nil)
+}
+func (h *ChatHandler) GetUnreadCounts(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	result, err := h.chatService.GetUnreadCounts(ctx, userID)
+	if err != nil {
+		return response.Error(c, chatErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Unread counts retrieved", result)
+}
+
+func chatErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrChatRoomNotFound), errors.Is(err, dto.ErrChatMessageNotFound),
+		errors.Is(err, dto.ErrChatParticipantMissing), errors.Is(err, dto.ErrChatMediaNotFound),
+		errors.Is(err, dto.ErrChatBanNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, dto.ErrChatMediaTooLarge):
+		return fiber.StatusRequestEntityTooLarge
+	case errors.Is(err, dto.ErrChatNotParticipant), errors.Is(err, dto.ErrPermissionDenied),
+		errors.Is(err, dto.ErrChatPostForbidden), errors.Is(err, dto.ErrChatEditForbidden),
+		errors.Is(err, dto.ErrChatRoleNotAllowed), errors.Is(err, dto.ErrChatBanned),
+		errors.Is(err, dto.ErrChatMemberMuted), errors.Is(err, dto.ErrChatBlocked):
+		return fiber.StatusForbidden
+	case errors.Is(err, dto.ErrChatSlowMode):
+		return fiber.StatusTooManyRequests
+	case errors.Is(err, dto.ErrChatMessageDeleted):
+		return fiber.StatusGone
+	case errors.Is(err, dto.ErrChatRoomRequired), errors.Is(err, dto.ErrChatInvalidRecipient),
+		errors.Is(err, dto.ErrChatPersonalRoomSize), errors.Is(err, dto.ErrChatRoomNameRequired),
+		errors.Is(err, dto.ErrChatPersonalRoomFixed), errors.Is(err, dto.ErrChatChannelMembership),
+		errors.Is(err, dto.ErrChatMessageEmpty),
+		errors.Is(err, dto.ErrChatMessageTooLong), errors.Is(err, dto.ErrChatPayloadRequired),
+		errors.Is(err, dto.ErrChatMediaRequired), errors.Is(err, dto.ErrChatMediaTypeMismatch),
+		errors.Is(err, dto.ErrChatMediaEmpty), errors.Is(err, dto.ErrChatInvalidReply),
+		errors.Is(err, dto.ErrChatInvalidForward), errors.Is(err, dto.ErrChatNoPreferences),
+		errors.Is(err, dto.ErrChatInvalidDateRange), errors.Is(err, dto.ErrChatInvalidReaction),
+		errors.Is(err, dto.ErrChatNotModeratable), errors.Is(err, dto.ErrChatSelfModeration):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusBadRequest
+	}
+}
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"tubexxi/video-api/internal/dto"
 	"tubexxi/video-api/internal/middleware"
 	"tubexxi/video-api/internal/service"
 	"tubexxi/video-api/pkg/response"
+	"tubexxi/video-api/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -33,10 +35,21 @@ func (h *ClientHandler) SendContactEmail(c *fiber.Ctx) error {
 
 	var req dto.ContactRequest
 	if err := c.BodyParser(&req); err != nil {
-		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
 	}
-	if err := h.clientService.SendContactEmail(ctx, &req, c.Get("Origin")); err != nil {
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	ticket, err := h.clientService.SubmitContact(ctx, userID, &req)
+	if err != nil {
+		if errors.Is(err, dto.ErrContactRateLimited) {
+			return response.Error(c, fiber.StatusTooManyRequests, err.Error(), nil)
+		}
 		return response.Error(c, fiber.StatusInternalServerError, err.Error(), nil)
 	}
-	return response.Success(c, "Contact email sent successfully", nil)
+	return response.Created(c, "Message received", fiber.Map{
+		"number": ticket.Number,
+	})
 }
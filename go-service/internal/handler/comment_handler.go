@@ -0,0 +1,387 @@
+package handler
+
+import (
+	"errors"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/middleware"
+	"tubexxi/video-api/internal/service"
+	"tubexxi/video-api/pkg/response"
+	"tubexxi/video-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type CommentHandler struct {
+	ctxinject      *middleware.ContextMiddleware
+	commentService *service.CommentService
+	logger         *zap.Logger
+}
+
+func NewCommentHandler(
+	ctxinject *middleware.ContextMiddleware,
+	commentService *service.CommentService,
+	logger *zap.Logger,
+) *CommentHandler {
+	return &CommentHandler{
+		ctxinject:      ctxinject,
+		commentService: commentService,
+		logger:         logger,
+	}
+}
+func (h *CommentHandler) ListComments(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var filter entity.CommentFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	comments, pagination, err := h.commentService.ListComments(ctx, viewerID(c), filter)
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.SuccessWithMeta(c, "Comments retrieved", comments, pagination)
+}
+func (h *CommentHandler) GetCommentTree(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var filter entity.CommentFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	trees, pagination, err := h.commentService.GetCommentTree(ctx, viewerID(c), filter)
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.SuccessWithMeta(c, "Comment tree retrieved", trees, pagination)
+}
+func (h *CommentHandler) GetCommentStream(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var req dto.CommentStreamRequest
+	if err := c.QueryParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	stream, err := h.commentService.StreamEvents(ctx, &req)
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Comment events retrieved", stream)
+}
+func (h *CommentHandler) GetCommentStats(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var filter entity.CommentStatsFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	stats, err := h.commentService.GetStats(ctx, filter, isAdminRole(c))
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Comment stats retrieved", stats)
+}
+func (h *CommentHandler) GetCommentLeaderboard(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var filter entity.CommentStatsFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	leaderboard, err := h.commentService.GetLeaderboard(ctx, filter)
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Comment leaderboard retrieved", leaderboard)
+}
+func (h *CommentHandler) GetComment(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	comment, err := h.commentService.GetComment(ctx, viewerID(c), c.Params("id"))
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Comment retrieved", comment)
+}
+func (h *CommentHandler) CreateComment(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req entity.CommentCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	comment, err := h.commentService.CreateComment(ctx, userID, &req)
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Created(c, "Comment created", comment)
+}
+func (h *CommentHandler) CreateGuestComment(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var req entity.CommentCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	clientIP, _ := c.Locals("real_ip").(string)
+	result, err := h.commentService.CreateGuestComment(ctx, clientIP, c.Get("Origin"), &req)
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Check your email to publish your comment", result)
+}
+func (h *CommentHandler) VerifyGuestComment(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	comment, err := h.commentService.VerifyGuestComment(ctx, c.Query("token"))
+	if err != nil {
+		if errors.Is(err, dto.ErrGuestTokenInvalid) {
+			return c.Redirect(h.commentService.GuestVerifiedRedirect(nil, "invalid"), fiber.StatusSeeOther)
+		}
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return c.Redirect(h.commentService.GuestVerifiedRedirect(comment, comment.Status), fiber.StatusSeeOther)
+}
+func (h *CommentHandler) GetNotificationSettings(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	settings, err := h.commentService.GetNotificationSettings(ctx, userID)
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Notification settings retrieved", settings)
+}
+func (h *CommentHandler) UpdateNotificationSettings(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.CommentNotificationSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	settings, err := h.commentService.UpdateNotificationSettings(ctx, userID, &req)
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Notification settings updated", settings)
+}
+func (h *CommentHandler) UpdateComment(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req entity.CommentUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	comment, err := h.commentService.UpdateComment(ctx, userID, c.Params("id"), &req)
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Comment updated", comment)
+}
+func (h *CommentHandler) DeleteComment(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	if err := h.commentService.DeleteComment(ctx, userID, c.Params("id"), isAdminRole(c)); err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Comment deleted", nil)
+}
+
+func (h *CommentHandler) LikeComment(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	result, err := h.commentService.LikeComment(ctx, userID, c.Params("id"))
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Comment liked", result)
+}
+func (h *CommentHandler) UnlikeComment(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	result, err := h.commentService.UnlikeComment(ctx, userID, c.Params("id"))
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Comment unliked", result)
+}
+func (h *CommentHandler) ModerationQueue(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var filter entity.CommentModerationFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	comments, pagination, err := h.commentService.ModerationQueue(ctx, filter)
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.SuccessWithMeta(c, "Moderation queue retrieved", comments, pagination)
+}
+func (h *CommentHandler) ApproveComments(c *fiber.Ctx) error {
+	return h.moderateComments(c, true)
+}
+func (h *CommentHandler) RejectComments(c *fiber.Ctx) error {
+	return h.moderateComments(c, false)
+}
+func (h *CommentHandler) moderateComments(c *fiber.Ctx, approve bool) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	adminID, ok := c.Locals("user_id").(string)
+	if !ok || adminID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.CommentModerationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	var result *dto.CommentModerationResult
+	var err error
+	if approve {
+		result, err = h.commentService.ApproveComments(ctx, adminID, &req)
+	} else {
+		result, err = h.commentService.RejectComments(ctx, adminID, &req)
+	}
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Comments moderated", result)
+}
+func (h *CommentHandler) ShadowBanAuthors(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	adminID, ok := c.Locals("user_id").(string)
+	if !ok || adminID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.CommentShadowBanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	result, err := h.commentService.ShadowBanAuthors(ctx, adminID, &req)
+	if err != nil {
+		return response.Error(c, commentErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Shadow ban updated", result)
+}
+
+// viewerID returns the caller's user ID when OptionalFirebaseAuth resolved one.
+func viewerID(c *fiber.Ctx) string {
+	userID, _ := c.Locals("user_id").(string)
+	return userID
+}
+
+func isAdminRole(c *fiber.Ctx) bool {
+	roleLevel, ok := c.Locals("role_level").(int)
+	return ok && (roleLevel == entity.RoleLevelAdmin || roleLevel == entity.RoleLevelSuperAdmin)
+}
+
+func commentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrCommentNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, dto.ErrPermissionDenied):
+		return fiber.StatusForbidden
+	case errors.Is(err, dto.ErrCommentDeleted), errors.Is(err, dto.ErrInvalidReplyTarget),
+		errors.Is(err, dto.ErrCommentDepthExceeded), errors.Is(err, dto.ErrCommentPageURLMissing),
+		errors.Is(err, dto.ErrInvalidStatsRange), errors.Is(err, dto.ErrStatsContentType),
+		errors.Is(err, dto.ErrGuestIdentityRequired):
+		return fiber.StatusUnprocessableEntity
+	case errors.Is(err, dto.ErrGuestRateLimited):
+		return fiber.StatusTooManyRequests
+	case errors.Is(err, dto.ErrGuestEmailRegistered):
+		return fiber.StatusConflict
+	case errors.Is(err, dto.ErrGuestTokenInvalid):
+		return fiber.StatusGone
+	case errors.Is(err, dto.ErrCommentStreamReset):
+		return fiber.StatusConflict
+	case errors.Is(err, dto.ErrUserNotFound):
+		return fiber.StatusUnauthorized
+	default:
+		return fiber.StatusBadRequest
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/middleware"
+	"tubexxi/video-api/internal/service"
+	"tubexxi/video-api/pkg/response"
+	"tubexxi/video-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type RealtimeHandler struct {
+	ctxinject       *middleware.ContextMiddleware
+	realtimeService *service.RealtimeService
+	logger          *zap.Logger
+}
+
+func NewRealtimeHandler(
+	ctxinject *middleware.ContextMiddleware,
+	realtimeService *service.RealtimeService,
+	logger *zap.Logger,
+) *RealtimeHandler {
+	return &RealtimeHandler{
+		ctxinject:       ctxinject,
+		realtimeService: realtimeService,
+		logger:          logger,
+	}
+}
+func (h *RealtimeHandler) GetConnectionToken(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	token, err := h.realtimeService.IssueConnectionToken(ctx, userID)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err.Error(), nil)
+	}
+	return response.Success(c, "Connection token issued", token)
+}
+func (h *RealtimeHandler) GetSubscriptionToken(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.SubscriptionTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	token, err := h.realtimeService.IssueSubscriptionToken(ctx, userID, req.Channel)
+	if err != nil {
+		switch {
+		case errors.Is(err, dto.ErrInvalidChannel):
+			return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, dto.ErrPermissionDenied):
+			return response.Error(c, fiber.StatusForbidden, err.Error(), nil)
+		default:
+			return response.Error(c, fiber.StatusInternalServerError, err.Error(), nil)
+		}
+	}
+	return response.Success(c, "Subscription token issued", token)
+}
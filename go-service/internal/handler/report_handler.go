@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/middleware"
+	"tubexxi/video-api/internal/service"
+	"tubexxi/video-api/pkg/response"
+	"tubexxi/video-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ReportHandler struct {
+	ctxinject     *middleware.ContextMiddleware
+	reportService *service.ReportService
+	logger        *zap.Logger
+}
+
+func NewReportHandler(
+	ctxinject *middleware.ContextMiddleware,
+	reportService *service.ReportService,
+	logger *zap.Logger,
+) *ReportHandler {
+	return &ReportHandler{
+		ctxinject:     ctxinject,
+		reportService: reportService,
+		logger:        logger,
+	}
+}
+func (h *ReportHandler) CreateReport(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.ReportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	report, err := h.reportService.CreateReport(ctx, userID, &req)
+	if err != nil {
+		return response.Error(c, reportErrorStatus(err), err.Error(), nil)
+	}
+	return response.Created(c, "Report submitted", report)
+}
+func (h *ReportHandler) ListReports(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var filter entity.ReportFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	cases, pagination, err := h.reportService.ListReports(ctx, filter)
+	if err != nil {
+		return response.Error(c, reportErrorStatus(err), err.Error(), nil)
+	}
+	return response.SuccessWithMeta(c, "Reports retrieved", cases, pagination)
+}
+func (h *ReportHandler) GetReportCase(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	targetType := c.Params("target_type")
+	if targetType != entity.ReportTargetComment && targetType != entity.ReportTargetChatMessage {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid target type", nil)
+	}
+	targetID, err := uuid.Parse(c.Params("target_id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid target ID", nil)
+	}
+
+	reportCase, err := h.reportService.GetReportCase(ctx, targetType, targetID)
+	if err != nil {
+		return response.Error(c, reportErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Report case retrieved", reportCase)
+}
+func (h *ReportHandler) ResolveReports(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	adminID, ok := c.Locals("user_id").(string)
+	if !ok || adminID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.ReportResolveRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	result, err := h.reportService.ResolveReports(ctx, adminID, &req)
+	if err != nil {
+		return response.Error(c, reportErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Reports resolved", result)
+}
+
+func reportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrCommentNotFound), errors.Is(err, dto.ErrChatMessageNotFound),
+		errors.Is(err, dto.ErrReportNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, dto.ErrPermissionDenied):
+		return fiber.StatusForbidden
+	case errors.Is(err, dto.ErrReportDuplicate):
+		return fiber.StatusConflict
+	case errors.Is(err, dto.ErrReportOwnContent), errors.Is(err, dto.ErrReportTargetDeleted),
+		errors.Is(err, dto.ErrReportNoAuthor):
+		return fiber.StatusUnprocessableEntity
+	case errors.Is(err, dto.ErrUserNotFound):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusBadRequest
+	}
+}
@@ -0,0 +1,383 @@
+package handler
+
+import (
+	"errors"
+	"mime/multipart"
+	"strings"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/middleware"
+	"tubexxi/video-api/internal/service"
+	"tubexxi/video-api/pkg/response"
+	"tubexxi/video-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type TicketHandler struct {
+	ctxinject     *middleware.ContextMiddleware
+	ticketService *service.TicketService
+	slaService    *service.TicketSLAService
+	logger        *zap.Logger
+}
+
+func NewTicketHandler(
+	ctxinject *middleware.ContextMiddleware,
+	ticketService *service.TicketService,
+	slaService *service.TicketSLAService,
+	logger *zap.Logger,
+) *TicketHandler {
+	return &TicketHandler{
+		ctxinject:     ctxinject,
+		ticketService: ticketService,
+		slaService:    slaService,
+		logger:        logger,
+	}
+}
+func (h *TicketHandler) CreateTicket(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.TicketCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	files, cleanup, err := ticketAttachments(c)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid form data", nil)
+	}
+	defer cleanup()
+
+	ticket, err := h.ticketService.CreateTicket(ctx, userID, &req, files)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Created(c, "Ticket created", ticket)
+}
+func (h *TicketHandler) ListMyTickets(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var filter entity.TicketFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	tickets, pagination, err := h.ticketService.ListMyTickets(ctx, userID, filter)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.SuccessWithMeta(c, "Tickets retrieved", tickets, pagination)
+}
+func (h *TicketHandler) GetMyTicket(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid ticket ID", nil)
+	}
+
+	ticket, err := h.ticketService.GetMyTicket(ctx, userID, id)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Ticket retrieved", ticket)
+}
+func (h *TicketHandler) Reply(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid ticket ID", nil)
+	}
+
+	var req dto.TicketReplyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	files, cleanup, err := ticketAttachments(c)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid form data", nil)
+	}
+	defer cleanup()
+
+	reply, err := h.ticketService.Reply(ctx, userID, id, &req, files)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Created(c, "Reply posted", reply)
+}
+func (h *TicketHandler) ListTickets(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var filter entity.TicketFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	tickets, pagination, err := h.ticketService.ListTickets(ctx, filter)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.SuccessWithMeta(c, "Tickets retrieved", tickets, pagination)
+}
+func (h *TicketHandler) GetTicket(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid ticket ID", nil)
+	}
+
+	ticket, err := h.ticketService.GetTicket(ctx, id)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Ticket retrieved", ticket)
+}
+func (h *TicketHandler) StaffReply(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	adminID, ok := c.Locals("user_id").(string)
+	if !ok || adminID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid ticket ID", nil)
+	}
+
+	var req dto.TicketReplyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	files, cleanup, err := ticketAttachments(c)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid form data", nil)
+	}
+	defer cleanup()
+
+	reply, err := h.ticketService.StaffReply(ctx, adminID, id, &req, files)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Created(c, "Reply posted", reply)
+}
+func (h *TicketHandler) AssignTicket(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	adminID, ok := c.Locals("user_id").(string)
+	if !ok || adminID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid ticket ID", nil)
+	}
+
+	var req dto.TicketAssignRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+
+	ticket, err := h.ticketService.AssignTicket(ctx, adminID, id, &req)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Ticket assignment updated", ticket)
+}
+func (h *TicketHandler) UpdatePriority(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	adminID, ok := c.Locals("user_id").(string)
+	if !ok || adminID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid ticket ID", nil)
+	}
+
+	var req dto.TicketPriorityRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	ticket, err := h.ticketService.UpdatePriority(ctx, adminID, id, &req)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Ticket priority updated", ticket)
+}
+func (h *TicketHandler) UpdateStatus(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	adminID, ok := c.Locals("user_id").(string)
+	if !ok || adminID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid ticket ID", nil)
+	}
+
+	var req dto.TicketStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	ticket, err := h.ticketService.UpdateStatus(ctx, adminID, id, &req)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Ticket status updated", ticket)
+}
+func (h *TicketHandler) UpdateCategory(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid ticket ID", nil)
+	}
+
+	var req dto.TicketCategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	ticket, err := h.ticketService.UpdateCategory(ctx, id, &req)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Ticket category updated", ticket)
+}
+func (h *TicketHandler) ListSLAPolicies(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	policies, err := h.slaService.ListPolicies(ctx)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "SLA policies retrieved", policies)
+}
+func (h *TicketHandler) SaveSLAPolicy(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var req dto.TicketSLAPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	policy, err := h.slaService.SavePolicy(ctx, &req)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "SLA policy saved", policy)
+}
+func (h *TicketHandler) DeleteSLAPolicy(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	id, err := uuid.Parse(c.Params("policyId"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid policy ID", nil)
+	}
+
+	if err := h.slaService.DeletePolicy(ctx, id); err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "SLA policy deleted", nil)
+}
+func (h *TicketHandler) SLADashboard(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	var query dto.TicketSLAReportQuery
+	if err := c.QueryParser(&query); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(query); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	report, err := h.slaService.Report(ctx, query)
+	if err != nil {
+		return response.Error(c, ticketErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "SLA report retrieved", report)
+}
+
+// ticketAttachments returns the "attachments" files of a multipart request. JSON requests have
+// none. The returned cleanup removes any temporary files the form spilled to disk.
+func ticketAttachments(c *fiber.Ctx) ([]*multipart.FileHeader, func(), error) {
+	if !strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+		return nil, func() {}, nil
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, func() {}, err
+	}
+	return form.File["attachments"], func() { form.RemoveAll() }, nil
+}
+
+func ticketErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrTicketNotFound), errors.Is(err, dto.ErrTicketSLAPolicyNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, dto.ErrTicketClosed), errors.Is(err, dto.ErrTicketTransition):
+		return fiber.StatusConflict
+	case errors.Is(err, dto.ErrTicketAttachmentTooLarge):
+		return fiber.StatusRequestEntityTooLarge
+	case errors.Is(err, dto.ErrTicketAssigneeInvalid), errors.Is(err, dto.ErrTicketTooManyAttachments),
+		errors.Is(err, dto.ErrTicketAttachmentEmpty), errors.Is(err, dto.ErrTicketSLAPolicyInvalid):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -4,33 +4,41 @@ import (
 	"errors"
 	"strings"
 	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
 	"tubexxi/video-api/internal/middleware"
 	"tubexxi/video-api/internal/service"
 	"tubexxi/video-api/pkg/response"
 	"tubexxi/video-api/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type UserHandler struct {
-	ctxinject   *middleware.ContextMiddleware
-	userService *service.UserService
-	rateLimiter *middleware.RateLimiterMiddleware
-	logger      *zap.Logger
+	ctxinject          *middleware.ContextMiddleware
+	userService        *service.UserService
+	viewHistoryService *service.ViewHistoryService
+	playbackService    *service.PlaybackService
+	rateLimiter        *middleware.RateLimiterMiddleware
+	logger             *zap.Logger
 }
 
 func NewUserHandler(
 	ctxinject *middleware.ContextMiddleware,
 	userService *service.UserService,
+	viewHistoryService *service.ViewHistoryService,
+	playbackService *service.PlaybackService,
 	rateLimiter *middleware.RateLimiterMiddleware,
 	logger *zap.Logger,
 ) *UserHandler {
 	return &UserHandler{
-		ctxinject:   ctxinject,
-		userService: userService,
-		rateLimiter: rateLimiter,
-		logger:      logger,
+		ctxinject:          ctxinject,
+		userService:        userService,
+		viewHistoryService: viewHistoryService,
+		playbackService:    playbackService,
+		rateLimiter:        rateLimiter,
+		logger:             logger,
 	}
 }
 func (h *UserHandler) GetCurrentUser(c *fiber.Ctx) error {
@@ -135,6 +143,9 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 
 	userUpdate, err := h.userService.UpdateProfile(ctx, userID, &req)
 	if err != nil {
+		if errors.Is(err, dto.ErrUsernameAlreadyExists) {
+			return response.Error(c, fiber.StatusConflict, err.Error(), nil)
+		}
 		return response.Error(c, fiber.StatusInternalServerError, err.Error(), nil)
 	}
 	return response.Success(c, "Profile updated successfully", userUpdate)
@@ -219,3 +230,159 @@ func (h *UserHandler) VerifyTwoFactor(c *fiber.Ctx) error {
 	}
 	return response.Success(c, "Two-factor authentication verified successfully", nil)
 }
+func (h *UserHandler) ListBlockedUsers(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var filter entity.UserBlockFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(filter); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	blocks, pagination, err := h.userService.ListBlockedUsers(ctx, userID, filter)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err.Error(), nil)
+	}
+	return response.SuccessWithMeta(c, "Blocked users retrieved", blocks, pagination)
+}
+func (h *UserHandler) BlockUser(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	targetID := c.Params("user_id")
+	if _, err := uuid.Parse(targetID); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	if err := h.userService.BlockUser(ctx, userID, targetID); err != nil {
+		switch {
+		case errors.Is(err, dto.ErrCannotBlockSelf):
+			return response.Error(c, fiber.StatusUnprocessableEntity, err.Error(), nil)
+		case errors.Is(err, dto.ErrUserNotFound):
+			return response.Error(c, fiber.StatusNotFound, err.Error(), nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, err.Error(), nil)
+	}
+	return response.Success(c, "User blocked", nil)
+}
+func (h *UserHandler) UnblockUser(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	targetID := c.Params("user_id")
+	if _, err := uuid.Parse(targetID); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	if err := h.userService.UnblockUser(ctx, userID, targetID); err != nil {
+		if errors.Is(err, dto.ErrUserNotBlocked) {
+			return response.Error(c, fiber.StatusNotFound, err.Error(), nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, err.Error(), nil)
+	}
+	return response.Success(c, "User unblocked", nil)
+}
+func (h *UserHandler) RecordView(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.ViewHistoryRecordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+	req.IPAddress = c.IP()
+	req.UserAgent = c.Get(fiber.HeaderUserAgent)
+	req.AcceptLanguage = c.Get(fiber.HeaderAcceptLanguage)
+	req.Platform, _ = c.Locals("platform").(string)
+
+	recorded, err := h.viewHistoryService.Record(ctx, userID, &req)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err.Error(), nil)
+	}
+	return response.Success(c, "View received", fiber.Map{"recorded": recorded})
+}
+func (h *UserHandler) PlaybackHeartbeat(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.PlaybackHeartbeatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	progress, err := h.playbackService.Heartbeat(ctx, userID, &req)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err.Error(), nil)
+	}
+	return response.Success(c, "Playback progress saved", progress)
+}
+func (h *UserHandler) GetPlaybackProgress(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	contentType := c.Params("type")
+	if contentType != entity.ViewHistoryTypeMovie && contentType != entity.ViewHistoryTypeSeries && contentType != entity.ViewHistoryTypeAnime {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid content type", nil)
+	}
+
+	progress, err := h.playbackService.GetProgress(ctx, userID, contentType, c.Params("slug"))
+	if err != nil {
+		if errors.Is(err, dto.ErrPlaybackProgressNotFound) {
+			return response.Error(c, fiber.StatusNotFound, err.Error(), nil)
+		}
+		return response.Error(c, fiber.StatusInternalServerError, err.Error(), nil)
+	}
+	return response.Success(c, "Playback progress retrieved", progress)
+}
+func (h *UserHandler) ContinueWatching(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var query dto.ContinueWatchingQuery
+	if err := c.QueryParser(&query); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if errs := utils.ValidateStruct(query); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	items, err := h.playbackService.ContinueWatching(ctx, userID, query)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err.Error(), nil)
+	}
+	return response.Success(c, "Continue watching retrieved", items)
+}
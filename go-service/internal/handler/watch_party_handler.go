@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"errors"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/middleware"
+	"tubexxi/video-api/internal/service"
+	"tubexxi/video-api/pkg/response"
+	"tubexxi/video-api/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WatchPartyHandler struct {
+	ctxinject         *middleware.ContextMiddleware
+	watchPartyService *service.WatchPartyService
+	logger            *zap.Logger
+}
+
+func NewWatchPartyHandler(
+	ctxinject *middleware.ContextMiddleware,
+	watchPartyService *service.WatchPartyService,
+	logger *zap.Logger,
+) *WatchPartyHandler {
+	return &WatchPartyHandler{
+		ctxinject:         ctxinject,
+		watchPartyService: watchPartyService,
+		logger:            logger,
+	}
+}
+func (h *WatchPartyHandler) Create(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+
+	var req dto.WatchPartyCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	party, err := h.watchPartyService.Create(ctx, userID, &req)
+	if err != nil {
+		return response.Error(c, watchPartyErrorStatus(err), err.Error(), nil)
+	}
+	return response.Created(c, "Watch party created", party)
+}
+func (h *WatchPartyHandler) Get(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid watch party ID", nil)
+	}
+
+	party, err := h.watchPartyService.Get(ctx, userID, roomID)
+	if err != nil {
+		return response.Error(c, watchPartyErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Watch party retrieved", party)
+}
+func (h *WatchPartyHandler) Join(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid watch party ID", nil)
+	}
+
+	party, err := h.watchPartyService.Join(ctx, userID, roomID)
+	if err != nil {
+		return response.Error(c, watchPartyErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Joined watch party", party)
+}
+func (h *WatchPartyHandler) Sync(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid watch party ID", nil)
+	}
+
+	result, err := h.watchPartyService.Sync(ctx, userID, roomID)
+	if err != nil {
+		return response.Error(c, watchPartyErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Playback state retrieved", result)
+}
+func (h *WatchPartyHandler) Control(c *fiber.Ctx) error {
+	ctx := h.ctxinject.HandlerContext(c)
+
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return response.Error(c, fiber.StatusUnauthorized, "User authentication required", nil)
+	}
+	roomID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid watch party ID", nil)
+	}
+
+	var req dto.WatchPartyPlaybackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errs := utils.ValidateStruct(req); len(errs) > 0 {
+		return response.Error(c, fiber.StatusUnprocessableEntity, response.ValidationErrors{Errors: errs}.Error(), nil)
+	}
+
+	result, err := h.watchPartyService.Control(ctx, userID, roomID, &req)
+	if err != nil {
+		return response.Error(c, watchPartyErrorStatus(err), err.Error(), nil)
+	}
+	return response.Success(c, "Playback updated", result)
+}
+
+func watchPartyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrWatchPartyNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, dto.ErrWatchPartyHostOnly):
+		return fiber.StatusForbidden
+	case errors.Is(err, dto.ErrWatchPartyFull):
+		return fiber.StatusConflict
+	case errors.Is(err, dto.ErrWatchPartyContentUnavailable), errors.Is(err, dto.ErrWatchPartyInvalidPlayer),
+		errors.Is(err, dto.ErrWatchPartyPositionRequired), errors.Is(err, dto.ErrWatchPartyPlayerRequired):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return chatErrorStatus(err)
+	}
+}
@@ -2,16 +2,20 @@ package helpers
 
 import (
 	"bytes"
-	"context"
-	"crypto/tls"
 	"fmt"
 	"html/template"
+	"net/mail"
 	"net/smtp"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 	"tubexxi/video-api/config"
 	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -40,6 +44,14 @@ func (h *MailHelper) SendEmail(payload *dto.SendMailMetaData, clientOrigin strin
 		return h.SendVerificationEmail(payload, clientOrigin)
 	case dto.RegistrationInfo:
 		return h.SendRegistrationInfo(payload, clientOrigin)
+	case dto.GuestCommentVerification:
+		return h.SendGuestCommentVerification(payload, clientOrigin)
+	case dto.CommentMentionNotice, dto.CommentReplyNotice:
+		return h.SendCommentNotification(payload, clientOrigin)
+	case dto.BroadcastAnnouncementNotice:
+		return h.SendAnnouncementEmail(payload, clientOrigin)
+	case dto.TicketAcknowledgement, dto.TicketReplyNotice, dto.TicketStatusNotice, dto.TicketAssignedNotice, dto.TicketSLABreachNotice:
+		return h.SendTicketNotification(payload, clientOrigin)
 	default:
 		return fmt.Errorf("unknown email type: %s", payload.Type)
 	}
@@ -134,105 +146,228 @@ func (h *MailHelper) SendRegistrationInfo(payload *dto.SendMailMetaData, clientO
 	return h.sendHTMLEmail(payload.To, subject, body)
 }
 
-func (m *MailHelper) SendContactEmail(ctx context.Context, payload *dto.ContactRequest, clientOrigin string) error {
+func (h *MailHelper) SendGuestCommentVerification(payload *dto.SendMailMetaData, clientOrigin string) error {
+	if payload.Comment == nil {
+		return fmt.Errorf("guest comment verification requires a comment")
+	}
 
-	settingEmail := m.mailConfig
+	username := payload.To
+	if payload.Comment.Name != nil && *payload.Comment.Name != "" {
+		username = *payload.Comment.Name
+	}
 
-	// Validate required SMTP settings
-	if settingEmail.SmtpHost == "" || settingEmail.SmtpPort == "0" || settingEmail.SmtpUsername == "" || settingEmail.SmtpPassword == "" {
-		return fmt.Errorf("incomplete SMTP configuration")
+	excerpt := []rune(payload.Comment.Comment)
+	if len(excerpt) > 280 {
+		excerpt = append(excerpt[:280], '…')
 	}
 
-	siteName := m.appConfig.AppName
-	if siteName == "" {
-		siteName = "Simontok"
+	data := struct {
+		Username        string
+		Comment         string
+		PageURL         string
+		VerificationURL string
+		ExpiresInHours  int
+		Year            int
+	}{
+		Username:        username,
+		Comment:         string(excerpt),
+		PageURL:         payload.Comment.PageURL,
+		VerificationURL: fmt.Sprintf("%s/api/comments/guest/verify?token=%s", strings.TrimRight(h.appConfig.URL, "/"), url.QueryEscape(payload.Token)),
+		ExpiresInHours:  int(time.Until(payload.ExpiredAt).Round(time.Hour).Hours()),
+		Year:            time.Now().Year(),
 	}
 
-	subject := fmt.Sprintf("Contact Us - %s", siteName)
-	body := m.getContactHTML(siteName, clientOrigin, payload)
+	subject := "Confirm Your Comment"
+	body, err := h.renderTemplate(guestCommentVerificationTemplate, data)
+	if err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
 
-	headers := make(map[string]string)
-	headers["From"] = fmt.Sprintf("%s <%s>", settingEmail.SmtpFromName, settingEmail.SmtpFromEmail)
-	headers["To"] = m.appConfig.AdminEmail
-	headers["Subject"] = subject
-	headers["MIME-Version"] = "1.0"
-	headers["Content-Type"] = "text/html; charset=\"UTF-8\""
+	return h.sendHTMLEmail(payload.To, subject, body)
+}
+func (h *MailHelper) SendCommentNotification(payload *dto.SendMailMetaData, clientOrigin string) error {
+	if payload.Comment == nil {
+		return fmt.Errorf("comment notification requires a comment")
+	}
 
-	message := ""
-	for k, v := range headers {
-		message += fmt.Sprintf("%s: %s\r\n", k, v)
+	username := payload.To
+	if payload.User != nil && payload.User.FullName != "" {
+		username = payload.User.FullName
+	}
+	actor := "Someone"
+	if payload.Comment.Author != nil && payload.Comment.Author.Name != "" {
+		actor = payload.Comment.Author.Name
 	}
-	message += "\r\n" + body
 
-	// Setup SMTP Auth
-	auth := smtp.PlainAuth("", settingEmail.SmtpUsername, settingEmail.SmtpPassword, settingEmail.SmtpHost)
+	action, subject := "mentioned you in a comment", fmt.Sprintf("%s mentioned you", actor)
+	if payload.Type == dto.CommentReplyNotice {
+		action, subject = "replied to your comment", fmt.Sprintf("%s replied to your comment", actor)
+	}
 
-	// SMTP Server Address
-	port, err := strconv.Atoi(settingEmail.SmtpPort)
+	excerpt := []rune(payload.Comment.Comment)
+	if len(excerpt) > 280 {
+		excerpt = append(excerpt[:280], '…')
+	}
+
+	data := struct {
+		Username string
+		Actor    string
+		Action   string
+		Title    string
+		Comment  string
+		Link     string
+		Year     int
+	}{
+		Username: username,
+		Actor:    actor,
+		Action:   action,
+		Comment:  string(excerpt),
+		Link:     payload.Link,
+		Year:     time.Now().Year(),
+	}
+	if payload.Comment.ContentTitle != nil {
+		data.Title = *payload.Comment.ContentTitle
+	}
+
+	body, err := h.renderTemplate(commentNotificationTemplate, data)
 	if err != nil {
-		return fmt.Errorf("invalid SMTP port: %w", err)
+		return fmt.Errorf("failed to render template: %w", err)
 	}
-	addr := fmt.Sprintf("%s:%d", settingEmail.SmtpHost, port)
 
-	// If port is 465, use implicit TLS
-	if settingEmail.SmtpPort == "465" {
-		// Handle TLS config for non-localhost
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: false,
-			ServerName:         settingEmail.SmtpHost,
-		}
+	return h.sendHTMLEmail(payload.To, subject, body)
+}
 
-		conn, err := tls.Dial("tcp", addr, tlsConfig)
-		if err != nil {
-			return fmt.Errorf("failed to dial TLS: %w", err)
-		}
+func (h *MailHelper) SendAnnouncementEmail(payload *dto.SendMailMetaData, clientOrigin string) error {
+	if payload.Announcement == nil {
+		return fmt.Errorf("announcement email requires an announcement")
+	}
 
-		c, err := smtp.NewClient(conn, settingEmail.SmtpHost)
-		if err != nil {
-			return fmt.Errorf("failed to create SMTP client: %w", err)
-		}
-		defer c.Quit()
+	username := payload.To
+	if payload.User != nil && payload.User.FullName != "" {
+		username = payload.User.FullName
+	}
 
-		if err = c.Auth(auth); err != nil {
-			return fmt.Errorf("failed to auth: %w", err)
-		}
+	link := payload.Link
+	if link == "" {
+		link = clientOrigin
+	}
 
-		if err = c.Mail(settingEmail.SmtpFromEmail); err != nil {
-			return fmt.Errorf("failed to set sender: %w", err)
-		}
+	data := struct {
+		Username string
+		Title    string
+		Body     string
+		Link     string
+		Year     int
+	}{
+		Username: username,
+		Title:    payload.Announcement.Title,
+		Body:     payload.Announcement.Body,
+		Link:     link,
+		Year:     time.Now().Year(),
+	}
 
-		if err = c.Rcpt(m.appConfig.AdminEmail); err != nil {
-			return fmt.Errorf("failed to set recipient: %w", err)
-		}
+	body, err := h.renderTemplate(announcementTemplate, data)
+	if err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
 
-		w, err := c.Data()
-		if err != nil {
-			return fmt.Errorf("failed to create data writer: %w", err)
+	return h.sendHTMLEmail(payload.To, payload.Announcement.Title, body)
+}
+func (h *MailHelper) SendTicketNotification(payload *dto.SendMailMetaData, clientOrigin string) error {
+	if payload.Ticket == nil {
+		return fmt.Errorf("ticket notification requires a ticket")
+	}
+
+	username := payload.To
+	if payload.User != nil && payload.User.FullName != "" {
+		username = payload.User.FullName
+	}
+
+	ticket := payload.Ticket
+	var summary, subject string
+	switch payload.Type {
+	case dto.TicketAcknowledgement:
+		summary = fmt.Sprintf("Thanks for getting in touch. We received your message and will get back to you soon. Your reference number is %s.", ticket.Number)
+		subject = fmt.Sprintf("We received your message [%s]", ticket.Number)
+	case dto.TicketReplyNotice:
+		summary = "There is a new reply on your support ticket."
+		subject = fmt.Sprintf("New reply on ticket %s", ticket.Number)
+	case dto.TicketAssignedNotice:
+		summary = "A support ticket has been assigned to you."
+		subject = fmt.Sprintf("Ticket %s assigned to you", ticket.Number)
+	case dto.TicketSLABreachNotice:
+		target := "first response"
+		due := ticket.CreatedAt
+		if breach := payload.SLABreach; breach != nil {
+			if breach.Kind == entity.TicketSLAResolution {
+				target = "resolution"
+			}
+			due = breach.DueAt
 		}
+		summary = fmt.Sprintf("This ticket missed its %s target, which was due %s.", target, due.UTC().Format("2 Jan 2006 15:04 MST"))
+		subject = fmt.Sprintf("SLA breached on ticket %s", ticket.Number)
+	default:
+		summary = fmt.Sprintf("Your support ticket is now %s.", ticket.Status)
+		subject = fmt.Sprintf("Ticket %s is %s", ticket.Number, ticket.Status)
+	}
 
-		_, err = w.Write([]byte(message))
-		if err != nil {
-			return fmt.Errorf("failed to write body: %w", err)
+	// The acknowledgement answers a public form to whatever address it was given, so it
+	// carries the reference number and none of the submitted text.
+	title := ticket.Title
+	if payload.Type == dto.TicketAcknowledgement {
+		title = fmt.Sprintf("Reference %s", ticket.Number)
+		if payload.User == nil || payload.User.ID == uuid.Nil {
+			username = "there"
 		}
+	}
 
-		err = w.Close()
-		if err != nil {
-			return fmt.Errorf("failed to close data writer: %w", err)
+	message := ""
+	if payload.TicketReply != nil {
+		message = payload.TicketReply.Body
+	}
+	if message != "" {
+		excerpt := []rune(message)
+		if len(excerpt) > 1000 {
+			excerpt = append(excerpt[:1000], '…')
 		}
+		message = string(excerpt)
+	}
 
-		return nil
+	data := struct {
+		Username string
+		Summary  string
+		Number   string
+		Title    string
+		Status   string
+		Priority string
+		Message  string
+		Link     string
+		ReplyTo  bool
+		Year     int
+	}{
+		Username: username,
+		Summary:  summary,
+		Number:   ticket.Number,
+		Title:    title,
+		Status:   string(ticket.Status),
+		Priority: ticket.Priority,
+		Message:  message,
+		Link:     payload.Link,
+		ReplyTo:  payload.ReplyTo != "",
+		Year:     time.Now().Year(),
+	}
 
-	} else {
-		// Standard smtp.SendMail for port 587 (STARTTLS) or 25 (Plain)
-		err := smtp.SendMail(addr, auth, settingEmail.SmtpFromEmail, []string{m.appConfig.AdminEmail}, []byte(message))
-		if err != nil {
-			return fmt.Errorf("failed to send email: %w", err)
-		}
+	body, err := h.renderTemplate(ticketNotificationTemplate, data)
+	if err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
 	}
 
-	return nil
+	var headers map[string]string
+	if payload.ReplyTo != "" {
+		headers = map[string]string{"Reply-To": payload.ReplyTo}
+	}
+	return h.sendHTMLEmailWithHeaders(payload.To, subject, body, headers)
 }
-
 func (h *MailHelper) renderTemplate(tmpl string, data interface{}) (string, error) {
 	t, err := template.New("email").Parse(tmpl)
 	if err != nil {
@@ -248,21 +383,49 @@ func (h *MailHelper) renderTemplate(tmpl string, data interface{}) (string, erro
 }
 
 func (h *MailHelper) sendHTMLEmail(to, subject, htmlBody string) error {
-	message := []byte("Subject: " + subject + "\r\n" +
-		"To: " + to + "\r\n" +
-		"MIME-Version: 1.0\r\n" +
+	return h.sendHTMLEmailWithHeaders(to, subject, htmlBody, nil)
+}
+
+// sendHTMLEmailWithHeaders sends an HTML email with extra headers such as Reply-To. Without
+// SMTP credentials the mail goes out unauthenticated, as a local SMTP stand-in expects.
+func (h *MailHelper) sendHTMLEmailWithHeaders(to, subject, htmlBody string, headers map[string]string) error {
+	clean := strings.NewReplacer("\r", "", "\n", "")
+
+	var message strings.Builder
+	message.WriteString("Subject: " + clean.Replace(subject) + "\r\n")
+	message.WriteString("To: " + clean.Replace(to) + "\r\n")
+	if h.mailConfig.SmtpFromEmail != "" && headers["From"] == "" {
+		from := (&mail.Address{Name: h.mailConfig.SmtpFromName, Address: h.mailConfig.SmtpFromEmail}).String()
+		message.WriteString("From: " + from + "\r\n")
+	}
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		message.WriteString(key + ": " + clean.Replace(headers[key]) + "\r\n")
+	}
+	message.WriteString("MIME-Version: 1.0\r\n" +
 		"Content-Type: text/html; charset=UTF-8\r\n\r\n" +
 		htmlBody + "\r\n")
 
-	auth := smtp.PlainAuth(
-		"",
-		h.mailConfig.SmtpUsername,
-		h.mailConfig.SmtpPassword,
-		h.mailConfig.SmtpHost,
-	)
+	var auth smtp.Auth
+	if h.mailConfig.SmtpUsername != "" {
+		auth = smtp.PlainAuth(
+			"",
+			h.mailConfig.SmtpUsername,
+			h.mailConfig.SmtpPassword,
+			h.mailConfig.SmtpHost,
+		)
+	}
+	sender := h.mailConfig.SmtpUsername
+	if sender == "" {
+		sender = h.mailConfig.SmtpFromEmail
+	}
 
 	addr := fmt.Sprintf("%s:%s", h.mailConfig.SmtpHost, h.mailConfig.SmtpPort)
-	err := smtp.SendMail(addr, auth, h.mailConfig.SmtpUsername, []string{to}, message)
+	err := smtp.SendMail(addr, auth, sender, []string{to}, []byte(message.String()))
 	if err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
@@ -372,7 +535,290 @@ const emailVerificationTemplate = `
 </html>
 `
 
+// Guest Comment Verification Template
+const guestCommentVerificationTemplate = `
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Confirm Your Comment</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f5f7fa;">
+    <table role="presentation" style="width: 100%; border-collapse: collapse; background-color: #f5f7fa;">
+        <tr>
+            <td align="center" style="padding: 40px 0;">
+                <table role="presentation" style="width: 600px; border-collapse: collapse; background-color: #ffffff; border-radius: 12px; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);">
+                    <!-- Header -->
+                    <tr>
+                        <td style="padding: 40px 40px 30px; text-align: center; background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); border-radius: 12px 12px 0 0;">
+                            <h1 style="margin: 0; color: #ffffff; font-size: 28px; font-weight: 600;">Confirm Your Comment</h1>
+                        </td>
+                    </tr>
+                    
+                    <!-- Body -->
+                    <tr>
+                        <td style="padding: 40px;">
+                            <p style="margin: 0 0 20px; color: #4a5568; font-size: 16px; line-height: 1.6;">
+                                Hi <strong>{{.Username}}</strong>,
+                            </p>
+                            <p style="margin: 0 0 20px; color: #4a5568; font-size: 16px; line-height: 1.6;">
+                                Thanks for joining the discussion! Your comment is on hold until you confirm this email address by clicking the button below.
+                            </p>
+                            <p style="margin: 0 0 30px; padding: 15px; background-color: #f7fafc; border-radius: 6px; font-size: 14px; color: #4a5568; border-left: 4px solid #667eea; white-space: pre-wrap;">{{.Comment}}</p>
+                            
+                            <!-- CTA Button -->
+                            <table role="presentation" style="width: 100%; border-collapse: collapse;">
+                                <tr>
+                                    <td align="center" style="padding: 20px 0;">
+                                        <a href="{{.VerificationURL}}" style="display: inline-block; padding: 16px 40px; background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: #ffffff; text-decoration: none; border-radius: 8px; font-weight: 600; font-size: 16px; box-shadow: 0 4px 6px rgba(102, 126, 234, 0.4);">
+                                            Publish My Comment
+                                        </a>
+                                    </td>
+                                </tr>
+                            </table>
+                            
+                            <p style="margin: 30px 0 20px; color: #718096; font-size: 14px; line-height: 1.6;">
+                                Or copy and paste this link into your browser:
+                            </p>
+                            <p style="margin: 0; padding: 15px; background-color: #f7fafc; border-radius: 6px; word-break: break-all; font-size: 13px; color: #4a5568; border-left: 4px solid #667eea;">
+                                {{.VerificationURL}}
+                            </p>
+                            
+                            <p style="margin: 30px 0 0; color: #718096; font-size: 14px; line-height: 1.6;">
+                                This link will expire in {{.ExpiresInHours}} hours. If you didn't post a comment on {{.PageURL}}, please ignore this email and it will be discarded.
+                            </p>
+                        </td>
+                    </tr>
+                    
+                    <!-- Footer -->
+                    <tr>
+                        <td style="padding: 30px 40px; background-color: #f7fafc; border-radius: 0 0 12px 12px; text-align: center;">
+                            <p style="margin: 0 0 10px; color: #a0aec0; font-size: 13px;">
+                                © {{.Year}} AGC Forge. All rights reserved.
+                            </p>
+                            <p style="margin: 0; color: #a0aec0; font-size: 13px;">
+                                Need help? Contact us at support@socialforge.io
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>
+`
+
+// Comment Notification Template
+const commentNotificationTemplate = `
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>New Comment Activity</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f5f7fa;">
+    <table role="presentation" style="width: 100%; border-collapse: collapse; background-color: #f5f7fa;">
+        <tr>
+            <td align="center" style="padding: 40px 0;">
+                <table role="presentation" style="width: 600px; border-collapse: collapse; background-color: #ffffff; border-radius: 12px; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);">
+                    <!-- Header -->
+                    <tr>
+                        <td style="padding: 40px 40px 30px; text-align: center; background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); border-radius: 12px 12px 0 0;">
+                            <h1 style="margin: 0; color: #ffffff; font-size: 28px; font-weight: 600;">New Comment Activity</h1>
+                        </td>
+                    </tr>
+                    
+                    <!-- Body -->
+                    <tr>
+                        <td style="padding: 40px;">
+                            <p style="margin: 0 0 20px; color: #4a5568; font-size: 16px; line-height: 1.6;">
+                                Hi <strong>{{.Username}}</strong>,
+                            </p>
+                            <p style="margin: 0 0 20px; color: #4a5568; font-size: 16px; line-height: 1.6;">
+                                <strong>{{.Actor}}</strong> {{.Action}}{{if .Title}} on <strong>{{.Title}}</strong>{{end}}:
+                            </p>
+                            <p style="margin: 0 0 30px; padding: 15px; background-color: #f7fafc; border-radius: 6px; font-size: 14px; color: #4a5568; border-left: 4px solid #667eea; white-space: pre-wrap;">{{.Comment}}</p>
+                            
+                            <!-- CTA Button -->
+                            <table role="presentation" style="width: 100%; border-collapse: collapse;">
+                                <tr>
+                                    <td align="center" style="padding: 20px 0;">
+                                        <a href="{{.Link}}" style="display: inline-block; padding: 16px 40px; background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: #ffffff; text-decoration: none; border-radius: 8px; font-weight: 600; font-size: 16px; box-shadow: 0 4px 6px rgba(102, 126, 234, 0.4);">
+                                            View Conversation
+                                        </a>
+                                    </td>
+                                </tr>
+                            </table>
+                            
+                            <p style="margin: 30px 0 0; color: #718096; font-size: 14px; line-height: 1.6;">
+                                You can mute mentions and replies, or turn these emails off, in your notification settings.
+                            </p>
+                        </td>
+                    </tr>
+                    
+                    <!-- Footer -->
+                    <tr>
+                        <td style="padding: 30px 40px; background-color: #f7fafc; border-radius: 0 0 12px 12px; text-align: center;">
+                            <p style="margin: 0 0 10px; color: #a0aec0; font-size: 13px;">
+                                © {{.Year}} AGC Forge. All rights reserved.
+                            </p>
+                            <p style="margin: 0; color: #a0aec0; font-size: 13px;">
+                                Need help? Contact us at support@socialforge.io
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>
+`
+
+const announcementTemplate = `
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}}</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f5f7fa;">
+    <table role="presentation" style="width: 100%; border-collapse: collapse; background-color: #f5f7fa;">
+        <tr>
+            <td align="center" style="padding: 40px 0;">
+                <table role="presentation" style="width: 600px; border-collapse: collapse; background-color: #ffffff; border-radius: 12px; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);">
+                    <!-- Header -->
+                    <tr>
+                        <td style="padding: 40px 40px 30px; text-align: center; background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); border-radius: 12px 12px 0 0;">
+                            <h1 style="margin: 0; color: #ffffff; font-size: 28px; font-weight: 600;">{{.Title}}</h1>
+                        </td>
+                    </tr>
+                    
+                    <!-- Body -->
+                    <tr>
+                        <td style="padding: 40px;">
+                            <p style="margin: 0 0 20px; color: #4a5568; font-size: 16px; line-height: 1.6;">
+                                Hi <strong>{{.Username}}</strong>,
+                            </p>
+                            <p style="margin: 0 0 30px; color: #4a5568; font-size: 16px; line-height: 1.6; white-space: pre-wrap;">{{.Body}}</p>
+                            
+                            <!-- CTA Button -->
+                            <table role="presentation" style="width: 100%; border-collapse: collapse;">
+                                <tr>
+                                    <td align="center" style="padding: 20px 0;">
+                                        <a href="{{.Link}}" style="display: inline-block; padding: 16px 40px; background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: #ffffff; text-decoration: none; border-radius: 8px; font-weight: 600; font-size: 16px; box-shadow: 0 4px 6px rgba(102, 126, 234, 0.4);">
+                                            Read More
+                                        </a>
+                                    </td>
+                                </tr>
+                            </table>
+                            
+                            <p style="margin: 30px 0 0; color: #718096; font-size: 14px; line-height: 1.6;">
+                                You are receiving this because you are subscribed to our announcements. Mute the channel to stop these emails.
+                            </p>
+                        </td>
+                    </tr>
+                    
+                    <!-- Footer -->
+                    <tr>
+                        <td style="padding: 30px 40px; background-color: #f7fafc; border-radius: 0 0 12px 12px; text-align: center;">
+                            <p style="margin: 0 0 10px; color: #a0aec0; font-size: 13px;">
+                                © {{.Year}} AGC Forge. All rights reserved.
+                            </p>
+                            <p style="margin: 0; color: #a0aec0; font-size: 13px;">
+                                Need help? Contact us at support@socialforge.io
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>
+`
+
 // Reset Password Template
+const ticketNotificationTemplate = `
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Ticket {{.Number}}</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f5f7fa;">
+    <table role="presentation" style="width: 100%; border-collapse: collapse; background-color: #f5f7fa;">
+        <tr>
+            <td align="center" style="padding: 40px 0;">
+                <table role="presentation" style="width: 600px; border-collapse: collapse; background-color: #ffffff; border-radius: 12px; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);">
+                    <!-- Header -->
+                    <tr>
+                        <td style="padding: 40px 40px 30px; text-align: center; background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); border-radius: 12px 12px 0 0;">
+                            <h1 style="margin: 0; color: #ffffff; font-size: 28px; font-weight: 600;">Ticket {{.Number}}</h1>
+                        </td>
+                    </tr>
+                    
+                    <!-- Body -->
+                    <tr>
+                        <td style="padding: 40px;">
+                            <p style="margin: 0 0 20px; color: #4a5568; font-size: 16px; line-height: 1.6;">
+                                Hi <strong>{{.Username}}</strong>,
+                            </p>
+                            <p style="margin: 0 0 20px; color: #4a5568; font-size: 16px; line-height: 1.6;">
+                                {{.Summary}}
+                            </p>
+                            <p style="margin: 0 0 20px; color: #4a5568; font-size: 14px; line-height: 1.6;">
+                                <strong>{{.Title}}</strong><br>
+                                Status: {{.Status}} &middot; Priority: {{.Priority}}
+                            </p>
+                            {{if .Message}}
+                            <p style="margin: 0 0 30px; padding: 16px 20px; background-color: #f7fafc; border-left: 4px solid #667eea; color: #4a5568; font-size: 15px; line-height: 1.6; white-space: pre-wrap;">{{.Message}}</p>
+                            {{end}}
+                            
+                            {{if .Link}}
+                            <!-- CTA Button -->
+                            <table role="presentation" style="width: 100%; border-collapse: collapse;">
+                                <tr>
+                                    <td align="center" style="padding: 20px 0;">
+                                        <a href="{{.Link}}" style="display: inline-block; padding: 16px 40px; background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: #ffffff; text-decoration: none; border-radius: 8px; font-weight: 600; font-size: 16px; box-shadow: 0 4px 6px rgba(102, 126, 234, 0.4);">
+                                            View Ticket
+                                        </a>
+                                    </td>
+                                </tr>
+                            </table>
+                            {{end}}
+                            {{if .ReplyTo}}
+                            <p style="margin: 30px 0 0; color: #718096; font-size: 14px; line-height: 1.6;">
+                                You can reply to this email directly to add to the conversation.
+                            </p>
+                            {{end}}
+                        </td>
+                    </tr>
+                    
+                    <!-- Footer -->
+                    <tr>
+                        <td style="padding: 30px 40px; background-color: #f7fafc; border-radius: 0 0 12px 12px; text-align: center;">
+                            <p style="margin: 0 0 10px; color: #a0aec0; font-size: 13px;">
+                                © {{.Year}} AGC Forge. All rights reserved.
+                            </p>
+                            <p style="margin: 0; color: #a0aec0; font-size: 13px;">
+                                Need help? Contact us at support@socialforge.io
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>
+`
+
 const resetPasswordTemplate = `
 <!DOCTYPE html>
 <html lang="en">
@@ -546,43 +992,3 @@ const registrationInfoTemplate = `
     </table>
 </body>
 </html>`
-
-func (m *MailHelper) getContactHTML(siteName, siteURL string, payload *dto.ContactRequest) string {
-	return fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; margin: 0; padding: 0; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; background-color: #f9f9f9; }
-        .header { background-color: #007bff; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
-        .content { background-color: white; padding: 30px; border-radius: 0 0 5px 5px; box-shadow: 0 2px 5px rgba(0,0,0,0.1); }
-        .button { display: inline-block; padding: 12px 24px; background-color: #007bff; color: white; text-decoration: none; border-radius: 4px; margin-top: 20px; font-weight: bold; }
-        .footer { text-align: center; margin-top: 20px; font-size: 12px; color: #666; }
-        p { margin-bottom: 15px; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1 style="margin:0;">%s</h1>
-        </div>
-        <div class="content">
-            <h2>New Contact Request</h2>
-            <p>You received a new contact request from %s.</p>
-            <p>Email: %s</p>
-            <p>Name: %s</p>
-            <p>Message: %s</p>
-            <p style="margin-top: 30px; font-size: 14px;">Or copy and paste this link into your browser:</p>
-            <p style="font-size: 13px; color: #007bff; word-break: break-all;"><a href="%s">%s</a></p>
-            <p>This link will expire in 1 hour.</p>
-        </div>
-        <div class="footer">
-            <p>&copy; %d %s. All rights reserved.</p>
-        </div>
-    </div>
-</body>
-</html>
-`, siteName, payload.Name, payload.Email, payload.Name, payload.Message, siteURL, siteURL, 2025, siteName)
-}
@@ -2,6 +2,7 @@ package asynqclient
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sync"
@@ -12,19 +13,30 @@ import (
 	"go.uber.org/zap"
 )
 
+// Tasks enqueued with asynq.Group(name) are held back and handed to the worker as one task of
+// type name, whose payload is the JSON array of their payloads. A group is flushed once it has
+// been quiet for groupGracePeriod, has waited groupMaxDelay or holds groupMaxSize tasks.
+const (
+	groupGracePeriod = 10 * time.Second
+	groupMaxDelay    = time.Minute
+	groupMaxSize     = 500
+	groupMaxRetry    = 3
+)
+
 var (
 	AsynqClientStorage *AsynqClientWrapper
 	asynqOnce          sync.Once
 )
 
 type AsynqClientWrapper struct {
-	client *asynq.Client
-	server *asynq.Server
-	mux    *asynq.ServeMux
-	config *config.RedisConfig
-	logger *zap.Logger
-	isUp   bool
-	mu     sync.RWMutex
+	client    *asynq.Client
+	server    *asynq.Server
+	scheduler *asynq.Scheduler
+	mux       *asynq.ServeMux
+	config    *config.RedisConfig
+	logger    *zap.Logger
+	isUp      bool
+	mu        sync.RWMutex
 }
 
 func NewAsynqClient(cfg *config.RedisConfig, logger *zap.Logger) (*AsynqClientWrapper, error) {
@@ -88,6 +100,10 @@ func (a *AsynqClientWrapper) NewAsynqServer() error {
 				// Exponential backoff: 1min, 5min, 10min
 				return time.Duration(n*n) * time.Minute
 			},
+			GroupGracePeriod: groupGracePeriod,
+			GroupMaxDelay:    groupMaxDelay,
+			GroupMaxSize:     groupMaxSize,
+			GroupAggregator:  asynq.GroupAggregatorFunc(a.aggregateGroup),
 		},
 	)
 
@@ -102,6 +118,26 @@ func (a *AsynqClientWrapper) NewAsynqServer() error {
 
 	return nil
 }
+func (a *AsynqClientWrapper) aggregateGroup(group string, tasks []*asynq.Task) *asynq.Task {
+	payloads := make([]json.RawMessage, 0, len(tasks))
+	for _, task := range tasks {
+		if !json.Valid(task.Payload()) {
+			a.logger.Warn("Dropping grouped task with invalid payload",
+				zap.String("group", group),
+				zap.String("type", task.Type()),
+			)
+			continue
+		}
+		payloads = append(payloads, task.Payload())
+	}
+
+	payloadBytes, _ := json.Marshal(payloads)
+	a.logger.Debug("Task group aggregated",
+		zap.String("group", group),
+		zap.Int("size", len(payloads)),
+	)
+	return asynq.NewTask(group, payloadBytes, asynq.MaxRetry(groupMaxRetry))
+}
 func GetAsynq() (*AsynqClientWrapper, error) {
 	if AsynqClientStorage == nil {
 		return nil, errors.New("asynq not initialized: call NewAsynqClient first")
@@ -163,6 +199,76 @@ func (a *AsynqClientWrapper) ShutdownServer() {
 		a.logger.Info("✅ Asynq server shut down successfully")
 	}
 }
+
+// RegisterPeriodicTask adds a task to the scheduler on a cron spec such as "@every 5m". Pass
+// asynq.Unique so that several workers running the scheduler enqueue each run only once.
+func (a *AsynqClientWrapper) RegisterPeriodicTask(cronspec, taskType string, payload interface{}, opts ...asynq.Option) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	a.mu.Lock()
+	if a.scheduler == nil {
+		a.scheduler = asynq.NewScheduler(
+			asynq.RedisClientOpt{
+				Addr:     a.config.GetRedisAddr(),
+				Password: a.config.RedisPassword,
+				DB:       a.config.RedisAsynqDB,
+			},
+			&asynq.SchedulerOpts{
+				Location: time.UTC,
+				EnqueueErrorHandler: func(task *asynq.Task, opts []asynq.Option, err error) {
+					if errors.Is(err, asynq.ErrDuplicateTask) {
+						return
+					}
+					a.logger.Error("Failed to enqueue periodic task",
+						zap.String("type", task.Type()),
+						zap.Error(err),
+					)
+				},
+			},
+		)
+	}
+	scheduler := a.scheduler
+	a.mu.Unlock()
+
+	entryID, err := scheduler.Register(cronspec, asynq.NewTask(taskType, payloadBytes, opts...))
+	if err != nil {
+		return fmt.Errorf("failed to register periodic task: %w", err)
+	}
+
+	a.logger.Debug("Periodic task registered",
+		zap.String("type", taskType),
+		zap.String("cronspec", cronspec),
+		zap.String("entry_id", entryID),
+	)
+	return nil
+}
+func (a *AsynqClientWrapper) StartScheduler() error {
+	a.mu.RLock()
+	scheduler := a.scheduler
+	a.mu.RUnlock()
+
+	if scheduler == nil {
+		return nil
+	}
+	if err := scheduler.Start(); err != nil {
+		return fmt.Errorf("failed to start asynq scheduler: %w", err)
+	}
+	a.logger.Info("✅ Asynq scheduler started")
+	return nil
+}
+func (a *AsynqClientWrapper) ShutdownScheduler() {
+	a.mu.RLock()
+	scheduler := a.scheduler
+	a.mu.RUnlock()
+
+	if scheduler != nil {
+		scheduler.Shutdown()
+		a.logger.Info("✅ Asynq scheduler shut down successfully")
+	}
+}
 func (a *AsynqClientWrapper) Close() error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -0,0 +1,61 @@
+package centrifugo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	NamespaceUser         = "user"
+	NamespaceConversation = "conversation"
+	NamespaceComments     = "comments"
+	NamespaceWatchParty   = "watch_party"
+
+	commentChannelHashLength = 32
+)
+
+/**
+ * ParseChannel splits a channel into its namespace and identifier
+ * @param {string} channel - The channel name, e.g. conversation:<id>
+ * @return {string} - The namespace
+ * @return {string} - The identifier after the namespace separator
+ */
+func ParseChannel(channel string) (string, string) {
+	namespace, id, found := strings.Cut(channel, ":")
+	if !found {
+		return "", channel
+	}
+	return namespace, id
+}
+
+func UserChannel(userID string) string {
+	return fmt.Sprintf("%s:%s", NamespaceUser, userID)
+}
+
+func ConversationChannel(conversationID string) string {
+	return fmt.Sprintf("%s:%s", NamespaceConversation, conversationID)
+}
+
+func WatchPartyChannel(roomID string) string {
+	return fmt.Sprintf("%s:%s", NamespaceWatchParty, roomID)
+}
+
+/**
+ * CommentsChannel derives the comment stream channel for a page
+ * @param {string} pageURL - The page the comments belong to
+ * @return {string} - The channel name, e.g. comments:<hash>
+ */
+func CommentsChannel(pageURL string) string {
+	sum := sha256.Sum256([]byte(pageURL))
+	return fmt.Sprintf("%s:%s", NamespaceComments, hex.EncodeToString(sum[:])[:commentChannelHashLength])
+}
+
+func IsCommentsChannelID(id string) bool {
+	if len(id) != commentChannelHashLength {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
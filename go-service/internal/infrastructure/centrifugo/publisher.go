@@ -3,6 +3,7 @@ package centrifugo
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 	"tubexxi/video-api/internal/infrastructure/contextpool"
@@ -11,6 +12,8 @@ import (
 	"go.uber.org/zap"
 )
 
+const errorCodeUnrecoverablePosition = 112
+
 func (c *CentrifugoClient) IsUp() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -42,13 +45,15 @@ func (c *CentrifugoClient) PublishMessage(ctx context.Context, channel string, d
 }
 
 func (c *CentrifugoClient) PublishToUser(ctx context.Context, userID string, data interface{}) error {
-	channel := fmt.Sprintf("user:%s", userID)
-	return c.PublishMessage(ctx, channel, data)
+	return c.PublishMessage(ctx, UserChannel(userID), data)
 }
 
 func (c *CentrifugoClient) PublishToConversation(ctx context.Context, conversationID string, data interface{}) error {
-	channel := fmt.Sprintf("conversation:%s", conversationID)
-	return c.PublishMessage(ctx, channel, data)
+	return c.PublishMessage(ctx, ConversationChannel(conversationID), data)
+}
+
+func (c *CentrifugoClient) PublishToWatchParty(ctx context.Context, roomID string, data interface{}) error {
+	return c.PublishMessage(ctx, WatchPartyChannel(roomID), data)
 }
 
 func (c *CentrifugoClient) BroadcastNewMessage(ctx context.Context, conversationID string, message map[string]interface{}) error {
@@ -77,6 +82,15 @@ func (c *CentrifugoClient) BroadcastMessageRead(ctx context.Context, conversatio
 	return c.PublishToConversation(ctx, conversationID, payload)
 }
 
+func (c *CentrifugoClient) BroadcastMessageDelivered(ctx context.Context, conversationID, userID string, messageIDs []string) error {
+	payload := map[string]interface{}{
+		"type":        "message_delivered",
+		"user_id":     userID,
+		"message_ids": messageIDs,
+	}
+	return c.PublishToConversation(ctx, conversationID, payload)
+}
+
 func (c *CentrifugoClient) BroadcastConversationUpdate(ctx context.Context, conversationID string, updates map[string]interface{}) error {
 	payload := map[string]interface{}{
 		"type":    "conversation_update",
@@ -114,17 +128,24 @@ func (c *CentrifugoClient) Presence(ctx context.Context, channel string) (*gocen
 	return &result, nil
 }
 
-func (c *CentrifugoClient) History(ctx context.Context, channel string, limit int) (*gocent.HistoryResult, error) {
+func (c *CentrifugoClient) History(ctx context.Context, channel string, limit int, opts ...gocent.HistoryOption) (*gocent.HistoryResult, error) {
 	ctx, cancel := contextpool.WithTimeoutIfNone(ctx, 5*time.Second)
 	defer cancel()
 
-	result, err := c.client.History(ctx, channel, gocent.WithLimit(limit))
+	result, err := c.client.History(ctx, channel, append([]gocent.HistoryOption{gocent.WithLimit(limit)}, opts...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get history for channel %s: %w", channel, err)
 	}
 	return &result, nil
 }
 
+// IsUnrecoverablePosition reports whether Centrifugo refused a history request because
+// the stream epoch changed or the requested offset is no longer retained.
+func IsUnrecoverablePosition(err error) bool {
+	var apiErr *gocent.Error
+	return errors.As(err, &apiErr) && apiErr.Code == errorCodeUnrecoverablePosition
+}
+
 func (c *CentrifugoClient) Channels(ctx context.Context) ([]string, error) {
 	ctx, cancel := contextpool.WithTimeoutIfNone(ctx, 5*time.Second)
 	defer cancel()
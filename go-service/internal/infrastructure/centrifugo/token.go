@@ -0,0 +1,100 @@
+package centrifugo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type ConnectionClaims struct {
+	Channels []string `json:"channels,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type SubscriptionClaims struct {
+	Channel string `json:"channel"`
+	jwt.RegisteredClaims
+}
+
+/**
+ * GenerateConnectionToken signs a Centrifugo connection JWT for the given user
+ * @param {string} userID - The user ID placed in the sub claim
+ * @param {[]string} channels - Optional server-side channels to subscribe on connect
+ * @return {string} - The signed token
+ * @return {time.Time} - The token expiry
+ * @return {error} - Error if signing fails
+ */
+func (c *CentrifugoClient) GenerateConnectionToken(userID string, channels []string) (string, time.Time, error) {
+	if userID == "" {
+		return "", time.Time{}, errors.New("user ID is required")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(c.tokenTTL())
+	claims := ConnectionClaims{
+		Channels: channels,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := c.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+/**
+ * GenerateSubscriptionToken signs a Centrifugo subscription JWT for a single channel
+ * @param {string} userID - The user ID placed in the sub claim
+ * @param {string} channel - The channel the token grants access to
+ * @return {string} - The signed token
+ * @return {time.Time} - The token expiry
+ * @return {error} - Error if signing fails
+ */
+func (c *CentrifugoClient) GenerateSubscriptionToken(userID string, channel string) (string, time.Time, error) {
+	if userID == "" || channel == "" {
+		return "", time.Time{}, errors.New("user ID and channel are required")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(c.tokenTTL())
+	claims := SubscriptionClaims{
+		Channel: channel,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := c.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+func (c *CentrifugoClient) sign(claims jwt.Claims) (string, error) {
+	if c.config.CentrifugoTokenSecret == "" {
+		return "", errors.New("centrifugo token secret is not configured")
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(c.config.CentrifugoTokenSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign centrifugo token: %w", err)
+	}
+	return token, nil
+}
+
+func (c *CentrifugoClient) tokenTTL() time.Duration {
+	if c.config.CentrifugoTokenTTL <= 0 {
+		return time.Hour
+	}
+	return c.config.CentrifugoTokenTTL
+}
@@ -9,17 +9,22 @@ import (
 
 	firebase "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/auth"
+	"firebase.google.com/go/v4/messaging"
 	"go.uber.org/zap"
 	"google.golang.org/api/option"
 )
 
 type FirebaseClient struct {
-	App        *firebase.App
-	AuthClient *auth.Client
-	logger     *zap.Logger
-	config     *config.AppConfig
+	App             *firebase.App
+	AuthClient      *auth.Client
+	MessagingClient *messaging.Client
+	logger          *zap.Logger
+	config          *config.AppConfig
 }
 
+// MaxMulticastTokens is the most device tokens FCM accepts in one multicast request.
+const MaxMulticastTokens = 500
+
 func NewFirebaseClient(ctx context.Context, logger *zap.Logger, cfg *config.AppConfig) (*FirebaseClient, error) {
 	if cfg.FirebaseProjectID == "" {
 		return nil, fmt.Errorf("missing FirebaseProjectID in config")
@@ -46,16 +51,24 @@ func NewFirebaseClient(ctx context.Context, logger *zap.Logger, cfg *config.AppC
 		return nil, fmt.Errorf("failed to initialize Firebase Auth: %w", err)
 	}
 
+	// Push is optional; without it announcements are still posted and emailed.
+	messagingClient, err := app.Messaging(ctx)
+	if err != nil {
+		logger.Warn("Failed to initialize Firebase Messaging", zap.Error(err))
+		messagingClient = nil
+	}
+
 	logger.Info("Firebase client initialized successfully",
 		zap.String("project_id", cfg.FirebaseProjectID),
 		zap.Bool("is_development", cfg.IsDevelopment()),
 	)
 
 	return &FirebaseClient{
-		App:        app,
-		AuthClient: authClient,
-		logger:     logger,
-		config:     cfg,
+		App:             app,
+		AuthClient:      authClient,
+		MessagingClient: messagingClient,
+		logger:          logger,
+		config:          cfg,
 	}, nil
 }
 
@@ -211,6 +224,47 @@ func (fc *FirebaseClient) SetCustomUserClaims(ctx context.Context, uid string, c
 	return nil
 }
 
+// SendMulticast sends one notification to up to MaxMulticastTokens devices. It returns how many
+// were delivered and the tokens FCM no longer recognises, so callers can forget them.
+func (fc *FirebaseClient) SendMulticast(ctx context.Context, tokens []string, title string, body string, data map[string]string) (int, []string, error) {
+	if fc.MessagingClient == nil {
+		return 0, nil, fmt.Errorf("firebase messaging is not initialized")
+	}
+	if len(tokens) == 0 {
+		return 0, nil, nil
+	}
+	if len(tokens) > MaxMulticastTokens {
+		return 0, nil, fmt.Errorf("multicast takes at most %d tokens, got %d", MaxMulticastTokens, len(tokens))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := fc.MessagingClient.SendEachForMulticast(ctx, &messaging.MulticastMessage{
+		Tokens: tokens,
+		Notification: &messaging.Notification{
+			Title: title,
+			Body:  body,
+		},
+		Data: data,
+	})
+	if err != nil {
+		fc.logger.Error("Failed to send multicast message", zap.Int("tokens", len(tokens)), zap.Error(err))
+		return 0, nil, err
+	}
+
+	var stale []string
+	for i, r := range resp.Responses {
+		if r.Success || r.Error == nil {
+			continue
+		}
+		if messaging.IsUnregistered(r.Error) || messaging.IsInvalidArgument(r.Error) {
+			stale = append(stale, tokens[i])
+		}
+	}
+	return resp.SuccessCount, stale, nil
+}
+
 func (fc *FirebaseClient) Close() error {
 	fc.logger.Info("Closing Firebase client")
 	return nil
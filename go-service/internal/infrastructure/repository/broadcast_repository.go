@@ -0,0 +1,527 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type BroadcastRepository interface {
+	BaseRepository
+	CreateChannel(ctx context.Context, room *entity.ChatRoom) (int64, error)
+	ListChannels(ctx context.Context, viewerID uuid.UUID, filter entity.BroadcastChannelFilter) ([]*entity.BroadcastChannel, int64, error)
+	CreateAnnouncement(ctx context.Context, announcement *entity.ChatAnnouncement) error
+	FindAnnouncementByID(ctx context.Context, id uuid.UUID) (*entity.ChatAnnouncement, error)
+	ListAnnouncements(ctx context.Context, roomID uuid.UUID, filter entity.AnnouncementFilter) ([]*entity.ChatAnnouncement, int64, error)
+	CancelAnnouncement(ctx context.Context, id uuid.UUID) error
+	ClaimAnnouncement(ctx context.Context, id uuid.UUID) (*entity.ChatAnnouncement, error)
+	ReleaseAnnouncement(ctx context.Context, id uuid.UUID) error
+	CompleteAnnouncement(ctx context.Context, announcement *entity.ChatAnnouncement) error
+	FailAnnouncement(ctx context.Context, id uuid.UUID, reason string) error
+	UpsertDevice(ctx context.Context, device *entity.UserDevice) error
+	DeleteDevice(ctx context.Context, userID uuid.UUID, token string) error
+	DeleteDeviceTokens(ctx context.Context, tokens []string) error
+	ListPushDevices(ctx context.Context, roomID uuid.UUID, target entity.BroadcastTarget, after uuid.UUID, limit int) ([]*entity.UserDevice, error)
+	ListEmailRecipients(ctx context.Context, roomID uuid.UUID, target entity.BroadcastTarget, after uuid.UUID, limit int) ([]*entity.BroadcastRecipient, error)
+}
+
+type broadcastRepository struct {
+	*baseRepository
+}
+
+func NewBroadcastRepository(db *pgxpool.Pool, logger *zap.Logger) BroadcastRepository {
+	return &broadcastRepository{
+		baseRepository: NewBaseRepository(
+			db,
+			logger,
+		).(*baseRepository),
+	}
+}
+
+const chatAnnouncementSelectColumns = `
+	a.id, a.room_id, a.created_by, a.title, a.body, a.link, a.send_push, a.send_email, a.status,
+	a.scheduled_at, a.sent_at, a.message_id, a.push_count, a.email_count, a.failure_reason,
+	a.created_at, a.updated_at`
+
+func scanChatAnnouncement(row pgx.Row) (*entity.ChatAnnouncement, error) {
+	var a entity.ChatAnnouncement
+	if err := row.Scan(
+		&a.ID, &a.RoomID, &a.CreatedBy, &a.Title, &a.Body, &a.Link, &a.SendPush, &a.SendEmail, &a.Status,
+		&a.ScheduledAt, &a.SentAt, &a.MessageID, &a.PushCount, &a.EmailCount, &a.FailureReason,
+		&a.CreatedAt, &a.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// whereBroadcastVisible hides channel rooms whose target leaves out the viewer's scope or
+// platform. Other room types are never affected.
+func whereBroadcastVisible(qb *QueryBuilder, alias string, scope string, platform string) {
+	if scope != "" {
+		qb.Where("("+alias+".type <> 'channel' OR COALESCE("+alias+".metadata->>'scope', '') IN ('', $?))", scope)
+	}
+	if platform != "" {
+		qb.Where("("+alias+".type <> 'channel' OR "+alias+".metadata->'platforms' IS NULL OR "+alias+".metadata->'platforms' @> to_jsonb($?::text))", platform)
+	}
+}
+
+// deviceTargetCondition matches devices (alias d) registered from a scope and platform the
+// channel target covers.
+func deviceTargetCondition(target entity.BroadcastTarget) (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	args := make([]interface{}, 0, 2)
+	if target.Scope != "" {
+		conditions = append(conditions, "d.scope = $?")
+		args = append(args, target.Scope)
+	}
+	if len(target.Platforms) > 0 {
+		conditions = append(conditions, "d.platform = ANY($?)")
+		args = append(args, target.Platforms)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// CreateChannel inserts a broadcast channel owned by room.CreatedBy and subscribes every active
+// user to it. It returns the number of subscribers.
+func (r *broadcastRepository) CreateChannel(ctx context.Context, room *entity.ChatRoom) (int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 60*time.Second)
+	defer cancel()
+
+	roomQuery := `
+		INSERT INTO chat_rooms (name, type, created_by, avatar_url, description, metadata)
+		VALUES ($1, 'channel', $2, $3, $4, $5)
+		RETURNING id, type, COALESCE(is_archived, FALSE), COALESCE(is_muted, FALSE), created_at, updated_at
+	`
+	subscribeQuery := `
+		INSERT INTO chat_room_participants (room_id, user_id, role)
+		SELECT $1, u.id, CASE WHEN u.id = $2 THEN 'admin' ELSE 'member' END
+		FROM users u
+		WHERE u.is_active AND u.deleted_at IS NULL
+		ON CONFLICT (room_id, user_id) DO NOTHING
+	`
+
+	var subscribers int64
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(
+			subCtx,
+			roomQuery,
+			room.Name,
+			room.CreatedBy,
+			room.AvatarURL,
+			room.Description,
+			room.Metadata,
+		).Scan(&room.ID, &room.Type, &room.IsArchived, &room.IsMuted, &room.CreatedAt, &room.UpdatedAt)
+		if err != nil {
+			r.logger.Error("[BroadcastRepository.CreateChannel]", zap.Error(err))
+			return fmt.Errorf("failed to create broadcast channel: %w", err)
+		}
+
+		tag, err := tx.Exec(subCtx, subscribeQuery, room.ID, room.CreatedBy)
+		if err != nil {
+			r.logger.Error("[BroadcastRepository.CreateChannel] subscribe", zap.Error(err))
+			return fmt.Errorf("failed to subscribe users: %w", err)
+		}
+		subscribers = tag.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return subscribers, nil
+}
+
+func (r *broadcastRepository) ListChannels(ctx context.Context, viewerID uuid.UUID, filter entity.BroadcastChannelFilter) ([]*entity.BroadcastChannel, int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	from := `
+		FROM chat_rooms cr
+		JOIN chat_room_participants p ON p.room_id = cr.id`
+
+	qb := NewQueryBuilder(`SELECT COUNT(*)`+from).
+		Where("cr.type = 'channel' AND cr.is_archived IS NOT TRUE").
+		Where("p.user_id = $?", viewerID)
+	whereBroadcastVisible(qb, "cr", filter.Scope, filter.Platform)
+
+	countQuery, countArgs := qb.Clone().Build()
+
+	var total int64
+	if err := r.db.QueryRow(subCtx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.logger.Error("[BroadcastRepository.ListChannels] count", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count broadcast channels: %w", err)
+	}
+
+	query, args := qb.ChangeBase(`
+		SELECT cr.id, COALESCE(cr.name, ''), cr.description, cr.avatar_url, COALESCE(cr.metadata, '{}'::jsonb),
+			(SELECT COUNT(*) FROM chat_room_participants sp WHERE sp.room_id = cr.id),
+			COALESCE(p.is_muted, FALSE), cr.last_message_at, cr.created_at` + from).Build()
+	query += ` ORDER BY COALESCE(cr.last_message_at, cr.created_at) DESC, cr.id
+		LIMIT ` + strconv.Itoa(filter.Limit) + ` OFFSET ` + strconv.Itoa(filter.Offset)
+
+	rows, err := r.db.Query(subCtx, query, args...)
+	if err != nil {
+		r.logger.Error("[BroadcastRepository.ListChannels]", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list broadcast channels: %w", err)
+	}
+	defer rows.Close()
+
+	channels := make([]*entity.BroadcastChannel, 0, filter.Limit)
+	for rows.Next() {
+		var c entity.BroadcastChannel
+		if err := rows.Scan(
+			&c.ID, &c.Name, &c.Description, &c.AvatarURL, &c.Target,
+			&c.Subscribers, &c.IsMuted, &c.LastMessageAt, &c.CreatedAt,
+		); err != nil {
+			r.logger.Error("[BroadcastRepository.ListChannels] scan", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to scan broadcast channel: %w", err)
+		}
+		channels = append(channels, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate broadcast channels: %w", err)
+	}
+	return channels, total, nil
+}
+
+func (r *broadcastRepository) CreateAnnouncement(ctx context.Context, announcement *entity.ChatAnnouncement) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO chat_announcements (room_id, created_by, title, body, link, send_push, send_email, scheduled_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, status, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(
+		subCtx,
+		query,
+		announcement.RoomID,
+		announcement.CreatedBy,
+		announcement.Title,
+		announcement.Body,
+		announcement.Link,
+		announcement.SendPush,
+		announcement.SendEmail,
+		announcement.ScheduledAt,
+	).Scan(&announcement.ID, &announcement.Status, &announcement.CreatedAt, &announcement.UpdatedAt)
+	if err != nil {
+		r.logger.Error("[BroadcastRepository.CreateAnnouncement]", zap.Error(err))
+		return fmt.Errorf("failed to create announcement: %w", err)
+	}
+	return nil
+}
+
+func (r *broadcastRepository) FindAnnouncementByID(ctx context.Context, id uuid.UUID) (*entity.ChatAnnouncement, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + chatAnnouncementSelectColumns + ` FROM chat_announcements a WHERE a.id = $1`
+
+	announcement, err := scanChatAnnouncement(r.db.QueryRow(subCtx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dto.ErrAnnouncementNotFound
+		}
+		r.logger.Error("[BroadcastRepository.FindAnnouncementByID]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find announcement: %w", err)
+	}
+	return announcement, nil
+}
+
+func (r *broadcastRepository) ListAnnouncements(ctx context.Context, roomID uuid.UUID, filter entity.AnnouncementFilter) ([]*entity.ChatAnnouncement, int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	qb := NewQueryBuilder(`SELECT COUNT(*) FROM chat_announcements a`).Where("a.room_id = $?", roomID)
+	if filter.Status != "" {
+		qb.Where("a.status = $?", filter.Status)
+	}
+
+	countQuery, countArgs := qb.Clone().Build()
+
+	var total int64
+	if err := r.db.QueryRow(subCtx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.logger.Error("[BroadcastRepository.ListAnnouncements] count", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count announcements: %w", err)
+	}
+
+	query, args := qb.ChangeBase(`SELECT ` + chatAnnouncementSelectColumns + ` FROM chat_announcements a`).Build()
+	query += ` ORDER BY a.scheduled_at DESC, a.id
+		LIMIT ` + strconv.Itoa(filter.Limit) + ` OFFSET ` + strconv.Itoa(filter.Offset)
+
+	rows, err := r.db.Query(subCtx, query, args...)
+	if err != nil {
+		r.logger.Error("[BroadcastRepository.ListAnnouncements]", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list announcements: %w", err)
+	}
+	defer rows.Close()
+
+	announcements := make([]*entity.ChatAnnouncement, 0, filter.Limit)
+	for rows.Next() {
+		announcement, err := scanChatAnnouncement(rows)
+		if err != nil {
+			r.logger.Error("[BroadcastRepository.ListAnnouncements] scan", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to scan announcement: %w", err)
+		}
+		announcements = append(announcements, announcement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate announcements: %w", err)
+	}
+	return announcements, total, nil
+}
+
+func (r *broadcastRepository) CancelAnnouncement(ctx context.Context, id uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE chat_announcements SET status = 'cancelled'
+		WHERE id = $1 AND status = 'scheduled'
+	`
+
+	tag, err := r.db.Exec(subCtx, query, id)
+	if err != nil {
+		r.logger.Error("[BroadcastRepository.CancelAnnouncement]", zap.Error(err))
+		return fmt.Errorf("failed to cancel announcement: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.FindAnnouncementByID(subCtx, id); err != nil {
+			return err
+		}
+		return dto.ErrAnnouncementNotScheduled
+	}
+	return nil
+}
+
+// ClaimAnnouncement moves a scheduled announcement to sending so only one worker delivers it.
+// It returns ErrAnnouncementNotScheduled when the announcement was cancelled or already claimed.
+func (r *broadcastRepository) ClaimAnnouncement(ctx context.Context, id uuid.UUID) (*entity.ChatAnnouncement, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE chat_announcements a SET status = 'sending'
+		WHERE a.id = $1 AND a.status = 'scheduled'
+		RETURNING ` + chatAnnouncementSelectColumns
+
+	announcement, err := scanChatAnnouncement(r.db.QueryRow(subCtx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dto.ErrAnnouncementNotScheduled
+		}
+		r.logger.Error("[BroadcastRepository.ClaimAnnouncement]", zap.Error(err))
+		return nil, fmt.Errorf("failed to claim announcement: %w", err)
+	}
+	return announcement, nil
+}
+
+// ReleaseAnnouncement hands a claimed announcement back so a retry can deliver it.
+func (r *broadcastRepository) ReleaseAnnouncement(ctx context.Context, id uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `UPDATE chat_announcements SET status = 'scheduled' WHERE id = $1 AND status = 'sending'`
+
+	if _, err := r.db.Exec(subCtx, query, id); err != nil {
+		r.logger.Error("[BroadcastRepository.ReleaseAnnouncement]", zap.Error(err))
+		return fmt.Errorf("failed to release announcement: %w", err)
+	}
+	return nil
+}
+
+func (r *broadcastRepository) CompleteAnnouncement(ctx context.Context, announcement *entity.ChatAnnouncement) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE chat_announcements
+		SET status = 'sent', sent_at = NOW(), message_id = $2, push_count = $3, email_count = $4
+		WHERE id = $1
+		RETURNING status, sent_at, updated_at
+	`
+
+	err := r.db.QueryRow(
+		subCtx,
+		query,
+		announcement.ID,
+		announcement.MessageID,
+		announcement.PushCount,
+		announcement.EmailCount,
+	).Scan(&announcement.Status, &announcement.SentAt, &announcement.UpdatedAt)
+	if err != nil {
+		r.logger.Error("[BroadcastRepository.CompleteAnnouncement]", zap.Error(err))
+		return fmt.Errorf("failed to complete announcement: %w", err)
+	}
+	return nil
+}
+
+func (r *broadcastRepository) FailAnnouncement(ctx context.Context, id uuid.UUID, reason string) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE chat_announcements SET status = 'failed', failure_reason = $2
+		WHERE id = $1 AND status IN ('scheduled', 'sending')
+	`
+
+	if _, err := r.db.Exec(subCtx, query, id, reason); err != nil {
+		r.logger.Error("[BroadcastRepository.FailAnnouncement]", zap.Error(err))
+		return fmt.Errorf("failed to mark announcement failed: %w", err)
+	}
+	return nil
+}
+
+// UpsertDevice registers a push token. A token that moves to another account or client is
+// reassigned rather than duplicated.
+func (r *broadcastRepository) UpsertDevice(ctx context.Context, device *entity.UserDevice) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_devices (user_id, token, platform, scope)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (token) DO UPDATE
+		SET user_id = EXCLUDED.user_id, platform = EXCLUDED.platform, scope = EXCLUDED.scope, last_seen_at = NOW()
+		RETURNING id, created_at, last_seen_at
+	`
+
+	err := r.db.QueryRow(subCtx, query, device.UserID, device.Token, device.Platform, device.Scope).
+		Scan(&device.ID, &device.CreatedAt, &device.LastSeenAt)
+	if err != nil {
+		r.logger.Error("[BroadcastRepository.UpsertDevice]", zap.Error(err))
+		return fmt.Errorf("failed to register device: %w", err)
+	}
+	return nil
+}
+
+func (r *broadcastRepository) DeleteDevice(ctx context.Context, userID uuid.UUID, token string) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM user_devices WHERE user_id = $1 AND token = $2`
+
+	if _, err := r.db.Exec(subCtx, query, userID, token); err != nil {
+		r.logger.Error("[BroadcastRepository.DeleteDevice]", zap.Error(err))
+		return fmt.Errorf("failed to delete device: %w", err)
+	}
+	return nil
+}
+
+func (r *broadcastRepository) DeleteDeviceTokens(ctx context.Context, tokens []string) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	query := `DELETE FROM user_devices WHERE token = ANY($1)`
+
+	if _, err := r.db.Exec(subCtx, query, tokens); err != nil {
+		r.logger.Error("[BroadcastRepository.DeleteDeviceTokens]", zap.Error(err))
+		return fmt.Errorf("failed to delete device tokens: %w", err)
+	}
+	return nil
+}
+
+// ListPushDevices pages through the devices of subscribers who have not muted the channel,
+// ordered by device ID. Pass the last ID of the previous page as after.
+func (r *broadcastRepository) ListPushDevices(ctx context.Context, roomID uuid.UUID, target entity.BroadcastTarget, after uuid.UUID, limit int) ([]*entity.UserDevice, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	qb := NewQueryBuilder(`
+		SELECT d.id, d.user_id, d.token, d.platform, d.scope, d.created_at, d.last_seen_at
+		FROM user_devices d
+		JOIN chat_room_participants p ON p.user_id = d.user_id`).
+		Where("p.room_id = $?", roomID).
+		Where("p.is_muted IS NOT TRUE").
+		Where("d.id > $?", after)
+	if target.IsTargeted() {
+		condition, conditionArgs := deviceTargetCondition(target)
+		qb.Where(condition, conditionArgs...)
+	}
+
+	query, args := qb.Build()
+	query += ` ORDER BY d.id LIMIT ` + strconv.Itoa(limit)
+
+	rows, err := r.db.Query(subCtx, query, args...)
+	if err != nil {
+		r.logger.Error("[BroadcastRepository.ListPushDevices]", zap.Error(err))
+		return nil, fmt.Errorf("failed to list push devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := make([]*entity.UserDevice, 0, limit)
+	for rows.Next() {
+		var d entity.UserDevice
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Token, &d.Platform, &d.Scope, &d.CreatedAt, &d.LastSeenAt); err != nil {
+			r.logger.Error("[BroadcastRepository.ListPushDevices] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan device: %w", err)
+		}
+		devices = append(devices, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate devices: %w", err)
+	}
+	return devices, nil
+}
+
+// ListEmailRecipients pages through verified subscribers who have not muted the channel,
+// ordered by user ID. Users carry no scope or platform of their own, so for a targeted
+// channel only users with a matching registered device are included.
+func (r *broadcastRepository) ListEmailRecipients(ctx context.Context, roomID uuid.UUID, target entity.BroadcastTarget, after uuid.UUID, limit int) ([]*entity.BroadcastRecipient, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	qb := NewQueryBuilder(`
+		SELECT u.id, u.email, u.full_name
+		FROM chat_room_participants p
+		JOIN users u ON u.id = p.user_id`).
+		Where("p.room_id = $?", roomID).
+		Where("p.is_muted IS NOT TRUE").
+		Where("u.is_active AND u.is_verified AND u.deleted_at IS NULL").
+		Where("u.id > $?", after)
+	if target.IsTargeted() {
+		condition, conditionArgs := deviceTargetCondition(target)
+		qb.Where("EXISTS (SELECT 1 FROM user_devices d WHERE d.user_id = u.id AND "+condition+")", conditionArgs...)
+	}
+
+	query, args := qb.Build()
+	query += ` ORDER BY u.id LIMIT ` + strconv.Itoa(limit)
+
+	rows, err := r.db.Query(subCtx, query, args...)
+	if err != nil {
+		r.logger.Error("[BroadcastRepository.ListEmailRecipients]", zap.Error(err))
+		return nil, fmt.Errorf("failed to list email recipients: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := make([]*entity.BroadcastRecipient, 0, limit)
+	for rows.Next() {
+		var rc entity.BroadcastRecipient
+		if err := rows.Scan(&rc.UserID, &rc.Email, &rc.FullName); err != nil {
+			r.logger.Error("[BroadcastRepository.ListEmailRecipients] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan email recipient: %w", err)
+		}
+		recipients = append(recipients, &rc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate email recipients: %w", err)
+	}
+	return recipients, nil
+}
@@ -0,0 +1,1331 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type ChatRoomRepository interface {
+	BaseRepository
+	IsParticipant(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (bool, error)
+	CreateMessage(ctx context.Context, message *entity.Chat) error
+	CreateMessageWithMedia(ctx context.Context, message *entity.Chat, mediaID uuid.UUID) error
+	FindMessageByID(ctx context.Context, id uuid.UUID) (*entity.Chat, error)
+	SoftDeleteMessage(ctx context.Context, id uuid.UUID) error
+	CreateRoom(ctx context.Context, room *entity.ChatRoom, participants []*entity.ChatParticipant) error
+	FindOrCreatePersonalRoom(ctx context.Context, userID uuid.UUID, otherUserID uuid.UUID) (uuid.UUID, error)
+	FindRoomByID(ctx context.Context, id uuid.UUID) (*entity.ChatRoom, error)
+	FindParticipant(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (*entity.ChatParticipant, error)
+	ListParticipants(ctx context.Context, roomID uuid.UUID) ([]*entity.ChatParticipant, error)
+	AddParticipants(ctx context.Context, roomID uuid.UUID, userIDs []uuid.UUID, role string) ([]uuid.UUID, error)
+	RemoveParticipant(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) error
+	UpdateMessageText(ctx context.Context, message *entity.Chat) error
+	FindMessagesByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Chat, error)
+	ListMessages(ctx context.Context, roomID uuid.UUID, viewerID uuid.UUID, before *entity.ChatCursor, limit int) ([]*entity.Chat, error)
+	MarkMessagesDelivered(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, messageIDs []uuid.UUID) ([]uuid.UUID, error)
+	MarkMessagesRead(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, messageIDs []uuid.UUID) ([]uuid.UUID, time.Time, error)
+	CountUnread(ctx context.Context, userID uuid.UUID, roomIDs []uuid.UUID) (map[uuid.UUID]int64, error)
+	CreateMedia(ctx context.Context, media *entity.ChatMedia) error
+	ListConversations(ctx context.Context, userID uuid.UUID, filter entity.ConversationFilter) ([]*entity.Conversation, int64, error)
+	ToggleReaction(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, reaction string) (bool, error)
+	ListReactionSummaries(ctx context.Context, messageIDs []uuid.UUID, viewerID uuid.UUID) (map[uuid.UUID][]*entity.ChatReactionSummary, error)
+	ListReactors(ctx context.Context, messageID uuid.UUID, limit int) ([]*entity.ChatReactor, error)
+	SearchMessages(ctx context.Context, filter entity.ChatFilter) ([]*entity.ChatSearchHit, int64, error)
+	UpdateParticipantPreferences(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, isPinned *bool, isMuted *bool) (*entity.ChatParticipant, error)
+	FindPendingMedia(ctx context.Context, id uuid.UUID, uploaderID uuid.UUID, roomID uuid.UUID) (*entity.ChatMedia, error)
+	UpdateParticipantRole(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, role string) error
+	SetParticipantMute(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, until *time.Time) error
+	UpdateSlowMode(ctx context.Context, roomID uuid.UUID, seconds int) error
+	BanUser(ctx context.Context, ban *entity.ChatRoomBan) error
+	UnbanUser(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) error
+	IsBanned(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (bool, error)
+	ListBans(ctx context.Context, roomID uuid.UUID) ([]*entity.ChatRoomBan, error)
+}
+
+const chatMessageSelectColumns = `
+	m.id, m.room_id, m.sender_id, m.reply_to_id, m.forwarded_from_id, m.message, m.type,
+	m.file_url, m.file_name, m.file_size, m.mime_type,
+	COALESCE(m.is_read, FALSE), COALESCE(m.is_delivered, FALSE), COALESCE(m.is_edited, FALSE),
+	COALESCE(m.is_deleted, FALSE), m.is_hidden, m.metadata, m.created_at, m.updated_at`
+
+func scanChatMessage(row pgx.Row, extra ...interface{}) (*entity.Chat, error) {
+	var m entity.Chat
+	dest := []interface{}{
+		&m.ID, &m.RoomID, &m.SenderID, &m.ReplyToID, &m.ForwardedFromID, &m.Message, &m.Type,
+		&m.FileURL, &m.FileName, &m.FileSize, &m.MimeType,
+		&m.IsRead, &m.IsDelivered, &m.IsEdited,
+		&m.IsDeleted, &m.IsHidden, &m.Metadata, &m.CreatedAt, &m.UpdatedAt,
+	}
+	dest = append(dest, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	m.IsForwarded = m.ForwardedFromID != nil
+	return &m, nil
+}
+
+type chatRoomRepository struct {
+	*baseRepository
+}
+
+func NewChatRoomRepository(db *pgxpool.Pool, logger *zap.Logger) ChatRoomRepository {
+	return &chatRoomRepository{
+		baseRepository: NewBaseRepository(
+			db,
+			logger,
+		).(*baseRepository),
+	}
+}
+
+func (r *chatRoomRepository) IsParticipant(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (bool, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM chat_room_participants p
+			JOIN chat_rooms r ON r.id = p.room_id
+			WHERE p.room_id = $1 AND p.user_id = $2 AND r.is_archived IS NOT TRUE
+		)
+	`
+
+	var exists bool
+	if err := r.db.QueryRow(subCtx, query, roomID, userID).Scan(&exists); err != nil {
+		r.logger.Error("[ChatRoomRepository.IsParticipant]", zap.Error(err))
+		return false, fmt.Errorf("failed to check room membership: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *chatRoomRepository) CreateMessage(ctx context.Context, message *entity.Chat) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if err := insertChatMessage(subCtx, r.db.QueryRow, message); err != nil {
+		r.logger.Error("[ChatRoomRepository.CreateMessage]", zap.Error(err))
+		return fmt.Errorf("failed to create chat message: %w", err)
+	}
+	return nil
+}
+
+// CreateMessageWithMedia inserts a message and claims a pending upload for it in one transaction.
+// The upload must belong to the sender and room and not be attached yet.
+func (r *chatRoomRepository) CreateMessageWithMedia(ctx context.Context, message *entity.Chat, mediaID uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	claimQuery := `
+		UPDATE chat_media SET message_id = $1
+		WHERE id = $2 AND uploader_id = $3 AND room_id = $4 AND message_id IS NULL
+	`
+
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		if err := insertChatMessage(subCtx, tx.QueryRow, message); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(subCtx, claimQuery, message.ID, mediaID, message.SenderID, message.RoomID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return dto.ErrChatMediaNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrChatMediaNotFound) {
+			return err
+		}
+		r.logger.Error("[ChatRoomRepository.CreateMessageWithMedia]", zap.Error(err))
+		return fmt.Errorf("failed to create chat message: %w", err)
+	}
+	return nil
+}
+
+func insertChatMessage(ctx context.Context, queryRow func(context.Context, string, ...any) pgx.Row, message *entity.Chat) error {
+	query := `
+		INSERT INTO chat_messages
+			(room_id, sender_id, reply_to_id, forwarded_from_id, message, type, file_url, file_name, file_size, mime_type, metadata)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, is_read, is_delivered, is_edited, is_deleted, created_at, updated_at
+	`
+
+	return queryRow(
+		ctx,
+		query,
+		message.RoomID,
+		message.SenderID,
+		message.ReplyToID,
+		message.ForwardedFromID,
+		message.Message,
+		message.Type,
+		message.FileURL,
+		message.FileName,
+		message.FileSize,
+		message.MimeType,
+		message.Metadata,
+	).Scan(
+		&message.ID,
+		&message.IsRead,
+		&message.IsDelivered,
+		&message.IsEdited,
+		&message.IsDeleted,
+		&message.CreatedAt,
+		&message.UpdatedAt,
+	)
+}
+
+func (r *chatRoomRepository) FindMessageByID(ctx context.Context, id uuid.UUID) (*entity.Chat, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + chatMessageSelectColumns + ` FROM chat_messages m WHERE m.id = $1`
+
+	message, err := scanChatMessage(r.db.QueryRow(subCtx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dto.ErrChatMessageNotFound
+		}
+		r.logger.Error("[ChatRoomRepository.FindMessageByID]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find chat message: %w", err)
+	}
+	return message, nil
+}
+
+func (r *chatRoomRepository) SoftDeleteMessage(ctx context.Context, id uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `UPDATE chat_messages SET is_deleted = TRUE WHERE id = $1 AND is_deleted IS NOT TRUE`
+
+	tag, err := r.db.Exec(subCtx, query, id)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.SoftDeleteMessage]", zap.Error(err))
+		return fmt.Errorf("failed to delete chat message: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return dto.ErrChatMessageNotFound
+	}
+	return nil
+}
+
+const chatRoomSelectColumns = `
+	r.id, r.name, r.type, r.created_by, r.avatar_url, r.description,
+	COALESCE(r.is_archived, FALSE), COALESCE(r.is_muted, FALSE), r.last_message_id, r.last_message_at,
+	r.metadata, r.slow_mode_seconds, r.created_at, r.updated_at`
+
+const chatParticipantSelectColumns = `
+	p.id, p.room_id, p.user_id, COALESCE(p.role, 'member'), p.joined_at, p.last_read_at,
+	COALESCE(p.is_muted, FALSE), COALESCE(p.is_pinned, FALSE), p.nickname, p.muted_until,
+	COALESCE(u.full_name, ''), u.username, u.avatar_url`
+
+func scanChatRoom(row pgx.Row) (*entity.ChatRoom, error) {
+	var room entity.ChatRoom
+	if err := row.Scan(
+		&room.ID, &room.Name, &room.Type, &room.CreatedBy, &room.AvatarURL, &room.Description,
+		&room.IsArchived, &room.IsMuted, &room.LastMessageID, &room.LastMessageAt,
+		&room.Metadata, &room.SlowModeSeconds, &room.CreatedAt, &room.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+func scanChatParticipant(row pgx.Row) (*entity.ChatParticipant, error) {
+	var p entity.ChatParticipant
+	if err := row.Scan(
+		&p.ID, &p.RoomID, &p.UserID, &p.Role, &p.JoinedAt, &p.LastReadAt,
+		&p.IsMuted, &p.IsPinned, &p.Nickname, &p.MutedUntil,
+		&p.FullName, &p.Username, &p.AvatarURL,
+	); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreateRoom inserts a room with its participants. Participants that are not active users are
+// skipped and keep a zero ID.
+func (r *chatRoomRepository) CreateRoom(ctx context.Context, room *entity.ChatRoom, participants []*entity.ChatParticipant) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	roomQuery := `
+		INSERT INTO chat_rooms (name, type, created_by, avatar_url, description, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, COALESCE(is_archived, FALSE), COALESCE(is_muted, FALSE), created_at, updated_at
+	`
+	participantQuery := `
+		INSERT INTO chat_room_participants (room_id, user_id, role)
+		SELECT $1, u.id, $3
+		FROM users u
+		WHERE u.id = $2 AND u.is_active AND u.deleted_at IS NULL
+		ON CONFLICT (room_id, user_id) DO NOTHING
+		RETURNING id, joined_at, last_read_at
+	`
+
+	return r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(
+			subCtx,
+			roomQuery,
+			room.Name,
+			room.Type,
+			room.CreatedBy,
+			room.AvatarURL,
+			room.Description,
+			room.Metadata,
+		).Scan(&room.ID, &room.IsArchived, &room.IsMuted, &room.CreatedAt, &room.UpdatedAt)
+		if err != nil {
+			r.logger.Error("[ChatRoomRepository.CreateRoom]", zap.Error(err))
+			return fmt.Errorf("failed to create chat room: %w", err)
+		}
+
+		for _, participant := range participants {
+			participant.RoomID = room.ID
+			err := tx.QueryRow(subCtx, participantQuery, room.ID, participant.UserID, participant.Role).
+				Scan(&participant.ID, &participant.JoinedAt, &participant.LastReadAt)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Error("[ChatRoomRepository.CreateRoom] participant", zap.Error(err))
+				return fmt.Errorf("failed to add chat room participant: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindOrCreatePersonalRoom returns the personal room between two users, creating it on first
+// contact. The advisory lock keeps concurrent first messages from creating two rooms.
+func (r *chatRoomRepository) FindOrCreatePersonalRoom(ctx context.Context, userID uuid.UUID, otherUserID uuid.UUID) (uuid.UUID, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	var roomID uuid.UUID
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		lockQuery := `SELECT pg_advisory_xact_lock(hashtextextended(LEAST($1::text, $2::text) || GREATEST($1::text, $2::text), 0))`
+		if _, err := tx.Exec(subCtx, lockQuery, userID, otherUserID); err != nil {
+			r.logger.Error("[ChatRoomRepository.FindOrCreatePersonalRoom] lock", zap.Error(err))
+			return fmt.Errorf("failed to lock personal room: %w", err)
+		}
+		if err := tx.QueryRow(subCtx, `SELECT create_personal_chat_room($1, $2)`, userID, otherUserID).Scan(&roomID); err != nil {
+			r.logger.Error("[ChatRoomRepository.FindOrCreatePersonalRoom]", zap.Error(err))
+			return fmt.Errorf("failed to find or create personal room: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return roomID, nil
+}
+
+func (r *chatRoomRepository) FindRoomByID(ctx context.Context, id uuid.UUID) (*entity.ChatRoom, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + chatRoomSelectColumns + ` FROM chat_rooms r WHERE r.id = $1 AND r.is_archived IS NOT TRUE`
+
+	room, err := scanChatRoom(r.db.QueryRow(subCtx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dto.ErrChatRoomNotFound
+		}
+		r.logger.Error("[ChatRoomRepository.FindRoomByID]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find chat room: %w", err)
+	}
+	return room, nil
+}
+
+func (r *chatRoomRepository) FindParticipant(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (*entity.ChatParticipant, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + chatParticipantSelectColumns + `
+		FROM chat_room_participants p
+		JOIN chat_rooms r ON r.id = p.room_id
+		LEFT JOIN users u ON u.id = p.user_id
+		WHERE p.room_id = $1 AND p.user_id = $2 AND r.is_archived IS NOT TRUE
+	`
+
+	participant, err := scanChatParticipant(r.db.QueryRow(subCtx, query, roomID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dto.ErrChatNotParticipant
+		}
+		r.logger.Error("[ChatRoomRepository.FindParticipant]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find chat participant: %w", err)
+	}
+	return participant, nil
+}
+
+func (r *chatRoomRepository) ListParticipants(ctx context.Context, roomID uuid.UUID) ([]*entity.ChatParticipant, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + chatParticipantSelectColumns + `
+		FROM chat_room_participants p
+		LEFT JOIN users u ON u.id = p.user_id
+		WHERE p.room_id = $1
+		ORDER BY p.joined_at ASC
+	`
+
+	rows, err := r.db.Query(subCtx, query, roomID)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.ListParticipants]", zap.Error(err))
+		return nil, fmt.Errorf("failed to list chat participants: %w", err)
+	}
+	defer rows.Close()
+
+	participants := make([]*entity.ChatParticipant, 0)
+	for rows.Next() {
+		participant, err := scanChatParticipant(rows)
+		if err != nil {
+			r.logger.Error("[ChatRoomRepository.ListParticipants] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan chat participant: %w", err)
+		}
+		participants = append(participants, participant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate chat participants: %w", err)
+	}
+	return participants, nil
+}
+
+// AddParticipants adds active users that are not yet in the room and not banned from it, and
+// returns who was added.
+func (r *chatRoomRepository) AddParticipants(ctx context.Context, roomID uuid.UUID, userIDs []uuid.UUID, role string) ([]uuid.UUID, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	added := make([]uuid.UUID, 0, len(userIDs))
+	if len(userIDs) == 0 {
+		return added, nil
+	}
+
+	query := `
+		INSERT INTO chat_room_participants (room_id, user_id, role)
+		SELECT $1, u.id, $3
+		FROM users u
+		WHERE u.id = ANY($2) AND u.is_active AND u.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM chat_room_bans b
+				WHERE b.room_id = $1 AND b.user_id = u.id AND (b.expires_at IS NULL OR b.expires_at > NOW())
+			)
+		ON CONFLICT (room_id, user_id) DO NOTHING
+		RETURNING user_id
+	`
+
+	rows, err := r.db.Query(subCtx, query, roomID, userIDs, role)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.AddParticipants]", zap.Error(err))
+		return nil, fmt.Errorf("failed to add chat participants: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan added participant: %w", err)
+		}
+		added = append(added, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate added participants: %w", err)
+	}
+	return added, nil
+}
+
+// RemoveParticipant removes a user from a room. When the last admin leaves, the longest-standing
+// moderator, or failing that member, is promoted so the room stays manageable.
+func (r *chatRoomRepository) RemoveParticipant(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	deleteQuery := `DELETE FROM chat_room_participants WHERE room_id = $1 AND user_id = $2 RETURNING role`
+	promoteQuery := `
+		UPDATE chat_room_participants SET role = 'admin'
+		WHERE id = (
+			SELECT id FROM chat_room_participants
+			WHERE room_id = $1
+			ORDER BY (role = 'moderator') DESC, joined_at ASC
+			LIMIT 1
+		) AND NOT EXISTS (
+			SELECT 1 FROM chat_room_participants WHERE room_id = $1 AND role = 'admin'
+		)
+	`
+
+	return r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		var role *string
+		if err := tx.QueryRow(subCtx, deleteQuery, roomID, userID).Scan(&role); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return dto.ErrChatParticipantMissing
+			}
+			r.logger.Error("[ChatRoomRepository.RemoveParticipant]", zap.Error(err))
+			return fmt.Errorf("failed to remove chat participant: %w", err)
+		}
+		if role == nil || *role != entity.ChatRoleAdmin {
+			return nil
+		}
+		if _, err := tx.Exec(subCtx, promoteQuery, roomID); err != nil {
+			r.logger.Error("[ChatRoomRepository.RemoveParticipant] promote", zap.Error(err))
+			return fmt.Errorf("failed to promote chat participant: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *chatRoomRepository) UpdateMessageText(ctx context.Context, message *entity.Chat) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE chat_messages SET message = $2, is_edited = TRUE
+		WHERE id = $1 AND is_deleted IS NOT TRUE
+		RETURNING is_edited, updated_at
+	`
+
+	if err := r.db.QueryRow(subCtx, query, message.ID, message.Message).Scan(&message.IsEdited, &message.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dto.ErrChatMessageNotFound
+		}
+		r.logger.Error("[ChatRoomRepository.UpdateMessageText]", zap.Error(err))
+		return fmt.Errorf("failed to update chat message: %w", err)
+	}
+	return nil
+}
+
+func (r *chatRoomRepository) FindMessagesByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Chat, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	messages := make([]*entity.Chat, 0, len(ids))
+	if len(ids) == 0 {
+		return messages, nil
+	}
+
+	query := `
+		SELECT ` + chatMessageSelectColumns + `, COALESCE(u.full_name, ''), u.avatar_url
+		FROM chat_messages m
+		LEFT JOIN users u ON u.id = m.sender_id
+		WHERE m.id = ANY($1)
+	`
+
+	rows, err := r.db.Query(subCtx, query, ids)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.FindMessagesByIDs]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find chat messages: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var message *entity.Chat
+		var senderName string
+		var senderAvatar *string
+		if message, err = scanChatMessage(rows, &senderName, &senderAvatar); err != nil {
+			r.logger.Error("[ChatRoomRepository.FindMessagesByIDs] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan chat message: %w", err)
+		}
+		message.SenderName = senderName
+		message.SenderAvatar = senderAvatar
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate chat messages: %w", err)
+	}
+	return messages, nil
+}
+
+// ListMessages pages through a room newest first. Messages hidden by reports are only
+// returned to their sender.
+func (r *chatRoomRepository) ListMessages(ctx context.Context, roomID uuid.UUID, viewerID uuid.UUID, before *entity.ChatCursor, limit int) ([]*entity.Chat, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	qb := NewQueryBuilder(`
+		SELECT `+chatMessageSelectColumns+`, COALESCE(u.full_name, ''), u.avatar_url
+		FROM chat_messages m
+		LEFT JOIN users u ON u.id = m.sender_id`).
+		Where("m.room_id = $?", roomID).
+		Where("(m.is_hidden IS NOT TRUE OR m.sender_id = $?)", viewerID)
+	if before != nil {
+		qb.Where("(m.created_at, m.id) < ($?, $?)", before.CreatedAt, before.ID)
+	}
+	query, args := qb.Build()
+	query += ` ORDER BY m.created_at DESC, m.id DESC LIMIT ` + strconv.Itoa(limit)
+
+	rows, err := r.db.Query(subCtx, query, args...)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.ListMessages]", zap.Error(err))
+		return nil, fmt.Errorf("failed to list chat messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]*entity.Chat, 0, limit)
+	for rows.Next() {
+		var senderName string
+		var senderAvatar *string
+		message, err := scanChatMessage(rows, &senderName, &senderAvatar)
+		if err != nil {
+			r.logger.Error("[ChatRoomRepository.ListMessages] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan chat message: %w", err)
+		}
+		message.SenderName = senderName
+		message.SenderAvatar = senderAvatar
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate chat messages: %w", err)
+	}
+	return messages, nil
+}
+
+// chatReceiptTargets selects the messages of room $1 another participant $2 can acknowledge: the
+// ids in $3, or everything after their last_read_at when $3 is empty.
+const chatReceiptTargets = `
+	SELECT m.id, m.created_at
+	FROM chat_messages m
+	WHERE m.room_id = $1
+		AND m.sender_id <> $2
+		AND m.is_deleted IS NOT TRUE
+		AND m.is_hidden IS NOT TRUE
+		AND CASE WHEN cardinality($3::uuid[]) = 0
+			THEN m.created_at > (SELECT last_read_at FROM chat_room_participants WHERE room_id = $1 AND user_id = $2)
+			ELSE m.id = ANY($3::uuid[])
+		END
+`
+
+// MarkMessagesDelivered records delivery receipts and returns the messages that were newly delivered.
+func (r *chatRoomRepository) MarkMessagesDelivered(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, messageIDs []uuid.UUID) ([]uuid.UUID, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		WITH target AS (` + chatReceiptTargets + `),
+		inserted AS (
+			INSERT INTO chat_message_deliveries (message_id, user_id)
+			SELECT id, $2 FROM target
+			ON CONFLICT (message_id, user_id) DO NOTHING
+			RETURNING message_id
+		)
+		UPDATE chat_messages SET is_delivered = TRUE
+		WHERE id IN (SELECT message_id FROM inserted)
+		RETURNING id
+	`
+
+	if messageIDs == nil {
+		messageIDs = []uuid.UUID{}
+	}
+
+	delivered := make([]uuid.UUID, 0, len(messageIDs))
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(subCtx, query, roomID, userID, messageIDs)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			delivered = append(delivered, id)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.MarkMessagesDelivered]", zap.Error(err))
+		return nil, fmt.Errorf("failed to mark chat messages delivered: %w", err)
+	}
+	return delivered, nil
+}
+
+// MarkMessagesRead records read receipts, which imply delivery, and moves the participant's
+// last_read_at forward to the newest message read. It returns the newly read messages and the
+// resulting last_read_at.
+func (r *chatRoomRepository) MarkMessagesRead(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, messageIDs []uuid.UUID) ([]uuid.UUID, time.Time, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	readQuery := `
+		WITH target AS (` + chatReceiptTargets + `),
+		delivered AS (
+			INSERT INTO chat_message_deliveries (message_id, user_id)
+			SELECT id, $2 FROM target
+			ON CONFLICT (message_id, user_id) DO NOTHING
+		),
+		inserted AS (
+			INSERT INTO chat_message_reads (message_id, user_id)
+			SELECT id, $2 FROM target
+			ON CONFLICT (message_id, user_id) DO NOTHING
+			RETURNING message_id
+		)
+		UPDATE chat_messages SET is_read = TRUE, is_delivered = TRUE
+		WHERE id IN (SELECT message_id FROM inserted)
+		RETURNING id, created_at
+	`
+	// When everything is marked read the watermark moves to now, so messages that were hidden or
+	// deleted meanwhile do not linger as unread.
+	watermarkQuery := `
+		UPDATE chat_room_participants
+		SET last_read_at = GREATEST(last_read_at, CASE WHEN cardinality($3::uuid[]) = 0 THEN NOW() ELSE $4 END)
+		WHERE room_id = $1 AND user_id = $2
+		RETURNING last_read_at
+	`
+
+	if messageIDs == nil {
+		messageIDs = []uuid.UUID{}
+	}
+
+	read := make([]uuid.UUID, 0, len(messageIDs))
+	var lastReadAt time.Time
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(subCtx, readQuery, roomID, userID, messageIDs)
+		if err != nil {
+			return err
+		}
+		var newest *time.Time
+		for rows.Next() {
+			var id uuid.UUID
+			var createdAt time.Time
+			if err := rows.Scan(&id, &createdAt); err != nil {
+				rows.Close()
+				return err
+			}
+			read = append(read, id)
+			if newest == nil || createdAt.After(*newest) {
+				newest = &createdAt
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if err := tx.QueryRow(subCtx, watermarkQuery, roomID, userID, messageIDs, newest).Scan(&lastReadAt); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return dto.ErrChatNotParticipant
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrChatNotParticipant) {
+			return nil, time.Time{}, err
+		}
+		r.logger.Error("[ChatRoomRepository.MarkMessagesRead]", zap.Error(err))
+		return nil, time.Time{}, fmt.Errorf("failed to mark chat messages read: %w", err)
+	}
+	return read, lastReadAt, nil
+}
+
+// chatUnreadCountExpr counts the messages from others newer than participant p's last_read_at.
+const chatUnreadCountExpr = `(
+	SELECT COUNT(*) FROM chat_messages um
+	WHERE um.room_id = p.room_id
+		AND um.created_at > p.last_read_at
+		AND um.sender_id <> p.user_id
+		AND um.type <> 'system'
+		AND um.is_deleted IS NOT TRUE
+		AND um.is_hidden IS NOT TRUE
+)`
+
+// CountUnread counts messages from others newer than the user's last_read_at, per room. Every
+// room the user belongs to is included when roomIDs is empty.
+func (r *chatRoomRepository) CountUnread(ctx context.Context, userID uuid.UUID, roomIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT p.room_id, ` + chatUnreadCountExpr + `
+		FROM chat_room_participants p
+		JOIN chat_rooms cr ON cr.id = p.room_id AND cr.is_archived IS NOT TRUE
+		WHERE p.user_id = $1
+			AND (cardinality($2::uuid[]) = 0 OR p.room_id = ANY($2::uuid[]))
+	`
+
+	if roomIDs == nil {
+		roomIDs = []uuid.UUID{}
+	}
+
+	rows, err := r.db.Query(subCtx, query, userID, roomIDs)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.CountUnread]", zap.Error(err))
+		return nil, fmt.Errorf("failed to count unread chat messages: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]int64)
+	for rows.Next() {
+		var roomID uuid.UUID
+		var count int64
+		if err := rows.Scan(&roomID, &count); err != nil {
+			r.logger.Error("[ChatRoomRepository.CountUnread] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan unread count: %w", err)
+		}
+		counts[roomID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate unread counts: %w", err)
+	}
+	return counts, nil
+}
+
+func (r *chatRoomRepository) CreateMedia(ctx context.Context, media *entity.ChatMedia) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO chat_media
+			(room_id, uploader_id, type, object_key, url, name, size, mime_type, width, height, duration, thumbnail_key, thumbnail)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(
+		subCtx,
+		query,
+		media.RoomID,
+		media.UploaderID,
+		media.Type,
+		media.ObjectKey,
+		media.URL,
+		media.Name,
+		media.Size,
+		media.MimeType,
+		media.Width,
+		media.Height,
+		media.Duration,
+		media.ThumbnailKey,
+		media.Thumbnail,
+	).Scan(&media.ID, &media.CreatedAt)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.CreateMedia]", zap.Error(err))
+		return fmt.Errorf("failed to create chat media: %w", err)
+	}
+	return nil
+}
+
+// FindPendingMedia returns an upload that the user made to the room and no message has claimed.
+func (r *chatRoomRepository) FindPendingMedia(ctx context.Context, id uuid.UUID, uploaderID uuid.UUID, roomID uuid.UUID) (*entity.ChatMedia, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, room_id, uploader_id, message_id, type, object_key, url, name, size, mime_type,
+			width, height, duration, thumbnail_key, thumbnail, created_at
+		FROM chat_media
+		WHERE id = $1 AND uploader_id = $2 AND room_id = $3 AND message_id IS NULL
+	`
+
+	var media entity.ChatMedia
+	err := r.db.QueryRow(subCtx, query, id, uploaderID, roomID).Scan(
+		&media.ID, &media.RoomID, &media.UploaderID, &media.MessageID, &media.Type, &media.ObjectKey,
+		&media.URL, &media.Name, &media.Size, &media.MimeType,
+		&media.Width, &media.Height, &media.Duration, &media.ThumbnailKey, &media.Thumbnail, &media.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dto.ErrChatMediaNotFound
+		}
+		r.logger.Error("[ChatRoomRepository.FindPendingMedia]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find chat media: %w", err)
+	}
+	return &media, nil
+}
+
+// ListConversations returns the user's non-archived rooms, pinned first and then by latest
+// activity, with unread and visible message counts.
+func (r *chatRoomRepository) ListConversations(ctx context.Context, userID uuid.UUID, filter entity.ConversationFilter) ([]*entity.Conversation, int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	from := `
+		FROM chat_room_participants p
+		JOIN chat_rooms cr ON cr.id = p.room_id AND cr.is_archived IS NOT TRUE`
+
+	qb := NewQueryBuilder(`SELECT COUNT(*)`+from).Where("p.user_id = $?", userID)
+	if filter.Type != "" {
+		qb.Where("cr.type = $?", filter.Type)
+	}
+	if filter.UnreadOnly {
+		qb.Where(chatUnreadCountExpr + " > 0")
+	}
+	whereBroadcastVisible(qb, "cr", filter.Scope, filter.Platform)
+
+	countQuery, countArgs := qb.Clone().Build()
+
+	var total int64
+	if err := r.db.QueryRow(subCtx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.logger.Error("[ChatRoomRepository.ListConversations] count", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count conversations: %w", err)
+	}
+
+	query, args := qb.ChangeBase(`
+		SELECT cr.id, cr.type, cr.name, cr.avatar_url, COALESCE(p.role, 'member'),
+			COALESCE(p.is_pinned, FALSE), COALESCE(p.is_muted, FALSE), p.last_read_at,
+			ou.id, COALESCE(ou.full_name, ''), ou.avatar_url,
+			cr.last_message_id, cr.last_message_at, cr.updated_at,
+			` + chatUnreadCountExpr + `,
+			(SELECT COUNT(*) FROM chat_messages tm
+				WHERE tm.room_id = cr.id AND tm.is_deleted IS NOT TRUE AND tm.is_hidden IS NOT TRUE)` + from + `
+		LEFT JOIN LATERAL (
+			SELECT op.user_id FROM chat_room_participants op
+			WHERE op.room_id = cr.id AND op.user_id <> p.user_id
+			LIMIT 1
+		) other ON cr.type = 'personal'
+		LEFT JOIN users ou ON ou.id = other.user_id`).Build()
+	query += ` ORDER BY COALESCE(p.is_pinned, FALSE) DESC, COALESCE(cr.last_message_at, cr.created_at) DESC, cr.id
+		LIMIT ` + strconv.Itoa(filter.Limit) + ` OFFSET ` + strconv.Itoa(filter.Offset)
+
+	rows, err := r.db.Query(subCtx, query, args...)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.ListConversations]", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	defer rows.Close()
+
+	conversations := make([]*entity.Conversation, 0, filter.Limit)
+	for rows.Next() {
+		var c entity.Conversation
+		if err := rows.Scan(
+			&c.RoomID, &c.Type, &c.Name, &c.AvatarURL, &c.Role,
+			&c.IsPinned, &c.IsMuted, &c.LastReadAt,
+			&c.OtherUserID, &c.OtherUserName, &c.OtherUserAvatar,
+			&c.LastMessageID, &c.LastMessageAt, &c.UpdatedAt,
+			&c.UnreadCount, &c.TotalMessages,
+		); err != nil {
+			r.logger.Error("[ChatRoomRepository.ListConversations] scan", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		conversations = append(conversations, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate conversations: %w", err)
+	}
+	return conversations, total, nil
+}
+
+func (r *chatRoomRepository) UpdateParticipantPreferences(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, isPinned *bool, isMuted *bool) (*entity.ChatParticipant, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		WITH updated AS (
+			UPDATE chat_room_participants
+			SET is_pinned = COALESCE($3, is_pinned), is_muted = COALESCE($4, is_muted)
+			WHERE room_id = $1 AND user_id = $2
+			RETURNING *
+		)
+		SELECT ` + chatParticipantSelectColumns + `
+		FROM updated p
+		LEFT JOIN users u ON u.id = p.user_id
+	`
+
+	participant, err := scanChatParticipant(r.db.QueryRow(subCtx, query, roomID, userID, isPinned, isMuted))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dto.ErrChatNotParticipant
+		}
+		r.logger.Error("[ChatRoomRepository.UpdateParticipantPreferences]", zap.Error(err))
+		return nil, fmt.Errorf("failed to update chat preferences: %w", err)
+	}
+	return participant, nil
+}
+
+var chatSearchSortColumns = map[string]string{
+	"created_at": "m.created_at",
+	"is_read":    "m.is_read",
+	"rank":       "rank",
+}
+
+// SearchMessages searches the messages of every room filter.UserID belongs to. The user ID is
+// always $1 and the search text, when present, $2 so the select list can reuse it for ranking
+// and highlighting.
+func (r *chatRoomRepository) SearchMessages(ctx context.Context, filter entity.ChatFilter) ([]*entity.ChatSearchHit, int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	from := `
+		FROM chat_messages m
+		JOIN chat_room_participants p ON p.room_id = m.room_id
+		JOIN chat_rooms cr ON cr.id = m.room_id AND cr.is_archived IS NOT TRUE
+		LEFT JOIN users u ON u.id = m.sender_id`
+
+	qb := NewQueryBuilder(`SELECT COUNT(*)`+from).Where("p.user_id = $?", filter.UserID)
+	searchColumns := `NULL::text, 0::float8 AS rank`
+	if filter.Search != nil {
+		qb.Where("to_tsvector('simple', COALESCE(m.message, '')) @@ websearch_to_tsquery('simple', $?)", *filter.Search)
+		searchColumns = `
+			ts_headline('simple',
+				replace(replace(replace(m.message, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+				websearch_to_tsquery('simple', $2),
+				'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" ... "'),
+			ts_rank(to_tsvector('simple', COALESCE(m.message, '')), websearch_to_tsquery('simple', $2))::float8 AS rank`
+	}
+	qb.Where("m.is_deleted IS NOT TRUE").
+		Where("m.type <> 'system'").
+		Where("(m.is_hidden IS NOT TRUE OR m.sender_id = p.user_id)")
+	if filter.GroupID != nil {
+		qb.Where("m.room_id = $?", *filter.GroupID)
+	}
+	if filter.OtherUserID != nil {
+		qb.Where(`cr.type = 'personal' AND EXISTS (
+			SELECT 1 FROM chat_room_participants op WHERE op.room_id = m.room_id AND op.user_id = $?
+		)`, *filter.OtherUserID)
+	}
+	if filter.Type != "" {
+		qb.Where("m.type = $?", filter.Type)
+	}
+	if filter.IsRead != nil {
+		qb.Where("COALESCE(m.is_read, FALSE) = $?", *filter.IsRead)
+	}
+	if filter.IsDelivered != nil {
+		qb.Where("COALESCE(m.is_delivered, FALSE) = $?", *filter.IsDelivered)
+	}
+	if filter.StartDate != nil {
+		qb.Where("m.created_at >= $?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		qb.Where("m.created_at <= $?", *filter.EndDate)
+	}
+
+	countQuery, countArgs := qb.Clone().Build()
+
+	var total int64
+	if err := r.db.QueryRow(subCtx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.logger.Error("[ChatRoomRepository.SearchMessages] count", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count chat messages: %w", err)
+	}
+
+	sortColumn, ok := chatSearchSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = chatSearchSortColumns["created_at"]
+	}
+	direction := "DESC"
+	if filter.SortOrder == "asc" {
+		direction = "ASC"
+	}
+
+	query, args := qb.ChangeBase(`
+		SELECT ` + chatMessageSelectColumns + `, COALESCE(u.full_name, ''), u.avatar_url,
+			cr.type, cr.name, ` + searchColumns + from).Build()
+	query += ` ORDER BY ` + sortColumn + ` ` + direction + `, m.created_at DESC, m.id DESC
+		LIMIT ` + strconv.Itoa(filter.Limit) + ` OFFSET ` + strconv.Itoa(filter.Offset)
+
+	rows, err := r.db.Query(subCtx, query, args...)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.SearchMessages]", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to search chat messages: %w", err)
+	}
+	defer rows.Close()
+
+	hits := make([]*entity.ChatSearchHit, 0, filter.Limit)
+	for rows.Next() {
+		var hit entity.ChatSearchHit
+		var senderName string
+		var senderAvatar *string
+		message, err := scanChatMessage(rows, &senderName, &senderAvatar, &hit.RoomType, &hit.RoomName, &hit.Highlight, &hit.Rank)
+		if err != nil {
+			r.logger.Error("[ChatRoomRepository.SearchMessages] scan", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to scan chat message: %w", err)
+		}
+		message.SenderName = senderName
+		message.SenderAvatar = senderAvatar
+		hit.Message = message
+		hits = append(hits, &hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate chat messages: %w", err)
+	}
+	return hits, total, nil
+}
+
+// ToggleReaction removes the user's reaction when present and adds it otherwise. It reports
+// whether the reaction was added.
+func (r *chatRoomRepository) ToggleReaction(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, reaction string) (bool, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	deleteQuery := `DELETE FROM chat_message_reactions WHERE message_id = $1 AND user_id = $2 AND reaction_type = $3`
+	insertQuery := `
+		INSERT INTO chat_message_reactions (message_id, user_id, reaction_type)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (message_id, user_id, reaction_type) DO NOTHING
+	`
+
+	var added bool
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(subCtx, deleteQuery, messageID, userID, reaction)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() > 0 {
+			return nil
+		}
+		if _, err := tx.Exec(subCtx, insertQuery, messageID, userID, reaction); err != nil {
+			return err
+		}
+		added = true
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.ToggleReaction]", zap.Error(err))
+		return false, fmt.Errorf("failed to toggle chat reaction: %w", err)
+	}
+	return added, nil
+}
+
+// ListReactionSummaries aggregates reactions per message and emoji, most used first.
+func (r *chatRoomRepository) ListReactionSummaries(ctx context.Context, messageIDs []uuid.UUID, viewerID uuid.UUID) (map[uuid.UUID][]*entity.ChatReactionSummary, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	summaries := make(map[uuid.UUID][]*entity.ChatReactionSummary)
+	if len(messageIDs) == 0 {
+		return summaries, nil
+	}
+
+	query := `
+		SELECT message_id, reaction_type, COUNT(*), BOOL_OR(user_id = $2)
+		FROM chat_message_reactions
+		WHERE message_id = ANY($1)
+		GROUP BY message_id, reaction_type
+		ORDER BY message_id, COUNT(*) DESC, MIN(created_at)
+	`
+
+	rows, err := r.db.Query(subCtx, query, messageIDs, viewerID)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.ListReactionSummaries]", zap.Error(err))
+		return nil, fmt.Errorf("failed to list chat reactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID uuid.UUID
+		var summary entity.ChatReactionSummary
+		if err := rows.Scan(&messageID, &summary.ReactionType, &summary.Count, &summary.ReactedByMe); err != nil {
+			r.logger.Error("[ChatRoomRepository.ListReactionSummaries] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan chat reaction: %w", err)
+		}
+		summaries[messageID] = append(summaries[messageID], &summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate chat reactions: %w", err)
+	}
+	return summaries, nil
+}
+
+func (r *chatRoomRepository) ListReactors(ctx context.Context, messageID uuid.UUID, limit int) ([]*entity.ChatReactor, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT cr.user_id, COALESCE(u.full_name, ''), u.username, u.avatar_url, cr.reaction_type, cr.created_at
+		FROM chat_message_reactions cr
+		LEFT JOIN users u ON u.id = cr.user_id
+		WHERE cr.message_id = $1
+		ORDER BY cr.created_at DESC
+		LIMIT ` + strconv.Itoa(limit)
+
+	rows, err := r.db.Query(subCtx, query, messageID)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.ListReactors]", zap.Error(err))
+		return nil, fmt.Errorf("failed to list chat reactors: %w", err)
+	}
+	defer rows.Close()
+
+	reactors := make([]*entity.ChatReactor, 0)
+	for rows.Next() {
+		var reactor entity.ChatReactor
+		if err := rows.Scan(
+			&reactor.UserID, &reactor.FullName, &reactor.Username, &reactor.AvatarURL,
+			&reactor.ReactionType, &reactor.CreatedAt,
+		); err != nil {
+			r.logger.Error("[ChatRoomRepository.ListReactors] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan chat reactor: %w", err)
+		}
+		reactors = append(reactors, &reactor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate chat reactors: %w", err)
+	}
+	return reactors, nil
+}
+
+func (r *chatRoomRepository) UpdateParticipantRole(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, role string) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `UPDATE chat_room_participants SET role = $3 WHERE room_id = $1 AND user_id = $2`
+
+	tag, err := r.db.Exec(subCtx, query, roomID, userID, role)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.UpdateParticipantRole]", zap.Error(err))
+		return fmt.Errorf("failed to update chat participant role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return dto.ErrChatParticipantMissing
+	}
+	return nil
+}
+
+// SetParticipantMute silences a participant until the given time; nil lifts the mute.
+func (r *chatRoomRepository) SetParticipantMute(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, until *time.Time) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `UPDATE chat_room_participants SET muted_until = $3 WHERE room_id = $1 AND user_id = $2`
+
+	tag, err := r.db.Exec(subCtx, query, roomID, userID, until)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.SetParticipantMute]", zap.Error(err))
+		return fmt.Errorf("failed to update chat participant mute: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return dto.ErrChatParticipantMissing
+	}
+	return nil
+}
+
+func (r *chatRoomRepository) UpdateSlowMode(ctx context.Context, roomID uuid.UUID, seconds int) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `UPDATE chat_rooms SET slow_mode_seconds = $2 WHERE id = $1 AND is_archived IS NOT TRUE`
+
+	tag, err := r.db.Exec(subCtx, query, roomID, seconds)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.UpdateSlowMode]", zap.Error(err))
+		return fmt.Errorf("failed to update chat room slow mode: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return dto.ErrChatRoomNotFound
+	}
+	return nil
+}
+
+// BanUser records or replaces a ban and removes the user from the room in one transaction.
+func (r *chatRoomRepository) BanUser(ctx context.Context, ban *entity.ChatRoomBan) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	banQuery := `
+		INSERT INTO chat_room_bans (room_id, user_id, banned_by, reason, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (room_id, user_id) DO UPDATE
+		SET banned_by = EXCLUDED.banned_by, reason = EXCLUDED.reason,
+			expires_at = EXCLUDED.expires_at, created_at = NOW()
+		RETURNING id, created_at
+	`
+	removeQuery := `DELETE FROM chat_room_participants WHERE room_id = $1 AND user_id = $2`
+
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(subCtx, banQuery, ban.RoomID, ban.UserID, ban.BannedBy, ban.Reason, ban.ExpiresAt).
+			Scan(&ban.ID, &ban.CreatedAt)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(subCtx, removeQuery, ban.RoomID, ban.UserID)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.BanUser]", zap.Error(err))
+		return fmt.Errorf("failed to ban chat user: %w", err)
+	}
+	return nil
+}
+
+func (r *chatRoomRepository) UnbanUser(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		DELETE FROM chat_room_bans
+		WHERE room_id = $1 AND user_id = $2 AND (expires_at IS NULL OR expires_at > NOW())
+	`
+
+	tag, err := r.db.Exec(subCtx, query, roomID, userID)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.UnbanUser]", zap.Error(err))
+		return fmt.Errorf("failed to unban chat user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return dto.ErrChatBanNotFound
+	}
+	return nil
+}
+
+func (r *chatRoomRepository) IsBanned(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (bool, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM chat_room_bans
+			WHERE room_id = $1 AND user_id = $2 AND (expires_at IS NULL OR expires_at > NOW())
+		)
+	`
+
+	var banned bool
+	if err := r.db.QueryRow(subCtx, query, roomID, userID).Scan(&banned); err != nil {
+		r.logger.Error("[ChatRoomRepository.IsBanned]", zap.Error(err))
+		return false, fmt.Errorf("failed to check chat ban: %w", err)
+	}
+	return banned, nil
+}
+
+// ListBans returns the room's active bans, newest first.
+func (r *chatRoomRepository) ListBans(ctx context.Context, roomID uuid.UUID) ([]*entity.ChatRoomBan, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT b.id, b.room_id, b.user_id, b.banned_by, b.reason, b.expires_at, b.created_at,
+			COALESCE(u.full_name, ''), u.username, u.avatar_url
+		FROM chat_room_bans b
+		LEFT JOIN users u ON u.id = b.user_id
+		WHERE b.room_id = $1 AND (b.expires_at IS NULL OR b.expires_at > NOW())
+		ORDER BY b.created_at DESC
+	`
+
+	rows, err := r.db.Query(subCtx, query, roomID)
+	if err != nil {
+		r.logger.Error("[ChatRoomRepository.ListBans]", zap.Error(err))
+		return nil, fmt.Errorf("failed to list chat bans: %w", err)
+	}
+	defer rows.Close()
+
+	bans := make([]*entity.ChatRoomBan, 0)
+	for rows.Next() {
+		var ban entity.ChatRoomBan
+		if err := rows.Scan(
+			&ban.ID, &ban.RoomID, &ban.UserID, &ban.BannedBy, &ban.Reason, &ban.ExpiresAt, &ban.CreatedAt,
+			&ban.FullName, &ban.Username, &ban.AvatarURL,
+		); err != nil {
+			r.logger.Error("[ChatRoomRepository.ListBans] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan chat ban: %w", err)
+		}
+		bans = append(bans, &ban)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate chat bans: %w", err)
+	}
+	return bans, nil
+}
@@ -0,0 +1,1068 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type CommentRepository interface {
+	BaseRepository
+	Create(ctx context.Context, comment *entity.Comment) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.Comment, error)
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Comment, error)
+	Update(ctx context.Context, comment *entity.Comment) error
+	SoftDelete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filter entity.CommentFilter) ([]*entity.Comment, int64, error)
+	FindDescendants(ctx context.Context, rootIDs []uuid.UUID, maxDepth int, viewerID *uuid.UUID) ([]*entity.Comment, error)
+	GetDepth(ctx context.Context, id uuid.UUID) (int, error)
+	Like(ctx context.Context, commentID uuid.UUID, userID uuid.UUID) (bool, int64, error)
+	Unlike(ctx context.Context, commentID uuid.UUID, userID uuid.UUID) (bool, int64, error)
+	FindLikedCommentIDs(ctx context.Context, userID uuid.UUID, commentIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	ListForModeration(ctx context.Context, filter entity.CommentModerationFilter) ([]*entity.Comment, int64, error)
+	CountByStatus(ctx context.Context, status string) (int64, error)
+	UpdateStatus(ctx context.Context, ids []uuid.UUID, status string, reason *string, moderatorID uuid.UUID) (int64, error)
+	GetReputation(ctx context.Context, userID uuid.UUID) (*entity.CommentAuthorReputation, error)
+	SetShadowBan(ctx context.Context, userIDs []uuid.UUID, banned bool, moderatorID uuid.UUID) (int64, error)
+	VerifyGuest(ctx context.Context, id uuid.UUID, status string, reason *string) (bool, error)
+	ReplaceMentions(ctx context.Context, commentID uuid.UUID, userIDs []uuid.UUID) error
+	FindMentions(ctx context.Context, commentIDs []uuid.UUID) (map[uuid.UUID][]*entity.CommentMention, error)
+	RecordNotifications(ctx context.Context, commentID uuid.UUID, recipients map[uuid.UUID]string) ([]uuid.UUID, error)
+	GetNotificationSettings(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]*entity.CommentNotificationSettings, error)
+	UpsertNotificationSettings(ctx context.Context, settings *entity.CommentNotificationSettings) error
+	Stats(ctx context.Context, filter entity.CommentStatsFilter) (*entity.CommentStats, error)
+	Leaderboard(ctx context.Context, filter entity.CommentStatsFilter) ([]*entity.CommentLeaderboardEntry, error)
+}
+
+type commentRepository struct {
+	*baseRepository
+}
+
+func NewCommentRepository(db *pgxpool.Pool, logger *zap.Logger) CommentRepository {
+	return &commentRepository{
+		baseRepository: NewBaseRepository(
+			db,
+			logger,
+		).(*baseRepository),
+	}
+}
+
+const commentSelectColumns = `
+	c.id, c.user_id, c.page_url, c.name, c.email, COALESCE(c.comment, ''), c.type, c.reply_to_id,
+	COALESCE(c.is_edited, FALSE), COALESCE(c.is_deleted, FALSE), c.media_url,
+	c.status, c.moderation_reason, c.moderated_by, c.moderated_at,
+	c.content_type, c.content_id, c.content_title, c.guest_verified_at, c.created_at, c.updated_at,
+	c.likes_count,
+	(SELECT COUNT(*) FROM comments r WHERE r.reply_to_id = c.id AND r.is_deleted IS NOT TRUE AND r.status = 'approved') AS replies_count,
+	u.full_name, u.avatar_url`
+
+// commentVisibleCondition keeps soft-deleted comments that still anchor live replies.
+const commentVisibleCondition = `(c.is_deleted IS NOT TRUE OR EXISTS (SELECT 1 FROM comments r WHERE r.reply_to_id = c.id AND r.is_deleted IS NOT TRUE AND r.status = 'approved'))`
+
+// commentPublishedCondition hides pending, rejected and shadow-banned comments from
+// everyone except their author. param is the placeholder bound to the viewer ID.
+func commentPublishedCondition(alias string, param string) string {
+	return `((` + alias + `.status = 'approved' AND NOT EXISTS (
+		SELECT 1 FROM comment_author_reputations rep
+		WHERE rep.user_id = ` + alias + `.user_id AND rep.is_shadow_banned
+	)) OR ` + alias + `.user_id = ` + param + `)`
+}
+
+// commentNotBlockedCondition hides comments by users the viewer has blocked. param is the
+// placeholder bound to the viewer ID; anonymous viewers see everything.
+func commentNotBlockedCondition(alias string, param string) string {
+	return `NOT EXISTS (
+		SELECT 1 FROM user_blocks ub
+		WHERE ub.blocker_id = ` + param + ` AND ub.blocked_id = ` + alias + `.user_id
+	)`
+}
+
+// commentAuthorKey identifies an author across registered users and guests, who are
+// told apart by their lowercased email.
+const commentAuthorKey = `COALESCE(c.user_id::text, 'guest:' || LOWER(c.email))`
+
+var commentSortColumns = map[string]string{
+	"created_at":    "c.created_at",
+	"likes_count":   "c.likes_count",
+	"replies_count": "replies_count",
+}
+
+func scanComment(row pgx.Row, extra ...interface{}) (*entity.Comment, error) {
+	var c entity.Comment
+	var authorName *string
+	var authorAvatar *string
+
+	dest := []interface{}{
+		&c.ID, &c.UserID, &c.PageURL, &c.Name, &c.Email, &c.Comment, &c.Type, &c.ReplyToID,
+		&c.IsEdited, &c.IsDeleted, &c.MediaURL,
+		&c.Status, &c.ModerationReason, &c.ModeratedBy, &c.ModeratedAt,
+		&c.ContentType, &c.ContentID, &c.ContentTitle, &c.GuestVerifiedAt, &c.CreatedAt, &c.UpdatedAt,
+		&c.LikesCount, &c.RepliesCount,
+		&authorName, &authorAvatar,
+	}
+	dest = append(dest, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case authorName != nil:
+		c.Author = &entity.CommentAuthor{
+			ID:        c.UserID,
+			Name:      *authorName,
+			AvatarURL: authorAvatar,
+		}
+	case c.IsGuest() && c.Name != nil:
+		c.Author = &entity.CommentAuthor{
+			Name:    *c.Name,
+			IsGuest: true,
+		}
+	}
+	return &c, nil
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *entity.Comment) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if comment.Status == "" {
+		comment.Status = entity.CommentStatusApproved
+	}
+
+	query := `
+		INSERT INTO comments
+			(user_id, page_url, name, email, comment, type, reply_to_id, media_url, status, moderation_reason,
+			content_type, content_id, content_title)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(
+			subCtx,
+			query,
+			comment.UserID,
+			comment.PageURL,
+			comment.Name,
+			comment.Email,
+			comment.Comment,
+			comment.Type,
+			comment.ReplyToID,
+			comment.MediaURL,
+			comment.Status,
+			comment.ModerationReason,
+			comment.ContentType,
+			comment.ContentID,
+			comment.ContentTitle,
+		).Scan(&comment.ID, &comment.CreatedAt, &comment.UpdatedAt)
+		if err != nil {
+			r.logger.Error("[CommentRepository.Create]", zap.Error(err))
+			return fmt.Errorf("failed to create comment: %w", err)
+		}
+
+		if comment.UserID == nil {
+			return nil
+		}
+		approved, rejected := reputationDelta("", comment.Status)
+		return r.adjustReputation(subCtx, tx, *comment.UserID, approved, rejected)
+	})
+}
+
+func (r *commentRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Comment, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + commentSelectColumns + `
+		FROM comments c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.id = $1`
+
+	comment, err := scanComment(r.db.QueryRow(subCtx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dto.ErrCommentNotFound
+		}
+		r.logger.Error("[CommentRepository.FindByID]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find comment: %w", err)
+	}
+	return comment, nil
+}
+
+func (r *commentRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Comment, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return []*entity.Comment{}, nil
+	}
+
+	query := `SELECT ` + commentSelectColumns + `
+		FROM comments c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.id = ANY($1)`
+
+	rows, err := r.db.Query(subCtx, query, ids)
+	if err != nil {
+		r.logger.Error("[CommentRepository.FindByIDs]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]*entity.Comment, 0, len(ids))
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			r.logger.Error("[CommentRepository.FindByIDs] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate comments: %w", err)
+	}
+	return comments, nil
+}
+
+func (r *commentRepository) Update(ctx context.Context, comment *entity.Comment) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE comments
+		SET comment = $2, media_url = $3, status = $4, moderation_reason = $5, is_edited = TRUE
+		WHERE id = $1 AND is_deleted IS NOT TRUE
+		RETURNING is_edited, updated_at
+	`
+
+	err := r.db.QueryRow(
+		subCtx,
+		query,
+		comment.ID,
+		comment.Comment,
+		comment.MediaURL,
+		comment.Status,
+		comment.ModerationReason,
+	).Scan(&comment.IsEdited, &comment.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dto.ErrCommentNotFound
+		}
+		r.logger.Error("[CommentRepository.Update]", zap.Error(err))
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+	return nil
+}
+
+func (r *commentRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `UPDATE comments SET is_deleted = TRUE WHERE id = $1 AND is_deleted IS NOT TRUE`
+
+	tag, err := r.db.Exec(subCtx, query, id)
+	if err != nil {
+		r.logger.Error("[CommentRepository.SoftDelete]", zap.Error(err))
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return dto.ErrCommentNotFound
+	}
+	return nil
+}
+
+func (r *commentRepository) List(ctx context.Context, filter entity.CommentFilter) ([]*entity.Comment, int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	qb := NewQueryBuilder(`FROM comments c LEFT JOIN users u ON u.id = c.user_id`).
+		Where("c.page_url = $?", filter.PageURL).
+		Where(commentVisibleCondition)
+
+	if filter.ParentID != nil {
+		qb.Where("c.reply_to_id = $?", *filter.ParentID)
+	} else {
+		qb.Where("c.reply_to_id IS NULL")
+	}
+	if filter.UserID != nil {
+		qb.Where("c.user_id = $?", *filter.UserID)
+	}
+	if filter.Type != "" {
+		qb.Where("c.type = $?", filter.Type)
+	}
+	qb.Where(commentPublishedCondition("c", "$?"), filter.ViewerID)
+	qb.Where(commentNotBlockedCondition("c", "$?"), filter.ViewerID)
+
+	countQuery, countArgs := qb.Clone().WithoutPagination().ChangeBase(`SELECT COUNT(*) FROM comments c LEFT JOIN users u ON u.id = c.user_id`).Build()
+
+	var total int64
+	if err := r.db.QueryRow(subCtx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.logger.Error("[CommentRepository.List] count", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count comments: %w", err)
+	}
+
+	sortColumn, ok := commentSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = commentSortColumns["created_at"]
+	}
+
+	query, args := qb.ChangeBase(`SELECT `+commentSelectColumns+` FROM comments c LEFT JOIN users u ON u.id = c.user_id`).
+		OrderByField(sortColumn, filter.SortOrder).
+		WithLimit(filter.Limit).
+		WithOffset(filter.Offset).
+		Build()
+
+	rows, err := r.db.Query(subCtx, query, args...)
+	if err != nil {
+		r.logger.Error("[CommentRepository.List]", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]*entity.Comment, 0)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			r.logger.Error("[CommentRepository.List] scan", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate comments: %w", err)
+	}
+	return comments, total, nil
+}
+
+func (r *commentRepository) FindDescendants(ctx context.Context, rootIDs []uuid.UUID, maxDepth int, viewerID *uuid.UUID) ([]*entity.Comment, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if len(rootIDs) == 0 {
+		return []*entity.Comment{}, nil
+	}
+
+	query := `
+		WITH RECURSIVE thread AS (
+			SELECT id, 0 AS depth, id::text AS path
+			FROM comments
+			WHERE id = ANY($1)
+			UNION ALL
+			SELECT child.id, thread.depth + 1, thread.path || '/' || child.id::text
+			FROM comments child
+			JOIN thread ON child.reply_to_id = thread.id
+			WHERE thread.depth < $2 AND ` + commentPublishedCondition("child", "$3") + `
+				AND ` + commentNotBlockedCondition("child", "$3") + `
+		)
+		SELECT ` + commentSelectColumns + `, thread.depth, thread.path
+		FROM thread
+		JOIN comments c ON c.id = thread.id
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE thread.depth > 0 AND ` + commentVisibleCondition + `
+		ORDER BY c.created_at ASC
+	`
+
+	rows, err := r.db.Query(subCtx, query, rootIDs, maxDepth, viewerID)
+	if err != nil {
+		r.logger.Error("[CommentRepository.FindDescendants]", zap.Error(err))
+		return nil, fmt.Errorf("failed to load replies: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]*entity.Comment, 0)
+	for rows.Next() {
+		var depth int
+		var path string
+		comment, err := scanComment(rows, &depth, &path)
+		if err != nil {
+			r.logger.Error("[CommentRepository.FindDescendants] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan reply: %w", err)
+		}
+		comment.Depth = depth
+		comment.Path = path
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate replies: %w", err)
+	}
+	return comments, nil
+}
+
+func (r *commentRepository) GetDepth(ctx context.Context, id uuid.UUID) (int, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, reply_to_id, 0 AS depth
+			FROM comments
+			WHERE id = $1
+			UNION ALL
+			SELECT parent.id, parent.reply_to_id, ancestors.depth + 1
+			FROM comments parent
+			JOIN ancestors ON ancestors.reply_to_id = parent.id
+		)
+		SELECT COALESCE(MAX(depth), 0) FROM ancestors
+	`
+
+	var depth int
+	if err := r.db.QueryRow(subCtx, query, id).Scan(&depth); err != nil {
+		r.logger.Error("[CommentRepository.GetDepth]", zap.Error(err))
+		return 0, fmt.Errorf("failed to resolve comment depth: %w", err)
+	}
+	return depth, nil
+}
+
+func (r *commentRepository) Like(ctx context.Context, commentID uuid.UUID, userID uuid.UUID) (bool, int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO comment_likes (comment_id, user_id)
+			VALUES ($1, $2)
+			ON CONFLICT (comment_id, user_id) DO NOTHING
+			RETURNING id
+		)
+		SELECT EXISTS (SELECT 1 FROM inserted)
+	`
+
+	var inserted bool
+	if err := r.db.QueryRow(subCtx, query, commentID, userID).Scan(&inserted); err != nil {
+		r.logger.Error("[CommentRepository.Like]", zap.Error(err))
+		return false, 0, fmt.Errorf("failed to like comment: %w", err)
+	}
+
+	count, err := r.likesCount(subCtx, commentID)
+	if err != nil {
+		return false, 0, err
+	}
+	return inserted, count, nil
+}
+
+func (r *commentRepository) Unlike(ctx context.Context, commentID uuid.UUID, userID uuid.UUID) (bool, int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM comment_likes WHERE comment_id = $1 AND user_id = $2`
+
+	tag, err := r.db.Exec(subCtx, query, commentID, userID)
+	if err != nil {
+		r.logger.Error("[CommentRepository.Unlike]", zap.Error(err))
+		return false, 0, fmt.Errorf("failed to unlike comment: %w", err)
+	}
+
+	count, err := r.likesCount(subCtx, commentID)
+	if err != nil {
+		return false, 0, err
+	}
+	return tag.RowsAffected() > 0, count, nil
+}
+
+func (r *commentRepository) FindLikedCommentIDs(ctx context.Context, userID uuid.UUID, commentIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	liked := make(map[uuid.UUID]bool, len(commentIDs))
+	if len(commentIDs) == 0 {
+		return liked, nil
+	}
+
+	query := `SELECT comment_id FROM comment_likes WHERE user_id = $1 AND comment_id = ANY($2)`
+
+	rows, err := r.db.Query(subCtx, query, userID, commentIDs)
+	if err != nil {
+		r.logger.Error("[CommentRepository.FindLikedCommentIDs]", zap.Error(err))
+		return nil, fmt.Errorf("failed to load liked comments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan liked comment: %w", err)
+		}
+		liked[id] = true
+	}
+	return liked, rows.Err()
+}
+
+func (r *commentRepository) likesCount(ctx context.Context, commentID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.QueryRow(ctx, `SELECT likes_count FROM comments WHERE id = $1`, commentID).Scan(&count); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, dto.ErrCommentNotFound
+		}
+		r.logger.Error("[CommentRepository.likesCount]", zap.Error(err))
+		return 0, fmt.Errorf("failed to read likes count: %w", err)
+	}
+	return count, nil
+}
+
+func (r *commentRepository) ListForModeration(ctx context.Context, filter entity.CommentModerationFilter) ([]*entity.Comment, int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	qb := NewQueryBuilder(`FROM comments c LEFT JOIN users u ON u.id = c.user_id`).
+		Where("c.status = $?", filter.Status).
+		Where("c.is_deleted IS NOT TRUE")
+
+	if filter.PageURL != "" {
+		qb.Where("c.page_url = $?", filter.PageURL)
+	}
+	if filter.UserID != nil {
+		qb.Where("c.user_id = $?", *filter.UserID)
+	}
+
+	countQuery, countArgs := qb.Clone().WithoutPagination().ChangeBase(`SELECT COUNT(*) FROM comments c LEFT JOIN users u ON u.id = c.user_id`).Build()
+
+	var total int64
+	if err := r.db.QueryRow(subCtx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.logger.Error("[CommentRepository.ListForModeration] count", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count moderation queue: %w", err)
+	}
+
+	query, args := qb.ChangeBase(`SELECT `+commentSelectColumns+` FROM comments c LEFT JOIN users u ON u.id = c.user_id`).
+		OrderByField("c.created_at", filter.SortOrder).
+		WithLimit(filter.Limit).
+		WithOffset(filter.Offset).
+		Build()
+
+	rows, err := r.db.Query(subCtx, query, args...)
+	if err != nil {
+		r.logger.Error("[CommentRepository.ListForModeration]", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list moderation queue: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]*entity.Comment, 0)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			r.logger.Error("[CommentRepository.ListForModeration] scan", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate moderation queue: %w", err)
+	}
+	return comments, total, nil
+}
+
+func (r *commentRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM comments WHERE status = $1 AND is_deleted IS NOT TRUE`
+
+	var total int64
+	if err := r.db.QueryRow(subCtx, query, status).Scan(&total); err != nil {
+		r.logger.Error("[CommentRepository.CountByStatus]", zap.Error(err))
+		return 0, fmt.Errorf("failed to count comments by status: %w", err)
+	}
+	return total, nil
+}
+
+func (r *commentRepository) UpdateStatus(ctx context.Context, ids []uuid.UUID, status string, reason *string, moderatorID uuid.UUID) (int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		WITH target AS (
+			SELECT id, user_id, status AS previous_status
+			FROM comments
+			WHERE id = ANY($1) AND status <> $2 AND is_deleted IS NOT TRUE
+			FOR UPDATE
+		)
+		UPDATE comments c
+		SET status = $2, moderation_reason = $3, moderated_by = $4, moderated_at = NOW()
+		FROM target t
+		WHERE c.id = t.id
+		RETURNING t.user_id, t.previous_status
+	`
+
+	var updated int64
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(subCtx, query, ids, status, reason, moderatorID)
+		if err != nil {
+			r.logger.Error("[CommentRepository.UpdateStatus]", zap.Error(err))
+			return fmt.Errorf("failed to update comment status: %w", err)
+		}
+
+		type delta struct{ approved, rejected int }
+		deltas := make(map[uuid.UUID]*delta)
+		for rows.Next() {
+			var userID *uuid.UUID
+			var previous string
+			if err := rows.Scan(&userID, &previous); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan moderated comment: %w", err)
+			}
+			updated++
+			if userID == nil {
+				continue
+			}
+			if deltas[*userID] == nil {
+				deltas[*userID] = &delta{}
+			}
+			approved, rejected := reputationDelta(previous, status)
+			deltas[*userID].approved += approved
+			deltas[*userID].rejected += rejected
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to iterate moderated comments: %w", err)
+		}
+
+		for userID, d := range deltas {
+			if err := r.adjustReputation(subCtx, tx, userID, d.approved, d.rejected); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+func (r *commentRepository) GetReputation(ctx context.Context, userID uuid.UUID) (*entity.CommentAuthorReputation, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT user_id, score, approved_count, rejected_count, is_shadow_banned,
+			shadow_banned_by, shadow_banned_at, created_at, updated_at
+		FROM comment_author_reputations
+		WHERE user_id = $1
+	`
+
+	var rep entity.CommentAuthorReputation
+	err := r.db.QueryRow(subCtx, query, userID).Scan(
+		&rep.UserID,
+		&rep.Score,
+		&rep.ApprovedCount,
+		&rep.RejectedCount,
+		&rep.IsShadowBanned,
+		&rep.ShadowBannedBy,
+		&rep.ShadowBannedAt,
+		&rep.CreatedAt,
+		&rep.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &entity.CommentAuthorReputation{UserID: userID}, nil
+		}
+		r.logger.Error("[CommentRepository.GetReputation]", zap.Error(err))
+		return nil, fmt.Errorf("failed to load author reputation: %w", err)
+	}
+	return &rep, nil
+}
+
+func (r *commentRepository) SetShadowBan(ctx context.Context, userIDs []uuid.UUID, banned bool, moderatorID uuid.UUID) (int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		INSERT INTO comment_author_reputations (user_id, is_shadow_banned, shadow_banned_by, shadow_banned_at)
+		SELECT u.id, $2, CASE WHEN $2 THEN $3::uuid END, CASE WHEN $2 THEN NOW() END
+		FROM users u
+		WHERE u.id = ANY($1)
+		ON CONFLICT (user_id) DO UPDATE SET
+			is_shadow_banned = EXCLUDED.is_shadow_banned,
+			shadow_banned_by = EXCLUDED.shadow_banned_by,
+			shadow_banned_at = EXCLUDED.shadow_banned_at
+		WHERE comment_author_reputations.is_shadow_banned IS DISTINCT FROM EXCLUDED.is_shadow_banned
+	`
+
+	tag, err := r.db.Exec(subCtx, query, userIDs, banned, moderatorID)
+	if err != nil {
+		r.logger.Error("[CommentRepository.SetShadowBan]", zap.Error(err))
+		return 0, fmt.Errorf("failed to update shadow ban: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// VerifyGuest releases an unverified guest comment into the given moderation status.
+// It reports false when the comment was already verified, deleted or is not a guest comment.
+func (r *commentRepository) VerifyGuest(ctx context.Context, id uuid.UUID, status string, reason *string) (bool, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE comments
+		SET status = $2, moderation_reason = $3, guest_verified_at = NOW()
+		WHERE id = $1 AND user_id IS NULL AND status = 'unverified' AND is_deleted IS NOT TRUE
+	`
+
+	tag, err := r.db.Exec(subCtx, query, id, status, reason)
+	if err != nil {
+		r.logger.Error("[CommentRepository.VerifyGuest]", zap.Error(err))
+		return false, fmt.Errorf("failed to verify guest comment: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ReplaceMentions makes the stored mentions of a comment match userIDs.
+func (r *commentRepository) ReplaceMentions(ctx context.Context, commentID uuid.UUID, userIDs []uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if userIDs == nil {
+		userIDs = []uuid.UUID{}
+	}
+
+	return r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(subCtx, `DELETE FROM comment_mentions WHERE comment_id = $1 AND NOT (user_id = ANY($2))`, commentID, userIDs); err != nil {
+			r.logger.Error("[CommentRepository.ReplaceMentions] delete", zap.Error(err))
+			return fmt.Errorf("failed to clear comment mentions: %w", err)
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+
+		query := `
+			INSERT INTO comment_mentions (comment_id, user_id)
+			SELECT $1, UNNEST($2::uuid[])
+			ON CONFLICT (comment_id, user_id) DO NOTHING
+		`
+		if _, err := tx.Exec(subCtx, query, commentID, userIDs); err != nil {
+			r.logger.Error("[CommentRepository.ReplaceMentions] insert", zap.Error(err))
+			return fmt.Errorf("failed to save comment mentions: %w", err)
+		}
+		return nil
+	})
+}
+
+// FindMentions loads the mentioned users of each comment. Offsets are left for the caller.
+func (r *commentRepository) FindMentions(ctx context.Context, commentIDs []uuid.UUID) (map[uuid.UUID][]*entity.CommentMention, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	mentions := make(map[uuid.UUID][]*entity.CommentMention)
+	if len(commentIDs) == 0 {
+		return mentions, nil
+	}
+
+	query := `
+		SELECT m.comment_id, u.id, u.username, u.full_name, u.avatar_url
+		FROM comment_mentions m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.comment_id = ANY($1) AND u.deleted_at IS NULL AND u.username IS NOT NULL
+	`
+
+	rows, err := r.db.Query(subCtx, query, commentIDs)
+	if err != nil {
+		r.logger.Error("[CommentRepository.FindMentions]", zap.Error(err))
+		return nil, fmt.Errorf("failed to load comment mentions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commentID uuid.UUID
+		var mention entity.CommentMention
+		if err := rows.Scan(&commentID, &mention.UserID, &mention.Username, &mention.Name, &mention.AvatarURL); err != nil {
+			return nil, fmt.Errorf("failed to scan comment mention: %w", err)
+		}
+		mentions[commentID] = append(mentions[commentID], &mention)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate comment mentions: %w", err)
+	}
+	return mentions, nil
+}
+
+// RecordNotifications logs recipients (user ID to reason) for a comment and returns only
+// those that had not been notified about it before.
+func (r *commentRepository) RecordNotifications(ctx context.Context, commentID uuid.UUID, recipients map[uuid.UUID]string) ([]uuid.UUID, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if len(recipients) == 0 {
+		return nil, nil
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(recipients))
+	reasons := make([]string, 0, len(recipients))
+	for userID, reason := range recipients {
+		userIDs = append(userIDs, userID)
+		reasons = append(reasons, reason)
+	}
+
+	query := `
+		INSERT INTO comment_notifications (comment_id, user_id, reason)
+		SELECT $1, r.user_id, r.reason
+		FROM UNNEST($2::uuid[], $3::text[]) AS r(user_id, reason)
+		ON CONFLICT (comment_id, user_id) DO NOTHING
+		RETURNING user_id
+	`
+
+	rows, err := r.db.Query(subCtx, query, commentID, userIDs, reasons)
+	if err != nil {
+		r.logger.Error("[CommentRepository.RecordNotifications]", zap.Error(err))
+		return nil, fmt.Errorf("failed to record comment notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var inserted []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan notified user: %w", err)
+		}
+		inserted = append(inserted, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notified users: %w", err)
+	}
+	return inserted, nil
+}
+
+// GetNotificationSettings returns stored settings keyed by user; users without a row get defaults.
+func (r *commentRepository) GetNotificationSettings(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]*entity.CommentNotificationSettings, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	settings := make(map[uuid.UUID]*entity.CommentNotificationSettings, len(userIDs))
+	for _, userID := range userIDs {
+		settings[userID] = &entity.CommentNotificationSettings{UserID: userID}
+	}
+	if len(userIDs) == 0 {
+		return settings, nil
+	}
+
+	query := `
+		SELECT user_id, mute_mentions, mute_replies, email_enabled, muted_until, created_at, updated_at
+		FROM comment_notification_settings
+		WHERE user_id = ANY($1)
+	`
+
+	rows, err := r.db.Query(subCtx, query, userIDs)
+	if err != nil {
+		r.logger.Error("[CommentRepository.GetNotificationSettings]", zap.Error(err))
+		return nil, fmt.Errorf("failed to load notification settings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s entity.CommentNotificationSettings
+		if err := rows.Scan(&s.UserID, &s.MuteMentions, &s.MuteReplies, &s.EmailEnabled, &s.MutedUntil, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification settings: %w", err)
+		}
+		settings[s.UserID] = &s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notification settings: %w", err)
+	}
+	return settings, nil
+}
+
+func (r *commentRepository) UpsertNotificationSettings(ctx context.Context, settings *entity.CommentNotificationSettings) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO comment_notification_settings (user_id, mute_mentions, mute_replies, email_enabled, muted_until)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET
+			mute_mentions = EXCLUDED.mute_mentions,
+			mute_replies = EXCLUDED.mute_replies,
+			email_enabled = EXCLUDED.email_enabled,
+			muted_until = EXCLUDED.muted_until
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRow(subCtx, query,
+		settings.UserID,
+		settings.MuteMentions,
+		settings.MuteReplies,
+		settings.EmailEnabled,
+		settings.MutedUntil,
+	).Scan(&settings.CreatedAt, &settings.UpdatedAt)
+	if err != nil {
+		r.logger.Error("[CommentRepository.UpsertNotificationSettings]", zap.Error(err))
+		return fmt.Errorf("failed to save notification settings: %w", err)
+	}
+	return nil
+}
+
+func (r *commentRepository) Stats(ctx context.Context, filter entity.CommentStatsFilter) (*entity.CommentStats, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	scoped, args := commentStatsScope(`SELECT `+commentAuthorKey+` AS author_key, c.type, c.reply_to_id, c.likes_count, c.created_at FROM comments c`, filter).Build()
+
+	query := `
+		WITH scoped AS (` + scoped + `)
+		SELECT
+			(SELECT COUNT(*) FROM scoped),
+			(SELECT COUNT(*) FROM scoped WHERE reply_to_id IS NOT NULL),
+			(SELECT COALESCE(SUM(likes_count), 0) FROM scoped),
+			(SELECT COUNT(DISTINCT author_key) FROM scoped),
+			(SELECT COALESCE(jsonb_object_agg(type, total), '{}'::jsonb)
+				FROM (SELECT type, COUNT(*) AS total FROM scoped GROUP BY type) by_type),
+			(SELECT COALESCE(jsonb_object_agg(hour, total), '{}'::jsonb)
+				FROM (
+					SELECT EXTRACT(HOUR FROM created_at AT TIME ZONE 'UTC')::int AS hour, COUNT(*) AS total
+					FROM scoped GROUP BY 1
+				) by_hour)
+	`
+
+	stats := &entity.CommentStats{}
+	err := r.db.QueryRow(subCtx, query, args...).Scan(
+		&stats.TotalComments,
+		&stats.TotalReplies,
+		&stats.TotalLikes,
+		&stats.UniqueUsers,
+		&stats.CommentsByType,
+		&stats.CommentsByHour,
+	)
+	if err != nil {
+		r.logger.Error("[CommentRepository.Stats]", zap.Error(err))
+		return nil, fmt.Errorf("failed to compute comment stats: %w", err)
+	}
+	return stats, nil
+}
+
+func (r *commentRepository) Leaderboard(ctx context.Context, filter entity.CommentStatsFilter) ([]*entity.CommentLeaderboardEntry, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	scoped, args := commentStatsScope(`SELECT c.content_type, c.content_id, c.content_title, `+commentAuthorKey+` AS author_key, c.likes_count, c.created_at FROM comments c`, filter).
+		Where("c.content_id IS NOT NULL").
+		Build()
+
+	query := `
+		SELECT
+			content_type,
+			content_id,
+			(ARRAY_AGG(content_title ORDER BY created_at DESC) FILTER (WHERE content_title IS NOT NULL))[1],
+			COUNT(*) AS comments_count,
+			COUNT(DISTINCT author_key) AS participants_count,
+			COALESCE(SUM(likes_count), 0) AS likes_count,
+			MAX(created_at) AS last_comment_at
+		FROM (` + scoped + `) scoped
+		GROUP BY content_type, content_id
+		ORDER BY comments_count DESC, participants_count DESC, last_comment_at DESC
+		LIMIT ` + strconv.Itoa(filter.Limit)
+
+	rows, err := r.db.Query(subCtx, query, args...)
+	if err != nil {
+		r.logger.Error("[CommentRepository.Leaderboard]", zap.Error(err))
+		return nil, fmt.Errorf("failed to load comment leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*entity.CommentLeaderboardEntry, 0, filter.Limit)
+	for rows.Next() {
+		var entry entity.CommentLeaderboardEntry
+		if err := rows.Scan(
+			&entry.ContentType,
+			&entry.ContentID,
+			&entry.ContentTitle,
+			&entry.CommentsCount,
+			&entry.ParticipantsCount,
+			&entry.LikesCount,
+			&entry.LastCommentAt,
+		); err != nil {
+			r.logger.Error("[CommentRepository.Leaderboard] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan leaderboard entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate leaderboard: %w", err)
+	}
+	return entries, nil
+}
+
+// commentStatsScope restricts aggregates to published comments inside the filter's range.
+func commentStatsScope(base string, filter entity.CommentStatsFilter) *QueryBuilder {
+	qb := NewQueryBuilder(base).
+		Where("c.status = 'approved'").
+		Where("c.is_deleted IS NOT TRUE").
+		Where("c.created_at >= $?", filter.DateFrom).
+		Where("c.created_at < $?", filter.DateTo).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM comment_author_reputations rep
+			WHERE rep.user_id = c.user_id AND rep.is_shadow_banned
+		)`)
+
+	if filter.PageURL != "" {
+		qb.Where("c.page_url = $?", filter.PageURL)
+	}
+	if filter.ContentType != "" {
+		qb.Where("c.content_type = $?", filter.ContentType)
+	}
+	if filter.ContentID != "" {
+		qb.Where("c.content_id = $?", filter.ContentID)
+	}
+	return qb
+}
+
+func (r *commentRepository) adjustReputation(ctx context.Context, tx pgx.Tx, userID uuid.UUID, approved int, rejected int) error {
+	return adjustCommentReputation(ctx, tx, r.logger, userID, approved, rejected)
+}
+
+// adjustCommentReputation applies approval/rejection deltas; every rejection weighs five approvals.
+// It is shared with the report repository, which hides and restores approved comments.
+func adjustCommentReputation(ctx context.Context, tx pgx.Tx, logger *zap.Logger, userID uuid.UUID, approved int, rejected int) error {
+	if approved == 0 && rejected == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO comment_author_reputations (user_id, approved_count, rejected_count, score)
+		VALUES ($1, GREATEST($2, 0), GREATEST($3, 0), GREATEST($2, 0) - 5 * GREATEST($3, 0))
+		ON CONFLICT (user_id) DO UPDATE SET
+			approved_count = GREATEST(comment_author_reputations.approved_count + $2, 0),
+			rejected_count = GREATEST(comment_author_reputations.rejected_count + $3, 0),
+			score = GREATEST(comment_author_reputations.approved_count + $2, 0)
+				- 5 * GREATEST(comment_author_reputations.rejected_count + $3, 0)
+	`
+
+	if _, err := tx.Exec(ctx, query, userID, approved, rejected); err != nil {
+		logger.Error("[CommentRepository.adjustReputation]", zap.Error(err))
+		return fmt.Errorf("failed to update author reputation: %w", err)
+	}
+	return nil
+}
+
+// reputationDelta converts a status transition into approval and rejection count changes.
+func reputationDelta(previous string, next string) (int, int) {
+	var approved, rejected int
+	switch previous {
+	case entity.CommentStatusApproved:
+		approved--
+	case entity.CommentStatusRejected:
+		rejected--
+	}
+	switch next {
+	case entity.CommentStatusApproved:
+		approved++
+	case entity.CommentStatusRejected:
+		rejected++
+	}
+	return approved, rejected
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type PlaybackProgressRepository interface {
+	BaseRepository
+	UpsertBatch(ctx context.Context, items []*entity.PlaybackProgress) error
+	Find(ctx context.Context, userID uuid.UUID, contentType string, slug string) (*entity.PlaybackProgress, error)
+	ListInProgress(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.PlaybackProgress, error)
+}
+
+type playbackProgressRepository struct {
+	*baseRepository
+}
+
+func NewPlaybackProgressRepository(db *pgxpool.Pool, logger *zap.Logger) PlaybackProgressRepository {
+	return &playbackProgressRepository{
+		baseRepository: NewBaseRepository(
+			db,
+			logger,
+		).(*baseRepository),
+	}
+}
+
+const playbackProgressColumns = `
+	id, user_id, type, slug, name, episode_number, episode_url, position_seconds, duration_seconds,
+	completed, next_episode_number, next_episode_url, watched_at, created_at, updated_at
+`
+
+// UpsertBatch stores the progress items in one transaction. An item no newer than the stored
+// row is ignored, so a late or repeated flush never winds progress back, unless it brings the
+// next-episode pointer the row is missing. The pointer is kept when the item has none and is
+// still at the same episode.
+func (r *playbackProgressRepository) UpsertBatch(ctx context.Context, items []*entity.PlaybackProgress) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO playback_progress (
+			user_id, type, slug, name, episode_number, episode_url, position_seconds,
+			duration_seconds, completed, next_episode_number, next_episode_url, watched_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (user_id, type, slug) DO UPDATE SET
+			name = COALESCE(EXCLUDED.name, playback_progress.name),
+			episode_number = EXCLUDED.episode_number,
+			episode_url = EXCLUDED.episode_url,
+			position_seconds = EXCLUDED.position_seconds,
+			duration_seconds = EXCLUDED.duration_seconds,
+			completed = EXCLUDED.completed,
+			next_episode_number = CASE WHEN EXCLUDED.next_episode_url IS NOT NULL OR EXCLUDED.next_episode_number IS NOT NULL
+					OR playback_progress.episode_number IS DISTINCT FROM EXCLUDED.episode_number
+					OR playback_progress.episode_url IS DISTINCT FROM EXCLUDED.episode_url
+				THEN EXCLUDED.next_episode_number ELSE playback_progress.next_episode_number END,
+			next_episode_url = CASE WHEN EXCLUDED.next_episode_url IS NOT NULL OR EXCLUDED.next_episode_number IS NOT NULL
+					OR playback_progress.episode_number IS DISTINCT FROM EXCLUDED.episode_number
+					OR playback_progress.episode_url IS DISTINCT FROM EXCLUDED.episode_url
+				THEN EXCLUDED.next_episode_url ELSE playback_progress.next_episode_url END,
+			watched_at = EXCLUDED.watched_at
+		WHERE playback_progress.watched_at < EXCLUDED.watched_at
+			OR (playback_progress.watched_at = EXCLUDED.watched_at
+				AND playback_progress.next_episode_url IS NULL AND playback_progress.next_episode_number IS NULL
+				AND (EXCLUDED.next_episode_url IS NOT NULL OR EXCLUDED.next_episode_number IS NOT NULL))
+	`
+
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for _, item := range items {
+			batch.Queue(
+				query,
+				item.UserID,
+				string(item.Type),
+				item.Slug,
+				item.Name,
+				item.EpisodeNumber,
+				item.EpisodeURL,
+				item.PositionSeconds,
+				item.DurationSeconds,
+				item.Completed,
+				item.NextEpisodeNumber,
+				item.NextEpisodeURL,
+				item.WatchedAt,
+			)
+		}
+
+		results := tx.SendBatch(subCtx, batch)
+		for range items {
+			if _, err := results.Exec(); err != nil {
+				results.Close()
+				return err
+			}
+		}
+		return results.Close()
+	})
+	if err != nil {
+		r.logger.Error("[PlaybackProgressRepository.UpsertBatch]", zap.Error(err))
+		return fmt.Errorf("failed to save playback progress: %w", err)
+	}
+	return nil
+}
+
+func (r *playbackProgressRepository) Find(ctx context.Context, userID uuid.UUID, contentType string, slug string) (*entity.PlaybackProgress, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + playbackProgressColumns + ` FROM playback_progress WHERE user_id = $1 AND type = $2 AND slug = $3`
+
+	progress, err := scanPlaybackProgress(r.db.QueryRow(subCtx, query, userID, contentType, slug))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dto.ErrPlaybackProgressNotFound
+		}
+		r.logger.Error("[PlaybackProgressRepository.Find]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find playback progress: %w", err)
+	}
+	return progress, nil
+}
+
+// ListInProgress returns the user's unfinished titles, and finished episodes with a next one
+// to go on to, most recently watched first.
+func (r *playbackProgressRepository) ListInProgress(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.PlaybackProgress, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + playbackProgressColumns + `
+		FROM playback_progress
+		WHERE user_id = $1
+			AND ((NOT completed AND position_seconds > 0)
+				OR (completed AND (next_episode_url IS NOT NULL OR next_episode_number IS NOT NULL)))
+		ORDER BY watched_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(subCtx, query, userID, limit)
+	if err != nil {
+		r.logger.Error("[PlaybackProgressRepository.ListInProgress]", zap.Error(err))
+		return nil, fmt.Errorf("failed to list playback progress: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*entity.PlaybackProgress, 0)
+	for rows.Next() {
+		progress, err := scanPlaybackProgress(rows)
+		if err != nil {
+			r.logger.Error("[PlaybackProgressRepository.ListInProgress] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan playback progress: %w", err)
+		}
+		items = append(items, progress)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate playback progress: %w", err)
+	}
+	return items, nil
+}
+
+func scanPlaybackProgress(row pgx.Row) (*entity.PlaybackProgress, error) {
+	var p entity.PlaybackProgress
+	err := row.Scan(
+		&p.ID, &p.UserID, &p.Type, &p.Slug, &p.Name, &p.EpisodeNumber, &p.EpisodeURL,
+		&p.PositionSeconds, &p.DurationSeconds, &p.Completed, &p.NextEpisodeNumber,
+		&p.NextEpisodeURL, &p.WatchedAt, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type ReportRepository interface {
+	BaseRepository
+	Create(ctx context.Context, report *entity.Report) error
+	CountOpenReporters(ctx context.Context, targetType string, targetID uuid.UUID) (int64, error)
+	FindByTarget(ctx context.Context, targetType string, targetID uuid.UUID) ([]*entity.Report, error)
+	ListCases(ctx context.Context, filter entity.ReportFilter) ([]*entity.ReportCase, int64, error)
+	FindCase(ctx context.Context, targetType string, targetID uuid.UUID) (*entity.ReportCase, error)
+	HideTarget(ctx context.Context, targetType string, targetID uuid.UUID, reason string) (bool, error)
+	RestoreTarget(ctx context.Context, targetType string, targetID uuid.UUID, adminID uuid.UUID) (bool, error)
+	Resolve(ctx context.Context, targetType string, targetID uuid.UUID, status string, action string, note *string, adminID uuid.UUID) (int64, error)
+}
+
+type reportRepository struct {
+	*baseRepository
+}
+
+func NewReportRepository(db *pgxpool.Pool, logger *zap.Logger) ReportRepository {
+	return &reportRepository{
+		baseRepository: NewBaseRepository(
+			db,
+			logger,
+		).(*baseRepository),
+	}
+}
+
+// reportHiddenCondition is true for comments the report threshold moved back to pending.
+const reportHiddenCondition = `cm.status = 'pending' AND cm.moderation_reason LIKE '` + entity.ReportHiddenReasonPrefix + `%'`
+
+var reportCaseSortColumns = map[string]string{
+	"reports_count":    "k.reports_count",
+	"last_reported_at": "k.last_reported_at",
+}
+
+func (r *reportRepository) Create(ctx context.Context, report *entity.Report) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO reports (target_type, target_id, target_author_id, reporter_id, reason, details)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (target_type, target_id, reporter_id) DO NOTHING
+		RETURNING id, status, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(
+		subCtx,
+		query,
+		report.TargetType,
+		report.TargetID,
+		report.TargetAuthorID,
+		report.ReporterID,
+		report.Reason,
+		report.Details,
+	).Scan(&report.ID, &report.Status, &report.CreatedAt, &report.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dto.ErrReportDuplicate
+		}
+		r.logger.Error("[ReportRepository.Create]", zap.Error(err))
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	return nil
+}
+
+func (r *reportRepository) CountOpenReporters(ctx context.Context, targetType string, targetID uuid.UUID) (int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT COUNT(DISTINCT reporter_id)
+		FROM reports
+		WHERE target_type = $1 AND target_id = $2 AND status = 'open'
+	`
+
+	var count int64
+	if err := r.db.QueryRow(subCtx, query, targetType, targetID).Scan(&count); err != nil {
+		r.logger.Error("[ReportRepository.CountOpenReporters]", zap.Error(err))
+		return 0, fmt.Errorf("failed to count reporters: %w", err)
+	}
+	return count, nil
+}
+
+func (r *reportRepository) FindByTarget(ctx context.Context, targetType string, targetID uuid.UUID) ([]*entity.Report, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT rp.id, rp.target_type, rp.target_id, rp.target_author_id, rp.reporter_id, rp.reason, rp.details,
+			rp.status, rp.action, rp.resolution_note, rp.resolved_by, rp.resolved_at, rp.created_at, rp.updated_at,
+			COALESCE(u.full_name, '')
+		FROM reports rp
+		LEFT JOIN users u ON u.id = rp.reporter_id
+		WHERE rp.target_type = $1 AND rp.target_id = $2
+		ORDER BY rp.created_at ASC
+	`
+
+	rows, err := r.db.Query(subCtx, query, targetType, targetID)
+	if err != nil {
+		r.logger.Error("[ReportRepository.FindByTarget]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := make([]*entity.Report, 0)
+	for rows.Next() {
+		var rp entity.Report
+		if err := rows.Scan(
+			&rp.ID, &rp.TargetType, &rp.TargetID, &rp.TargetAuthorID, &rp.ReporterID, &rp.Reason, &rp.Details,
+			&rp.Status, &rp.Action, &rp.ResolutionNote, &rp.ResolvedBy, &rp.ResolvedAt, &rp.CreatedAt, &rp.UpdatedAt,
+			&rp.ReporterName,
+		); err != nil {
+			r.logger.Error("[ReportRepository.FindByTarget] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, &rp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate reports: %w", err)
+	}
+	return reports, nil
+}
+
+// ListCases groups matching reports by their target, joined with the reported content.
+func (r *reportRepository) ListCases(ctx context.Context, filter entity.ReportFilter) ([]*entity.ReportCase, int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	qb := NewQueryBuilder(`SELECT rp.target_type, rp.target_id, rp.reporter_id, rp.reason, rp.created_at FROM reports rp`)
+	if filter.Status != "" {
+		qb.Where("rp.status = $?", filter.Status)
+	}
+	if filter.TargetType != "" {
+		qb.Where("rp.target_type = $?", filter.TargetType)
+	}
+	if filter.Reason != "" {
+		qb.Where("rp.reason = $?", filter.Reason)
+	}
+	matched, args := qb.Build()
+
+	countQuery := `
+		WITH matched AS (` + matched + `)
+		SELECT COUNT(*) FROM (SELECT 1 FROM matched GROUP BY target_type, target_id) t
+	`
+
+	var total int64
+	if err := r.db.QueryRow(subCtx, countQuery, args...).Scan(&total); err != nil {
+		r.logger.Error("[ReportRepository.ListCases] count", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count report cases: %w", err)
+	}
+
+	sortColumn, ok := reportCaseSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = reportCaseSortColumns["reports_count"]
+	}
+	direction := "DESC"
+	if filter.SortOrder == "asc" {
+		direction = "ASC"
+	}
+
+	query := `
+		WITH matched AS (` + matched + `),
+		cases AS (
+			SELECT target_type, target_id, COUNT(DISTINCT reporter_id) AS reports_count,
+				ARRAY_AGG(reason) AS reasons, MIN(created_at) AS first_reported_at, MAX(created_at) AS last_reported_at
+			FROM matched
+			GROUP BY target_type, target_id
+		)
+		SELECT ` + reportCaseSelectColumns + `
+		FROM cases k` + reportCaseJoins + `
+		ORDER BY ` + sortColumn + ` ` + direction + `, k.target_id
+		LIMIT ` + strconv.Itoa(filter.Limit) + ` OFFSET ` + strconv.Itoa(filter.Offset)
+
+	rows, err := r.db.Query(subCtx, query, args...)
+	if err != nil {
+		r.logger.Error("[ReportRepository.ListCases]", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list report cases: %w", err)
+	}
+	defer rows.Close()
+
+	cases := make([]*entity.ReportCase, 0)
+	for rows.Next() {
+		reportCase, err := scanReportCase(rows)
+		if err != nil {
+			r.logger.Error("[ReportRepository.ListCases] scan", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to scan report case: %w", err)
+		}
+		cases = append(cases, reportCase)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate report cases: %w", err)
+	}
+	return cases, total, nil
+}
+
+func (r *reportRepository) FindCase(ctx context.Context, targetType string, targetID uuid.UUID) (*entity.ReportCase, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		WITH cases AS (
+			SELECT target_type, target_id, COUNT(DISTINCT reporter_id) AS reports_count,
+				ARRAY_AGG(reason) AS reasons, MIN(created_at) AS first_reported_at, MAX(created_at) AS last_reported_at
+			FROM reports
+			WHERE target_type = $1 AND target_id = $2
+			GROUP BY target_type, target_id
+		)
+		SELECT ` + reportCaseSelectColumns + `
+		FROM cases k` + reportCaseJoins
+
+	reportCase, err := scanReportCase(r.db.QueryRow(subCtx, query, targetType, targetID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dto.ErrReportNotFound
+		}
+		r.logger.Error("[ReportRepository.FindCase]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find report case: %w", err)
+	}
+	return reportCase, nil
+}
+
+const reportCaseSelectColumns = `
+	k.target_type, k.target_id, COALESCE(cm.user_id, ch.sender_id), u.full_name, COALESCE(cm.comment, ch.message),
+	COALESCE(` + reportHiddenCondition + `, ch.is_hidden, FALSE), COALESCE(cm.is_deleted, ch.is_deleted, TRUE),
+	k.reports_count, k.reasons, k.first_reported_at, k.last_reported_at`
+
+const reportCaseJoins = `
+	LEFT JOIN comments cm ON k.target_type = 'comment' AND cm.id = k.target_id
+	LEFT JOIN chat_messages ch ON k.target_type = 'chat_message' AND ch.id = k.target_id
+	LEFT JOIN users u ON u.id = COALESCE(cm.user_id, ch.sender_id)`
+
+func scanReportCase(row pgx.Row) (*entity.ReportCase, error) {
+	var c entity.ReportCase
+	var reasons []string
+	if err := row.Scan(
+		&c.TargetType, &c.TargetID, &c.TargetAuthorID, &c.AuthorName, &c.Excerpt,
+		&c.IsHidden, &c.IsDeleted,
+		&c.ReportsCount, &reasons, &c.FirstReportedAt, &c.LastReportedAt,
+	); err != nil {
+		return nil, err
+	}
+	c.Reasons = make(map[string]int64, len(reasons))
+	for _, reason := range reasons {
+		c.Reasons[reason]++
+	}
+	return &c, nil
+}
+
+// HideTarget pulls reported content from public view. Approved comments go back to pending,
+// where the moderation queue picks them up; chat messages are flagged hidden.
+func (r *reportRepository) HideTarget(ctx context.Context, targetType string, targetID uuid.UUID, reason string) (bool, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if targetType == entity.ReportTargetChatMessage {
+		query := `UPDATE chat_messages SET is_hidden = TRUE WHERE id = $1 AND is_hidden IS NOT TRUE AND is_deleted IS NOT TRUE`
+		tag, err := r.db.Exec(subCtx, query, targetID)
+		if err != nil {
+			r.logger.Error("[ReportRepository.HideTarget]", zap.Error(err))
+			return false, fmt.Errorf("failed to hide chat message: %w", err)
+		}
+		return tag.RowsAffected() > 0, nil
+	}
+
+	query := `
+		UPDATE comments
+		SET status = 'pending', moderation_reason = $2, moderated_by = NULL, moderated_at = NOW()
+		WHERE id = $1 AND status = 'approved' AND is_deleted IS NOT TRUE
+		RETURNING user_id
+	`
+
+	hidden := false
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		var userID *uuid.UUID
+		if err := tx.QueryRow(subCtx, query, targetID, entity.ReportHiddenReasonPrefix+reason).Scan(&userID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			r.logger.Error("[ReportRepository.HideTarget]", zap.Error(err))
+			return fmt.Errorf("failed to hide comment: %w", err)
+		}
+		hidden = true
+		if userID == nil {
+			return nil
+		}
+		return adjustCommentReputation(subCtx, tx, r.logger, *userID, -1, 0)
+	})
+	if err != nil {
+		return false, err
+	}
+	return hidden, nil
+}
+
+// RestoreTarget reverses HideTarget. Comments held for any other reason stay in the queue.
+func (r *reportRepository) RestoreTarget(ctx context.Context, targetType string, targetID uuid.UUID, adminID uuid.UUID) (bool, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if targetType == entity.ReportTargetChatMessage {
+		query := `UPDATE chat_messages SET is_hidden = FALSE WHERE id = $1 AND is_hidden IS TRUE AND is_deleted IS NOT TRUE`
+		tag, err := r.db.Exec(subCtx, query, targetID)
+		if err != nil {
+			r.logger.Error("[ReportRepository.RestoreTarget]", zap.Error(err))
+			return false, fmt.Errorf("failed to restore chat message: %w", err)
+		}
+		return tag.RowsAffected() > 0, nil
+	}
+
+	query := `
+		UPDATE comments cm
+		SET status = 'approved', moderation_reason = NULL, moderated_by = $2, moderated_at = NOW()
+		WHERE cm.id = $1 AND ` + reportHiddenCondition + ` AND cm.is_deleted IS NOT TRUE
+		RETURNING cm.user_id
+	`
+
+	restored := false
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		var userID *uuid.UUID
+		if err := tx.QueryRow(subCtx, query, targetID, adminID).Scan(&userID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			r.logger.Error("[ReportRepository.RestoreTarget]", zap.Error(err))
+			return fmt.Errorf("failed to restore comment: %w", err)
+		}
+		restored = true
+		if userID == nil {
+			return nil
+		}
+		return adjustCommentReputation(subCtx, tx, r.logger, *userID, 1, 0)
+	})
+	if err != nil {
+		return false, err
+	}
+	return restored, nil
+}
+
+func (r *reportRepository) Resolve(ctx context.Context, targetType string, targetID uuid.UUID, status string, action string, note *string, adminID uuid.UUID) (int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE reports
+		SET status = $3, action = $4, resolution_note = $5, resolved_by = $6, resolved_at = NOW()
+		WHERE target_type = $1 AND target_id = $2 AND status = 'open'
+	`
+
+	tag, err := r.db.Exec(subCtx, query, targetType, targetID, status, action, note, adminID)
+	if err != nil {
+		r.logger.Error("[ReportRepository.Resolve]", zap.Error(err))
+		return 0, fmt.Errorf("failed to resolve reports: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
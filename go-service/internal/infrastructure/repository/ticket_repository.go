@@ -0,0 +1,457 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type TicketRepository interface {
+	BaseRepository
+	Create(ctx context.Context, ticket *entity.Ticket) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.Ticket, error)
+	FindByReplyToken(ctx context.Context, token string) (*entity.Ticket, error)
+	List(ctx context.Context, filter entity.TicketFilter) ([]*entity.Ticket, int64, error)
+	CreateReply(ctx context.Context, reply *entity.TicketReply, reopen bool) error
+	ListReplies(ctx context.Context, ticketID uuid.UUID) ([]*entity.TicketReply, error)
+	ListAttachments(ctx context.Context, ticketID uuid.UUID) ([]*entity.TicketAttachment, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, from entity.TicketStatus, to entity.TicketStatus) error
+	Assign(ctx context.Context, id uuid.UUID, assigneeID *uuid.UUID) error
+	UpdatePriority(ctx context.Context, id uuid.UUID, priority string) error
+	UpdateCategory(ctx context.Context, id uuid.UUID, category string) error
+}
+
+type ticketRepository struct {
+	*baseRepository
+}
+
+func NewTicketRepository(db *pgxpool.Pool, logger *zap.Logger) TicketRepository {
+	return &ticketRepository{
+		baseRepository: NewBaseRepository(
+			db,
+			logger,
+		).(*baseRepository),
+	}
+}
+
+const ticketSelectColumns = `
+	t.id, t.number, t.user_id, t.source, t.contact_name, t.contact_email, t.reply_token,
+	t.title, t.description, t.category, t.status, t.priority, t.assignee_id,
+	t.last_reply_at, t.first_response_at, t.resolved_at, t.closed_at, t.created_at, t.updated_at,
+	t.created_at + make_interval(mins => sla.first_response_minutes),
+	t.created_at + make_interval(mins => sla.resolution_minutes),
+	COALESCE(u.full_name, t.contact_name, ''), a.full_name`
+
+const ticketSelectFrom = `
+	FROM tickets t
+	LEFT JOIN users u ON u.id = t.user_id
+	LEFT JOIN users a ON a.id = t.assignee_id
+	LEFT` + ticketSLAPolicyJoin
+
+var ticketSortColumns = map[string]string{
+	"created_at": "t.created_at",
+	"updated_at": "t.updated_at",
+	"priority":   "CASE t.priority WHEN 'urgent' THEN 4 WHEN 'high' THEN 3 WHEN 'normal' THEN 2 ELSE 1 END",
+}
+
+func scanTicket(row pgx.Row) (*entity.Ticket, error) {
+	var t entity.Ticket
+	if err := row.Scan(
+		&t.ID, &t.Number, &t.UserID, &t.Source, &t.ContactName, &t.ContactEmail, &t.ReplyToken,
+		&t.Title, &t.Description, &t.Category, &t.Status, &t.Priority, &t.AssigneeID,
+		&t.LastReplyAt, &t.FirstResponseAt, &t.ResolvedAt, &t.ClosedAt, &t.CreatedAt, &t.UpdatedAt,
+		&t.FirstResponseDueAt, &t.ResolutionDueAt,
+		&t.UserName, &t.AssigneeName,
+	); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Create stores the ticket and the attachments of its opening message in one transaction.
+func (r *ticketRepository) Create(ctx context.Context, ticket *entity.Ticket) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO tickets (number, user_id, source, contact_name, contact_email, reply_token, title, description, category, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, status, created_at, updated_at
+	`
+
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(
+			subCtx,
+			query,
+			ticket.Number,
+			ticket.UserID,
+			ticket.Source,
+			ticket.ContactName,
+			ticket.ContactEmail,
+			ticket.ReplyToken,
+			ticket.Title,
+			ticket.Description,
+			ticket.Category,
+			ticket.Priority,
+		).Scan(&ticket.ID, &ticket.Status, &ticket.CreatedAt, &ticket.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return insertTicketAttachments(subCtx, tx, ticket.ID, nil, ticket.Attachments)
+	})
+	if err != nil {
+		r.logger.Error("[TicketRepository.Create]", zap.Error(err))
+		return fmt.Errorf("failed to create ticket: %w", err)
+	}
+	return nil
+}
+
+func (r *ticketRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Ticket, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + ticketSelectColumns + ticketSelectFrom + ` WHERE t.id = $1`
+
+	ticket, err := scanTicket(r.db.QueryRow(subCtx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dto.ErrTicketNotFound
+		}
+		r.logger.Error("[TicketRepository.FindByID]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find ticket: %w", err)
+	}
+	return ticket, nil
+}
+
+func (r *ticketRepository) FindByReplyToken(ctx context.Context, token string) (*entity.Ticket, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + ticketSelectColumns + ticketSelectFrom + ` WHERE t.reply_token = $1`
+
+	ticket, err := scanTicket(r.db.QueryRow(subCtx, query, token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dto.ErrTicketNotFound
+		}
+		r.logger.Error("[TicketRepository.FindByReplyToken]", zap.Error(err))
+		return nil, fmt.Errorf("failed to find ticket: %w", err)
+	}
+	return ticket, nil
+}
+
+func (r *ticketRepository) List(ctx context.Context, filter entity.TicketFilter) ([]*entity.Ticket, int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	qb := NewQueryBuilder(`SELECT COUNT(*) FROM tickets t`)
+	if filter.UserID != nil {
+		qb.Where("t.user_id = $?", *filter.UserID)
+	}
+	if filter.Status != "" {
+		qb.Where("t.status = $?", filter.Status)
+	}
+	if filter.Source != "" {
+		qb.Where("t.source = $?", filter.Source)
+	}
+	if filter.Category != "" {
+		qb.Where("t.category = $?", filter.Category)
+	}
+	if filter.Priority != "" {
+		qb.Where("t.priority = $?", filter.Priority)
+	}
+	if filter.AssigneeID != "" {
+		qb.Where("t.assignee_id = $?", filter.AssigneeID)
+	} else if filter.Unassigned {
+		qb.Where("t.assignee_id IS NULL")
+	}
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		qb.Where("(t.number ILIKE $? OR t.title ILIKE $? OR t.contact_email ILIKE $?)", pattern, pattern, pattern)
+	}
+
+	countQuery, countArgs := qb.Clone().Build()
+
+	var total int64
+	if err := r.db.QueryRow(subCtx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.logger.Error("[TicketRepository.List] count", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count tickets: %w", err)
+	}
+
+	sortColumn, ok := ticketSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = ticketSortColumns["updated_at"]
+	}
+	sortOrder := "DESC"
+	if filter.SortOrder == "asc" {
+		sortOrder = "ASC"
+	}
+
+	query, args := qb.ChangeBase(`SELECT ` + ticketSelectColumns + ticketSelectFrom).Build()
+	query += ` ORDER BY ` + sortColumn + ` ` + sortOrder + `, t.created_at DESC
+		LIMIT ` + strconv.Itoa(filter.Limit) + ` OFFSET ` + strconv.Itoa(filter.Offset)
+
+	rows, err := r.db.Query(subCtx, query, args...)
+	if err != nil {
+		r.logger.Error("[TicketRepository.List]", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list tickets: %w", err)
+	}
+	defer rows.Close()
+
+	tickets := make([]*entity.Ticket, 0, filter.Limit)
+	for rows.Next() {
+		ticket, err := scanTicket(rows)
+		if err != nil {
+			r.logger.Error("[TicketRepository.List] scan", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to scan ticket: %w", err)
+		}
+		tickets = append(tickets, ticket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate tickets: %w", err)
+	}
+	return tickets, total, nil
+}
+
+// CreateReply stores a reply with its attachments and bumps the ticket's last_reply_at. With
+// reopen set, a resolved ticket goes back to open in the same transaction. The first staff reply
+// stamps the ticket's first response time. A reply whose email Message-ID was already threaded
+// is reported as ErrTicketReplyDuplicate.
+func (r *ticketRepository) CreateReply(ctx context.Context, reply *entity.TicketReply, reopen bool) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	insertQuery := `
+		INSERT INTO ticket_replies (ticket_id, author_id, body, is_staff, via, email_message_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (email_message_id) WHERE email_message_id IS NOT NULL DO NOTHING
+		RETURNING id, via, created_at
+	`
+	updateQuery := `
+		UPDATE tickets SET
+			last_reply_at = $2,
+			first_response_at = CASE WHEN $4 THEN COALESCE(first_response_at, $2) ELSE first_response_at END,
+			status = CASE WHEN $3 AND status = 'resolved' THEN 'open' ELSE status END,
+			resolved_at = CASE WHEN $3 AND status = 'resolved' THEN NULL ELSE resolved_at END
+		WHERE id = $1 AND status <> 'closed'
+	`
+
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		if reply.Via == "" {
+			reply.Via = entity.TicketReplyViaApp
+		}
+		err := tx.QueryRow(subCtx, insertQuery, reply.TicketID, reply.AuthorID, reply.Body, reply.IsStaff, reply.Via, reply.MessageID).
+			Scan(&reply.ID, &reply.Via, &reply.CreatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dto.ErrTicketReplyDuplicate
+		}
+		if err != nil {
+			return err
+		}
+		tag, err := tx.Exec(subCtx, updateQuery, reply.TicketID, reply.CreatedAt, reopen, reply.IsStaff)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return dto.ErrTicketClosed
+		}
+		return insertTicketAttachments(subCtx, tx, reply.TicketID, &reply.ID, reply.Attachments)
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrTicketClosed) || errors.Is(err, dto.ErrTicketReplyDuplicate) {
+			return err
+		}
+		r.logger.Error("[TicketRepository.CreateReply]", zap.Error(err))
+		return fmt.Errorf("failed to create ticket reply: %w", err)
+	}
+	return nil
+}
+
+func (r *ticketRepository) ListReplies(ctx context.Context, ticketID uuid.UUID) ([]*entity.TicketReply, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT tr.id, tr.ticket_id, tr.author_id, tr.body, tr.is_staff, tr.via, tr.created_at,
+			COALESCE(u.full_name, CASE WHEN tr.author_id IS NULL AND NOT tr.is_staff THEN t.contact_name END, '')
+		FROM ticket_replies tr
+		JOIN tickets t ON t.id = tr.ticket_id
+		LEFT JOIN users u ON u.id = tr.author_id
+		WHERE tr.ticket_id = $1
+		ORDER BY tr.created_at ASC, tr.id
+	`
+
+	rows, err := r.db.Query(subCtx, query, ticketID)
+	if err != nil {
+		r.logger.Error("[TicketRepository.ListReplies]", zap.Error(err))
+		return nil, fmt.Errorf("failed to list ticket replies: %w", err)
+	}
+	defer rows.Close()
+
+	replies := make([]*entity.TicketReply, 0)
+	for rows.Next() {
+		var tr entity.TicketReply
+		if err := rows.Scan(&tr.ID, &tr.TicketID, &tr.AuthorID, &tr.Body, &tr.IsStaff, &tr.Via, &tr.CreatedAt, &tr.AuthorName); err != nil {
+			r.logger.Error("[TicketRepository.ListReplies] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan ticket reply: %w", err)
+		}
+		replies = append(replies, &tr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ticket replies: %w", err)
+	}
+	return replies, nil
+}
+
+func (r *ticketRepository) ListAttachments(ctx context.Context, ticketID uuid.UUID) ([]*entity.TicketAttachment, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, ticket_id, reply_id, uploader_id, object_key, url, name, size, mime_type, created_at
+		FROM ticket_attachments
+		WHERE ticket_id = $1
+		ORDER BY created_at ASC, id
+	`
+
+	rows, err := r.db.Query(subCtx, query, ticketID)
+	if err != nil {
+		r.logger.Error("[TicketRepository.ListAttachments]", zap.Error(err))
+		return nil, fmt.Errorf("failed to list ticket attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := make([]*entity.TicketAttachment, 0)
+	for rows.Next() {
+		var ta entity.TicketAttachment
+		if err := rows.Scan(
+			&ta.ID, &ta.TicketID, &ta.ReplyID, &ta.UploaderID, &ta.ObjectKey, &ta.URL, &ta.Name, &ta.Size, &ta.MimeType, &ta.CreatedAt,
+		); err != nil {
+			r.logger.Error("[TicketRepository.ListAttachments] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan ticket attachment: %w", err)
+		}
+		attachments = append(attachments, &ta)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ticket attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// UpdateStatus moves the ticket from one status to another. It only applies while the ticket
+// is still in the expected status, so concurrent changes cannot skip a workflow step.
+func (r *ticketRepository) UpdateStatus(ctx context.Context, id uuid.UUID, from entity.TicketStatus, to entity.TicketStatus) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE tickets SET
+			status = $3,
+			resolved_at = CASE WHEN $3 = 'resolved' THEN NOW() WHEN $3 = 'open' THEN NULL ELSE resolved_at END,
+			closed_at = CASE WHEN $3 = 'closed' THEN NOW() ELSE closed_at END
+		WHERE id = $1 AND status = $2
+	`
+
+	tag, err := r.db.Exec(subCtx, query, id, from, to)
+	if err != nil {
+		r.logger.Error("[TicketRepository.UpdateStatus]", zap.Error(err))
+		return fmt.Errorf("failed to update ticket status: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.FindByID(subCtx, id); err != nil {
+			return err
+		}
+		return dto.ErrTicketTransition
+	}
+	return nil
+}
+
+func (r *ticketRepository) Assign(ctx context.Context, id uuid.UUID, assigneeID *uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `UPDATE tickets SET assignee_id = $2 WHERE id = $1`
+
+	tag, err := r.db.Exec(subCtx, query, id, assigneeID)
+	if err != nil {
+		r.logger.Error("[TicketRepository.Assign]", zap.Error(err))
+		return fmt.Errorf("failed to assign ticket: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return dto.ErrTicketNotFound
+	}
+	return nil
+}
+
+func (r *ticketRepository) UpdatePriority(ctx context.Context, id uuid.UUID, priority string) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `UPDATE tickets SET priority = $2 WHERE id = $1`
+
+	tag, err := r.db.Exec(subCtx, query, id, priority)
+	if err != nil {
+		r.logger.Error("[TicketRepository.UpdatePriority]", zap.Error(err))
+		return fmt.Errorf("failed to update ticket priority: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return dto.ErrTicketNotFound
+	}
+	return nil
+}
+
+func (r *ticketRepository) UpdateCategory(ctx context.Context, id uuid.UUID, category string) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `UPDATE tickets SET category = $2 WHERE id = $1`
+
+	tag, err := r.db.Exec(subCtx, query, id, category)
+	if err != nil {
+		r.logger.Error("[TicketRepository.UpdateCategory]", zap.Error(err))
+		return fmt.Errorf("failed to update ticket category: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return dto.ErrTicketNotFound
+	}
+	return nil
+}
+
+func insertTicketAttachments(ctx context.Context, tx pgx.Tx, ticketID uuid.UUID, replyID *uuid.UUID, attachments []*entity.TicketAttachment) error {
+	query := `
+		INSERT INTO ticket_attachments (ticket_id, reply_id, uploader_id, object_key, url, name, size, mime_type)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	for _, attachment := range attachments {
+		attachment.TicketID = ticketID
+		attachment.ReplyID = replyID
+		err := tx.QueryRow(
+			ctx,
+			query,
+			attachment.TicketID,
+			attachment.ReplyID,
+			attachment.UploaderID,
+			attachment.ObjectKey,
+			attachment.URL,
+			attachment.Name,
+			attachment.Size,
+			attachment.MimeType,
+		).Scan(&attachment.ID, &attachment.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type TicketSLARepository interface {
+	BaseRepository
+	ListPolicies(ctx context.Context) ([]*entity.TicketSLAPolicy, error)
+	UpsertPolicy(ctx context.Context, policy *entity.TicketSLAPolicy) error
+	DeletePolicy(ctx context.Context, id uuid.UUID) error
+	ListBreaches(ctx context.Context, limit int) ([]*entity.TicketSLABreach, error)
+	ClaimEscalation(ctx context.Context, ticketID uuid.UUID, kind string) (bool, error)
+	Report(ctx context.Context, since time.Time, category string) (*entity.TicketSLAReport, error)
+}
+
+type ticketSLARepository struct {
+	*baseRepository
+}
+
+func NewTicketSLARepository(db *pgxpool.Pool, logger *zap.Logger) TicketSLARepository {
+	return &ticketSLARepository{
+		baseRepository: NewBaseRepository(
+			db,
+			logger,
+		).(*baseRepository),
+	}
+}
+
+// ticketSLAPolicyJoin picks the policy for ticket t as "sla": the one for its category when
+// there is one, else the default for its priority. Prefix it with LEFT to keep tickets no
+// policy applies to.
+const ticketSLAPolicyJoin = ` JOIN LATERAL (
+		SELECT p.first_response_minutes, p.resolution_minutes
+		FROM ticket_sla_policies p
+		WHERE p.is_active AND p.priority = t.priority AND (p.category = t.category OR p.category IS NULL)
+		ORDER BY p.category IS NULL
+		LIMIT 1
+	) sla ON TRUE`
+
+const (
+	ticketResponseDue   = `t.created_at + make_interval(mins => sla.first_response_minutes)`
+	ticketResolutionDue = `t.created_at + make_interval(mins => sla.resolution_minutes)`
+)
+
+func (r *ticketSLARepository) ListPolicies(ctx context.Context) ([]*entity.TicketSLAPolicy, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, category, priority, first_response_minutes, resolution_minutes, is_active, created_at, updated_at
+		FROM ticket_sla_policies
+		ORDER BY category NULLS FIRST,
+			CASE priority WHEN 'urgent' THEN 4 WHEN 'high' THEN 3 WHEN 'normal' THEN 2 ELSE 1 END DESC
+	`
+
+	rows, err := r.db.Query(subCtx, query)
+	if err != nil {
+		r.logger.Error("[TicketSLARepository.ListPolicies]", zap.Error(err))
+		return nil, fmt.Errorf("failed to list SLA policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := make([]*entity.TicketSLAPolicy, 0)
+	for rows.Next() {
+		var p entity.TicketSLAPolicy
+		if err := rows.Scan(
+			&p.ID, &p.Category, &p.Priority, &p.FirstResponseMinutes, &p.ResolutionMinutes,
+			&p.IsActive, &p.CreatedAt, &p.UpdatedAt,
+		); err != nil {
+			r.logger.Error("[TicketSLARepository.ListPolicies] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan SLA policy: %w", err)
+		}
+		policies = append(policies, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate SLA policies: %w", err)
+	}
+	return policies, nil
+}
+
+// UpsertPolicy creates the policy for its category and priority, or replaces the targets of the
+// existing one.
+func (r *ticketSLARepository) UpsertPolicy(ctx context.Context, policy *entity.TicketSLAPolicy) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO ticket_sla_policies (category, priority, first_response_minutes, resolution_minutes, is_active)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT ((COALESCE(category, '')), priority) DO UPDATE SET
+			first_response_minutes = EXCLUDED.first_response_minutes,
+			resolution_minutes = EXCLUDED.resolution_minutes,
+			is_active = EXCLUDED.is_active
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(
+		subCtx,
+		query,
+		policy.Category,
+		policy.Priority,
+		policy.FirstResponseMinutes,
+		policy.ResolutionMinutes,
+		policy.IsActive,
+	).Scan(&policy.ID, &policy.CreatedAt, &policy.UpdatedAt)
+	if err != nil {
+		r.logger.Error("[TicketSLARepository.UpsertPolicy]", zap.Error(err))
+		return fmt.Errorf("failed to save SLA policy: %w", err)
+	}
+	return nil
+}
+
+func (r *ticketSLARepository) DeletePolicy(ctx context.Context, id uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	tag, err := r.db.Exec(subCtx, `DELETE FROM ticket_sla_policies WHERE id = $1`, id)
+	if err != nil {
+		r.logger.Error("[TicketSLARepository.DeletePolicy]", zap.Error(err))
+		return fmt.Errorf("failed to delete SLA policy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return dto.ErrTicketSLAPolicyNotFound
+	}
+	return nil
+}
+
+// ListBreaches returns open tickets past a target they have not been escalated for yet, the
+// longest overdue first.
+func (r *ticketSLARepository) ListBreaches(ctx context.Context, limit int) ([]*entity.TicketSLABreach, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	const breachColumns = `
+			t.id, t.number, t.title, t.category, t.priority, t.assignee_id, a.full_name`
+	const breachFrom = `
+			FROM tickets t` + ticketSLAPolicyJoin + `
+			LEFT JOIN users a ON a.id = t.assignee_id`
+
+	query := `
+		SELECT * FROM (
+			SELECT` + breachColumns + `, '` + entity.TicketSLAFirstResponse + `' AS kind, ` + ticketResponseDue + ` AS due_at, t.created_at` + breachFrom + `
+			WHERE t.status = 'open' AND t.first_response_at IS NULL AND t.sla_response_escalated_at IS NULL
+				AND ` + ticketResponseDue + ` <= NOW()
+			UNION ALL
+			SELECT` + breachColumns + `, '` + entity.TicketSLAResolution + `' AS kind, ` + ticketResolutionDue + ` AS due_at, t.created_at` + breachFrom + `
+			WHERE t.status = 'open' AND t.sla_resolution_escalated_at IS NULL
+				AND ` + ticketResolutionDue + ` <= NOW()
+		) breaches
+		ORDER BY due_at
+		LIMIT ` + strconv.Itoa(limit)
+
+	rows, err := r.db.Query(subCtx, query)
+	if err != nil {
+		r.logger.Error("[TicketSLARepository.ListBreaches]", zap.Error(err))
+		return nil, fmt.Errorf("failed to list SLA breaches: %w", err)
+	}
+	defer rows.Close()
+
+	breaches := make([]*entity.TicketSLABreach, 0)
+	for rows.Next() {
+		var b entity.TicketSLABreach
+		if err := rows.Scan(
+			&b.TicketID, &b.Number, &b.Title, &b.Category, &b.Priority, &b.AssigneeID, &b.AssigneeName,
+			&b.Kind, &b.DueAt, &b.CreatedAt,
+		); err != nil {
+			r.logger.Error("[TicketSLARepository.ListBreaches] scan", zap.Error(err))
+			return nil, fmt.Errorf("failed to scan SLA breach: %w", err)
+		}
+		breaches = append(breaches, &b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate SLA breaches: %w", err)
+	}
+	return breaches, nil
+}
+
+// ClaimEscalation marks a breach as escalated. It reports false when the breach was already
+// claimed, so concurrent checks escalate each breach once.
+func (r *ticketSLARepository) ClaimEscalation(ctx context.Context, ticketID uuid.UUID, kind string) (bool, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	column := "sla_resolution_escalated_at"
+	if kind == entity.TicketSLAFirstResponse {
+		column = "sla_response_escalated_at"
+	}
+	query := `UPDATE tickets SET ` + column + ` = NOW() WHERE id = $1 AND ` + column + ` IS NULL`
+
+	tag, err := r.db.Exec(subCtx, query, ticketID)
+	if err != nil {
+		r.logger.Error("[TicketSLARepository.ClaimEscalation]", zap.Error(err))
+		return false, fmt.Errorf("failed to claim SLA escalation: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Report measures the tickets opened since the given time against their targets, and the
+// current open backlog by age. Tickets no policy applies to count towards the totals only.
+func (r *ticketSLARepository) Report(ctx context.Context, since time.Time, category string) (*entity.TicketSLAReport, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	report := &entity.TicketSLAReport{Since: since, Category: category}
+
+	windowQuery := `
+		WITH scoped AS (
+			SELECT t.created_at, t.first_response_at, t.resolved_at,
+				` + ticketResponseDue + ` AS response_due,
+				` + ticketResolutionDue + ` AS resolution_due
+			FROM tickets t
+			LEFT` + ticketSLAPolicyJoin + `
+			WHERE t.created_at >= $1 AND ($2 = '' OR t.category = $2)
+		)
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE first_response_at <= response_due),
+			COUNT(*) FILTER (WHERE first_response_at > response_due OR (first_response_at IS NULL AND response_due < NOW())),
+			COUNT(*) FILTER (WHERE first_response_at IS NULL AND response_due >= NOW()),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM first_response_at - created_at)::float8) / 60,
+			COUNT(*) FILTER (WHERE resolved_at <= resolution_due),
+			COUNT(*) FILTER (WHERE resolved_at > resolution_due OR (resolved_at IS NULL AND resolution_due < NOW())),
+			COUNT(*) FILTER (WHERE resolved_at IS NULL AND resolution_due >= NOW()),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM resolved_at - created_at)::float8) / 60
+		FROM scoped
+	`
+
+	err := r.db.QueryRow(subCtx, windowQuery, since, category).Scan(
+		&report.Tickets,
+		&report.FirstResponse.Met,
+		&report.FirstResponse.Breached,
+		&report.FirstResponse.Pending,
+		&report.FirstResponse.MedianMinutes,
+		&report.Resolution.Met,
+		&report.Resolution.Breached,
+		&report.Resolution.Pending,
+		&report.Resolution.MedianMinutes,
+	)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		r.logger.Error("[TicketSLARepository.Report] window", zap.Error(err))
+		return nil, fmt.Errorf("failed to compute SLA compliance: %w", err)
+	}
+
+	backlogQuery := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE (t.first_response_at IS NULL AND ` + ticketResponseDue + ` < NOW()) OR ` + ticketResolutionDue + ` < NOW()),
+			MAX(EXTRACT(EPOCH FROM NOW() - t.created_at)::float8) / 3600,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM NOW() - t.created_at)::float8) / 3600,
+			AVG(EXTRACT(EPOCH FROM NOW() - t.created_at)::float8) / 3600,
+			COUNT(*) FILTER (WHERE t.created_at > NOW() - INTERVAL '1 day'),
+			COUNT(*) FILTER (WHERE t.created_at <= NOW() - INTERVAL '1 day' AND t.created_at > NOW() - INTERVAL '3 days'),
+			COUNT(*) FILTER (WHERE t.created_at <= NOW() - INTERVAL '3 days' AND t.created_at > NOW() - INTERVAL '7 days'),
+			COUNT(*) FILTER (WHERE t.created_at <= NOW() - INTERVAL '7 days')
+		FROM tickets t
+		LEFT` + ticketSLAPolicyJoin + `
+		WHERE t.status = 'open' AND ($1 = '' OR t.category = $1)
+	`
+
+	buckets := make([]int64, 4)
+	err = r.db.QueryRow(subCtx, backlogQuery, category).Scan(
+		&report.Backlog.Open,
+		&report.Backlog.Breached,
+		&report.Backlog.OldestAgeHours,
+		&report.Backlog.MedianAgeHours,
+		&report.Backlog.AverageAgeHours,
+		&buckets[0], &buckets[1], &buckets[2], &buckets[3],
+	)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		r.logger.Error("[TicketSLARepository.Report] backlog", zap.Error(err))
+		return nil, fmt.Errorf("failed to compute ticket backlog: %w", err)
+	}
+	for i, label := range []string{"<1d", "1-3d", "3-7d", ">7d"} {
+		report.Backlog.Buckets = append(report.Backlog.Buckets, entity.TicketBacklogBucket{Label: label, Count: buckets[i]})
+	}
+
+	return report, nil
+}
@@ -45,6 +45,12 @@ type UserRepository interface {
 	FindByRoleID(ctx context.Context, roleID uuid.UUID) ([]*entity.User, error)
 	FindRoleByName(ctx context.Context, name string) (*entity.Role, error)
 	SetRoleID(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error
+	UpdateUsername(ctx context.Context, id uuid.UUID, username string) error
+	FindByUsernames(ctx context.Context, usernames []string) ([]*entity.User, error)
+	Block(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error
+	Unblock(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error
+	IsBlocked(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) (bool, error)
+	ListBlocked(ctx context.Context, blockerID uuid.UUID, filter entity.UserBlockFilter) ([]*entity.UserBlock, int64, error)
 }
 
 type userRepository struct {
@@ -166,8 +172,9 @@ func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Us
 
 	query := `
 		SELECT 
-			u.id, u.email, u.password_hash, u.full_name, u.role_id, u.phone, u.avatar_url,
-			u.two_fa_secret, u.is_active, u.is_verified, u.created_at, u.updated_at,
+			u.id, u.email, u.username, u.password_hash, u.full_name, u.role_id, u.phone, u.avatar_url,
+			u.two_fa_secret, u.is_active, u.is_verified, u.email_verified_at, u.last_login_at,
+			u.created_at, u.updated_at,
 			r.id, r.name, r.level
 		FROM users u
 		LEFT JOIN roles r ON u.role_id = r.id
@@ -182,6 +189,7 @@ func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Us
 	err := r.db.QueryRow(subCtx, query, id).Scan(
 		&user.ID,
 		&user.Email,
+		&user.Username,
 		&user.PasswordHash,
 		&user.FullName,
 		&userRoleID,
@@ -190,6 +198,8 @@ func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Us
 		&user.TwoFaSecret,
 		&user.IsActive,
 		&user.IsVerified,
+		&user.EmailVerifiedAt,
+		&user.LastLoginAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&roleID,
@@ -220,8 +230,9 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*entity
 
 	query := `
 		SELECT 
-			u.id, u.email, u.password_hash, u.full_name, u.role_id, u.phone, u.avatar_url,
-			u.two_fa_secret, u.is_active, u.is_verified, u.created_at, u.updated_at,
+			u.id, u.email, u.username, u.password_hash, u.full_name, u.role_id, u.phone, u.avatar_url,
+			u.two_fa_secret, u.is_active, u.is_verified, u.email_verified_at, u.last_login_at,
+			u.created_at, u.updated_at,
 			r.id, r.name, r.level
 		FROM users u
 		LEFT JOIN roles r ON u.role_id = r.id
@@ -236,6 +247,7 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*entity
 	err := r.db.QueryRow(subCtx, query, email).Scan(
 		&user.ID,
 		&user.Email,
+		&user.Username,
 		&user.PasswordHash,
 		&user.FullName,
 		&userRoleID,
@@ -244,6 +256,8 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*entity
 		&user.TwoFaSecret,
 		&user.IsActive,
 		&user.IsVerified,
+		&user.EmailVerifiedAt,
+		&user.LastLoginAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&roleID,
@@ -283,7 +297,7 @@ func (r *userRepository) Update(ctx context.Context, user *entity.User) (*entity
 			is_verified = $7,
 			email_verified_at = $8
 		WHERE id = $9 AND deleted_at IS NULL
-		RETURNING id, email, full_name, role_id, phone, avatar_url, is_active, is_verified, email_verified_at, last_login_at, created_at, updated_at
+		RETURNING id, email, username, full_name, role_id, phone, avatar_url, is_active, is_verified, email_verified_at, last_login_at, created_at, updated_at
 	`
 	args := []interface{}{
 		user.Email,
@@ -304,6 +318,7 @@ func (r *userRepository) Update(ctx context.Context, user *entity.User) (*entity
 		args...).Scan(
 		&updatedUser.ID,
 		&updatedUser.Email,
+		&updatedUser.Username,
 		&updatedUser.FullName,
 		&updatedUser.RoleID,
 		&updatedUser.Phone,
@@ -347,7 +362,7 @@ func (r *userRepository) UpdateTx(ctx context.Context, tx pgx.Tx, user *entity.U
 			is_verified = $7,
 			email_verified_at = $8
 		WHERE id = $9 AND deleted_at IS NULL
-		RETURNING id, email, full_name, role_id, phone, avatar_url, is_active, is_verified, email_verified_at, last_login_at, created_at, updated_at
+		RETURNING id, email, username, full_name, role_id, phone, avatar_url, is_active, is_verified, email_verified_at, last_login_at, created_at, updated_at
 	`
 	args := []interface{}{
 		user.Email,
@@ -365,6 +380,7 @@ func (r *userRepository) UpdateTx(ctx context.Context, tx pgx.Tx, user *entity.U
 	err := tx.QueryRow(subCtx, query, args...).Scan(
 		&updatedUser.ID,
 		&updatedUser.Email,
+		&updatedUser.Username,
 		&updatedUser.FullName,
 		&updatedUser.RoleID,
 		&updatedUser.Phone,
@@ -656,7 +672,7 @@ func (r *userRepository) Search(ctx context.Context, params dto.QueryParamsReque
 
 	qb := NewQueryBuilder(`
 		SELECT 
-			u.id, u.email, u.full_name, u.avatar_url, u.role_id, u.phone, u.is_active, u.is_verified, u.email_verified_at, u.last_login_at, u.created_at, u.updated_at,
+			u.id, u.email, u.username, u.full_name, u.avatar_url, u.role_id, u.phone, u.is_active, u.is_verified, u.email_verified_at, u.last_login_at, u.created_at, u.updated_at,
 			r.id, r.name, r.level
 		FROM users u
 		LEFT JOIN roles r ON u.role_id = r.id
@@ -733,6 +749,7 @@ func (r *userRepository) Search(ctx context.Context, params dto.QueryParamsReque
 		err := rows.Scan(
 			&user.ID,
 			&user.Email,
+			&user.Username,
 			&user.FullName,
 			&user.AvatarURL,
 			&user.RoleID,
@@ -779,7 +796,7 @@ func (r *userRepository) FindByRoleID(ctx context.Context, roleID uuid.UUID) ([]
 
 	query := `
 		SELECT 
-			u.id, u.email, u.password_hash, u.full_name, u.role_id, u.phone, u.avatar_url,
+			u.id, u.email, u.username, u.password_hash, u.full_name, u.role_id, u.phone, u.avatar_url,
 			u.two_fa_secret, u.is_active, u.is_verified, u.created_at, u.updated_at,
 			r.id, r.name, r.level
 		FROM users u
@@ -804,6 +821,7 @@ func (r *userRepository) FindByRoleID(ctx context.Context, roleID uuid.UUID) ([]
 		err := rows.Scan(
 			&user.ID,
 			&user.Email,
+			&user.Username,
 			&user.PasswordHash,
 			&user.FullName,
 			&userRoleID,
@@ -873,3 +891,148 @@ func (r *userRepository) SetRoleID(ctx context.Context, userID uuid.UUID, roleID
 	}
 	return nil
 }
+func (r *userRepository) UpdateUsername(ctx context.Context, id uuid.UUID, username string) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `UPDATE users SET username = $1 WHERE id = $2 AND deleted_at IS NULL`
+	result, err := r.db.Exec(subCtx, query, username, id)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23505" {
+			return dto.ErrUsernameAlreadyExists
+		}
+		return fmt.Errorf("failed to update username: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found or already updated")
+	}
+	return nil
+}
+func (r *userRepository) FindByUsernames(ctx context.Context, usernames []string) ([]*entity.User, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if len(usernames) == 0 {
+		return []*entity.User{}, nil
+	}
+
+	lowered := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		lowered = append(lowered, strings.ToLower(username))
+	}
+
+	query := `
+		SELECT id, email, username, full_name, avatar_url, is_active
+		FROM users
+		WHERE LOWER(username) = ANY($1) AND deleted_at IS NULL AND is_active
+	`
+	rows, err := r.db.Query(subCtx, query, lowered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users by username: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*entity.User, 0, len(usernames))
+	for rows.Next() {
+		var user entity.User
+		if err := rows.Scan(&user.ID, &user.Email, &user.Username, &user.FullName, &user.AvatarURL, &user.IsActive); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, &user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+	return users, nil
+}
+
+// Block records that blockerID blocked blockedID. Blocking twice is a no-op.
+func (r *userRepository) Block(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_blocks (blocker_id, blocked_id)
+		SELECT $1, u.id FROM users u WHERE u.id = $2 AND u.deleted_at IS NULL
+		ON CONFLICT (blocker_id, blocked_id) DO NOTHING
+	`
+
+	if _, err := r.db.Exec(subCtx, query, blockerID, blockedID); err != nil {
+		r.logger.Error("[UserRepository.Block]", zap.Error(err))
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) Unblock(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`
+
+	tag, err := r.db.Exec(subCtx, query, blockerID, blockedID)
+	if err != nil {
+		r.logger.Error("[UserRepository.Unblock]", zap.Error(err))
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return dto.ErrUserNotBlocked
+	}
+	return nil
+}
+
+func (r *userRepository) IsBlocked(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) (bool, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	query := `SELECT EXISTS (SELECT 1 FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2)`
+
+	var blocked bool
+	if err := r.db.QueryRow(subCtx, query, blockerID, blockedID).Scan(&blocked); err != nil {
+		r.logger.Error("[UserRepository.IsBlocked]", zap.Error(err))
+		return false, fmt.Errorf("failed to check user block: %w", err)
+	}
+	return blocked, nil
+}
+
+// ListBlocked returns the users blockerID has blocked, most recent first.
+func (r *userRepository) ListBlocked(ctx context.Context, blockerID uuid.UUID, filter entity.UserBlockFilter) ([]*entity.UserBlock, int64, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	var total int64
+	if err := r.db.QueryRow(subCtx, `SELECT COUNT(*) FROM user_blocks WHERE blocker_id = $1`, blockerID).Scan(&total); err != nil {
+		r.logger.Error("[UserRepository.ListBlocked] count", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count blocked users: %w", err)
+	}
+
+	query := `
+		SELECT b.blocked_id, COALESCE(u.full_name, ''), u.username, u.avatar_url, b.created_at
+		FROM user_blocks b
+		LEFT JOIN users u ON u.id = b.blocked_id
+		WHERE b.blocker_id = $1
+		ORDER BY b.created_at DESC, b.blocked_id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(subCtx, query, blockerID, filter.Limit, filter.Offset)
+	if err != nil {
+		r.logger.Error("[UserRepository.ListBlocked]", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list blocked users: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := make([]*entity.UserBlock, 0)
+	for rows.Next() {
+		var block entity.UserBlock
+		if err := rows.Scan(&block.UserID, &block.FullName, &block.Username, &block.AvatarURL, &block.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan blocked user: %w", err)
+		}
+		blocks = append(blocks, &block)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+	return blocks, total, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type ViewHistoryRepository interface {
+	BaseRepository
+	InsertBatch(ctx context.Context, views []*entity.ViewHistory, window time.Duration) (int64, error)
+}
+
+type viewHistoryRepository struct {
+	*baseRepository
+}
+
+func NewViewHistoryRepository(db *pgxpool.Pool, logger *zap.Logger) ViewHistoryRepository {
+	return &viewHistoryRepository{
+		baseRepository: NewBaseRepository(
+			db,
+			logger,
+		).(*baseRepository),
+	}
+}
+
+// InsertBatch writes the views in one transaction, skipping any view of a title the same user
+// already viewed less than window before it. It returns how many rows were inserted.
+func (r *viewHistoryRepository) InsertBatch(ctx context.Context, views []*entity.ViewHistory, window time.Duration) (int64, error) {
+	if len(views) == 0 {
+		return 0, nil
+	}
+
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO view_histories (
+			user_id, type, slug, name, page_url, ip_address, user_agent,
+			browser_language, device_type, browser, platform, view_time
+		)
+		SELECT $1::uuid, $2::varchar, $3::text, $4::text, $5::text, $6::text, $7::text,
+			$8::text, $9::text, $10::text, $11::text, $12::timestamp
+		WHERE NOT EXISTS (
+			SELECT 1 FROM view_histories
+			WHERE user_id = $1::uuid AND type = $2::varchar AND slug = $3::text
+				AND view_time > $12::timestamp - make_interval(secs => $13)
+				AND view_time <= $12::timestamp
+		)
+	`
+
+	var inserted int64
+	err := r.WithTransaction(subCtx, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for _, view := range views {
+			batch.Queue(
+				query,
+				view.UserID,
+				string(view.Type),
+				view.Slug,
+				view.Name,
+				view.PageURL,
+				view.IPAddress,
+				view.UserAgent,
+				view.BrowserLanguage,
+				view.DeviceType,
+				view.Browser,
+				view.Platform,
+				view.ViewTime.UTC(),
+				window.Seconds(),
+			)
+		}
+
+		results := tx.SendBatch(subCtx, batch)
+		for range views {
+			tag, err := results.Exec()
+			if err != nil {
+				results.Close()
+				return err
+			}
+			inserted += tag.RowsAffected()
+		}
+		return results.Close()
+	})
+	if err != nil {
+		r.logger.Error("[ViewHistoryRepository.InsertBatch]", zap.Error(err))
+		return 0, fmt.Errorf("failed to insert view histories: %w", err)
+	}
+	return inserted, nil
+}
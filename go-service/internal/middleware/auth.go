@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -25,6 +27,7 @@ var (
 	ErrInvalidToken   = "Unauthorized - Invalid token format"
 	ErrExpiredToken   = "Unauthorized - Invalid or expired token"
 	ErrInvalidAuth    = "Unauthorized - Invalid authorization header"
+	ErrInactiveUser   = "Forbidden - Account is suspended"
 )
 
 type AuthMiddleware struct {
@@ -57,6 +60,20 @@ func NewAuthMiddleware(
 	}
 }
 
+type AuthIdentity struct {
+	User        *entity.User
+	RoleLevel   int
+	RoleName    string
+	FirebaseUID string
+	Claims      map[string]interface{}
+}
+
+var (
+	errFirebaseVerification = errors.New("firebase token verification failed")
+	errAuthInternal         = errors.New("internal_error")
+	errUserInactive         = errors.New("user account is inactive")
+)
+
 func (m *AuthMiddleware) FirebaseAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		ctx := m.ctxinject.From(c)
@@ -67,113 +84,173 @@ func (m *AuthMiddleware) FirebaseAuth() fiber.Handler {
 			return response.Error(c, fiber.StatusUnauthorized, err.Error(), nil)
 		}
 
-		firebaseToken, err := m.firebase.VerifyIDToken(ctx, tokenStr, m.checkRevoked)
+		identity, err := m.Authenticate(ctx, tokenStr)
 		if err != nil {
-			m.logger.Error("❌ [STEP 1 FAILED] Firebase token verification failed",
-				zap.Error(err),
-				zap.String("path", c.Path()))
-			return m.handleTokenError(c, err)
+			switch {
+			case errors.Is(err, errFirebaseVerification):
+				m.logger.Error("❌ [STEP 1 FAILED] Firebase token verification failed",
+					zap.Error(err),
+					zap.String("path", c.Path()))
+				return m.handleTokenError(c, err)
+			case errors.Is(err, errAuthInternal):
+				return response.Error(c, fiber.StatusInternalServerError, "internal_error", nil)
+			case errors.Is(err, errUserInactive):
+				return response.Error(c, fiber.StatusForbidden, ErrInactiveUser, nil)
+			default:
+				return response.Error(c, fiber.StatusUnauthorized, ErrInvalidToken, nil)
+			}
 		}
 
-		email, _ := firebaseToken.Claims["email"].(string)
-		if email == "" {
-			return response.Error(c, fiber.StatusUnauthorized, ErrInvalidToken, nil)
+		user := identity.User
+		setIdentityLocals(c, identity)
+
+		m.logger.Debug("✅ [FINAL] Firebase authentication successful",
+			zap.String("user_id", user.ID.String()),
+			zap.Int("role_level", identity.RoleLevel),
+			zap.String("role_name", identity.RoleName),
+			zap.String("firebase_uid", identity.FirebaseUID),
+			zap.String("path", c.Path()))
+
+		return c.Next()
+	}
+}
+
+// OptionalFirebaseAuth resolves the caller when a valid bearer token is present
+// and otherwise lets the request through anonymously.
+func (m *AuthMiddleware) OptionalFirebaseAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			return c.Next()
 		}
 
-		name, _ := firebaseToken.Claims["name"].(string)
-		picture, _ := firebaseToken.Claims["picture"].(string)
-		phone, _ := firebaseToken.Claims["phone_number"].(string)
-		emailVerified, _ := firebaseToken.Claims["email_verified"].(bool)
+		tokenStr, err := m.extractTokenFromHeader(c)
+		if err != nil {
+			return c.Next()
+		}
 
-		user, err := m.userRepo.FindByEmail(ctx, email)
+		identity, err := m.Authenticate(m.ctxinject.From(c), tokenStr)
 		if err != nil {
-			randomPassword := utils.GenerateRandomPassword()
-			hash, hashErr := utils.HashPassword(randomPassword)
-			if hashErr != nil {
-				m.logger.Error("failed to hash fallback password", zap.Error(hashErr))
-				return response.Error(c, fiber.StatusInternalServerError, "internal_error", nil)
-			}
+			m.logger.Debug("Optional firebase authentication skipped", zap.Error(err), zap.String("path", c.Path()))
+			return c.Next()
+		}
 
-			nm := name
-			if nm == "" {
-				nm = "User"
-			}
+		setIdentityLocals(c, identity)
+		return c.Next()
+	}
+}
 
-			newUser := &entity.User{
-				ID:           uuid.New(),
-				Email:        email,
-				PasswordHash: hash,
-				FullName:     nm,
-				Phone:        entity.NewNullString(phone),
-				AvatarURL:    entity.NewNullString(picture),
-				IsActive:     true,
-				IsVerified:   emailVerified,
-				CreatedAt:    time.Now(),
-			}
+func setIdentityLocals(c *fiber.Ctx, identity *AuthIdentity) {
+	c.Locals("user_id", identity.User.ID.String())
+	c.Locals("email", identity.User.Email)
+	c.Locals("role_level", identity.RoleLevel)
+	c.Locals("role_name", identity.RoleName)
+	c.Locals("firebase_uid", identity.FirebaseUID)
+	c.Locals("firebase_claims", identity.Claims)
+}
 
-			role, err := m.roleRepo.FindByLevel(ctx, entity.RoleLevelUser)
-			if err != nil {
-				m.logger.Error("failed to find role: %w", zap.Error(err))
-				return response.Error(c, fiber.StatusInternalServerError, "internal_error", nil)
-			}
-			newUser.RoleID = role.ID
+// Authenticate verifies a Firebase ID token and resolves (or provisions) the local user.
+// It is shared by FirebaseAuth and callers that receive tokens outside the Authorization header.
+func (m *AuthMiddleware) Authenticate(ctx context.Context, tokenStr string) (*AuthIdentity, error) {
+	firebaseToken, err := m.firebase.VerifyIDToken(ctx, tokenStr, m.checkRevoked)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errFirebaseVerification, err)
+	}
 
-			if createErr := m.userRepo.CreateWithRecovery(ctx, newUser); createErr != nil {
-				m.logger.Error("failed to create user from firebase token", zap.Error(createErr), zap.String("email", email))
-				return response.Error(c, fiber.StatusUnauthorized, ErrInvalidToken, nil)
-			}
-			user = newUser
-		}
+	email, _ := firebaseToken.Claims["email"].(string)
+	if email == "" {
+		return nil, errors.New(ErrInvalidToken)
+	}
 
-		if emailVerified && !user.IsVerified {
-			_ = m.userRepo.SetEmailVerified(ctx, user.ID, true)
-			user.IsVerified = true
-		}
+	name, _ := firebaseToken.Claims["name"].(string)
+	picture, _ := firebaseToken.Claims["picture"].(string)
+	phone, _ := firebaseToken.Claims["phone_number"].(string)
+	emailVerified, _ := firebaseToken.Claims["email_verified"].(bool)
 
-		changed := false
-		if name != "" && (strings.TrimSpace(user.FullName) == "" || user.FullName == "User") {
-			user.FullName = name
-			changed = true
+	user, err := m.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		randomPassword := utils.GenerateRandomPassword()
+		hash, hashErr := utils.HashPassword(randomPassword)
+		if hashErr != nil {
+			m.logger.Error("failed to hash fallback password", zap.Error(hashErr))
+			return nil, errAuthInternal
 		}
-		if phone != "" && !user.Phone.Valid {
-			user.Phone = entity.NewNullString(phone)
-			changed = true
+
+		nm := name
+		if nm == "" {
+			nm = "User"
 		}
-		if picture != "" && !user.AvatarURL.Valid {
-			user.AvatarURL = entity.NewNullString(picture)
-			changed = true
+
+		newUser := &entity.User{
+			ID:           uuid.New(),
+			Email:        email,
+			PasswordHash: hash,
+			FullName:     nm,
+			Phone:        entity.NewNullString(phone),
+			AvatarURL:    entity.NewNullString(picture),
+			IsActive:     true,
+			IsVerified:   emailVerified,
+			CreatedAt:    time.Now(),
 		}
-		if changed {
-			if updated, upErr := m.userRepo.UpdateWithRecovery(ctx, user); upErr == nil && updated != nil {
-				user = updated
-			}
+
+		role, err := m.roleRepo.FindByLevel(ctx, entity.RoleLevelUser)
+		if err != nil {
+			m.logger.Error("failed to find role: %w", zap.Error(err))
+			return nil, errAuthInternal
 		}
+		newUser.RoleID = role.ID
 
-		var roleLevel int
-		var roleName string
-		if user.Role != nil && user.Role.ID != uuid.Nil {
-			roleLevel = int(user.Role.Level)
-			roleName = user.Role.Name
-		} else {
-			roleLevel, roleName = extractRoleFromClaims(firebaseToken.Claims)
+		if createErr := m.userRepo.CreateWithRecovery(ctx, newUser); createErr != nil {
+			m.logger.Error("failed to create user from firebase token", zap.Error(createErr), zap.String("email", email))
+			return nil, errors.New(ErrInvalidToken)
 		}
+		user = newUser
+	}
 
-		c.Locals("user_id", user.ID.String())
-		c.Locals("email", user.Email)
-		c.Locals("role_level", roleLevel)
-		c.Locals("role_name", roleName)
-		c.Locals("firebase_uid", firebaseToken.UID)
-		c.Locals("firebase_claims", firebaseToken.Claims)
+	// Suspended accounts keep a valid Firebase session, so the local flag is authoritative.
+	if !user.IsActive {
+		return nil, errUserInactive
+	}
 
-		m.logger.Debug("✅ [FINAL] Firebase authentication successful",
-			zap.String("user_id", user.ID.String()),
-			zap.Int("role_level", roleLevel),
-			zap.String("role_name", roleName),
-			zap.String("firebase_uid", firebaseToken.UID),
-			zap.String("path", c.Path()))
+	if emailVerified && !user.IsVerified {
+		_ = m.userRepo.SetEmailVerified(ctx, user.ID, true)
+		user.IsVerified = true
+	}
 
-		return c.Next()
+	changed := false
+	if name != "" && (strings.TrimSpace(user.FullName) == "" || user.FullName == "User") {
+		user.FullName = name
+		changed = true
 	}
+	if phone != "" && !user.Phone.Valid {
+		user.Phone = entity.NewNullString(phone)
+		changed = true
+	}
+	if picture != "" && !user.AvatarURL.Valid {
+		user.AvatarURL = entity.NewNullString(picture)
+		changed = true
+	}
+	if changed {
+		if updated, upErr := m.userRepo.UpdateWithRecovery(ctx, user); upErr == nil && updated != nil {
+			user = updated
+		}
+	}
+
+	var roleLevel int
+	var roleName string
+	if user.Role != nil && user.Role.ID != uuid.Nil {
+		roleLevel = int(user.Role.Level)
+		roleName = user.Role.Name
+	} else {
+		roleLevel, roleName = extractRoleFromClaims(firebaseToken.Claims)
+	}
+
+	return &AuthIdentity{
+		User:        user,
+		RoleLevel:   roleLevel,
+		RoleName:    roleName,
+		FirebaseUID: firebaseToken.UID,
+		Claims:      firebaseToken.Claims,
+	}, nil
 }
 
 func extractRoleFromClaims(claims map[string]interface{}) (int, string) {
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"fmt"
+	"strings"
 	"time"
 	"tubexxi/video-api/internal/infrastructure/contextpool"
 	redisclient "tubexxi/video-api/internal/infrastructure/redis-client"
@@ -75,6 +76,10 @@ func (rm *RateLimiterMiddleware) ResetLimitCounters(c *fiber.Ctx) {
 }
 func (rm *RateLimiterMiddleware) GlobalRequestLimiter() fiber.Handler {
 	return limiter.New(limiter.Config{
+		Next: func(c *fiber.Ctx) bool {
+			// Centrifugo proxies every client from a single address
+			return strings.HasPrefix(c.Path(), "/api/realtime/proxy")
+		},
 		KeyGenerator: func(c *fiber.Ctx) string {
 			ip := c.Locals("real_ip").(string)
 			return fmt.Sprintf("global:%s:%s", ip, c.Method())
@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"time"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BroadcastRoutes struct {
+	path      string
+	handler   *handler.BroadcastHandler
+	ctxinject *middleware.ContextMiddleware
+	limiter   *middleware.RateLimiterMiddleware
+	auth      *middleware.AuthMiddleware
+	admin     *middleware.AdminMiddleware
+	csrf      *middleware.CSRFMiddleware
+	scope     *middleware.ScopeMiddleware
+}
+
+func NewBroadcastRoutes(
+	handler *handler.BroadcastHandler,
+	ctxinject *middleware.ContextMiddleware,
+	limiter *middleware.RateLimiterMiddleware,
+	auth *middleware.AuthMiddleware,
+	admin *middleware.AdminMiddleware,
+	csrf *middleware.CSRFMiddleware,
+	scope *middleware.ScopeMiddleware,
+) *BroadcastRoutes {
+	return &BroadcastRoutes{
+		path:      "/broadcasts",
+		handler:   handler,
+		ctxinject: ctxinject,
+		limiter:   limiter,
+		auth:      auth,
+		admin:     admin,
+		csrf:      csrf,
+		scope:     scope,
+	}
+}
+func (r *BroadcastRoutes) RegisterRoutes(parent fiber.Router) {
+	router := parent.Group(r.path)
+
+	protected := router.Group("/protected")
+	protected.Use(r.scope.SettingsScopeMiddleware(), r.auth.FirebaseAuth())
+
+	protected.Get("/channels", r.handler.ListChannels)
+	protected.Post("/devices", r.limiter.BaseLimiter("broadcast_device", 20, 1*time.Minute), r.handler.RegisterDevice)
+	protected.Delete("/devices", r.limiter.BaseLimiter("broadcast_device", 20, 1*time.Minute), r.handler.UnregisterDevice)
+
+	admin := router.Group("/admin/protected")
+	admin.Use(r.auth.FirebaseAuth(), r.admin.Handler())
+
+	admin.Get("/channels", r.handler.ListAllChannels)
+	admin.Post("/channels", r.csrf.CSRFProtect(), r.handler.CreateChannel)
+	admin.Get("/channels/:id/announcements", r.handler.ListAnnouncements)
+	admin.Post("/channels/:id/announcements",
+		r.csrf.CSRFProtect(),
+		r.limiter.BaseLimiter("broadcast_announcement", 30, 1*time.Minute), r.handler.ScheduleAnnouncement)
+	admin.Delete("/announcements/:id", r.csrf.CSRFProtect(), r.handler.CancelAnnouncement)
+}
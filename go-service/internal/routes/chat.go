@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"time"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ChatRoutes struct {
+	path      string
+	handler   *handler.ChatHandler
+	ctxinject *middleware.ContextMiddleware
+	limiter   *middleware.RateLimiterMiddleware
+	auth      *middleware.AuthMiddleware
+	scope     *middleware.ScopeMiddleware
+}
+
+func NewChatRoutes(
+	handler *handler.ChatHandler,
+	ctxinject *middleware.ContextMiddleware,
+	limiter *middleware.RateLimiterMiddleware,
+	auth *middleware.AuthMiddleware,
+	scope *middleware.ScopeMiddleware,
+) *ChatRoutes {
+	return &ChatRoutes{
+		path:      "/chat",
+		handler:   handler,
+		ctxinject: ctxinject,
+		limiter:   limiter,
+		auth:      auth,
+		scope:     scope,
+	}
+}
+func (r *ChatRoutes) RegisterRoutes(parent fiber.Router) {
+	router := parent.Group(r.path)
+	router.Use(r.scope.SettingsScopeMiddleware(), r.auth.FirebaseAuth())
+
+	router.Get("/conversations", r.handler.ListConversations)
+	router.Get("/search", r.limiter.BaseLimiter("chat_search", 30, 1*time.Minute), r.handler.SearchMessages)
+	router.Post("/rooms", r.limiter.BaseLimiter("chat_room_create", 20, 1*time.Minute), r.handler.CreateRoom)
+	router.Get("/rooms/:id", r.handler.GetRoom)
+	router.Get("/rooms/:id/messages", r.handler.GetHistory)
+	router.Patch("/rooms/:id/preferences", r.handler.UpdatePreferences)
+	router.Post("/rooms/:id/participants", r.limiter.BaseLimiter("chat_participants", 30, 1*time.Minute), r.handler.AddParticipants)
+	router.Delete("/rooms/:id/participants/:user_id", r.handler.RemoveParticipant)
+	router.Post("/rooms/:id/participants/:user_id/kick", r.limiter.BaseLimiter("chat_moderation", 60, 1*time.Minute), r.handler.KickParticipant)
+	router.Post("/rooms/:id/participants/:user_id/mute", r.limiter.BaseLimiter("chat_moderation", 60, 1*time.Minute), r.handler.MuteParticipant)
+	router.Delete("/rooms/:id/participants/:user_id/mute", r.handler.UnmuteParticipant)
+	router.Put("/rooms/:id/participants/:user_id/role", r.limiter.BaseLimiter("chat_moderation", 60, 1*time.Minute), r.handler.UpdateParticipantRole)
+	router.Get("/rooms/:id/bans", r.handler.ListBans)
+	router.Post("/rooms/:id/bans/:user_id", r.limiter.BaseLimiter("chat_moderation", 60, 1*time.Minute), r.handler.BanUser)
+	router.Delete("/rooms/:id/bans/:user_id", r.handler.UnbanUser)
+	router.Put("/rooms/:id/slow-mode", r.limiter.BaseLimiter("chat_moderation", 60, 1*time.Minute), r.handler.SetSlowMode)
+	router.Post("/rooms/:id/media", r.limiter.BaseLimiter("chat_media_upload", 20, 1*time.Minute), r.handler.UploadMedia)
+	router.Post("/rooms/:id/delivered", r.limiter.BaseLimiter("chat_receipts", 120, 1*time.Minute), r.handler.MarkDelivered)
+	router.Post("/rooms/:id/read", r.limiter.BaseLimiter("chat_receipts", 120, 1*time.Minute), r.handler.MarkRead)
+	router.Post("/rooms/:id/typing", r.limiter.BaseLimiter("chat_typing", 60, 1*time.Minute), r.handler.SetTyping)
+	router.Get("/unread", r.handler.GetUnreadCounts)
+
+	router.Post("/messages", r.limiter.BaseLimiter("chat_message_send", 60, 1*time.Minute), r.handler.SendMessage)
+	router.Put("/messages/:id", r.limiter.BaseLimiter("chat_message_edit", 30, 1*time.Minute), r.handler.EditMessage)
+	router.Delete("/messages/:id", r.handler.DeleteMessage)
+	router.Get("/messages/:id/reactions", r.handler.GetReactions)
+	router.Post("/messages/:id/reactions", r.limiter.BaseLimiter("chat_reaction", 60, 1*time.Minute), r.handler.ToggleReaction)
+}
@@ -12,18 +12,21 @@ type ClientRoutes struct {
 	path    string
 	handler *handler.ClientHandler
 	limiter *middleware.RateLimiterMiddleware
+	auth    *middleware.AuthMiddleware
 	csrf    *middleware.CSRFMiddleware
 }
 
 func NewClientRoutes(
 	handler *handler.ClientHandler,
 	limiter *middleware.RateLimiterMiddleware,
+	auth *middleware.AuthMiddleware,
 	csrf *middleware.CSRFMiddleware,
 ) *ClientRoutes {
 	return &ClientRoutes{
 		path:    "/client",
 		handler: handler,
 		limiter: limiter,
+		auth:    auth,
 		csrf:    csrf,
 	}
 }
@@ -38,6 +41,7 @@ func (r *ClientRoutes) RegisterRoutes(parent fiber.Router) {
 			5*time.Minute,
 		),
 		r.csrf.CSRFProtect(),
+		r.auth.OptionalFirebaseAuth(),
 		r.handler.SendContactEmail,
 	)
 }
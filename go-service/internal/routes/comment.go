@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"time"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CommentRoutes struct {
+	path      string
+	handler   *handler.CommentHandler
+	ctxinject *middleware.ContextMiddleware
+	limiter   *middleware.RateLimiterMiddleware
+	auth      *middleware.AuthMiddleware
+	admin     *middleware.AdminMiddleware
+	csrf      *middleware.CSRFMiddleware
+}
+
+func NewCommentRoutes(
+	handler *handler.CommentHandler,
+	ctxinject *middleware.ContextMiddleware,
+	limiter *middleware.RateLimiterMiddleware,
+	auth *middleware.AuthMiddleware,
+	admin *middleware.AdminMiddleware,
+	csrf *middleware.CSRFMiddleware,
+) *CommentRoutes {
+	return &CommentRoutes{
+		path:      "/comments",
+		handler:   handler,
+		ctxinject: ctxinject,
+		limiter:   limiter,
+		auth:      auth,
+		admin:     admin,
+		csrf:      csrf,
+	}
+}
+func (r *CommentRoutes) RegisterRoutes(parent fiber.Router) {
+	router := parent.Group(r.path)
+
+	protected := router.Group("/protected")
+	protected.Use(r.auth.FirebaseAuth())
+
+	protected.Post("/", r.limiter.BaseLimiter("comment_create", 10, 1*time.Minute), r.handler.CreateComment)
+	protected.Get("/notification-settings", r.handler.GetNotificationSettings)
+	protected.Put("/notification-settings", r.limiter.BaseLimiter("comment_notification_settings", 20, 1*time.Minute), r.handler.UpdateNotificationSettings)
+	protected.Put("/:id", r.limiter.BaseLimiter("comment_update", 20, 1*time.Minute), r.handler.UpdateComment)
+	protected.Delete("/:id", r.handler.DeleteComment)
+	protected.Post("/:id/like", r.limiter.BaseLimiter("comment_like", 60, 1*time.Minute), r.handler.LikeComment)
+	protected.Delete("/:id/like", r.limiter.BaseLimiter("comment_like", 60, 1*time.Minute), r.handler.UnlikeComment)
+
+	moderation := router.Group("/admin/protected")
+	moderation.Use(r.auth.FirebaseAuth(), r.admin.Handler())
+
+	moderation.Get("/queue", r.handler.ModerationQueue)
+	moderation.Get("/stats", r.handler.GetCommentStats)
+	moderation.Get("/leaderboard", r.handler.GetCommentLeaderboard)
+	moderation.Post("/approve", r.csrf.CSRFProtect(), r.handler.ApproveComments)
+	moderation.Post("/reject", r.csrf.CSRFProtect(), r.handler.RejectComments)
+	moderation.Post("/shadow-ban",
+		r.csrf.CSRFProtect(),
+		r.limiter.BlockLimiter("comment_shadow_ban", 30, 30*time.Minute), r.handler.ShadowBanAuthors)
+
+	guest := router.Group("/guest")
+	guest.Post("/", r.limiter.BaseLimiter("comment_guest_create", 5, 1*time.Minute), r.handler.CreateGuestComment)
+	guest.Get("/verify", r.limiter.BaseLimiter("comment_guest_verify", 20, 1*time.Minute), r.handler.VerifyGuestComment)
+
+	router.Get("/", r.auth.OptionalFirebaseAuth(), r.handler.ListComments)
+	router.Get("/tree", r.auth.OptionalFirebaseAuth(), r.handler.GetCommentTree)
+	router.Get("/stats", r.limiter.BaseLimiter("comment_stats", 30, 1*time.Minute), r.handler.GetCommentStats)
+	router.Get("/leaderboard", r.limiter.BaseLimiter("comment_stats", 30, 1*time.Minute), r.handler.GetCommentLeaderboard)
+	router.Get("/stream", r.limiter.BaseLimiter("comment_stream", 60, 1*time.Minute), r.handler.GetCommentStream)
+	router.Get("/:id", r.auth.OptionalFirebaseAuth(), r.handler.GetComment)
+}
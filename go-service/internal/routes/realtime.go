@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"time"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RealtimeRoutes struct {
+	path      string
+	handler   *handler.RealtimeHandler
+	proxy     *handler.CentrifugoProxyHandler
+	ctxinject *middleware.ContextMiddleware
+	limiter   *middleware.RateLimiterMiddleware
+	auth      *middleware.AuthMiddleware
+}
+
+func NewRealtimeRoutes(
+	handler *handler.RealtimeHandler,
+	proxy *handler.CentrifugoProxyHandler,
+	ctxinject *middleware.ContextMiddleware,
+	limiter *middleware.RateLimiterMiddleware,
+	auth *middleware.AuthMiddleware,
+) *RealtimeRoutes {
+	return &RealtimeRoutes{
+		path:      "/realtime",
+		handler:   handler,
+		proxy:     proxy,
+		ctxinject: ctxinject,
+		limiter:   limiter,
+		auth:      auth,
+	}
+}
+func (r *RealtimeRoutes) RegisterRoutes(parent fiber.Router) {
+	router := parent.Group(r.path)
+
+	proxy := router.Group("/proxy", r.proxy.VerifySecret())
+	proxy.Post("/connect", r.proxy.Connect)
+	proxy.Post("/subscribe", r.proxy.Subscribe)
+	proxy.Post("/publish", r.proxy.Publish)
+	proxy.Post("/rpc", r.proxy.RPC)
+
+	protected := router.Group("/protected")
+	protected.Use(r.auth.FirebaseAuth())
+
+	protected.Post("/connection-token", r.limiter.BaseLimiter("realtime_connection_token", 30, 1*time.Minute), r.handler.GetConnectionToken)
+	protected.Post("/subscription-token", r.limiter.BaseLimiter("realtime_subscription_token", 120, 1*time.Minute), r.handler.GetSubscriptionToken)
+}
@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"time"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReportRoutes struct {
+	path      string
+	handler   *handler.ReportHandler
+	ctxinject *middleware.ContextMiddleware
+	limiter   *middleware.RateLimiterMiddleware
+	auth      *middleware.AuthMiddleware
+	admin     *middleware.AdminMiddleware
+	csrf      *middleware.CSRFMiddleware
+}
+
+func NewReportRoutes(
+	handler *handler.ReportHandler,
+	ctxinject *middleware.ContextMiddleware,
+	limiter *middleware.RateLimiterMiddleware,
+	auth *middleware.AuthMiddleware,
+	admin *middleware.AdminMiddleware,
+	csrf *middleware.CSRFMiddleware,
+) *ReportRoutes {
+	return &ReportRoutes{
+		path:      "/reports",
+		handler:   handler,
+		ctxinject: ctxinject,
+		limiter:   limiter,
+		auth:      auth,
+		admin:     admin,
+		csrf:      csrf,
+	}
+}
+func (r *ReportRoutes) RegisterRoutes(parent fiber.Router) {
+	router := parent.Group(r.path)
+
+	protected := router.Group("/protected")
+	protected.Use(r.auth.FirebaseAuth())
+
+	protected.Post("/", r.limiter.BaseLimiter("report_create", 10, 1*time.Minute), r.handler.CreateReport)
+
+	triage := router.Group("/admin/protected")
+	triage.Use(r.auth.FirebaseAuth(), r.admin.Handler())
+
+	triage.Get("/", r.handler.ListReports)
+	triage.Post("/resolve", r.csrf.CSRFProtect(), r.handler.ResolveReports)
+	triage.Get("/:target_type/:target_id", r.handler.GetReportCase)
+}
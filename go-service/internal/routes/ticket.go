@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"time"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TicketRoutes struct {
+	path      string
+	handler   *handler.TicketHandler
+	ctxinject *middleware.ContextMiddleware
+	limiter   *middleware.RateLimiterMiddleware
+	auth      *middleware.AuthMiddleware
+	admin     *middleware.AdminMiddleware
+	csrf      *middleware.CSRFMiddleware
+}
+
+func NewTicketRoutes(
+	handler *handler.TicketHandler,
+	ctxinject *middleware.ContextMiddleware,
+	limiter *middleware.RateLimiterMiddleware,
+	auth *middleware.AuthMiddleware,
+	admin *middleware.AdminMiddleware,
+	csrf *middleware.CSRFMiddleware,
+) *TicketRoutes {
+	return &TicketRoutes{
+		path:      "/tickets",
+		handler:   handler,
+		ctxinject: ctxinject,
+		limiter:   limiter,
+		auth:      auth,
+		admin:     admin,
+		csrf:      csrf,
+	}
+}
+func (r *TicketRoutes) RegisterRoutes(parent fiber.Router) {
+	router := parent.Group(r.path)
+
+	protected := router.Group("/protected")
+	protected.Use(r.auth.FirebaseAuth())
+
+	protected.Post("/", r.limiter.BaseLimiter("ticket_create", 5, 10*time.Minute), r.handler.CreateTicket)
+	protected.Get("/", r.handler.ListMyTickets)
+	protected.Get("/:id", r.handler.GetMyTicket)
+	protected.Post("/:id/replies", r.limiter.BaseLimiter("ticket_reply", 20, 1*time.Minute), r.handler.Reply)
+
+	desk := router.Group("/admin/protected")
+	desk.Use(r.auth.FirebaseAuth(), r.admin.Handler())
+
+	desk.Get("/", r.handler.ListTickets)
+	desk.Get("/sla/dashboard", r.handler.SLADashboard)
+	desk.Get("/sla/policies", r.handler.ListSLAPolicies)
+	desk.Put("/sla/policies", r.csrf.CSRFProtect(), r.handler.SaveSLAPolicy)
+	desk.Delete("/sla/policies/:policyId", r.csrf.CSRFProtect(), r.handler.DeleteSLAPolicy)
+	desk.Get("/:id", r.handler.GetTicket)
+	desk.Post("/:id/replies", r.csrf.CSRFProtect(), r.handler.StaffReply)
+	desk.Put("/:id/assign", r.csrf.CSRFProtect(), r.handler.AssignTicket)
+	desk.Put("/:id/priority", r.csrf.CSRFProtect(), r.handler.UpdatePriority)
+	desk.Put("/:id/category", r.csrf.CSRFProtect(), r.handler.UpdateCategory)
+	desk.Put("/:id/status", r.csrf.CSRFProtect(), r.handler.UpdateStatus)
+}
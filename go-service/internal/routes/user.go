@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"time"
 	"tubexxi/video-api/internal/handler"
 	"tubexxi/video-api/internal/middleware"
 
@@ -46,4 +47,14 @@ func (r *UserRoutes) RegisterRoutes(parent fiber.Router) {
 	protected.Put("/password", r.handler.ChangePassword)
 	protected.Post("/two-factor/enable", r.handler.EnableTwoFactor)
 	protected.Post("/two-factor/verify", r.handler.VerifyTwoFactor)
+
+	protected.Get("/blocks", r.handler.ListBlockedUsers)
+	protected.Post("/blocks/:user_id", r.limiter.BaseLimiter("user_block", 30, 1*time.Minute), r.handler.BlockUser)
+	protected.Delete("/blocks/:user_id", r.handler.UnblockUser)
+
+	protected.Post("/view-history", r.limiter.BaseLimiter("view_history", 60, 1*time.Minute), r.handler.RecordView)
+
+	protected.Put("/playback", r.limiter.BaseLimiter("playback_heartbeat", 120, 1*time.Minute), r.handler.PlaybackHeartbeat)
+	protected.Get("/playback/:type/:slug", r.handler.GetPlaybackProgress)
+	protected.Get("/continue-watching", r.handler.ContinueWatching)
 }
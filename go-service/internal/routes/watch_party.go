@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"time"
+	"tubexxi/video-api/internal/handler"
+	"tubexxi/video-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WatchPartyRoutes struct {
+	path      string
+	handler   *handler.WatchPartyHandler
+	ctxinject *middleware.ContextMiddleware
+	limiter   *middleware.RateLimiterMiddleware
+	auth      *middleware.AuthMiddleware
+}
+
+func NewWatchPartyRoutes(
+	handler *handler.WatchPartyHandler,
+	ctxinject *middleware.ContextMiddleware,
+	limiter *middleware.RateLimiterMiddleware,
+	auth *middleware.AuthMiddleware,
+) *WatchPartyRoutes {
+	return &WatchPartyRoutes{
+		path:      "/watch-parties",
+		handler:   handler,
+		ctxinject: ctxinject,
+		limiter:   limiter,
+		auth:      auth,
+	}
+}
+func (r *WatchPartyRoutes) RegisterRoutes(parent fiber.Router) {
+	router := parent.Group(r.path)
+	router.Use(r.auth.FirebaseAuth())
+
+	router.Post("/", r.limiter.BaseLimiter("watch_party_create", 10, 1*time.Minute), r.handler.Create)
+	router.Get("/:id", r.handler.Get)
+	router.Get("/:id/sync", r.limiter.BaseLimiter("watch_party_sync", 120, 1*time.Minute), r.handler.Sync)
+	router.Post("/:id/join", r.limiter.BaseLimiter("watch_party_join", 30, 1*time.Minute), r.handler.Join)
+	router.Post("/:id/playback", r.limiter.BaseLimiter("watch_party_playback", 120, 1*time.Minute), r.handler.Control)
+}
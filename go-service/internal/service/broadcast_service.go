@@ -0,0 +1,478 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"tubexxi/video-api/config"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	helpers "tubexxi/video-api/internal/helper"
+	asynqclient "tubexxi/video-api/internal/infrastructure/asynq-client"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+	firebaseclient "tubexxi/video-api/internal/infrastructure/firebase-client"
+	"tubexxi/video-api/internal/infrastructure/repository"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+const (
+	broadcastQueue          = "default"
+	broadcastMaxRetry       = 5
+	broadcastMaxLead        = 90 * 24 * time.Hour
+	broadcastEmailBatchSize = 200
+)
+
+type BroadcastService struct {
+	broadcastRepo repository.BroadcastRepository
+	chatRoomRepo  repository.ChatRoomRepository
+	chat          *ChatService
+	asynq         *asynqclient.AsynqClientWrapper
+	firebase      *firebaseclient.FirebaseClient
+	mail          *helpers.MailHelper
+	appConfig     *config.AppConfig
+	logger        *zap.Logger
+}
+
+func NewBroadcastService(
+	broadcastRepo repository.BroadcastRepository,
+	chatRoomRepo repository.ChatRoomRepository,
+	chat *ChatService,
+	asynq *asynqclient.AsynqClientWrapper,
+	firebase *firebaseclient.FirebaseClient,
+	mail *helpers.MailHelper,
+	appConfig *config.AppConfig,
+	logger *zap.Logger,
+) *BroadcastService {
+	return &BroadcastService{
+		broadcastRepo: broadcastRepo,
+		chatRoomRepo:  chatRoomRepo,
+		chat:          chat,
+		asynq:         asynq,
+		firebase:      firebase,
+		mail:          mail,
+		appConfig:     appConfig,
+		logger:        logger,
+	}
+}
+
+// CreateChannel opens a broadcast channel and subscribes every active user to it. The caller
+// becomes the channel admin.
+func (s *BroadcastService) CreateChannel(ctx context.Context, userID string, req *dto.BroadcastChannelCreateRequest) (*entity.BroadcastChannel, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 60*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, dto.ErrChatRoomNameRequired
+	}
+
+	target := entity.BroadcastTarget{Scope: strings.TrimSpace(req.Scope)}
+	for _, platform := range req.Platforms {
+		if !slices.Contains(target.Platforms, platform) {
+			target.Platforms = append(target.Platforms, platform)
+		}
+	}
+
+	room := &entity.ChatRoom{
+		Name:      &name,
+		CreatedBy: userUUID,
+	}
+	if description := strings.TrimSpace(req.Description); description != "" {
+		room.Description = &description
+	}
+	if avatar := strings.TrimSpace(req.AvatarURL); avatar != "" {
+		room.AvatarURL = &avatar
+	}
+	if target.IsTargeted() {
+		if room.Metadata, err = json.Marshal(target); err != nil {
+			return nil, fmt.Errorf("failed to marshal channel target: %w", err)
+		}
+	}
+
+	subscribers, err := s.broadcastRepo.CreateChannel(subCtx, room)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.BroadcastChannel{
+		ID:          room.ID,
+		Name:        name,
+		Description: room.Description,
+		AvatarURL:   room.AvatarURL,
+		Target:      target,
+		Subscribers: subscribers,
+		CreatedAt:   room.CreatedAt,
+	}, nil
+}
+
+// ListChannels returns the broadcast channels the caller is subscribed to. A filter scope or
+// platform hides channels targeted elsewhere.
+func (s *BroadcastService) ListChannels(ctx context.Context, userID string, filter entity.BroadcastChannelFilter) ([]*entity.BroadcastChannel, dto.Pagination, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, dto.Pagination{}, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	filter.SetDefaults()
+	channels, total, err := s.broadcastRepo.ListChannels(subCtx, userUUID, filter)
+	if err != nil {
+		return nil, dto.Pagination{}, err
+	}
+	return channels, commentPagination(total, filter.Limit, filter.Offset), nil
+}
+
+// ScheduleAnnouncement stores an announcement and enqueues its delivery for ScheduledAt, or
+// right away when no time is given.
+func (s *BroadcastService) ScheduleAnnouncement(ctx context.Context, userID string, roomID uuid.UUID, req *dto.AnnouncementCreateRequest) (*entity.ChatAnnouncement, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	if _, err := s.channel(subCtx, roomID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	scheduledAt := now
+	if req.ScheduledAt != nil {
+		// A minute of slack absorbs clock skew for "send now" requests from the dashboard.
+		if req.ScheduledAt.Before(now.Add(-time.Minute)) {
+			return nil, dto.ErrAnnouncementInPast
+		}
+		if req.ScheduledAt.After(now.Add(broadcastMaxLead)) {
+			return nil, dto.ErrAnnouncementTooFar
+		}
+		if req.ScheduledAt.After(now) {
+			scheduledAt = *req.ScheduledAt
+		}
+	}
+
+	announcement := &entity.ChatAnnouncement{
+		RoomID:      roomID,
+		CreatedBy:   userUUID,
+		Title:       strings.TrimSpace(req.Title),
+		Body:        strings.TrimSpace(req.Body),
+		SendPush:    req.SendPush,
+		SendEmail:   req.SendEmail,
+		ScheduledAt: scheduledAt,
+	}
+	if link := strings.TrimSpace(req.Link); link != "" {
+		announcement.Link = &link
+	}
+	if announcement.Title == "" || announcement.Body == "" {
+		return nil, dto.ErrChatMessageEmpty
+	}
+
+	if err := s.broadcastRepo.CreateAnnouncement(subCtx, announcement); err != nil {
+		return nil, err
+	}
+
+	err = s.asynq.EnqueueTaskAt(
+		dto.TaskBroadcastAnnouncement,
+		dto.BroadcastAnnouncementTask{AnnouncementID: announcement.ID},
+		scheduledAt,
+		asynq.TaskID(announcement.ID.String()),
+		asynq.Queue(broadcastQueue),
+		asynq.MaxRetry(broadcastMaxRetry),
+	)
+	if err != nil {
+		if failErr := s.broadcastRepo.FailAnnouncement(subCtx, announcement.ID, err.Error()); failErr != nil {
+			s.logger.Warn("[BroadcastService.ScheduleAnnouncement] failed to mark announcement failed", zap.Error(failErr))
+		}
+		return nil, err
+	}
+	return announcement, nil
+}
+
+func (s *BroadcastService) ListAnnouncements(ctx context.Context, roomID uuid.UUID, filter entity.AnnouncementFilter) ([]*entity.ChatAnnouncement, dto.Pagination, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if _, err := s.channel(subCtx, roomID); err != nil {
+		return nil, dto.Pagination{}, err
+	}
+
+	filter.SetDefaults()
+	announcements, total, err := s.broadcastRepo.ListAnnouncements(subCtx, roomID, filter)
+	if err != nil {
+		return nil, dto.Pagination{}, err
+	}
+	return announcements, commentPagination(total, filter.Limit, filter.Offset), nil
+}
+
+// CancelAnnouncement stops a scheduled announcement. The queued task is removed as well; if
+// that fails the worker still skips it because the announcement is no longer scheduled.
+func (s *BroadcastService) CancelAnnouncement(ctx context.Context, id uuid.UUID) (*entity.ChatAnnouncement, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if err := s.broadcastRepo.CancelAnnouncement(subCtx, id); err != nil {
+		return nil, err
+	}
+	if err := s.asynq.DeleteTask(broadcastQueue, id.String()); err != nil {
+		s.logger.Warn("[BroadcastService.CancelAnnouncement] failed to delete queued task",
+			zap.String("announcement_id", id.String()),
+			zap.Error(err),
+		)
+	}
+	return s.broadcastRepo.FindAnnouncementByID(subCtx, id)
+}
+
+// RegisterDevice stores a push token for the platform and settings scope of the request.
+func (s *BroadcastService) RegisterDevice(ctx context.Context, userID string, platform string, scope string, req *dto.DeviceRegisterRequest) (*entity.UserDevice, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	if platform != entity.DevicePlatformWeb && platform != entity.DevicePlatformMobile {
+		return nil, dto.ErrDevicePlatformRequired
+	}
+	if scope == "" {
+		scope = "default"
+	}
+
+	device := &entity.UserDevice{
+		UserID:   userUUID,
+		Token:    strings.TrimSpace(req.Token),
+		Platform: platform,
+		Scope:    scope,
+	}
+	if err := s.broadcastRepo.UpsertDevice(subCtx, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *BroadcastService) UnregisterDevice(ctx context.Context, userID string, req *dto.DeviceRegisterRequest) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+	return s.broadcastRepo.DeleteDevice(subCtx, userUUID, strings.TrimSpace(req.Token))
+}
+
+// DeliverAnnouncement posts a due announcement into its channel and fans it out. It is run by
+// the worker; announcements that were cancelled or already delivered are skipped. A failed
+// post is handed back for retry until the last attempt, which marks it failed.
+func (s *BroadcastService) DeliverAnnouncement(ctx context.Context, id uuid.UUID, lastAttempt bool) error {
+	announcement, err := s.broadcastRepo.ClaimAnnouncement(ctx, id)
+	if errors.Is(err, dto.ErrAnnouncementNotScheduled) {
+		s.logger.Info("[BroadcastService.DeliverAnnouncement] announcement no longer scheduled, skipping",
+			zap.String("announcement_id", id.String()),
+		)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	room, err := s.chatRoomRepo.FindRoomByID(ctx, announcement.RoomID)
+	if errors.Is(err, dto.ErrChatRoomNotFound) {
+		return s.broadcastRepo.FailAnnouncement(ctx, id, "broadcast channel no longer exists")
+	}
+	if err == nil {
+		err = s.postAnnouncement(ctx, announcement)
+	}
+	if err != nil {
+		if lastAttempt {
+			if failErr := s.broadcastRepo.FailAnnouncement(ctx, id, err.Error()); failErr != nil {
+				s.logger.Warn("[BroadcastService.DeliverAnnouncement] failed to mark announcement failed", zap.Error(failErr))
+			}
+		} else if releaseErr := s.broadcastRepo.ReleaseAnnouncement(ctx, id); releaseErr != nil {
+			s.logger.Warn("[BroadcastService.DeliverAnnouncement] failed to release announcement", zap.Error(releaseErr))
+		}
+		return err
+	}
+
+	target := broadcastTarget(room)
+	if announcement.SendPush {
+		announcement.PushCount = s.pushAnnouncement(ctx, announcement, target)
+	}
+	if announcement.SendEmail {
+		announcement.EmailCount = s.emailAnnouncement(ctx, announcement, target)
+	}
+
+	// The message is out; a retry would find the announcement claimed and do nothing, so a
+	// bookkeeping failure is only logged.
+	if err := s.broadcastRepo.CompleteAnnouncement(ctx, announcement); err != nil {
+		s.logger.Error("[BroadcastService.DeliverAnnouncement] failed to complete announcement",
+			zap.String("announcement_id", id.String()),
+			zap.Error(err),
+		)
+	}
+	return nil
+}
+
+func (s *BroadcastService) postAnnouncement(ctx context.Context, announcement *entity.ChatAnnouncement) error {
+	metadata := map[string]interface{}{
+		"announcement_id": announcement.ID,
+		"title":           announcement.Title,
+	}
+	if announcement.Link != nil {
+		metadata["link"] = *announcement.Link
+	}
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal announcement metadata: %w", err)
+	}
+
+	text := announcement.Title + "\n\n" + announcement.Body
+	message := &entity.Chat{
+		RoomID:   announcement.RoomID,
+		SenderID: announcement.CreatedBy,
+		Message:  &text,
+		Type:     entity.ChatMessageTypeText,
+		Metadata: raw,
+	}
+	if err := s.chatRoomRepo.CreateMessage(ctx, message); err != nil {
+		return err
+	}
+	s.chat.broadcastMessage(ctx, message)
+	announcement.MessageID = &message.ID
+	return nil
+}
+
+// pushAnnouncement sends the announcement to every targeted device of unmuted subscribers and
+// forgets tokens FCM no longer knows. It returns the number of delivered notifications.
+func (s *BroadcastService) pushAnnouncement(ctx context.Context, announcement *entity.ChatAnnouncement, target entity.BroadcastTarget) int {
+	if s.firebase == nil || s.firebase.MessagingClient == nil {
+		s.logger.Warn("[BroadcastService.pushAnnouncement] push is not configured, skipping",
+			zap.String("announcement_id", announcement.ID.String()),
+		)
+		return 0
+	}
+
+	data := map[string]string{
+		"type":            "announcement",
+		"announcement_id": announcement.ID.String(),
+		"room_id":         announcement.RoomID.String(),
+	}
+	if announcement.Link != nil {
+		data["link"] = *announcement.Link
+	}
+
+	sent := 0
+	after := uuid.Nil
+	for ctx.Err() == nil {
+		devices, err := s.broadcastRepo.ListPushDevices(ctx, announcement.RoomID, target, after, firebaseclient.MaxMulticastTokens)
+		if err != nil {
+			s.logger.Warn("[BroadcastService.pushAnnouncement] failed to list devices", zap.Error(err))
+			break
+		}
+		if len(devices) == 0 {
+			break
+		}
+		after = devices[len(devices)-1].ID
+
+		tokens := make([]string, 0, len(devices))
+		for _, device := range devices {
+			tokens = append(tokens, device.Token)
+		}
+		delivered, stale, err := s.firebase.SendMulticast(ctx, tokens, announcement.Title, announcement.Body, data)
+		if err != nil {
+			s.logger.Warn("[BroadcastService.pushAnnouncement] failed to send batch",
+				zap.String("announcement_id", announcement.ID.String()),
+				zap.Error(err),
+			)
+		}
+		sent += delivered
+		if err := s.broadcastRepo.DeleteDeviceTokens(ctx, stale); err != nil {
+			s.logger.Warn("[BroadcastService.pushAnnouncement] failed to prune stale tokens", zap.Error(err))
+		}
+		if len(devices) < firebaseclient.MaxMulticastTokens {
+			break
+		}
+	}
+	return sent
+}
+
+// emailAnnouncement mails the announcement to verified subscribers who have not muted the
+// channel. It returns the number of emails sent.
+func (s *BroadcastService) emailAnnouncement(ctx context.Context, announcement *entity.ChatAnnouncement, target entity.BroadcastTarget) int {
+	link := ""
+	if announcement.Link != nil {
+		link = *announcement.Link
+	}
+
+	sent := 0
+	after := uuid.Nil
+	for ctx.Err() == nil {
+		recipients, err := s.broadcastRepo.ListEmailRecipients(ctx, announcement.RoomID, target, after, broadcastEmailBatchSize)
+		if err != nil {
+			s.logger.Warn("[BroadcastService.emailAnnouncement] failed to list recipients", zap.Error(err))
+			break
+		}
+		if len(recipients) == 0 {
+			break
+		}
+		after = recipients[len(recipients)-1].UserID
+
+		for _, recipient := range recipients {
+			if ctx.Err() != nil {
+				break
+			}
+			err := s.mail.SendEmail(&dto.SendMailMetaData{
+				Type:         dto.BroadcastAnnouncementNotice,
+				To:           recipient.Email,
+				User:         &entity.User{ID: recipient.UserID, Email: recipient.Email, FullName: recipient.FullName},
+				Announcement: announcement,
+				Link:         link,
+			}, s.appConfig.ClientUrl)
+			if err != nil {
+				s.logger.Warn("[BroadcastService.emailAnnouncement] failed to send email",
+					zap.String("user_id", recipient.UserID.String()),
+					zap.Error(err),
+				)
+				continue
+			}
+			sent++
+		}
+		if len(recipients) < broadcastEmailBatchSize {
+			break
+		}
+	}
+	return sent
+}
+
+func (s *BroadcastService) channel(ctx context.Context, roomID uuid.UUID) (*entity.ChatRoom, error) {
+	room, err := s.chatRoomRepo.FindRoomByID(ctx, roomID)
+	if errors.Is(err, dto.ErrChatRoomNotFound) || (err == nil && !room.IsChannel()) {
+		return nil, dto.ErrBroadcastChannelNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+func broadcastTarget(room *entity.ChatRoom) entity.BroadcastTarget {
+	var target entity.BroadcastTarget
+	if len(room.Metadata) > 0 {
+		_ = json.Unmarshal(room.Metadata, &target)
+	}
+	return target
+}
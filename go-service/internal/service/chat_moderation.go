@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const chatSlowModeKey = "chat:slowmode:%s:%s"
+
+// KickParticipant removes a lower-ranked member from a group, channel or watch party. Unlike a
+// ban, the user may be added again straight away.
+func (s *ChatService) KickParticipant(ctx context.Context, userID string, roomID uuid.UUID, targetID uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	actor, target, err := s.moderationTarget(subCtx, userID, roomID, targetID)
+	if err != nil {
+		return err
+	}
+	if err := s.chatRoomRepo.RemoveParticipant(subCtx, roomID, targetID); err != nil {
+		return err
+	}
+
+	s.postSystemMessage(subCtx, roomID, actor.UserID,
+		fmt.Sprintf("%s removed %s", actor.FullName, target.FullName),
+		map[string]interface{}{"action": dto.ChatEventParticipantKicked, "user_id": targetID},
+	)
+	s.publishToUser(subCtx, targetID, dto.ChatEventRoomLeft, roomID)
+	return nil
+}
+
+// BanUser removes a user from the room and keeps them out until the ban expires. Users who are
+// not members can be banned pre-emptively.
+func (s *ChatService) BanUser(ctx context.Context, userID string, roomID uuid.UUID, targetID uuid.UUID, req *dto.ChatBanRequest) (*entity.ChatRoomBan, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	name := ""
+	wasMember := true
+	actor, target, err := s.moderationTarget(subCtx, userID, roomID, targetID)
+	switch {
+	case err == nil:
+		name = target.FullName
+	case errors.Is(err, dto.ErrChatParticipantMissing) && actor != nil:
+		user, err := s.userRepo.FindByID(subCtx, targetID)
+		if err != nil {
+			return nil, dto.ErrChatInvalidRecipient
+		}
+		name = user.FullName
+		wasMember = false
+	default:
+		return nil, err
+	}
+
+	ban := &entity.ChatRoomBan{
+		RoomID:   roomID,
+		UserID:   targetID,
+		BannedBy: &actor.UserID,
+		FullName: name,
+	}
+	if reason := strings.TrimSpace(req.Reason); reason != "" {
+		ban.Reason = &reason
+	}
+	text := fmt.Sprintf("%s banned %s", actor.FullName, name)
+	if req.DurationMinutes != nil {
+		expiresAt := time.Now().UTC().Add(time.Duration(*req.DurationMinutes) * time.Minute)
+		ban.ExpiresAt = &expiresAt
+		text = fmt.Sprintf("%s for %s", text, chatDurationText(*req.DurationMinutes))
+	}
+	if err := s.chatRoomRepo.BanUser(subCtx, ban); err != nil {
+		return nil, err
+	}
+
+	s.postSystemMessage(subCtx, roomID, actor.UserID, text,
+		map[string]interface{}{"action": dto.ChatEventParticipantBanned, "user_id": targetID, "expires_at": ban.ExpiresAt},
+	)
+	if wasMember {
+		s.publishToUser(subCtx, targetID, dto.ChatEventRoomLeft, roomID)
+	}
+	return ban, nil
+}
+
+func (s *ChatService) UnbanUser(ctx context.Context, userID string, roomID uuid.UUID, targetID uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	actor, err := s.moderator(subCtx, userID, roomID)
+	if err != nil {
+		return err
+	}
+	if err := s.chatRoomRepo.UnbanUser(subCtx, roomID, targetID); err != nil {
+		return err
+	}
+
+	name := "a user"
+	if user, err := s.userRepo.FindByID(subCtx, targetID); err == nil {
+		name = user.FullName
+	}
+	s.postSystemMessage(subCtx, roomID, actor.UserID,
+		fmt.Sprintf("%s unbanned %s", actor.FullName, name),
+		map[string]interface{}{"action": dto.ChatEventParticipantUnbanned, "user_id": targetID},
+	)
+	return nil
+}
+
+func (s *ChatService) ListBans(ctx context.Context, userID string, roomID uuid.UUID) ([]*entity.ChatRoomBan, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if _, err := s.moderator(subCtx, userID, roomID); err != nil {
+		return nil, err
+	}
+	return s.chatRoomRepo.ListBans(subCtx, roomID)
+}
+
+// MuteParticipant stops a lower-ranked member from posting for a while. They stay in the room
+// and keep reading.
+func (s *ChatService) MuteParticipant(ctx context.Context, userID string, roomID uuid.UUID, targetID uuid.UUID, req *dto.ChatMuteRequest) (*entity.ChatParticipant, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	actor, target, err := s.moderationTarget(subCtx, userID, roomID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	until := time.Now().UTC().Add(time.Duration(req.DurationMinutes) * time.Minute)
+	if err := s.chatRoomRepo.SetParticipantMute(subCtx, roomID, targetID, &until); err != nil {
+		return nil, err
+	}
+	target.MutedUntil = &until
+
+	s.postSystemMessage(subCtx, roomID, actor.UserID,
+		fmt.Sprintf("%s muted %s for %s", actor.FullName, target.FullName, chatDurationText(req.DurationMinutes)),
+		map[string]interface{}{"action": dto.ChatEventParticipantMuted, "user_id": targetID, "muted_until": until},
+	)
+	return target, nil
+}
+
+func (s *ChatService) UnmuteParticipant(ctx context.Context, userID string, roomID uuid.UUID, targetID uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	actor, target, err := s.moderationTarget(subCtx, userID, roomID, targetID)
+	if err != nil {
+		return err
+	}
+	if !target.IsSilenced(time.Now()) {
+		return nil
+	}
+	if err := s.chatRoomRepo.SetParticipantMute(subCtx, roomID, targetID, nil); err != nil {
+		return err
+	}
+
+	s.postSystemMessage(subCtx, roomID, actor.UserID,
+		fmt.Sprintf("%s unmuted %s", actor.FullName, target.FullName),
+		map[string]interface{}{"action": dto.ChatEventParticipantUnmuted, "user_id": targetID},
+	)
+	return nil
+}
+
+// UpdateParticipantRole promotes or demotes a member. Only admins may change roles, and admins
+// cannot change each other's.
+func (s *ChatService) UpdateParticipantRole(ctx context.Context, userID string, roomID uuid.UUID, targetID uuid.UUID, req *dto.ChatRoleRequest) (*entity.ChatParticipant, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	actor, target, err := s.moderationTarget(subCtx, userID, roomID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsAdmin() {
+		return nil, dto.ErrChatRoleNotAllowed
+	}
+	if target.Role == req.Role {
+		return target, nil
+	}
+	if err := s.chatRoomRepo.UpdateParticipantRole(subCtx, roomID, targetID, req.Role); err != nil {
+		return nil, err
+	}
+
+	verb := "promoted"
+	if chatRoleRank(req.Role) < chatRoleRank(target.Role) {
+		verb = "demoted"
+	}
+	s.postSystemMessage(subCtx, roomID, actor.UserID,
+		fmt.Sprintf("%s %s %s to %s", actor.FullName, verb, target.FullName, req.Role),
+		map[string]interface{}{"action": dto.ChatEventRoleChanged, "user_id": targetID, "role": req.Role, "previous_role": target.Role},
+	)
+	target.Role = req.Role
+	return target, nil
+}
+
+// SetSlowMode sets the minimum number of seconds between messages from one member. Admins and
+// moderators are exempt.
+func (s *ChatService) SetSlowMode(ctx context.Context, userID string, roomID uuid.UUID, req *dto.ChatSlowModeRequest) (*entity.ChatRoom, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	actor, err := s.moderator(subCtx, userID, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.chatRoomRepo.UpdateSlowMode(subCtx, roomID, *req.Seconds); err != nil {
+		return nil, err
+	}
+
+	text := fmt.Sprintf("%s turned off slow mode", actor.FullName)
+	if *req.Seconds > 0 {
+		text = fmt.Sprintf("%s turned on slow mode: one message every %d seconds", actor.FullName, *req.Seconds)
+	}
+	s.postSystemMessage(subCtx, roomID, actor.UserID, text,
+		map[string]interface{}{"action": dto.ChatEventSlowModeChanged, "seconds": *req.Seconds},
+	)
+	return s.loadRoom(subCtx, roomID)
+}
+
+// checkPostingAllowed rejects messages from muted members and, in slow mode, members who posted
+// too recently. Slow mode fails open when Redis is unavailable.
+func (s *ChatService) checkPostingAllowed(ctx context.Context, room *entity.ChatRoom, sender *entity.ChatParticipant) error {
+	if sender.IsSilenced(time.Now()) {
+		return dto.ErrChatMemberMuted
+	}
+	if room.SlowModeSeconds <= 0 || sender.CanModerate() {
+		return nil
+	}
+
+	key := fmt.Sprintf(chatSlowModeKey, room.ID, sender.UserID)
+	acquired, err := s.redis.Client().SetNX(ctx, key, 1, time.Duration(room.SlowModeSeconds)*time.Second).Result()
+	if err != nil {
+		s.logger.Warn("[ChatService.checkPostingAllowed] failed to check slow mode", zap.Error(err))
+		return nil
+	}
+	if !acquired {
+		return dto.ErrChatSlowMode
+	}
+	return nil
+}
+
+// releaseSlowMode gives a slow mode slot back when the message it was taken for was not stored.
+func (s *ChatService) releaseSlowMode(ctx context.Context, room *entity.ChatRoom, sender *entity.ChatParticipant) {
+	if room.SlowModeSeconds <= 0 || sender.CanModerate() {
+		return
+	}
+	if err := s.redis.Client().Del(ctx, fmt.Sprintf(chatSlowModeKey, room.ID, sender.UserID)).Err(); err != nil {
+		s.logger.Warn("[ChatService.releaseSlowMode] failed to release slow mode slot", zap.Error(err))
+	}
+}
+
+// moderator returns the caller's membership when they may moderate the room.
+func (s *ChatService) moderator(ctx context.Context, userID string, roomID uuid.UUID) (*entity.ChatParticipant, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	room, actor, err := s.roomForParticipant(ctx, roomID, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	if room.IsPersonal() {
+		return nil, dto.ErrChatNotModeratable
+	}
+	if !actor.CanModerate() {
+		return nil, dto.ErrPermissionDenied
+	}
+	return actor, nil
+}
+
+// moderationTarget loads a moderator and the member they act on. The actor is still returned
+// with ErrChatParticipantMissing so bans can target non-members.
+func (s *ChatService) moderationTarget(ctx context.Context, userID string, roomID uuid.UUID, targetID uuid.UUID) (*entity.ChatParticipant, *entity.ChatParticipant, error) {
+	actor, err := s.moderator(ctx, userID, roomID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if actor.UserID == targetID {
+		return nil, nil, dto.ErrChatSelfModeration
+	}
+	target, err := s.chatRoomRepo.FindParticipant(ctx, roomID, targetID)
+	if err != nil {
+		if errors.Is(err, dto.ErrChatNotParticipant) {
+			return actor, nil, dto.ErrChatParticipantMissing
+		}
+		return nil, nil, err
+	}
+	if !outranks(actor, target) {
+		return nil, nil, dto.ErrPermissionDenied
+	}
+	return actor, target, nil
+}
+
+func chatRoleRank(role string) int {
+	switch role {
+	case entity.ChatRoleAdmin:
+		return 2
+	case entity.ChatRoleModerator:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func chatDurationText(minutes int) string {
+	unit, value := "minute", minutes
+	switch {
+	case minutes%1440 == 0:
+		unit, value = "day", minutes/1440
+	case minutes%60 == 0:
+		unit, value = "hour", minutes/60
+	}
+	if value != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", value, unit)
+}
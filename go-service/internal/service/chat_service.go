@@ -0,0 +1,1365 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"tubexxi/video-api/config"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/centrifugo"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+	minioclient "tubexxi/video-api/internal/infrastructure/minio-client"
+	redisclient "tubexxi/video-api/internal/infrastructure/redis-client"
+	"tubexxi/video-api/internal/infrastructure/repository"
+	"tubexxi/video-api/pkg/utils"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxChatMessageLength = 4000
+	// chatTypingThrottle is how often a user's "typing" state is re-broadcast to a room.
+	chatTypingThrottle = 3 * time.Second
+)
+
+type ChatService struct {
+	chatRoomRepo repository.ChatRoomRepository
+	userRepo     repository.UserRepository
+	centrifugo   *centrifugo.CentrifugoClient
+	redis        *redisclient.RedisClient
+	minio        *minioclient.MinioClient
+	cfg          *config.Config
+	logger       *zap.Logger
+}
+
+func NewChatService(
+	chatRoomRepo repository.ChatRoomRepository,
+	userRepo repository.UserRepository,
+	centrifugo *centrifugo.CentrifugoClient,
+	redis *redisclient.RedisClient,
+	minio *minioclient.MinioClient,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *ChatService {
+	return &ChatService{
+		chatRoomRepo: chatRoomRepo,
+		userRepo:     userRepo,
+		centrifugo:   centrifugo,
+		redis:        redis,
+		minio:        minio,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+// CreateRoom opens a personal room with one other user, or a group owned by the caller. Personal
+// rooms are reused when the two users already share one. Broadcast channels are opened by admins
+// through BroadcastService.
+func (s *ChatService) CreateRoom(ctx context.Context, userID string, req *dto.ChatRoomCreateRequest) (*entity.ChatRoom, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	others := make([]uuid.UUID, 0, len(req.ParticipantIDs))
+	seen := map[uuid.UUID]bool{userUUID: true}
+	for _, id := range req.ParticipantIDs {
+		if !seen[id] {
+			seen[id] = true
+			others = append(others, id)
+		}
+	}
+
+	if req.Type == entity.ChatRoomTypePersonal {
+		if len(others) != 1 {
+			return nil, dto.ErrChatPersonalRoomSize
+		}
+		roomID, err := s.personalRoom(subCtx, userUUID, others[0])
+		if err != nil {
+			return nil, err
+		}
+		return s.loadRoom(subCtx, roomID)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, dto.ErrChatRoomNameRequired
+	}
+	room := &entity.ChatRoom{
+		Name:      &name,
+		Type:      req.Type,
+		CreatedBy: userUUID,
+	}
+	if description := strings.TrimSpace(req.Description); description != "" {
+		room.Description = &description
+	}
+	if avatar := strings.TrimSpace(req.AvatarURL); avatar != "" {
+		room.AvatarURL = &avatar
+	}
+
+	participants := []*entity.ChatParticipant{{UserID: userUUID, Role: entity.ChatRoleAdmin}}
+	for _, id := range others {
+		participants = append(participants, &entity.ChatParticipant{UserID: id, Role: entity.ChatRoleMember})
+	}
+	if err := s.chatRoomRepo.CreateRoom(subCtx, room, participants); err != nil {
+		return nil, err
+	}
+
+	for _, participant := range participants[1:] {
+		if participant.ID != uuid.Nil {
+			s.publishToUser(subCtx, participant.UserID, dto.ChatEventRoomJoined, room.ID)
+		}
+	}
+	return s.loadRoom(subCtx, room.ID)
+}
+
+// GetRoom returns a room and its participants to one of its members.
+func (s *ChatService) GetRoom(ctx context.Context, userID string, roomID uuid.UUID) (*entity.ChatRoom, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	if _, err := s.chatRoomRepo.FindParticipant(subCtx, roomID, userUUID); err != nil {
+		return nil, err
+	}
+	return s.loadRoom(subCtx, roomID)
+}
+
+// AddParticipants lets admins and moderators add users to a group. Only admins may grant the
+// moderator or admin role. Broadcast channels subscribe everyone on their own.
+func (s *ChatService) AddParticipants(ctx context.Context, userID string, roomID uuid.UUID, req *dto.ChatParticipantsRequest) (*dto.ChatParticipantsResult, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	room, actor, err := s.roomForParticipant(subCtx, roomID, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	if room.IsPersonal() {
+		return nil, dto.ErrChatPersonalRoomFixed
+	}
+	if room.IsChannel() {
+		return nil, dto.ErrChatChannelMembership
+	}
+	if !actor.CanModerate() {
+		return nil, dto.ErrPermissionDenied
+	}
+
+	role := req.Role
+	if role == "" {
+		role = entity.ChatRoleMember
+	}
+	if role != entity.ChatRoleMember && !actor.IsAdmin() {
+		return nil, dto.ErrChatRoleNotAllowed
+	}
+
+	added, err := s.chatRoomRepo.AddParticipants(subCtx, roomID, req.UserIDs, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(added) > 0 {
+		names := s.participantNames(subCtx, roomID, added)
+		s.postSystemMessage(subCtx, roomID, userUUID,
+			fmt.Sprintf("%s added %s", actor.FullName, strings.Join(names, ", ")),
+			map[string]interface{}{"action": dto.ChatEventParticipantsAdded, "user_ids": added},
+		)
+		for _, id := range added {
+			s.publishToUser(subCtx, id, dto.ChatEventRoomJoined, roomID)
+		}
+	}
+	return &dto.ChatParticipantsResult{RoomID: roomID, Added: added}, nil
+}
+
+// RemoveParticipant removes a member from a group. Anyone may leave; removing someone else needs
+// a role above theirs. Broadcast channels cannot be left, only muted.
+func (s *ChatService) RemoveParticipant(ctx context.Context, userID string, roomID uuid.UUID, targetID uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	room, actor, err := s.roomForParticipant(subCtx, roomID, userUUID)
+	if err != nil {
+		return err
+	}
+	if room.IsPersonal() {
+		return dto.ErrChatPersonalRoomFixed
+	}
+	if room.IsChannel() {
+		return dto.ErrChatChannelMembership
+	}
+
+	text := fmt.Sprintf("%s left", actor.FullName)
+	if targetID != userUUID {
+		target, err := s.chatRoomRepo.FindParticipant(subCtx, roomID, targetID)
+		if err != nil {
+			return dto.ErrChatParticipantMissing
+		}
+		if !outranks(actor, target) {
+			return dto.ErrPermissionDenied
+		}
+		text = fmt.Sprintf("%s removed %s", actor.FullName, target.FullName)
+	}
+
+	if err := s.chatRoomRepo.RemoveParticipant(subCtx, roomID, targetID); err != nil {
+		return err
+	}
+
+	s.postSystemMessage(subCtx, roomID, userUUID, text,
+		map[string]interface{}{"action": dto.ChatEventParticipantRemoved, "user_id": targetID},
+	)
+	s.publishToUser(subCtx, targetID, dto.ChatEventRoomLeft, roomID)
+	return nil
+}
+
+// SendMessage stores a message and broadcasts it to the room.
+func (s *ChatService) SendMessage(ctx context.Context, userID string, req *entity.ChatCreateRequest) (*entity.Chat, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	message, err := s.CreateMessage(subCtx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	s.broadcastMessage(subCtx, message)
+	return message, nil
+}
+
+// CreateMessage validates and stores a message without broadcasting it. The Centrifugo publish
+// proxy uses it directly because Centrifugo fans out the returned publication itself.
+func (s *ChatService) CreateMessage(ctx context.Context, userID string, req *entity.ChatCreateRequest) (*entity.Chat, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	roomID, err := s.targetRoom(subCtx, userUUID, req)
+	if err != nil {
+		return nil, err
+	}
+	room, sender, err := s.roomForParticipant(subCtx, roomID, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	if room.IsChannel() && !sender.CanModerate() {
+		return nil, dto.ErrChatPostForbidden
+	}
+	if room.IsPersonal() {
+		if err := s.ensureNotBlocked(subCtx, roomID, userUUID); err != nil {
+			return nil, err
+		}
+	}
+
+	message := &entity.Chat{RoomID: roomID, SenderID: userUUID}
+	var media *entity.ChatMedia
+	switch {
+	case req.ForwardedFromID != nil:
+		err = s.applyForward(subCtx, userUUID, message, *req.ForwardedFromID)
+	case isChatMediaType(req.Type):
+		if media, err = s.pendingMedia(subCtx, userUUID, roomID, req); err == nil {
+			err = applyMedia(message, media, req.Message)
+		}
+	default:
+		err = applyMessageContent(message, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ReplyToID != nil {
+		parent, err := s.chatRoomRepo.FindMessageByID(subCtx, *req.ReplyToID)
+		if err != nil || parent.RoomID != roomID || parent.IsDeleted || (parent.IsHidden && parent.SenderID != userUUID) {
+			return nil, dto.ErrChatInvalidReply
+		}
+		message.ReplyToID = &parent.ID
+		message.ReplyTo = parent
+	}
+
+	if err := s.checkPostingAllowed(subCtx, room, sender); err != nil {
+		return nil, err
+	}
+	if media != nil {
+		err = s.chatRoomRepo.CreateMessageWithMedia(subCtx, message, media.ID)
+	} else {
+		err = s.chatRoomRepo.CreateMessage(subCtx, message)
+	}
+	if err != nil {
+		s.releaseSlowMode(subCtx, room, sender)
+		return nil, err
+	}
+	message.IsSentByMe = true
+	message.SenderName = sender.FullName
+	message.SenderAvatar = sender.AvatarURL
+	return message, nil
+}
+
+// EditMessage replaces the text of the caller's own text message.
+func (s *ChatService) EditMessage(ctx context.Context, userID string, messageID uuid.UUID, req *entity.ChatUpdateRequest) (*entity.Chat, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	message, err := s.chatRoomRepo.FindMessageByID(subCtx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.chatRoomRepo.FindParticipant(subCtx, message.RoomID, userUUID); err != nil {
+		return nil, err
+	}
+	if message.IsDeleted {
+		return nil, dto.ErrChatMessageDeleted
+	}
+	if message.SenderID != userUUID || message.Type != entity.ChatMessageTypeText || message.ForwardedFromID != nil {
+		return nil, dto.ErrChatEditForbidden
+	}
+
+	text, err := normaliseChatText(req.Message, true)
+	if err != nil {
+		return nil, err
+	}
+	message.Message = text
+	if err := s.chatRoomRepo.UpdateMessageText(subCtx, message); err != nil {
+		return nil, err
+	}
+	message.IsSentByMe = true
+
+	s.publishRoomEvent(subCtx, message.RoomID, map[string]interface{}{
+		"type":    dto.ChatEventMessageUpdated,
+		"message": chatMessagePayload(message),
+	})
+	return message, nil
+}
+
+// DeleteMessage soft-deletes a message. Senders delete their own; admins and moderators any.
+func (s *ChatService) DeleteMessage(ctx context.Context, userID string, messageID uuid.UUID) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	message, err := s.chatRoomRepo.FindMessageByID(subCtx, messageID)
+	if err != nil {
+		return err
+	}
+	participant, err := s.chatRoomRepo.FindParticipant(subCtx, message.RoomID, userUUID)
+	if err != nil {
+		return err
+	}
+	if message.IsDeleted {
+		return dto.ErrChatMessageDeleted
+	}
+	isOwn := message.SenderID == userUUID && !message.IsSystemMessage()
+	if !isOwn && !participant.CanModerate() {
+		return dto.ErrPermissionDenied
+	}
+
+	if err := s.chatRoomRepo.SoftDeleteMessage(subCtx, messageID); err != nil {
+		return err
+	}
+	s.publishRoomEvent(subCtx, message.RoomID, map[string]interface{}{
+		"type":       dto.ChatEventMessageDeleted,
+		"message_id": messageID.String(),
+	})
+	return nil
+}
+
+// UploadMedia stores an attachment for a later message in the room. The type, MIME type, size,
+// image dimensions and thumbnail all come from the uploaded bytes rather than the client.
+func (s *ChatService) UploadMedia(ctx context.Context, userID string, roomID uuid.UUID, file *multipart.FileHeader) (*entity.ChatMedia, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 30*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	room, participant, err := s.roomForParticipant(subCtx, roomID, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	if room.IsChannel() && !participant.CanModerate() {
+		return nil, dto.ErrChatPostForbidden
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload: %w", err)
+	}
+	defer src.Close()
+
+	limits := &s.cfg.Chat
+	maxSize := max(limits.MaxImageSize, limits.MaxVideoSize, limits.MaxAudioSize, limits.MaxFileSize)
+	data, err := io.ReadAll(io.LimitReader(src, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, dto.ErrChatMediaEmpty
+	}
+
+	mimeType := http.DetectContentType(data)
+	mediaType := chatMediaTypeFor(mimeType)
+	if int64(len(data)) > chatMediaSizeLimit(limits, mediaType) {
+		return nil, dto.ErrChatMediaTooLarge
+	}
+
+	mediaID := uuid.New()
+	name := chatMediaName(file.Filename)
+	media := &entity.ChatMedia{
+		RoomID:     roomID,
+		UploaderID: userUUID,
+		ObjectKey:  fmt.Sprintf("chat/%s/%s%s", roomID, mediaID, chatMediaExtension(name)),
+		Type:       mediaType,
+		Name:       name,
+		Size:       int64(len(data)),
+		MimeType:   mimeType,
+	}
+
+	var thumbnail []byte
+	if mediaType == entity.ChatMessageTypeImage {
+		if width, height, err := utils.ImageDimensions(data); err == nil {
+			media.Width, media.Height = &width, &height
+		}
+		if thumbnail, err = utils.JPEGThumbnail(data, limits.ThumbnailMaxSide); err != nil {
+			s.logger.Debug("[ChatService.UploadMedia] thumbnail skipped", zap.String("mime_type", mimeType), zap.Error(err))
+			thumbnail = nil
+		}
+	}
+
+	bucket := s.cfg.MinIO.MinioBucketName
+	media.URL, err = s.minio.UploadFile(subCtx, bucket, media.ObjectKey, bytes.NewReader(data), media.Size, chatStoredContentType(mimeType))
+	if err != nil {
+		return nil, err
+	}
+	if thumbnail != nil {
+		thumbnailKey := fmt.Sprintf("chat/%s/%s_thumb.jpg", roomID, mediaID)
+		thumbnailURL, err := s.minio.UploadFile(subCtx, bucket, thumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg")
+		if err != nil {
+			s.logger.Warn("[ChatService.UploadMedia] failed to store thumbnail", zap.Error(err))
+		} else {
+			media.ThumbnailKey = &thumbnailKey
+			media.Thumbnail = &thumbnailURL
+		}
+	}
+
+	if err := s.chatRoomRepo.CreateMedia(subCtx, media); err != nil {
+		s.removeMediaObjects(subCtx, media)
+		return nil, err
+	}
+	return media, nil
+}
+
+// GetHistory pages through a room newest first using an opaque cursor.
+func (s *ChatService) GetHistory(ctx context.Context, userID string, roomID uuid.UUID, filter entity.ChatHistoryFilter) (*dto.ChatHistoryResponse, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	if _, err := s.chatRoomRepo.FindParticipant(subCtx, roomID, userUUID); err != nil {
+		return nil, err
+	}
+
+	filter.SetDefaults()
+	var before *entity.ChatCursor
+	if filter.Cursor != "" {
+		if before, err = decodeChatCursor(filter.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	messages, err := s.chatRoomRepo.ListMessages(subCtx, roomID, userUUID, before, filter.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.ChatHistoryResponse{Messages: messages}
+	if len(messages) > filter.Limit {
+		result.Messages = messages[:filter.Limit]
+		result.HasMore = true
+		last := result.Messages[len(result.Messages)-1]
+		cursor := encodeChatCursor(&entity.ChatCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		result.NextCursor = &cursor
+	}
+
+	if err := s.attachReplies(subCtx, userUUID, result.Messages); err != nil {
+		return nil, err
+	}
+	if err := s.attachReactions(subCtx, userUUID, result.Messages); err != nil {
+		return nil, err
+	}
+	for _, message := range result.Messages {
+		message.IsSentByMe = message.SenderID == userUUID
+		redactDeletedMessage(message)
+	}
+	return result, nil
+}
+
+// ListConversations returns the caller's inbox with the last visible message of each room.
+func (s *ChatService) ListConversations(ctx context.Context, userID string, filter entity.ConversationFilter) ([]*entity.Conversation, dto.Pagination, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, dto.Pagination{}, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	filter.SetDefaults()
+	conversations, total, err := s.chatRoomRepo.ListConversations(subCtx, userUUID, filter)
+	if err != nil {
+		return nil, dto.Pagination{}, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(conversations))
+	for _, conversation := range conversations {
+		if conversation.LastMessageID != nil {
+			ids = append(ids, *conversation.LastMessageID)
+		}
+	}
+	messages, err := s.chatRoomRepo.FindMessagesByIDs(subCtx, ids)
+	if err != nil {
+		return nil, dto.Pagination{}, err
+	}
+	byID := make(map[uuid.UUID]*entity.Chat, len(messages))
+	for _, message := range messages {
+		message.IsSentByMe = message.SenderID == userUUID
+		redactDeletedMessage(message)
+		byID[message.ID] = message
+	}
+	for _, conversation := range conversations {
+		if conversation.LastMessageID != nil {
+			conversation.LastMessage = byID[*conversation.LastMessageID]
+		}
+	}
+	return conversations, commentPagination(total, filter.Limit, filter.Offset), nil
+}
+
+// SearchMessages searches the caller's messages across their rooms. Narrowing to a room the
+// caller does not belong to is refused rather than silently returning nothing.
+func (s *ChatService) SearchMessages(ctx context.Context, userID string, filter entity.ChatFilter) ([]*entity.ChatSearchHit, dto.Pagination, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, dto.Pagination{}, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	filter.UserID = userUUID
+	filter.SetDefaults()
+
+	if filter.StartDate != nil && filter.EndDate != nil && filter.StartDate.After(*filter.EndDate) {
+		return nil, dto.Pagination{}, dto.ErrChatInvalidDateRange
+	}
+	if filter.GroupID != nil {
+		if _, err := s.chatRoomRepo.FindParticipant(subCtx, *filter.GroupID, userUUID); err != nil {
+			return nil, dto.Pagination{}, err
+		}
+	}
+
+	hits, total, err := s.chatRoomRepo.SearchMessages(subCtx, filter)
+	if err != nil {
+		return nil, dto.Pagination{}, err
+	}
+	for _, hit := range hits {
+		hit.Message.IsSentByMe = hit.Message.SenderID == userUUID
+	}
+	return hits, commentPagination(total, filter.Limit, filter.Offset), nil
+}
+
+// UpdatePreferences pins or mutes a room for the caller and syncs the change to their sessions.
+func (s *ChatService) UpdatePreferences(ctx context.Context, userID string, roomID uuid.UUID, req *dto.ChatRoomPreferencesRequest) (*entity.ChatParticipant, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	if req.IsPinned == nil && req.IsMuted == nil {
+		return nil, dto.ErrChatNoPreferences
+	}
+
+	participant, err := s.chatRoomRepo.UpdateParticipantPreferences(subCtx, roomID, userUUID, req.IsPinned, req.IsMuted)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.centrifugo.PublishToUser(subCtx, userID, map[string]interface{}{
+		"type":      dto.ChatEventPreferencesUpdated,
+		"room_id":   roomID.String(),
+		"is_pinned": participant.IsPinned,
+		"is_muted":  participant.IsMuted,
+	}); err != nil {
+		s.logger.Warn("[ChatService.UpdatePreferences] failed to sync preferences",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+	}
+	return participant, nil
+}
+
+// MarkDelivered records that the caller's client received messages and tells the room.
+func (s *ChatService) MarkDelivered(ctx context.Context, userID string, roomID uuid.UUID, req *dto.ChatReceiptRequest) (*dto.ChatReceiptResult, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	if _, err := s.chatRoomRepo.FindParticipant(subCtx, roomID, userUUID); err != nil {
+		return nil, err
+	}
+
+	delivered, err := s.chatRoomRepo.MarkMessagesDelivered(subCtx, roomID, userUUID, req.MessageIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(delivered) > 0 {
+		if err := s.centrifugo.BroadcastMessageDelivered(subCtx, roomID.String(), userID, uuidStrings(delivered)); err != nil {
+			s.logger.Warn("[ChatService.MarkDelivered] failed to broadcast delivery receipt",
+				zap.String("room_id", roomID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	unread, err := s.unreadCount(subCtx, userUUID, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ChatReceiptResult{RoomID: roomID, MessageIDs: delivered, UnreadCount: unread}, nil
+}
+
+// MarkRead records read receipts, advances the caller's read position and syncs the new unread
+// count to their other sessions.
+func (s *ChatService) MarkRead(ctx context.Context, userID string, roomID uuid.UUID, req *dto.ChatReceiptRequest) (*dto.ChatReceiptResult, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	if _, err := s.chatRoomRepo.FindParticipant(subCtx, roomID, userUUID); err != nil {
+		return nil, err
+	}
+
+	read, lastReadAt, err := s.chatRoomRepo.MarkMessagesRead(subCtx, roomID, userUUID, req.MessageIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(read) > 0 {
+		if err := s.centrifugo.BroadcastMessageRead(subCtx, roomID.String(), userID, uuidStrings(read)); err != nil {
+			s.logger.Warn("[ChatService.MarkRead] failed to broadcast read receipt",
+				zap.String("room_id", roomID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	unread, err := s.unreadCount(subCtx, userUUID, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.centrifugo.PublishToUser(subCtx, userID, map[string]interface{}{
+		"type":         dto.ChatEventUnreadUpdated,
+		"room_id":      roomID.String(),
+		"unread_count": unread,
+		"last_read_at": lastReadAt,
+	}); err != nil {
+		s.logger.Warn("[ChatService.MarkRead] failed to sync unread count",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+	}
+	return &dto.ChatReceiptResult{RoomID: roomID, MessageIDs: read, UnreadCount: unread, LastReadAt: &lastReadAt}, nil
+}
+
+// GetUnreadCounts returns the caller's unread count for every room they belong to.
+func (s *ChatService) GetUnreadCounts(ctx context.Context, userID string) (*dto.ChatUnreadResponse, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	counts, err := s.chatRoomRepo.CountUnread(subCtx, userUUID, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &dto.ChatUnreadResponse{Rooms: counts}
+	for _, count := range counts {
+		result.Total += count
+	}
+	return result, nil
+}
+
+// SetTyping relays the caller's typing state to the room. Starting to type is broadcast at most
+// once per chatTypingThrottle; stopping is always relayed and resets the throttle.
+func (s *ChatService) SetTyping(ctx context.Context, userID string, roomID uuid.UUID, isTyping bool) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 5*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	key := fmt.Sprintf("chat:typing:%s:%s", roomID, userUUID)
+	if isTyping {
+		acquired, err := s.redis.Client().SetNX(subCtx, key, 1, chatTypingThrottle).Result()
+		if err != nil {
+			s.logger.Warn("[ChatService.SetTyping] failed to check typing throttle", zap.Error(err))
+		} else if !acquired {
+			return nil
+		}
+	} else if err := s.redis.Client().Del(subCtx, key).Err(); err != nil {
+		s.logger.Warn("[ChatService.SetTyping] failed to reset typing throttle", zap.Error(err))
+	}
+
+	if _, err := s.chatRoomRepo.FindParticipant(subCtx, roomID, userUUID); err != nil {
+		return err
+	}
+	if err := s.centrifugo.BroadcastTypingIndicator(subCtx, roomID.String(), userID, isTyping); err != nil {
+		s.logger.Warn("[ChatService.SetTyping] failed to broadcast typing indicator",
+			zap.String("room_id", roomID.String()),
+			zap.Error(err),
+		)
+	}
+	return nil
+}
+
+func (s *ChatService) unreadCount(ctx context.Context, userID uuid.UUID, roomID uuid.UUID) (int64, error) {
+	counts, err := s.chatRoomRepo.CountUnread(ctx, userID, []uuid.UUID{roomID})
+	if err != nil {
+		return 0, err
+	}
+	return counts[roomID], nil
+}
+
+// ToggleReaction adds the caller's reaction to a visible message, or removes it when already
+// present, and broadcasts the new counts to the room.
+func (s *ChatService) ToggleReaction(ctx context.Context, userID string, messageID uuid.UUID, req *dto.ChatReactionRequest) (*dto.ChatReactionResult, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	reaction, ok := entity.NormalizeChatReaction(req.Reaction)
+	if !ok {
+		return nil, dto.ErrChatInvalidReaction
+	}
+
+	message, err := s.visibleMessage(subCtx, userUUID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	added, err := s.chatRoomRepo.ToggleReaction(subCtx, messageID, userUUID, reaction)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.chatRoomRepo.ListReactionSummaries(subCtx, []uuid.UUID{messageID}, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	eventType := dto.ChatEventReactionRemoved
+	if added {
+		eventType = dto.ChatEventReactionAdded
+	}
+	counts := make(map[string]int64, len(summaries[messageID]))
+	for _, summary := range summaries[messageID] {
+		counts[summary.ReactionType] = summary.Count
+	}
+	s.publishRoomEvent(subCtx, message.RoomID, map[string]interface{}{
+		"type":       eventType,
+		"message_id": messageID.String(),
+		"user_id":    userID,
+		"reaction":   reaction,
+		"counts":     counts,
+	})
+
+	summary := summaries[messageID]
+	if summary == nil {
+		summary = make([]*entity.ChatReactionSummary, 0)
+	}
+	return &dto.ChatReactionResult{MessageID: messageID, Reaction: reaction, Added: added, Summary: summary}, nil
+}
+
+// GetReactions lists who reacted to a message, newest first.
+func (s *ChatService) GetReactions(ctx context.Context, userID string, messageID uuid.UUID) ([]*entity.ChatReactor, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	if _, err := s.visibleMessage(subCtx, userUUID, messageID); err != nil {
+		return nil, err
+	}
+	return s.chatRoomRepo.ListReactors(subCtx, messageID, 200)
+}
+
+// visibleMessage loads a message the user can see and interact with: they belong to its room,
+// it is not deleted, and it is not hidden from them.
+func (s *ChatService) visibleMessage(ctx context.Context, userID uuid.UUID, messageID uuid.UUID) (*entity.Chat, error) {
+	message, err := s.chatRoomRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.chatRoomRepo.FindParticipant(ctx, message.RoomID, userID); err != nil {
+		return nil, err
+	}
+	if message.IsHidden && message.SenderID != userID {
+		return nil, dto.ErrChatMessageNotFound
+	}
+	if message.IsDeleted {
+		return nil, dto.ErrChatMessageDeleted
+	}
+	return message, nil
+}
+
+func (s *ChatService) attachReactions(ctx context.Context, viewerID uuid.UUID, messages []*entity.Chat) error {
+	ids := make([]uuid.UUID, 0, len(messages))
+	for _, message := range messages {
+		if !message.IsDeleted {
+			ids = append(ids, message.ID)
+		}
+	}
+	summaries, err := s.chatRoomRepo.ListReactionSummaries(ctx, ids, viewerID)
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		message.ReactionSummary = summaries[message.ID]
+		message.ReactionsCount = 0
+		for _, summary := range message.ReactionSummary {
+			message.ReactionsCount += summary.Count
+		}
+	}
+	return nil
+}
+
+func (s *ChatService) attachReplies(ctx context.Context, viewerID uuid.UUID, messages []*entity.Chat) error {
+	ids := make([]uuid.UUID, 0)
+	for _, message := range messages {
+		if message.ReplyToID != nil {
+			ids = append(ids, *message.ReplyToID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	parents, err := s.chatRoomRepo.FindMessagesByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uuid.UUID]*entity.Chat, len(parents))
+	for _, parent := range parents {
+		if parent.IsHidden && parent.SenderID != viewerID {
+			continue
+		}
+		redactDeletedMessage(parent)
+		byID[parent.ID] = parent
+	}
+	for _, message := range messages {
+		if message.ReplyToID != nil {
+			message.ReplyTo = byID[*message.ReplyToID]
+		}
+	}
+	return nil
+}
+
+// targetRoom resolves group_id to a room, or receiver_id to the personal room with that user.
+func (s *ChatService) targetRoom(ctx context.Context, userID uuid.UUID, req *entity.ChatCreateRequest) (uuid.UUID, error) {
+	switch {
+	case req.GroupID != nil && req.ReceiverID == nil:
+		return *req.GroupID, nil
+	case req.ReceiverID != nil && req.GroupID == nil:
+		return s.personalRoom(ctx, userID, *req.ReceiverID)
+	default:
+		return uuid.Nil, dto.ErrChatRoomRequired
+	}
+}
+
+func (s *ChatService) personalRoom(ctx context.Context, userID uuid.UUID, otherID uuid.UUID) (uuid.UUID, error) {
+	if otherID == userID {
+		return uuid.Nil, dto.ErrChatInvalidRecipient
+	}
+	other, err := s.userRepo.FindByID(ctx, otherID)
+	if err != nil || !other.IsActive {
+		return uuid.Nil, dto.ErrChatInvalidRecipient
+	}
+	blocked, err := s.userRepo.IsBlocked(ctx, otherID, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if blocked {
+		return uuid.Nil, dto.ErrChatBlocked
+	}
+	return s.chatRoomRepo.FindOrCreatePersonalRoom(ctx, userID, otherID)
+}
+
+// ensureNotBlocked rejects messages in a personal room whose other participant blocked the sender.
+func (s *ChatService) ensureNotBlocked(ctx context.Context, roomID uuid.UUID, senderID uuid.UUID) error {
+	participants, err := s.chatRoomRepo.ListParticipants(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	for _, participant := range participants {
+		if participant.UserID == senderID {
+			continue
+		}
+		blocked, err := s.userRepo.IsBlocked(ctx, participant.UserID, senderID)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return dto.ErrChatBlocked
+		}
+	}
+	return nil
+}
+
+func (s *ChatService) roomForParticipant(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (*entity.ChatRoom, *entity.ChatParticipant, error) {
+	participant, err := s.chatRoomRepo.FindParticipant(ctx, roomID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	room, err := s.chatRoomRepo.FindRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return room, participant, nil
+}
+
+func (s *ChatService) loadRoom(ctx context.Context, roomID uuid.UUID) (*entity.ChatRoom, error) {
+	room, err := s.chatRoomRepo.FindRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	// Every user subscribes to a broadcast channel; listing them all is never useful.
+	if room.IsChannel() {
+		return room, nil
+	}
+	room.Participants, err = s.chatRoomRepo.ListParticipants(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// applyForward copies the content of a message the sender can see. Forward chains keep pointing
+// at the original message.
+func (s *ChatService) applyForward(ctx context.Context, userID uuid.UUID, message *entity.Chat, sourceID uuid.UUID) error {
+	source, err := s.chatRoomRepo.FindMessageByID(ctx, sourceID)
+	if err != nil || source.IsDeleted || source.IsHidden || source.IsSystemMessage() {
+		return dto.ErrChatInvalidForward
+	}
+	isMember, err := s.chatRoomRepo.IsParticipant(ctx, source.RoomID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return dto.ErrChatInvalidForward
+	}
+
+	message.Type = source.Type
+	message.Message = source.Message
+	message.FileURL = source.FileURL
+	message.FileName = source.FileName
+	message.FileSize = source.FileSize
+	message.MimeType = source.MimeType
+	message.Metadata = source.Metadata
+	message.ForwardedFromID = &source.ID
+	if source.ForwardedFromID != nil {
+		message.ForwardedFromID = source.ForwardedFromID
+	}
+	message.IsForwarded = true
+	return nil
+}
+
+// pendingMedia loads the caller's unclaimed upload for a media message.
+func (s *ChatService) pendingMedia(ctx context.Context, userID uuid.UUID, roomID uuid.UUID, req *entity.ChatCreateRequest) (*entity.ChatMedia, error) {
+	if req.Media == nil {
+		return nil, dto.ErrChatMediaRequired
+	}
+	media, err := s.chatRoomRepo.FindPendingMedia(ctx, req.Media.ID, userID, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if media.Type != req.Type {
+		return nil, dto.ErrChatMediaTypeMismatch
+	}
+	return media, nil
+}
+
+func (s *ChatService) removeMediaObjects(ctx context.Context, media *entity.ChatMedia) {
+	keys := []string{media.ObjectKey}
+	if media.ThumbnailKey != nil {
+		keys = append(keys, *media.ThumbnailKey)
+	}
+	for _, key := range keys {
+		if err := s.minio.DeleteFile(ctx, s.cfg.MinIO.MinioBucketName, key); err != nil {
+			s.logger.Warn("[ChatService.removeMediaObjects] failed to delete object", zap.String("object", key), zap.Error(err))
+		}
+	}
+}
+
+// applyMedia copies the server-side attachment details onto the message. The caption is optional.
+func applyMedia(message *entity.Chat, media *entity.ChatMedia, caption *string) error {
+	text, err := normaliseChatText(caption, false)
+	if err != nil {
+		return err
+	}
+	metadata, err := json.Marshal(struct {
+		MediaID   uuid.UUID `json:"media_id"`
+		Width     *int      `json:"width,omitempty"`
+		Height    *int      `json:"height,omitempty"`
+		Duration  *int      `json:"duration,omitempty"`
+		Thumbnail *string   `json:"thumbnail,omitempty"`
+	}{media.ID, media.Width, media.Height, media.Duration, media.Thumbnail})
+	if err != nil {
+		return fmt.Errorf("failed to marshal media metadata: %w", err)
+	}
+
+	message.Type = media.Type
+	message.Message = text
+	message.FileURL = &media.URL
+	message.FileName = &media.Name
+	message.FileSize = &media.Size
+	message.MimeType = &media.MimeType
+	message.Metadata = metadata
+	return nil
+}
+
+func applyMessageContent(message *entity.Chat, req *entity.ChatCreateRequest) error {
+	message.Type = req.Type
+	switch req.Type {
+	case entity.ChatMessageTypeText:
+		text, err := normaliseChatText(req.Message, true)
+		if err != nil {
+			return err
+		}
+		message.Message = text
+	case entity.ChatMessageTypeLocation, entity.ChatMessageTypeContact:
+		var payload interface{}
+		if req.Type == entity.ChatMessageTypeLocation && req.Location != nil {
+			payload = req.Location
+		}
+		if req.Type == entity.ChatMessageTypeContact && req.Contact != nil {
+			payload = req.Contact
+		}
+		if payload == nil {
+			return dto.ErrChatPayloadRequired
+		}
+		metadata, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal message metadata: %w", err)
+		}
+		message.Metadata = metadata
+
+		caption, err := normaliseChatText(req.Message, false)
+		if err != nil {
+			return err
+		}
+		message.Message = caption
+	default:
+		return dto.ErrChatMediaRequired
+	}
+	return nil
+}
+
+// normaliseChatText trims message text and enforces the length limit. Empty text is an error
+// only when required.
+func normaliseChatText(text *string, required bool) (*string, error) {
+	if text == nil || strings.TrimSpace(*text) == "" {
+		if required {
+			return nil, dto.ErrChatMessageEmpty
+		}
+		return nil, nil
+	}
+	trimmed := strings.TrimSpace(*text)
+	if utf8.RuneCountInString(trimmed) > maxChatMessageLength {
+		return nil, dto.ErrChatMessageTooLong
+	}
+	return &trimmed, nil
+}
+
+// postSystemMessage records a room event as a system message and broadcasts it.
+func (s *ChatService) postSystemMessage(ctx context.Context, roomID uuid.UUID, actorID uuid.UUID, text string, metadata map[string]interface{}) {
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		s.logger.Warn("[ChatService.postSystemMessage] failed to marshal metadata", zap.Error(err))
+		raw = nil
+	}
+	message := &entity.Chat{
+		RoomID:   roomID,
+		SenderID: actorID,
+		Message:  &text,
+		Type:     entity.ChatMessageTypeSystem,
+		Metadata: raw,
+	}
+	if err := s.chatRoomRepo.CreateMessage(ctx, message); err != nil {
+		s.logger.Warn("[ChatService.postSystemMessage] failed to store system message",
+			zap.String("room_id", roomID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	s.broadcastMessage(ctx, message)
+}
+
+func (s *ChatService) participantNames(ctx context.Context, roomID uuid.UUID, userIDs []uuid.UUID) []string {
+	wanted := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+	participants, err := s.chatRoomRepo.ListParticipants(ctx, roomID)
+	if err != nil {
+		return []string{fmt.Sprintf("%d members", len(userIDs))}
+	}
+	names := make([]string, 0, len(userIDs))
+	for _, participant := range participants {
+		if wanted[participant.UserID] {
+			names = append(names, participant.FullName)
+		}
+	}
+	return names
+}
+
+func (s *ChatService) broadcastMessage(ctx context.Context, message *entity.Chat) {
+	if err := s.centrifugo.BroadcastNewMessage(ctx, message.RoomID.String(), chatMessagePayload(message)); err != nil {
+		s.logger.Warn("[ChatService.broadcastMessage] failed to broadcast message",
+			zap.String("room_id", message.RoomID.String()),
+			zap.String("message_id", message.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// publishRoomEvent pushes an event to the room's conversation channel. Failures are logged only.
+func (s *ChatService) publishRoomEvent(ctx context.Context, roomID uuid.UUID, payload map[string]interface{}) {
+	if err := s.centrifugo.PublishToConversation(ctx, roomID.String(), payload); err != nil {
+		s.logger.Warn("[ChatService.publishRoomEvent] failed to publish room event",
+			zap.String("room_id", roomID.String()),
+			zap.Any("type", payload["type"]),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *ChatService) publishToUser(ctx context.Context, userID uuid.UUID, eventType string, roomID uuid.UUID) {
+	payload := map[string]interface{}{
+		"type":    eventType,
+		"room_id": roomID.String(),
+	}
+	if err := s.centrifugo.PublishToUser(ctx, userID.String(), payload); err != nil {
+		s.logger.Warn("[ChatService.publishToUser] failed to notify user",
+			zap.String("user_id", userID.String()),
+			zap.String("type", eventType),
+			zap.Error(err),
+		)
+	}
+}
+
+// chatMessagePayload converts a message into the map BroadcastNewMessage expects. The viewer
+// specific is_sent_by_me flag is dropped because every participant receives the same payload.
+func chatMessagePayload(message *entity.Chat) map[string]interface{} {
+	snapshot := *message
+	snapshot.IsSentByMe = false
+	raw, err := json.Marshal(&snapshot)
+	if err != nil {
+		return map[string]interface{}{"id": message.ID.String(), "room_id": message.RoomID.String()}
+	}
+	payload := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return map[string]interface{}{"id": message.ID.String(), "room_id": message.RoomID.String()}
+	}
+	delete(payload, "is_sent_by_me")
+	return payload
+}
+
+func isChatMediaType(messageType string) bool {
+	switch messageType {
+	case entity.ChatMessageTypeImage, entity.ChatMessageTypeVideo, entity.ChatMessageTypeAudio, entity.ChatMessageTypeFile:
+		return true
+	default:
+		return false
+	}
+}
+
+// chatMediaTypeFor maps a sniffed MIME type to a message type. Only images we can decode count as
+// images; anything unrecognised is a plain file.
+func chatMediaTypeFor(mimeType string) string {
+	base, _, _ := mime.ParseMediaType(mimeType)
+	switch {
+	case base == "image/jpeg", base == "image/png", base == "image/gif", base == "image/webp":
+		return entity.ChatMessageTypeImage
+	case strings.HasPrefix(base, "video/"):
+		return entity.ChatMessageTypeVideo
+	case strings.HasPrefix(base, "audio/"), base == "application/ogg":
+		return entity.ChatMessageTypeAudio
+	default:
+		return entity.ChatMessageTypeFile
+	}
+}
+
+func chatMediaSizeLimit(limits *config.ChatConfig, mediaType string) int64 {
+	switch mediaType {
+	case entity.ChatMessageTypeImage:
+		return limits.MaxImageSize
+	case entity.ChatMessageTypeVideo:
+		return limits.MaxVideoSize
+	case entity.ChatMessageTypeAudio:
+		return limits.MaxAudioSize
+	default:
+		return limits.MaxFileSize
+	}
+}
+
+// chatStoredContentType is the Content-Type the object is served with. The bucket is public, so
+// anything a browser might render as a page is downgraded to a download.
+func chatStoredContentType(mimeType string) string {
+	base, _, _ := mime.ParseMediaType(mimeType)
+	switch chatMediaTypeFor(mimeType) {
+	case entity.ChatMessageTypeImage, entity.ChatMessageTypeVideo, entity.ChatMessageTypeAudio:
+		return base
+	}
+	switch base {
+	case "application/pdf", "application/zip", "text/plain":
+		return base
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func chatMediaName(filename string) string {
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(filename, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if utf8.RuneCountInString(name) > 255 {
+		name = string([]rune(name)[:255])
+	}
+	return name
+}
+
+func chatMediaExtension(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if len(ext) < 2 || len(ext) > 10 {
+		return ""
+	}
+	for _, r := range ext[1:] {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	return ext
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	return values
+}
+
+func redactDeletedMessage(message *entity.Chat) {
+	if !message.IsDeleted {
+		return
+	}
+	message.Message = nil
+	message.FileURL = nil
+	message.FileName = nil
+	message.FileSize = nil
+	message.MimeType = nil
+	message.Metadata = nil
+}
+
+// outranks reports whether actor may remove target: admins remove anyone but admins,
+// moderators remove members.
+func outranks(actor *entity.ChatParticipant, target *entity.ChatParticipant) bool {
+	switch actor.Role {
+	case entity.ChatRoleAdmin:
+		return target.Role != entity.ChatRoleAdmin
+	case entity.ChatRoleModerator:
+		return target.Role == entity.ChatRoleMember
+	default:
+		return false
+	}
+}
+
+func encodeChatCursor(cursor *entity.ChatCursor) string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + ":" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeChatCursor(value string) (*entity.ChatCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, dto.ErrChatInvalidCursor
+	}
+	nanos, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return nil, dto.ErrChatInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, dto.ErrChatInvalidCursor
+	}
+	messageID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, dto.ErrChatInvalidCursor
+	}
+	return &entity.ChatCursor{CreatedAt: time.Unix(0, unixNano), ID: messageID}, nil
+}
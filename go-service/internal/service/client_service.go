@@ -3,26 +3,28 @@ package service
 import (
 	"context"
 	"tubexxi/video-api/internal/dto"
-	helpers "tubexxi/video-api/internal/helper"
+	"tubexxi/video-api/internal/entity"
 
 	"go.uber.org/zap"
 )
 
 type ClientService struct {
-	mailHelper *helpers.MailHelper
-	logger     *zap.Logger
+	ticketService *TicketService
+	logger        *zap.Logger
 }
 
 func NewClientService(
-	mailHelper *helpers.MailHelper,
+	ticketService *TicketService,
 	logger *zap.Logger,
 ) *ClientService {
 	return &ClientService{
-		mailHelper: mailHelper,
-		logger:     logger,
+		ticketService: ticketService,
+		logger:        logger,
 	}
 }
 
-func (s *ClientService) SendContactEmail(ctx context.Context, payload *dto.ContactRequest, clientOrigin string) error {
-	return s.mailHelper.SendContactEmail(ctx, payload, clientOrigin)
+// SubmitContact files the contact form as a support ticket; userID is empty for visitors
+// who are not signed in.
+func (s *ClientService) SubmitContact(ctx context.Context, userID string, payload *dto.ContactRequest) (*entity.Ticket, error) {
+	return s.ticketService.SubmitContact(ctx, userID, payload)
 }
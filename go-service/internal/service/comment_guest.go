@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+	"tubexxi/video-api/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	guestCommentTokenKey     = "comment:guest:verify:%s"
+	guestCommentEmailRateKey = "comment:guest:rate:email:%s"
+	guestCommentIPRateKey    = "comment:guest:rate:ip:%s"
+)
+
+// CreateGuestComment stores a comment from a visitor without an account. It stays
+// unverified, and hidden from everyone, until the link mailed to the guest is opened.
+func (s *CommentService) CreateGuestComment(ctx context.Context, clientIP string, clientOrigin string, req *entity.CommentCreateRequest) (*dto.CommentGuestResponse, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	pageURL := strings.TrimSpace(req.PageURL)
+	if pageURL == "" {
+		return nil, dto.ErrCommentPageURLMissing
+	}
+
+	var name, email string
+	if req.Name != nil {
+		name = strings.TrimSpace(*req.Name)
+	}
+	if req.Email != nil {
+		email = strings.ToLower(strings.TrimSpace(*req.Email))
+	}
+	if name == "" || email == "" {
+		return nil, dto.ErrGuestIdentityRequired
+	}
+	if user, err := s.userRepo.FindByEmail(subCtx, email); err == nil && user != nil {
+		return nil, dto.ErrGuestEmailRegistered
+	}
+
+	if err := s.allowGuestComment(subCtx, fmt.Sprintf(guestCommentEmailRateKey, hashGuestKey(email)), s.cfg.GuestLimitPerEmail); err != nil {
+		return nil, err
+	}
+	if clientIP != "" {
+		if err := s.allowGuestComment(subCtx, fmt.Sprintf(guestCommentIPRateKey, clientIP), s.cfg.GuestLimitPerIP); err != nil {
+			return nil, err
+		}
+	}
+
+	var parent *entity.Comment
+	if req.ReplyToID != nil {
+		var err error
+		parent, err = s.validateReplyTarget(subCtx, nil, *req.ReplyToID, pageURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	commentType := req.Type
+	if commentType == "" {
+		commentType = "text"
+	}
+
+	comment := &entity.Comment{
+		PageURL:   pageURL,
+		Name:      &name,
+		Email:     &email,
+		Comment:   strings.TrimSpace(req.Comment),
+		Type:      commentType,
+		ReplyToID: req.ReplyToID,
+		MediaURL:  req.MediaURL,
+		Status:    entity.CommentStatusUnverified,
+	}
+	applyCommentContent(comment, req)
+	if comment.ContentID == nil && parent != nil {
+		comment.ContentType, comment.ContentID, comment.ContentTitle = parent.ContentType, parent.ContentID, parent.ContentTitle
+	}
+	if err := s.commentRepo.Create(subCtx, comment); err != nil {
+		return nil, err
+	}
+	if err := s.saveMentions(subCtx, comment); err != nil {
+		s.logger.Warn("[CommentService.CreateGuestComment] failed to save mentions", zap.Error(err))
+	}
+
+	token := utils.GenerateSecureToken(32)
+	expiresAt := time.Now().Add(s.cfg.GuestVerificationTTL)
+	tokenKey := fmt.Sprintf(guestCommentTokenKey, token)
+	if err := s.redis.Client().Set(subCtx, tokenKey, comment.ID.String(), s.cfg.GuestVerificationTTL).Err(); err != nil {
+		s.discardGuestComment(subCtx, comment.ID)
+		return nil, fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	err := s.mail.SendEmail(&dto.SendMailMetaData{
+		Token:     token,
+		Type:      dto.GuestCommentVerification,
+		To:        email,
+		Comment:   comment,
+		ExpiredAt: expiresAt,
+	}, clientOrigin)
+	if err != nil {
+		s.logger.Error("[CommentService.CreateGuestComment] failed to send verification email", zap.Error(err))
+		s.redis.Client().Del(subCtx, tokenKey)
+		s.discardGuestComment(subCtx, comment.ID)
+		return nil, fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	comment.Author = &entity.CommentAuthor{Name: name, IsGuest: true}
+	return &dto.CommentGuestResponse{
+		Comment:              comment,
+		VerificationRequired: true,
+		ExpiresAt:            expiresAt,
+	}, nil
+}
+
+// VerifyGuestComment consumes a verification token and sends the guest comment through
+// the moderation chain, publishing it when approved. Tokens are single use.
+func (s *CommentService) VerifyGuestComment(ctx context.Context, token string) (*entity.Comment, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, dto.ErrGuestTokenInvalid
+	}
+
+	value, err := s.redis.Client().GetDel(subCtx, fmt.Sprintf(guestCommentTokenKey, token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, dto.ErrGuestTokenInvalid
+		}
+		return nil, fmt.Errorf("failed to read verification token: %w", err)
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, dto.ErrGuestTokenInvalid
+	}
+
+	comment, err := s.commentRepo.FindByID(subCtx, id)
+	if err != nil {
+		if errors.Is(err, dto.ErrCommentNotFound) {
+			return nil, dto.ErrGuestTokenInvalid
+		}
+		return nil, err
+	}
+	if !comment.IsGuest() || comment.IsDeleted || comment.Status != entity.CommentStatusUnverified {
+		return nil, dto.ErrGuestTokenInvalid
+	}
+	if err := s.attachMentions(subCtx, []*entity.Comment{comment}); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.moderate(subCtx, comment); err != nil {
+		return nil, err
+	}
+	verified, err := s.commentRepo.VerifyGuest(subCtx, comment.ID, comment.Status, comment.ModerationReason)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, dto.ErrGuestTokenInvalid
+	}
+	now := time.Now()
+	comment.GuestVerifiedAt = &now
+
+	switch comment.Status {
+	case entity.CommentStatusApproved:
+		s.publishEvent(subCtx, newCommentEvent(dto.CommentEventCreated, comment, true))
+		s.notifyParticipants(subCtx, comment)
+	case entity.CommentStatusPending:
+		s.alertIfQueueBacklogged(subCtx)
+	}
+	return comment, nil
+}
+
+// GuestVerifiedRedirect returns where to send a guest after following the verification
+// link: the comment's page when it lives on the client site, otherwise the site root.
+func (s *CommentService) GuestVerifiedRedirect(comment *entity.Comment, result string) string {
+	pageURL := ""
+	if comment != nil {
+		pageURL = comment.PageURL
+	}
+	target := s.clientPageURL(pageURL)
+
+	query := target.Query()
+	query.Set("comment_verification", result)
+	if comment != nil {
+		query.Set("comment_id", comment.ID.String())
+	}
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+// commentLink points at a comment on its page, for notifications.
+func (s *CommentService) commentLink(comment *entity.Comment) string {
+	target := s.clientPageURL(comment.PageURL)
+	target.Fragment = "comment-" + comment.ID.String()
+	return target.String()
+}
+
+// clientPageURL resolves a page URL against the client site. Pages on any other host
+// fall back to the site root so links built from user input never leave the client.
+func (s *CommentService) clientPageURL(pageURL string) *url.URL {
+	client, err := url.Parse(strings.TrimRight(s.appConfig.ClientUrl, "/"))
+	if err != nil {
+		client = &url.URL{}
+	}
+
+	if page, err := url.Parse(pageURL); err == nil && pageURL != "" {
+		switch {
+		case page.Host == "" && page.Scheme == "" && strings.HasPrefix(page.Path, "/"):
+			return client.ResolveReference(&url.URL{Path: page.Path, RawQuery: page.RawQuery})
+		case page.Host == client.Host && (page.Scheme == "http" || page.Scheme == "https"):
+			page.Fragment = ""
+			return page
+		}
+	}
+	return client
+}
+
+// allowGuestComment counts a guest submission against a fixed window.
+func (s *CommentService) allowGuestComment(ctx context.Context, key string, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	var count *redis.IntCmd
+	_, err := s.redis.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		count = pipe.Incr(ctx, key)
+		pipe.ExpireNX(ctx, key, s.cfg.GuestRateWindow)
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("[CommentService.allowGuestComment] rate limit check failed", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	if count.Val() > int64(limit) {
+		return dto.ErrGuestRateLimited
+	}
+	return nil
+}
+
+func (s *CommentService) discardGuestComment(ctx context.Context, id uuid.UUID) {
+	if err := s.commentRepo.SoftDelete(ctx, id); err != nil {
+		s.logger.Warn("[CommentService.discardGuestComment] failed to discard guest comment",
+			zap.String("comment_id", id.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+func hashGuestKey(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
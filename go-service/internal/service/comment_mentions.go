@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// mentionPattern matches @username when it is not part of a word or an email address.
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_@])@([A-Za-z0-9_]{3,32})\b`)
+
+type mentionToken struct {
+	username string
+	offset   int
+	length   int
+}
+
+// parseMentions finds every @username in text with its rune offset and length.
+func parseMentions(text string) []mentionToken {
+	matches := mentionPattern.FindAllStringSubmatchIndex(text, -1)
+	tokens := make([]mentionToken, 0, len(matches))
+	for _, match := range matches {
+		start, end := match[2]-1, match[3]
+		tokens = append(tokens, mentionToken{
+			username: strings.ToLower(text[match[2]:end]),
+			offset:   utf8.RuneCountInString(text[:start]),
+			length:   utf8.RuneCountInString(text[start:end]),
+		})
+	}
+	return tokens
+}
+
+// applyMentions fills comment.Mentions with one entity per occurrence of a known user.
+func applyMentions(comment *entity.Comment, known map[string]*entity.CommentMention) {
+	comment.Mentions = nil
+	if comment.IsDeleted || len(known) == 0 {
+		return
+	}
+	for _, token := range parseMentions(comment.Comment) {
+		user, ok := known[token.username]
+		if !ok {
+			continue
+		}
+		mention := *user
+		mention.Offset, mention.Length = token.offset, token.length
+		comment.Mentions = append(comment.Mentions, &mention)
+	}
+}
+
+// resolveMentions looks up the users mentioned in a comment body, records them on the
+// comment and returns their IDs. Only the first MaxMentionsPerComment names are resolved.
+func (s *CommentService) resolveMentions(ctx context.Context, comment *entity.Comment) ([]uuid.UUID, error) {
+	seen := make(map[string]bool)
+	var usernames []string
+	for _, token := range parseMentions(comment.Comment) {
+		if seen[token.username] {
+			continue
+		}
+		seen[token.username] = true
+		usernames = append(usernames, token.username)
+		if len(usernames) >= s.cfg.MaxMentionsPerComment {
+			break
+		}
+	}
+	if len(usernames) == 0 {
+		comment.Mentions = nil
+		return nil, nil
+	}
+
+	users, err := s.userRepo.FindByUsernames(ctx, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]*entity.CommentMention, len(users))
+	ids := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		known[strings.ToLower(user.Username.String)] = &entity.CommentMention{
+			UserID:    user.ID,
+			Username:  user.Username.String,
+			Name:      user.FullName,
+			AvatarURL: user.AvatarURL.Ptr(),
+		}
+		ids = append(ids, user.ID)
+	}
+	applyMentions(comment, known)
+	return ids, nil
+}
+
+// saveMentions resolves and stores the mentions of a freshly written comment.
+func (s *CommentService) saveMentions(ctx context.Context, comment *entity.Comment) error {
+	ids, err := s.resolveMentions(ctx, comment)
+	if err != nil {
+		return err
+	}
+	return s.commentRepo.ReplaceMentions(ctx, comment.ID, ids)
+}
+
+// attachMentions loads stored mentions for a page of comments with a single query.
+func (s *CommentService) attachMentions(ctx context.Context, comments []*entity.Comment) error {
+	ids := make([]uuid.UUID, 0, len(comments))
+	for _, comment := range comments {
+		if !comment.IsDeleted {
+			ids = append(ids, comment.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	mentions, err := s.commentRepo.FindMentions(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		known := make(map[string]*entity.CommentMention, len(mentions[comment.ID]))
+		for _, mention := range mentions[comment.ID] {
+			known[strings.ToLower(mention.Username)] = mention
+		}
+		applyMentions(comment, known)
+	}
+	return nil
+}
+
+// notifyParticipants tells mentioned users and the parent comment's author about a comment
+// that has just become public. Recipients who muted the reason are skipped, and a user is
+// notified at most once per comment however often it is edited or re-approved.
+func (s *CommentService) notifyParticipants(ctx context.Context, comment *entity.Comment) {
+	recipients := make(map[uuid.UUID]string)
+	for _, mention := range comment.Mentions {
+		recipients[mention.UserID] = entity.CommentNotificationMention
+	}
+	if comment.ReplyToID != nil {
+		parent, err := s.commentRepo.FindByID(ctx, *comment.ReplyToID)
+		if err == nil && parent.UserID != nil && !parent.IsDeleted {
+			recipients[*parent.UserID] = entity.CommentNotificationReply
+		}
+	}
+	if comment.UserID != nil {
+		delete(recipients, *comment.UserID)
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(recipients))
+	for userID := range recipients {
+		userIDs = append(userIDs, userID)
+	}
+	settings, err := s.commentRepo.GetNotificationSettings(ctx, userIDs)
+	if err != nil {
+		s.logger.Warn("[CommentService.notifyParticipants] failed to load settings", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for userID, reason := range recipients {
+		if !settings[userID].Allows(reason, now) {
+			delete(recipients, userID)
+		}
+	}
+
+	notified, err := s.commentRepo.RecordNotifications(ctx, comment.ID, recipients)
+	if err != nil {
+		s.logger.Warn("[CommentService.notifyParticipants] failed to record notifications", zap.Error(err))
+		return
+	}
+
+	for _, userID := range notified {
+		notification := newCommentNotification(comment, recipients[userID])
+		if err := s.centrifugo.PublishToUser(ctx, userID.String(), notification); err != nil {
+			s.logger.Warn("[CommentService.notifyParticipants] failed to publish notification",
+				zap.String("user_id", userID.String()),
+				zap.Error(err),
+			)
+		}
+		if settings[userID].EmailEnabled {
+			s.emailNotification(ctx, userID, comment, recipients[userID])
+		}
+	}
+}
+
+// emailNotification sends the notification email in the background so SMTP latency
+// never holds up the comment request.
+func (s *CommentService) emailNotification(ctx context.Context, userID uuid.UUID, comment *entity.Comment, reason string) {
+	mailType := dto.CommentMentionNotice
+	if reason == entity.CommentNotificationReply {
+		mailType = dto.CommentReplyNotice
+	}
+	snapshot := *comment
+	link := s.commentLink(comment)
+
+	go func() {
+		subCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+
+		user, err := s.userRepo.FindByID(subCtx, userID)
+		if err != nil {
+			return
+		}
+		err = s.mail.SendEmail(&dto.SendMailMetaData{
+			Type:    mailType,
+			To:      user.Email,
+			User:    user,
+			Comment: &snapshot,
+			Link:    link,
+		}, s.appConfig.ClientUrl)
+		if err != nil {
+			s.logger.Warn("[CommentService.emailNotification] failed to send email",
+				zap.String("user_id", userID.String()),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+func (s *CommentService) GetNotificationSettings(ctx context.Context, userID string) (*entity.CommentNotificationSettings, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	settings, err := s.commentRepo.GetNotificationSettings(subCtx, []uuid.UUID{userUUID})
+	if err != nil {
+		return nil, err
+	}
+	return settings[userUUID], nil
+}
+
+func (s *CommentService) UpdateNotificationSettings(ctx context.Context, userID string, req *dto.CommentNotificationSettingsRequest) (*entity.CommentNotificationSettings, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	settings := &entity.CommentNotificationSettings{
+		UserID:       userUUID,
+		MuteMentions: *req.MuteMentions,
+		MuteReplies:  *req.MuteReplies,
+		EmailEnabled: *req.EmailEnabled,
+		MutedUntil:   req.MutedUntil,
+	}
+	if settings.MutedUntil != nil && !settings.MutedUntil.After(time.Now()) {
+		settings.MutedUntil = nil
+	}
+	if err := s.commentRepo.UpsertNotificationSettings(subCtx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func newCommentNotification(comment *entity.Comment, reason string) *dto.CommentNotification {
+	eventType := dto.CommentEventMentioned
+	if reason == entity.CommentNotificationReply {
+		eventType = dto.CommentEventReplied
+	}
+
+	excerpt := []rune(comment.Comment)
+	if len(excerpt) > 140 {
+		excerpt = append(excerpt[:140], '…')
+	}
+
+	return &dto.CommentNotification{
+		Type:         eventType,
+		CommentID:    comment.ID,
+		ReplyToID:    comment.ReplyToID,
+		PageURL:      comment.PageURL,
+		ContentTitle: comment.ContentTitle,
+		Excerpt:      string(excerpt),
+		Actor:        comment.Author,
+		OccurredAt:   time.Now().UTC(),
+	}
+}
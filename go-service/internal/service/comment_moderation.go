@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"tubexxi/video-api/config"
+	"tubexxi/video-api/internal/entity"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// ModerationInput is what every CommentModerator sees for a new comment.
+// UserID and Reputation are nil for guest comments.
+type ModerationInput struct {
+	UserID     *uuid.UUID
+	PageURL    string
+	Comment    string
+	MediaURL   *string
+	Reputation *entity.CommentAuthorReputation
+}
+
+// ModerationVerdict is a moderator's objection to a comment. Status is pending or rejected.
+type ModerationVerdict struct {
+	Status    string
+	Reason    string
+	Moderator string
+}
+
+// CommentModerator is a single rule in the moderation chain. Moderate returns nil when
+// the rule has no objection.
+type CommentModerator interface {
+	Name() string
+	Moderate(ctx context.Context, input *ModerationInput) *ModerationVerdict
+}
+
+type ModerationChain struct {
+	moderators []CommentModerator
+}
+
+func NewModerationChain(moderators ...CommentModerator) *ModerationChain {
+	return &ModerationChain{moderators: moderators}
+}
+
+// NewDefaultModerationChain wires the blocklist, link-spam and reputation rules.
+func NewDefaultModerationChain(cfg *config.CommentConfig) *ModerationChain {
+	return NewModerationChain(
+		NewBlocklistModerator(defaultModerationBlocklist),
+		NewLinkSpamModerator(cfg.MaxLinksPerComment),
+		NewReputationModerator(cfg.ReputationPendingScore, cfg.ReputationRejectScore),
+	)
+}
+
+func (c *ModerationChain) Use(moderator CommentModerator) {
+	c.moderators = append(c.moderators, moderator)
+}
+
+// Evaluate runs every moderator and keeps the strictest verdict. A rejection stops the chain.
+func (c *ModerationChain) Evaluate(ctx context.Context, input *ModerationInput) *ModerationVerdict {
+	verdict := &ModerationVerdict{Status: entity.CommentStatusApproved}
+	for _, moderator := range c.moderators {
+		result := moderator.Moderate(ctx, input)
+		if result == nil {
+			continue
+		}
+		if result.Moderator == "" {
+			result.Moderator = moderator.Name()
+		}
+		if result.Status == entity.CommentStatusRejected {
+			return result
+		}
+		if verdict.Status == entity.CommentStatusApproved {
+			verdict = result
+		}
+	}
+	return verdict
+}
+
+// BlocklistModerator matches normalised words against a multilingual blocklist.
+// Each entry maps a word to the status it triggers.
+type BlocklistModerator struct {
+	words map[string]string
+}
+
+func NewBlocklistModerator(words map[string]string) *BlocklistModerator {
+	normalised := make(map[string]string, len(words)*2)
+	for word, status := range words {
+		word = strings.ToLower(word)
+		normalised[word] = status
+		normalised[collapseRepeats(word)] = status
+	}
+	return &BlocklistModerator{words: normalised}
+}
+
+func (m *BlocklistModerator) Name() string {
+	return "blocklist"
+}
+
+func (m *BlocklistModerator) Moderate(ctx context.Context, input *ModerationInput) *ModerationVerdict {
+	var verdict *ModerationVerdict
+	for _, token := range moderationTokens(input.Comment) {
+		status, ok := m.words[token]
+		if !ok {
+			status, ok = m.words[collapseRepeats(token)]
+		}
+		if !ok {
+			continue
+		}
+		if status == entity.CommentStatusRejected {
+			return &ModerationVerdict{Status: status, Reason: "blocked term detected"}
+		}
+		if verdict == nil {
+			verdict = &ModerationVerdict{Status: status, Reason: "flagged term detected"}
+		}
+	}
+	return verdict
+}
+
+var (
+	linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+|\b[a-z0-9][a-z0-9-]*\.(?:com|net|org|info|biz|xyz|top|site|online|club|live|link|click|io|me|id|co|ly|gg|vip|bet|cc|to)\b(?:/[^\s<>"']*)?`)
+
+	shortenerHosts = map[string]bool{
+		"bit.ly": true, "tinyurl.com": true, "t.co": true, "goo.gl": true, "ow.ly": true,
+		"is.gd": true, "s.id": true, "cutt.ly": true, "shorturl.at": true, "rebrand.ly": true,
+		"t.me": true, "wa.me": true, "chat.whatsapp.com": true, "linktr.ee": true,
+	}
+)
+
+// LinkSpamModerator flags comments with too many links, shortened or invite links,
+// long character runs or shouting.
+type LinkSpamModerator struct {
+	maxLinks int
+}
+
+func NewLinkSpamModerator(maxLinks int) *LinkSpamModerator {
+	if maxLinks < 0 {
+		maxLinks = 0
+	}
+	return &LinkSpamModerator{maxLinks: maxLinks}
+}
+
+func (m *LinkSpamModerator) Name() string {
+	return "link_spam"
+}
+
+func (m *LinkSpamModerator) Moderate(ctx context.Context, input *ModerationInput) *ModerationVerdict {
+	links := linkPattern.FindAllString(input.Comment, -1)
+
+	if len(links) > m.maxLinks*3+2 {
+		return &ModerationVerdict{Status: entity.CommentStatusRejected, Reason: fmt.Sprintf("contains %d links", len(links))}
+	}
+	if len(links) > m.maxLinks {
+		return &ModerationVerdict{Status: entity.CommentStatusPending, Reason: fmt.Sprintf("contains %d links", len(links))}
+	}
+	for _, link := range links {
+		if shortenerHosts[linkHost(link)] {
+			return &ModerationVerdict{Status: entity.CommentStatusPending, Reason: "contains a shortened or invite link"}
+		}
+	}
+
+	if hasCharacterRun(input.Comment, 10) {
+		return &ModerationVerdict{Status: entity.CommentStatusPending, Reason: "contains repeated characters"}
+	}
+	if isShouting(input.Comment) {
+		return &ModerationVerdict{Status: entity.CommentStatusPending, Reason: "mostly uppercase"}
+	}
+	return nil
+}
+
+// ReputationModerator holds or rejects comments from authors whose moderation history
+// has dropped below the configured scores.
+type ReputationModerator struct {
+	pendingScore int
+	rejectScore  int
+}
+
+func NewReputationModerator(pendingScore int, rejectScore int) *ReputationModerator {
+	return &ReputationModerator{pendingScore: pendingScore, rejectScore: rejectScore}
+}
+
+func (m *ReputationModerator) Name() string {
+	return "reputation"
+}
+
+func (m *ReputationModerator) Moderate(ctx context.Context, input *ModerationInput) *ModerationVerdict {
+	if input.Reputation == nil {
+		return nil
+	}
+	switch score := input.Reputation.Score; {
+	case score <= m.rejectScore:
+		return &ModerationVerdict{Status: entity.CommentStatusRejected, Reason: "author reputation too low"}
+	case score <= m.pendingScore:
+		return &ModerationVerdict{Status: entity.CommentStatusPending, Reason: "author reputation under review"}
+	}
+	return nil
+}
+
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s", "!", "i",
+)
+
+// moderationTokens lowercases the text, undoes common character substitutions and
+// also joins runs of single letters so "f u c k" is matched as one word.
+func moderationTokens(text string) []string {
+	text = leetReplacer.Replace(strings.ToLower(text))
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	tokens := make([]string, 0, len(fields)+1)
+	var spelled strings.Builder
+	for _, field := range fields {
+		tokens = append(tokens, field)
+		if len([]rune(field)) == 1 {
+			spelled.WriteString(field)
+			continue
+		}
+		if spelled.Len() > 1 {
+			tokens = append(tokens, spelled.String())
+		}
+		spelled.Reset()
+	}
+	if spelled.Len() > 1 {
+		tokens = append(tokens, spelled.String())
+	}
+	return tokens
+}
+
+func collapseRepeats(word string) string {
+	var b strings.Builder
+	var last rune
+	for i, r := range word {
+		if i > 0 && r == last {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
+
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+func hasCharacterRun(text string, length int) bool {
+	var last rune
+	run := 0
+	for _, r := range text {
+		if r == last {
+			run++
+		} else {
+			last, run = r, 1
+		}
+		if run >= length && !unicode.IsSpace(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isShouting(text string) bool {
+	var letters, upper int
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters >= 20 && upper*10 >= letters*8
+}
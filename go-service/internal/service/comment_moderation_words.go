@@ -0,0 +1,84 @@
+package service
+
+import "tubexxi/video-api/internal/entity"
+
+// defaultModerationBlocklist covers English, Indonesian/Malay, Javanese and Spanish.
+// Gambling and piracy-reseller spam is rejected outright; general profanity is held for review.
+var defaultModerationBlocklist = map[string]string{
+	// Gambling / spam (id)
+	"judol":     entity.CommentStatusRejected,
+	"togel":     entity.CommentStatusRejected,
+	"gacor":     entity.CommentStatusRejected,
+	"maxwin":    entity.CommentStatusRejected,
+	"slot88":    entity.CommentStatusRejected,
+	"slotgacor": entity.CommentStatusRejected,
+	"depo":      entity.CommentStatusPending,
+	"deposit":   entity.CommentStatusPending,
+	"pinjol":    entity.CommentStatusRejected,
+	"bokep":     entity.CommentStatusRejected,
+
+	// Spam (en)
+	"casino":   entity.CommentStatusPending,
+	"viagra":   entity.CommentStatusRejected,
+	"porn":     entity.CommentStatusRejected,
+	"xxx":      entity.CommentStatusPending,
+	"onlyfans": entity.CommentStatusPending,
+
+	// Profanity (en)
+	"fuck":         entity.CommentStatusPending,
+	"fucking":      entity.CommentStatusPending,
+	"motherfucker": entity.CommentStatusPending,
+	"shit":         entity.CommentStatusPending,
+	"bitch":        entity.CommentStatusPending,
+	"asshole":      entity.CommentStatusPending,
+	"bastard":      entity.CommentStatusPending,
+	"cunt":         entity.CommentStatusPending,
+	"dick":         entity.CommentStatusPending,
+	"pussy":        entity.CommentStatusPending,
+	"whore":        entity.CommentStatusPending,
+	"slut":         entity.CommentStatusPending,
+	"retard":       entity.CommentStatusPending,
+	"faggot":       entity.CommentStatusRejected,
+	"nigger":       entity.CommentStatusRejected,
+	"nigga":        entity.CommentStatusRejected,
+
+	// Profanity (id / ms)
+	"anjing":   entity.CommentStatusPending,
+	"anjir":    entity.CommentStatusPending,
+	"anjrit":   entity.CommentStatusPending,
+	"bangsat":  entity.CommentStatusPending,
+	"kontol":   entity.CommentStatusPending,
+	"memek":    entity.CommentStatusPending,
+	"ngentot":  entity.CommentStatusPending,
+	"entot":    entity.CommentStatusPending,
+	"perek":    entity.CommentStatusPending,
+	"lonte":    entity.CommentStatusPending,
+	"pelacur":  entity.CommentStatusPending,
+	"goblok":   entity.CommentStatusPending,
+	"tolol":    entity.CommentStatusPending,
+	"bajingan": entity.CommentStatusPending,
+	"keparat":  entity.CommentStatusPending,
+	"kampret":  entity.CommentStatusPending,
+	"pukimak":  entity.CommentStatusPending,
+	"puki":     entity.CommentStatusPending,
+
+	// Profanity (jv)
+	"jancok":  entity.CommentStatusPending,
+	"jancuk":  entity.CommentStatusPending,
+	"cok":     entity.CommentStatusPending,
+	"asu":     entity.CommentStatusPending,
+	"matamu":  entity.CommentStatusPending,
+	"raimu":   entity.CommentStatusPending,
+	"gathel":  entity.CommentStatusPending,
+	"dancuk":  entity.CommentStatusPending,
+	"diancuk": entity.CommentStatusPending,
+
+	// Profanity (es)
+	"puta":    entity.CommentStatusPending,
+	"puto":    entity.CommentStatusPending,
+	"mierda":  entity.CommentStatusPending,
+	"cabron":  entity.CommentStatusPending,
+	"pendejo": entity.CommentStatusPending,
+	"joder":   entity.CommentStatusPending,
+	"coño":    entity.CommentStatusPending,
+}
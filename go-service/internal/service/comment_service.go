@@ -0,0 +1,1024 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+	"tubexxi/video-api/config"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	helpers "tubexxi/video-api/internal/helper"
+	"tubexxi/video-api/internal/infrastructure/centrifugo"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+	redisclient "tubexxi/video-api/internal/infrastructure/redis-client"
+	"tubexxi/video-api/internal/infrastructure/repository"
+	"tubexxi/video-api/pkg/telegram"
+
+	"github.com/centrifugal/gocent/v3"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxCommentDepth           = 10
+	commentModerationAlertKey = "comment:moderation:queue_alert"
+	defaultCommentStreamLimit = 50
+	defaultCommentStatsWindow = 30 * 24 * time.Hour
+	maxCommentStatsWindow     = 366 * 24 * time.Hour
+	defaultLeaderboardLimit   = 10
+)
+
+var commentContentSegments = map[string]string{
+	"movie":  "movies",
+	"movies": "movies",
+	"series": "series",
+	"anime":  "anime",
+	"animes": "anime",
+}
+
+type CommentService struct {
+	commentRepo repository.CommentRepository
+	userRepo    repository.UserRepository
+	moderation  *ModerationChain
+	centrifugo  *centrifugo.CentrifugoClient
+	notifier    telegram.Notifier
+	redis       *redisclient.RedisClient
+	mail        *helpers.MailHelper
+	appConfig   *config.AppConfig
+	cfg         *config.CommentConfig
+	logger      *zap.Logger
+}
+
+func NewCommentService(
+	commentRepo repository.CommentRepository,
+	userRepo repository.UserRepository,
+	moderation *ModerationChain,
+	centrifugo *centrifugo.CentrifugoClient,
+	notifier telegram.Notifier,
+	redis *redisclient.RedisClient,
+	mail *helpers.MailHelper,
+	appConfig *config.AppConfig,
+	cfg *config.CommentConfig,
+	logger *zap.Logger,
+) *CommentService {
+	return &CommentService{
+		commentRepo: commentRepo,
+		userRepo:    userRepo,
+		moderation:  moderation,
+		centrifugo:  centrifugo,
+		notifier:    notifier,
+		redis:       redis,
+		mail:        mail,
+		appConfig:   appConfig,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+func (s *CommentService) ListComments(ctx context.Context, viewerID string, filter entity.CommentFilter) ([]*entity.Comment, dto.Pagination, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	filter.PageURL = strings.TrimSpace(filter.PageURL)
+	if filter.PageURL == "" {
+		return nil, dto.Pagination{}, dto.ErrCommentPageURLMissing
+	}
+	filter.SetDefaults()
+	filter.ViewerID = parseViewerID(viewerID)
+
+	comments, total, err := s.commentRepo.List(subCtx, filter)
+	if err != nil {
+		return nil, dto.Pagination{}, err
+	}
+
+	loaded := comments
+	if filter.IncludeReplies && len(comments) > 0 {
+		trees, err := s.loadTrees(subCtx, comments, filter.Depth, filter.ViewerID)
+		if err != nil {
+			return nil, dto.Pagination{}, err
+		}
+		for _, tree := range trees {
+			attachReplies(tree)
+		}
+		loaded = entity.FlattenTree(trees, filter.Depth)
+	}
+
+	if err := s.markLikedByViewer(subCtx, viewerID, loaded); err != nil {
+		return nil, dto.Pagination{}, err
+	}
+	if err := s.attachMentions(subCtx, loaded); err != nil {
+		return nil, dto.Pagination{}, err
+	}
+	maskDeletedComments(comments)
+	return comments, commentPagination(total, filter.Limit, filter.Offset), nil
+}
+
+func (s *CommentService) GetCommentTree(ctx context.Context, viewerID string, filter entity.CommentFilter) ([]*entity.CommentTree, dto.Pagination, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	filter.PageURL = strings.TrimSpace(filter.PageURL)
+	if filter.PageURL == "" {
+		return nil, dto.Pagination{}, dto.ErrCommentPageURLMissing
+	}
+	filter.SetDefaults()
+	filter.ViewerID = parseViewerID(viewerID)
+
+	roots, total, err := s.commentRepo.List(subCtx, filter)
+	if err != nil {
+		return nil, dto.Pagination{}, err
+	}
+
+	trees, err := s.loadTrees(subCtx, roots, filter.Depth, filter.ViewerID)
+	if err != nil {
+		return nil, dto.Pagination{}, err
+	}
+
+	flattened := entity.FlattenTree(trees, filter.Depth)
+	if err := s.markLikedByViewer(subCtx, viewerID, flattened); err != nil {
+		return nil, dto.Pagination{}, err
+	}
+	if err := s.attachMentions(subCtx, flattened); err != nil {
+		return nil, dto.Pagination{}, err
+	}
+	maskDeletedComments(flattened)
+	return trees, commentPagination(total, filter.Limit, filter.Offset), nil
+}
+
+func (s *CommentService) GetComment(ctx context.Context, viewerID string, commentID string) (*entity.Comment, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	id, err := uuid.Parse(commentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid comment ID format: %w", err)
+	}
+
+	comment, err := s.commentRepo.FindByID(subCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensurePublished(subCtx, comment, parseViewerID(viewerID)); err != nil {
+		return nil, err
+	}
+	if err := s.markLikedByViewer(subCtx, viewerID, []*entity.Comment{comment}); err != nil {
+		return nil, err
+	}
+	if err := s.attachMentions(subCtx, []*entity.Comment{comment}); err != nil {
+		return nil, err
+	}
+	maskDeletedComments([]*entity.Comment{comment})
+	return comment, nil
+}
+
+func (s *CommentService) LikeComment(ctx context.Context, userID string, commentID string) (*dto.CommentLikeResponse, error) {
+	return s.toggleLike(ctx, userID, commentID, true)
+}
+
+func (s *CommentService) UnlikeComment(ctx context.Context, userID string, commentID string) (*dto.CommentLikeResponse, error) {
+	return s.toggleLike(ctx, userID, commentID, false)
+}
+
+func (s *CommentService) toggleLike(ctx context.Context, userID string, commentID string, like bool) (*dto.CommentLikeResponse, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userIDUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	id, err := uuid.Parse(commentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid comment ID format: %w", err)
+	}
+
+	comment, err := s.commentRepo.FindByID(subCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.IsDeleted {
+		return nil, dto.ErrCommentDeleted
+	}
+	if err := s.ensurePublished(subCtx, comment, &userIDUUID); err != nil {
+		return nil, err
+	}
+
+	var changed bool
+	var count int64
+	if like {
+		changed, count, err = s.commentRepo.Like(subCtx, id, userIDUUID)
+	} else {
+		changed, count, err = s.commentRepo.Unlike(subCtx, id, userIDUUID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if changed && s.isPublic(subCtx, comment) {
+		eventType := dto.CommentEventUnliked
+		if like {
+			eventType = dto.CommentEventLiked
+		}
+		event := newCommentEvent(eventType, comment, false)
+		event.Like = &dto.CommentLikeEvent{UserID: userIDUUID, Liked: like, LikesCount: count}
+		s.publishEvent(subCtx, event)
+	}
+
+	return &dto.CommentLikeResponse{
+		CommentID:  id,
+		Liked:      like,
+		Changed:    changed,
+		LikesCount: count,
+	}, nil
+}
+
+// markLikedByViewer fills IsLikedByMe for every comment with a single lookup.
+func (s *CommentService) markLikedByViewer(ctx context.Context, viewerID string, comments []*entity.Comment) error {
+	if viewerID == "" || len(comments) == 0 {
+		return nil
+	}
+	viewer, err := uuid.Parse(viewerID)
+	if err != nil {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+
+	liked, err := s.commentRepo.FindLikedCommentIDs(ctx, viewer, ids)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		comment.IsLikedByMe = liked[comment.ID]
+	}
+	return nil
+}
+
+func (s *CommentService) CreateComment(ctx context.Context, userID string, req *entity.CommentCreateRequest) (*entity.Comment, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userIDUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	user, err := s.userRepo.FindByID(subCtx, userIDUUID)
+	if err != nil {
+		return nil, dto.ErrUserNotFound
+	}
+
+	pageURL := strings.TrimSpace(req.PageURL)
+	if pageURL == "" {
+		return nil, dto.ErrCommentPageURLMissing
+	}
+
+	var parent *entity.Comment
+	if req.ReplyToID != nil {
+		parent, err = s.validateReplyTarget(subCtx, &user.ID, *req.ReplyToID, pageURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	commentType := req.Type
+	if commentType == "" {
+		commentType = "text"
+	}
+
+	comment := &entity.Comment{
+		UserID:    &user.ID,
+		PageURL:   pageURL,
+		Comment:   strings.TrimSpace(req.Comment),
+		Type:      commentType,
+		ReplyToID: req.ReplyToID,
+		MediaURL:  req.MediaURL,
+	}
+	applyCommentContent(comment, req)
+	if comment.ContentID == nil && parent != nil {
+		comment.ContentType, comment.ContentID, comment.ContentTitle = parent.ContentType, parent.ContentID, parent.ContentTitle
+	}
+	reputation, err := s.moderate(subCtx, comment)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.commentRepo.Create(subCtx, comment); err != nil {
+		return nil, err
+	}
+	if comment.Status == entity.CommentStatusPending {
+		s.alertIfQueueBacklogged(subCtx)
+	}
+
+	comment.Author = &entity.CommentAuthor{
+		ID:        &user.ID,
+		Name:      user.FullName,
+		AvatarURL: user.AvatarURL.Ptr(),
+	}
+	if err := s.saveMentions(subCtx, comment); err != nil {
+		s.logger.Warn("[CommentService.CreateComment] failed to save mentions", zap.Error(err))
+	}
+	if comment.Status == entity.CommentStatusApproved && !reputation.IsShadowBanned {
+		s.publishEvent(subCtx, newCommentEvent(dto.CommentEventCreated, comment, true))
+		s.notifyParticipants(subCtx, comment)
+	}
+	return comment, nil
+}
+
+func (s *CommentService) UpdateComment(ctx context.Context, userID string, commentID string, req *entity.CommentUpdateRequest) (*entity.Comment, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	comment, err := s.findOwnedComment(subCtx, userID, commentID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Comment != nil {
+		text := strings.TrimSpace(*req.Comment)
+		if text == "" {
+			return nil, fmt.Errorf("comment cannot be empty")
+		}
+		comment.Comment = text
+	}
+	if req.MediaURL != nil {
+		comment.MediaURL = req.MediaURL
+	}
+
+	// Edits go through the chain again so an approved comment cannot be rewritten into spam.
+	// A stricter verdict replaces the current status; edits never lift a hold.
+	wasPublic := s.isPublic(subCtx, comment)
+	previousStatus, previousReason := comment.Status, comment.ModerationReason
+	if _, err := s.moderate(subCtx, comment); err != nil {
+		return nil, err
+	}
+	if previousStatus != entity.CommentStatusApproved && comment.Status == entity.CommentStatusApproved {
+		comment.Status, comment.ModerationReason = previousStatus, previousReason
+	}
+
+	if err := s.commentRepo.Update(subCtx, comment); err != nil {
+		return nil, err
+	}
+	if previousStatus != comment.Status && comment.Status == entity.CommentStatusPending {
+		s.alertIfQueueBacklogged(subCtx)
+	}
+	if err := s.saveMentions(subCtx, comment); err != nil {
+		s.logger.Warn("[CommentService.UpdateComment] failed to save mentions", zap.Error(err))
+	}
+
+	if wasPublic {
+		if comment.Status == entity.CommentStatusApproved {
+			s.publishEvent(subCtx, newCommentEvent(dto.CommentEventUpdated, comment, true))
+			s.notifyParticipants(subCtx, comment)
+		} else {
+			s.publishEvent(subCtx, newCommentEvent(dto.CommentEventDeleted, comment, false))
+		}
+	}
+	return comment, nil
+}
+
+func (s *CommentService) DeleteComment(ctx context.Context, userID string, commentID string, isAdmin bool) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	comment, err := s.findOwnedComment(subCtx, userID, commentID, isAdmin)
+	if err != nil {
+		return err
+	}
+
+	wasPublic := s.isPublic(subCtx, comment)
+	if err := s.commentRepo.SoftDelete(subCtx, comment.ID); err != nil {
+		return err
+	}
+	if wasPublic {
+		s.publishEvent(subCtx, newCommentEvent(dto.CommentEventDeleted, comment, false))
+	}
+	return nil
+}
+
+func (s *CommentService) findOwnedComment(ctx context.Context, userID string, commentID string, isAdmin bool) (*entity.Comment, error) {
+	userIDUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	id, err := uuid.Parse(commentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid comment ID format: %w", err)
+	}
+
+	comment, err := s.commentRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.IsDeleted {
+		return nil, dto.ErrCommentDeleted
+	}
+	if !comment.IsAuthoredBy(&userIDUUID) && !isAdmin {
+		return nil, dto.ErrPermissionDenied
+	}
+	return comment, nil
+}
+
+func (s *CommentService) validateReplyTarget(ctx context.Context, userID *uuid.UUID, parentID uuid.UUID, pageURL string) (*entity.Comment, error) {
+	parent, err := s.commentRepo.FindByID(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.IsDeleted {
+		return nil, dto.ErrCommentDeleted
+	}
+	if err := s.ensurePublished(ctx, parent, userID); err != nil {
+		return nil, err
+	}
+	if parent.PageURL != pageURL {
+		return nil, dto.ErrInvalidReplyTarget
+	}
+
+	depth, err := s.commentRepo.GetDepth(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if depth+1 > maxCommentDepth {
+		return nil, dto.ErrCommentDepthExceeded
+	}
+	return parent, nil
+}
+
+func (s *CommentService) loadTrees(ctx context.Context, roots []*entity.Comment, depth int, viewerID *uuid.UUID) ([]*entity.CommentTree, error) {
+	rootIDs := make([]uuid.UUID, 0, len(roots))
+	for _, root := range roots {
+		rootIDs = append(rootIDs, root.ID)
+	}
+
+	descendants, err := s.commentRepo.FindDescendants(ctx, rootIDs, depth, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	all := make([]*entity.Comment, 0, len(roots)+len(descendants))
+	all = append(all, roots...)
+	all = append(all, descendants...)
+	return entity.BuildTree(all), nil
+}
+
+// moderate runs the comment through the moderation chain and records the verdict on it.
+// Guests have no reputation; they get an empty one back.
+func (s *CommentService) moderate(ctx context.Context, comment *entity.Comment) (*entity.CommentAuthorReputation, error) {
+	input := &ModerationInput{
+		UserID:   comment.UserID,
+		PageURL:  comment.PageURL,
+		Comment:  comment.Comment,
+		MediaURL: comment.MediaURL,
+	}
+	reputation := &entity.CommentAuthorReputation{}
+	if comment.UserID != nil {
+		var err error
+		reputation, err = s.commentRepo.GetReputation(ctx, *comment.UserID)
+		if err != nil {
+			return nil, err
+		}
+		input.Reputation = reputation
+	}
+
+	verdict := s.moderation.Evaluate(ctx, input)
+
+	comment.Status = verdict.Status
+	comment.ModerationReason = nil
+	if verdict.Status != entity.CommentStatusApproved {
+		reason := verdict.Moderator + ": " + verdict.Reason
+		comment.ModerationReason = &reason
+		s.logger.Info("[CommentService.moderate] comment held",
+			zap.Stringp("user_id", commentAuthorID(comment)),
+			zap.String("status", verdict.Status),
+			zap.String("reason", reason),
+		)
+	}
+	return reputation, nil
+}
+
+// ensurePublished hides unapproved and shadow-banned comments from everyone but their author.
+func (s *CommentService) ensurePublished(ctx context.Context, comment *entity.Comment, viewerID *uuid.UUID) error {
+	if comment.IsAuthoredBy(viewerID) {
+		return nil
+	}
+	if !comment.IsVisibleTo(viewerID) {
+		return dto.ErrCommentNotFound
+	}
+	if comment.IsGuest() {
+		return nil
+	}
+
+	reputation, err := s.commentRepo.GetReputation(ctx, *comment.UserID)
+	if err != nil {
+		return err
+	}
+	if reputation.IsShadowBanned {
+		return dto.ErrCommentNotFound
+	}
+	return nil
+}
+
+// alertIfQueueBacklogged notifies Telegram once per cooldown while the pending queue
+// is at or above the configured threshold.
+func (s *CommentService) alertIfQueueBacklogged(ctx context.Context) {
+	threshold := s.cfg.ModerationQueueAlertThreshold
+	if threshold <= 0 {
+		return
+	}
+
+	pending, err := s.commentRepo.CountByStatus(ctx, entity.CommentStatusPending)
+	if err != nil || pending < int64(threshold) {
+		return
+	}
+
+	acquired, err := s.redis.Client().SetNX(ctx, commentModerationAlertKey, pending, s.cfg.ModerationAlertCooldown).Result()
+	if err != nil {
+		s.logger.Warn("[CommentService.alertIfQueueBacklogged] failed to acquire alert cooldown", zap.Error(err))
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	s.notifier.SendAlert(telegram.AlertRequest{
+		Subject: "Comment moderation queue backed up",
+		Message: fmt.Sprintf("%d comments are waiting for review (threshold %d)", pending, threshold),
+		Metadata: map[string]interface{}{
+			"pending":   pending,
+			"threshold": threshold,
+			"timestamp": time.Now(),
+		},
+	})
+}
+
+func (s *CommentService) ModerationQueue(ctx context.Context, filter entity.CommentModerationFilter) ([]*entity.Comment, dto.Pagination, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	filter.PageURL = strings.TrimSpace(filter.PageURL)
+	filter.SetDefaults()
+
+	comments, total, err := s.commentRepo.ListForModeration(subCtx, filter)
+	if err != nil {
+		return nil, dto.Pagination{}, err
+	}
+	return comments, commentPagination(total, filter.Limit, filter.Offset), nil
+}
+
+func (s *CommentService) ApproveComments(ctx context.Context, adminID string, req *dto.CommentModerationRequest) (*dto.CommentModerationResult, error) {
+	return s.setCommentStatus(ctx, adminID, req, entity.CommentStatusApproved)
+}
+
+func (s *CommentService) RejectComments(ctx context.Context, adminID string, req *dto.CommentModerationRequest) (*dto.CommentModerationResult, error) {
+	return s.setCommentStatus(ctx, adminID, req, entity.CommentStatusRejected)
+}
+
+func (s *CommentService) setCommentStatus(ctx context.Context, adminID string, req *dto.CommentModerationRequest, status string) (*dto.CommentModerationResult, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	var reason *string
+	if text := strings.TrimSpace(req.Reason); text != "" {
+		reason = &text
+	}
+
+	comments, err := s.commentRepo.FindByIDs(subCtx, req.CommentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachMentions(subCtx, comments); err != nil {
+		return nil, err
+	}
+	wasPublic := make(map[uuid.UUID]bool, len(comments))
+	for _, comment := range comments {
+		wasPublic[comment.ID] = s.isPublic(subCtx, comment)
+	}
+
+	updated, err := s.commentRepo.UpdateStatus(subCtx, req.CommentIDs, status, reason, adminUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, comment := range comments {
+		if comment.IsDeleted || comment.Status == status {
+			continue
+		}
+		comment.Status = status
+		switch {
+		case status == entity.CommentStatusApproved && s.isPublic(subCtx, comment):
+			s.publishEvent(subCtx, newCommentEvent(dto.CommentEventCreated, comment, true))
+			s.notifyParticipants(subCtx, comment)
+		case status != entity.CommentStatusApproved && wasPublic[comment.ID]:
+			s.publishEvent(subCtx, newCommentEvent(dto.CommentEventDeleted, comment, false))
+		}
+	}
+
+	pending, err := s.commentRepo.CountByStatus(subCtx, entity.CommentStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CommentModerationResult{Status: status, Updated: updated, Pending: pending}, nil
+}
+
+func (s *CommentService) ShadowBanAuthors(ctx context.Context, adminID string, req *dto.CommentShadowBanRequest) (*dto.CommentModerationResult, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	for _, userID := range req.UserIDs {
+		if userID == adminUUID {
+			return nil, dto.ErrPermissionDenied
+		}
+	}
+
+	updated, err := s.commentRepo.SetShadowBan(subCtx, req.UserIDs, *req.Banned, adminUUID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CommentModerationResult{Updated: updated}, nil
+}
+
+// StreamEvents returns comment events published to a page's channel after the given
+// offset, or the latest events when no position is supplied.
+func (s *CommentService) StreamEvents(ctx context.Context, req *dto.CommentStreamRequest) (*dto.CommentStreamResponse, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	pageURL := strings.TrimSpace(req.PageURL)
+	if pageURL == "" {
+		return nil, dto.ErrCommentPageURLMissing
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultCommentStreamLimit
+	}
+
+	channel := centrifugo.CommentsChannel(pageURL)
+	resume := req.Since > 0 || req.Epoch != ""
+
+	var opts []gocent.HistoryOption
+	if resume {
+		opts = append(opts, gocent.WithSince(&gocent.StreamPosition{Offset: req.Since, Epoch: req.Epoch}))
+	} else {
+		opts = append(opts, gocent.WithReverse(true))
+	}
+
+	history, err := s.centrifugo.History(subCtx, channel, limit, opts...)
+	if err != nil {
+		if centrifugo.IsUnrecoverablePosition(err) {
+			return nil, dto.ErrCommentStreamReset
+		}
+		return nil, err
+	}
+	if req.Epoch != "" && history.Epoch != req.Epoch {
+		return nil, dto.ErrCommentStreamReset
+	}
+
+	events := make([]*dto.CommentEvent, 0, len(history.Publications))
+	for _, publication := range history.Publications {
+		var event dto.CommentEvent
+		if err := json.Unmarshal(publication.Data, &event); err != nil {
+			s.logger.Warn("[CommentService.StreamEvents] skipping malformed publication",
+				zap.Uint64("offset", publication.Offset),
+				zap.Error(err),
+			)
+			continue
+		}
+		event.Offset = publication.Offset
+		events = append(events, &event)
+	}
+	if !resume {
+		for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+			events[i], events[j] = events[j], events[i]
+		}
+	}
+
+	return &dto.CommentStreamResponse{
+		Channel: channel,
+		Epoch:   history.Epoch,
+		Offset:  history.Offset,
+		Events:  events,
+	}, nil
+}
+
+// isPublic reports whether a comment is currently shown to everyone on its page.
+func (s *CommentService) isPublic(ctx context.Context, comment *entity.Comment) bool {
+	return !comment.IsDeleted && s.ensurePublished(ctx, comment, nil) == nil
+}
+
+// publishEvent pushes an event to the page's comment channel. Failures are logged only;
+// clients recover missed events through StreamEvents.
+func (s *CommentService) publishEvent(ctx context.Context, event *dto.CommentEvent) {
+	event.OccurredAt = time.Now().UTC()
+	if err := s.centrifugo.PublishMessage(ctx, centrifugo.CommentsChannel(event.PageURL), event); err != nil {
+		s.logger.Warn("[CommentService.publishEvent] failed to publish comment event",
+			zap.String("type", event.Type),
+			zap.String("comment_id", event.CommentID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+func newCommentEvent(eventType string, comment *entity.Comment, withComment bool) *dto.CommentEvent {
+	event := &dto.CommentEvent{
+		Type:      eventType,
+		PageURL:   comment.PageURL,
+		CommentID: comment.ID,
+		ReplyToID: comment.ReplyToID,
+	}
+	if withComment {
+		snapshot := *comment
+		snapshot.IsLikedByMe = false
+		snapshot.Replies = nil
+		snapshot.Parent = nil
+		event.Comment = &snapshot
+	}
+	return event
+}
+
+// GetStats computes CommentStats for a page, a title, a content type or the whole site.
+// Site-wide and per-type stats are limited to admins.
+func (s *CommentService) GetStats(ctx context.Context, filter entity.CommentStatsFilter, isAdmin bool) (*dto.CommentStatsResponse, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if err := normaliseStatsFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	scope := commentStatsScope(filter)
+	if !isAdmin && (scope == dto.CommentStatsScopeSite || scope == dto.CommentStatsScopeContentType) {
+		return nil, dto.ErrPermissionDenied
+	}
+
+	key := commentStatsCacheKey("stats", filter)
+	var cached dto.CommentStatsResponse
+	if s.readCache(subCtx, key, &cached) {
+		return &cached, nil
+	}
+
+	stats, err := s.commentRepo.Stats(subCtx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.CommentStatsResponse{
+		Scope:       scope,
+		PageURL:     filter.PageURL,
+		ContentType: filter.ContentType,
+		ContentID:   filter.ContentID,
+		From:        filter.DateFrom,
+		To:          filter.DateTo,
+		Stats:       stats,
+		GeneratedAt: time.Now().UTC(),
+	}
+	if scope == dto.CommentStatsScopeSite || scope == dto.CommentStatsScopeContentType {
+		result.TopTitles, err = s.commentRepo.Leaderboard(subCtx, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.writeCache(subCtx, key, result)
+	return result, nil
+}
+
+// GetLeaderboard ranks titles by the number of published comments in the date range.
+func (s *CommentService) GetLeaderboard(ctx context.Context, filter entity.CommentStatsFilter) (*dto.CommentLeaderboardResponse, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	filter.PageURL = ""
+	filter.ContentID = ""
+	if err := normaliseStatsFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	key := commentStatsCacheKey("leaderboard", filter)
+	var cached dto.CommentLeaderboardResponse
+	if s.readCache(subCtx, key, &cached) {
+		return &cached, nil
+	}
+
+	entries, err := s.commentRepo.Leaderboard(subCtx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.CommentLeaderboardResponse{
+		ContentType: filter.ContentType,
+		From:        filter.DateFrom,
+		To:          filter.DateTo,
+		Entries:     entries,
+		GeneratedAt: time.Now().UTC(),
+	}
+	s.writeCache(subCtx, key, result)
+	return result, nil
+}
+
+func (s *CommentService) readCache(ctx context.Context, key string, dest interface{}) bool {
+	raw, err := s.redis.Client().Get(ctx, key).Bytes()
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(raw, dest) == nil
+}
+
+func (s *CommentService) writeCache(ctx context.Context, key string, value interface{}) {
+	if s.cfg.StatsCacheTTL <= 0 {
+		return
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err := s.redis.Client().Set(ctx, key, raw, s.cfg.StatsCacheTTL).Err(); err != nil {
+		s.logger.Warn("[CommentService.writeCache] failed to cache comment stats", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// normaliseStatsFilter parses the date range and applies defaults. The default range ends
+// at the top of the next hour so repeated requests share a cache entry.
+func normaliseStatsFilter(filter *entity.CommentStatsFilter) error {
+	filter.PageURL = strings.TrimSpace(filter.PageURL)
+	filter.ContentID = strings.TrimSpace(filter.ContentID)
+	if filter.ContentID != "" && filter.ContentType == "" {
+		return dto.ErrStatsContentType
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLeaderboardLimit
+	}
+
+	to := time.Now().UTC().Truncate(time.Hour).Add(time.Hour)
+	if filter.To != "" {
+		parsed, err := parseStatsDate(filter.To, true)
+		if err != nil {
+			return err
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultCommentStatsWindow)
+	if filter.From != "" {
+		parsed, err := parseStatsDate(filter.From, false)
+		if err != nil {
+			return err
+		}
+		from = parsed
+	}
+	if !from.Before(to) || to.Sub(from) > maxCommentStatsWindow {
+		return dto.ErrInvalidStatsRange
+	}
+
+	filter.DateFrom, filter.DateTo = from, to
+	return nil
+}
+
+// parseStatsDate accepts a calendar date or an RFC3339 timestamp. A calendar date used as
+// the end of a range includes the whole day.
+func parseStatsDate(value string, endOfRange bool) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.UTC(), nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, dto.ErrInvalidStatsRange
+	}
+	if endOfRange {
+		parsed = parsed.Add(24 * time.Hour)
+	}
+	return parsed, nil
+}
+
+func commentStatsScope(filter entity.CommentStatsFilter) string {
+	switch {
+	case filter.PageURL != "":
+		return dto.CommentStatsScopePage
+	case filter.ContentID != "":
+		return dto.CommentStatsScopeContent
+	case filter.ContentType != "":
+		return dto.CommentStatsScopeContentType
+	default:
+		return dto.CommentStatsScopeSite
+	}
+}
+
+func commentStatsCacheKey(kind string, filter entity.CommentStatsFilter) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%s|%s|%d|%d|%d",
+		filter.PageURL,
+		filter.ContentType,
+		filter.ContentID,
+		filter.DateFrom.Unix(),
+		filter.DateTo.Unix(),
+		filter.Limit,
+	)))
+	return "comment:" + kind + ":" + hex.EncodeToString(sum[:])
+}
+
+// applyCommentContent records which title a comment belongs to, preferring the client's
+// reference and otherwise deriving it from paths such as /anime/<slug>/... or /movies/detail/<slug>.
+func applyCommentContent(comment *entity.Comment, req *entity.CommentCreateRequest) {
+	contentType, contentID := req.ContentType, strings.TrimSpace(req.ContentID)
+	if contentType == "" || contentID == "" {
+		contentType, contentID = deriveCommentContent(comment.PageURL)
+	}
+	if contentType == "" || contentID == "" {
+		return
+	}
+
+	comment.ContentType = &contentType
+	comment.ContentID = &contentID
+	if title := strings.TrimSpace(req.ContentTitle); title != "" {
+		comment.ContentTitle = &title
+	}
+}
+
+func deriveCommentContent(pageURL string) (string, string) {
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return "", ""
+	}
+
+	segments := strings.FieldsFunc(strings.ToLower(parsed.Path), func(r rune) bool { return r == '/' })
+	for i, segment := range segments {
+		contentType, ok := commentContentSegments[segment]
+		if !ok {
+			continue
+		}
+		for _, slug := range segments[i+1:] {
+			if slug == "detail" || slug == "watch" || slug == "episode" {
+				continue
+			}
+			return contentType, slug
+		}
+		return "", ""
+	}
+	return "", ""
+}
+
+func parseViewerID(viewerID string) *uuid.UUID {
+	if viewerID == "" {
+		return nil
+	}
+	id, err := uuid.Parse(viewerID)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+func commentAuthorID(comment *entity.Comment) *string {
+	if comment.UserID == nil {
+		return nil
+	}
+	id := comment.UserID.String()
+	return &id
+}
+
+func attachReplies(tree *entity.CommentTree) {
+	tree.Comment.Replies = make([]*entity.Comment, 0, len(tree.Children))
+	for _, child := range tree.Children {
+		attachReplies(child)
+		tree.Comment.Replies = append(tree.Comment.Replies, child.Comment)
+	}
+}
+
+func maskDeletedComments(comments []*entity.Comment) {
+	for _, comment := range comments {
+		if comment.IsDeleted {
+			comment.Comment = ""
+			comment.MediaURL = nil
+		}
+		for _, reply := range comment.Replies {
+			maskDeletedComments([]*entity.Comment{reply})
+		}
+	}
+}
+
+func commentPagination(total int64, limit int, offset int) dto.Pagination {
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	currentPage := offset/limit + 1
+	return dto.Pagination{
+		CurrentPage: currentPage,
+		Limit:       limit,
+		TotalItems:  total,
+		TotalPages:  totalPages,
+		HasNext:     currentPage < totalPages,
+		HasPrev:     currentPage > 1,
+	}
+}
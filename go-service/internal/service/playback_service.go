@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"tubexxi/video-api/config"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+	redisclient "tubexxi/video-api/internal/infrastructure/redis-client"
+	"tubexxi/video-api/internal/infrastructure/repository"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// playbackProgressKey holds a user's latest heartbeats, one hash field per title, until the
+	// worker has written them and a while after, so reads see progress the worker has not
+	// flushed yet.
+	playbackProgressKey = "playback:progress:%s"
+	// playbackDirtyKey is the set of users with heartbeats the worker has not flushed.
+	playbackDirtyKey = "playback:dirty"
+	// playbackNextKey caches the next episode resolved for an episode of a title.
+	playbackNextKey = "playback:next:%s:%s:%s"
+	playbackNextTTL = 6 * time.Hour
+	// playbackNone marks a cached lookup that found no next episode.
+	playbackNone = "none"
+
+	// playbackCompleteRatio is how much of a title counts as having watched it.
+	playbackCompleteRatio = 0.95
+	playbackFlushUsers    = 100
+	playbackFlushRounds   = 50
+
+	defaultContinueWatchingLimit = 20
+)
+
+type PlaybackService struct {
+	playbackRepo repository.PlaybackProgressRepository
+	movies       *MovieService
+	animes       *AnimeService
+	redis        *redisclient.RedisClient
+	cfg          *config.PlaybackConfig
+	logger       *zap.Logger
+}
+
+func NewPlaybackService(
+	playbackRepo repository.PlaybackProgressRepository,
+	movies *MovieService,
+	animes *AnimeService,
+	redis *redisclient.RedisClient,
+	cfg *config.PlaybackConfig,
+	logger *zap.Logger,
+) *PlaybackService {
+	return &PlaybackService{
+		playbackRepo: playbackRepo,
+		movies:       movies,
+		animes:       animes,
+		redis:        redis,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+type playbackNextEpisode struct {
+	Number *int    `json:"number,omitempty"`
+	URL    *string `json:"url,omitempty"`
+}
+
+// Heartbeat stores the player's position in Redis and marks the user for the next flush. A
+// position within the last few percent of the duration completes the title.
+func (s *PlaybackService) Heartbeat(ctx context.Context, userID string, req *dto.PlaybackHeartbeatRequest) (*entity.PlaybackProgress, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 5*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	progress := &entity.PlaybackProgress{
+		UserID:          userUUID,
+		Type:            entity.ViewHistoryType(req.Type),
+		Slug:            strings.ToLower(strings.TrimSpace(req.Slug)),
+		EpisodeNumber:   req.EpisodeNumber,
+		PositionSeconds: req.PositionSeconds,
+		DurationSeconds: req.DurationSeconds,
+		Completed:       req.Completed,
+		WatchedAt:       time.Now().UTC(),
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		progress.Name = &name
+	}
+	if episodeURL := strings.TrimSpace(req.EpisodeURL); episodeURL != "" {
+		progress.EpisodeURL = &episodeURL
+	}
+	if progress.Type == entity.ViewHistoryTypeMovie {
+		progress.EpisodeNumber = nil
+		progress.EpisodeURL = nil
+	}
+	if progress.DurationSeconds > 0 {
+		if progress.PositionSeconds > progress.DurationSeconds {
+			progress.PositionSeconds = progress.DurationSeconds
+		}
+		if float64(progress.PositionSeconds) >= float64(progress.DurationSeconds)*playbackCompleteRatio {
+			progress.Completed = true
+		}
+	}
+
+	payload, err := json.Marshal(progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode playback progress: %w", err)
+	}
+
+	key := fmt.Sprintf(playbackProgressKey, userUUID)
+	_, err = s.redis.Client().TxPipelined(subCtx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(subCtx, key, playbackField(string(progress.Type), progress.Slug), payload)
+		pipe.Expire(subCtx, key, s.progressTTL())
+		pipe.SAdd(subCtx, playbackDirtyKey, userUUID.String())
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("[PlaybackService.Heartbeat]", zap.Error(err))
+		return nil, fmt.Errorf("failed to save playback progress: %w", err)
+	}
+	return progress, nil
+}
+
+// GetProgress returns where the user is in a title, taking a heartbeat not yet flushed into
+// account, so another device can resume from it.
+func (s *PlaybackService) GetProgress(ctx context.Context, userID string, contentType string, slug string) (*entity.PlaybackProgress, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	slug = strings.ToLower(strings.TrimSpace(slug))
+
+	stored, err := s.playbackRepo.Find(ctx, userUUID, contentType, slug)
+	if err != nil && !errors.Is(err, dto.ErrPlaybackProgressNotFound) {
+		return nil, err
+	}
+
+	var pending *entity.PlaybackProgress
+	raw, err := s.redis.Client().HGet(ctx, fmt.Sprintf(playbackProgressKey, userUUID), playbackField(contentType, slug)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		s.logger.Warn("[PlaybackService.GetProgress] failed to read pending progress", zap.Error(err))
+	} else if err == nil {
+		pending = s.decodeProgress(raw)
+	}
+
+	progress := mergePlaybackProgress(stored, pending)
+	if progress == nil {
+		return nil, dto.ErrPlaybackProgressNotFound
+	}
+	return progress, nil
+}
+
+// ContinueWatching lists the titles the user left part way through, and finished episodes with
+// a next one, most recently watched first.
+func (s *PlaybackService) ContinueWatching(ctx context.Context, userID string, query dto.ContinueWatchingQuery) ([]*entity.PlaybackProgress, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultContinueWatchingLimit
+	}
+
+	stored, err := s.playbackRepo.ListInProgress(ctx, userUUID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.redis.Client().HGetAll(ctx, fmt.Sprintf(playbackProgressKey, userUUID)).Result()
+	if err != nil {
+		s.logger.Warn("[PlaybackService.ContinueWatching] failed to read pending progress", zap.Error(err))
+		pending = nil
+	}
+
+	byTitle := make(map[string]*entity.PlaybackProgress, len(stored)+len(pending))
+	for _, item := range stored {
+		byTitle[playbackField(string(item.Type), item.Slug)] = item
+	}
+	for field, raw := range pending {
+		if item := s.decodeProgress(raw); item != nil {
+			byTitle[field] = mergePlaybackProgress(byTitle[field], item)
+		}
+	}
+
+	items := make([]*entity.PlaybackProgress, 0, len(byTitle))
+	for _, item := range byTitle {
+		if item.InProgress() {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(a, b int) bool {
+		return items[a].WatchedAt.After(items[b].WatchedAt)
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// Flush writes the heartbeats of every user marked since the last flush to Postgres, resolving
+// the next episode of series and anime on the way. Users whose progress could not be written
+// are marked again for the next run.
+func (s *PlaybackService) Flush(ctx context.Context) (int, error) {
+	flushed := 0
+	for round := 0; round < playbackFlushRounds; round++ {
+		users, err := s.redis.Client().SPopN(ctx, playbackDirtyKey, playbackFlushUsers).Result()
+		if err != nil {
+			return flushed, fmt.Errorf("failed to read pending playback users: %w", err)
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		var items []*entity.PlaybackProgress
+		for _, userID := range users {
+			pending, err := s.redis.Client().HGetAll(ctx, fmt.Sprintf(playbackProgressKey, userID)).Result()
+			if err != nil {
+				s.remark(ctx, users)
+				return flushed, fmt.Errorf("failed to read pending playback progress: %w", err)
+			}
+			for _, raw := range pending {
+				if item := s.decodeProgress(raw); item != nil {
+					s.resolveNextEpisode(ctx, item)
+					items = append(items, item)
+				}
+			}
+		}
+
+		if err := s.playbackRepo.UpsertBatch(ctx, items); err != nil {
+			s.remark(ctx, users)
+			return flushed, err
+		}
+		flushed += len(items)
+	}
+
+	if flushed > 0 {
+		s.logger.Debug("[PlaybackService.Flush] flushed playback progress", zap.Int("count", flushed))
+	}
+	return flushed, nil
+}
+
+func (s *PlaybackService) remark(ctx context.Context, users []string) {
+	members := make([]interface{}, len(users))
+	for i, user := range users {
+		members[i] = user
+	}
+	if err := s.redis.Client().SAdd(context.WithoutCancel(ctx), playbackDirtyKey, members...).Err(); err != nil {
+		s.logger.Error("[PlaybackService.remark] failed to mark users for the next flush", zap.Error(err))
+	}
+}
+
+// resolveNextEpisode fills in the episode after the one being watched. Lookups go through the
+// scraper and are cached; a failed lookup leaves the pointer empty, which keeps the stored one.
+func (s *PlaybackService) resolveNextEpisode(ctx context.Context, item *entity.PlaybackProgress) {
+	if item.Type == entity.ViewHistoryTypeMovie || (item.EpisodeNumber == nil && item.EpisodeURL == nil) {
+		return
+	}
+
+	episode := ""
+	if item.EpisodeURL != nil {
+		episode = *item.EpisodeURL
+	} else {
+		episode = strconv.Itoa(*item.EpisodeNumber)
+	}
+	cacheKey := fmt.Sprintf(playbackNextKey, item.Type, item.Slug, episode)
+
+	if cached, err := s.redis.Client().Get(ctx, cacheKey).Result(); err == nil {
+		var next playbackNextEpisode
+		if cached != playbackNone && json.Unmarshal([]byte(cached), &next) == nil {
+			item.NextEpisodeNumber, item.NextEpisodeURL = next.Number, next.URL
+		}
+		return
+	}
+
+	var (
+		next *playbackNextEpisode
+		err  error
+	)
+	switch item.Type {
+	case entity.ViewHistoryTypeSeries:
+		next, err = s.nextSeriesEpisode(ctx, item)
+	case entity.ViewHistoryTypeAnime:
+		next, err = s.nextAnimeEpisode(ctx, item)
+	}
+	if err != nil {
+		s.logger.Warn("[PlaybackService.resolveNextEpisode] failed to look up the next episode",
+			zap.String("type", string(item.Type)),
+			zap.String("slug", item.Slug),
+			zap.Error(err),
+		)
+		return
+	}
+
+	value := playbackNone
+	if next != nil {
+		item.NextEpisodeNumber, item.NextEpisodeURL = next.Number, next.URL
+		if encoded, err := json.Marshal(next); err == nil {
+			value = string(encoded)
+		}
+	}
+	if err := s.redis.Client().Set(ctx, cacheKey, value, playbackNextTTL).Err(); err != nil {
+		s.logger.Warn("[PlaybackService.resolveNextEpisode] failed to cache the next episode", zap.Error(err))
+	}
+}
+
+// nextSeriesEpisode finds the current episode in the series' season list, by URL when known,
+// and returns the one after it, which may open the next season.
+func (s *PlaybackService) nextSeriesEpisode(ctx context.Context, item *entity.PlaybackProgress) (*playbackNextEpisode, error) {
+	detail, err := s.movies.GetSeriesDetail(ctx, item.Slug)
+	if err != nil {
+		return nil, err
+	}
+	if detail == nil || detail.SeasonList == nil {
+		return nil, nil
+	}
+
+	var episodes []entity.EpisodeList
+	for _, season := range *detail.SeasonList {
+		if season.EpisodeList != nil {
+			episodes = append(episodes, *season.EpisodeList...)
+		}
+	}
+
+	current := -1
+	for i, episode := range episodes {
+		if item.EpisodeURL != nil && episode.EpisodeUrl != nil && *episode.EpisodeUrl == *item.EpisodeURL {
+			current = i
+			break
+		}
+	}
+	if current < 0 && item.EpisodeNumber != nil {
+		for i, episode := range episodes {
+			if episode.EpisodeNumber != nil && int(*episode.EpisodeNumber) == *item.EpisodeNumber {
+				current = i
+				break
+			}
+		}
+	}
+	if current < 0 || current+1 >= len(episodes) {
+		return nil, nil
+	}
+
+	following := episodes[current+1]
+	next := &playbackNextEpisode{URL: following.EpisodeUrl}
+	if following.EpisodeNumber != nil {
+		number := int(*following.EpisodeNumber)
+		next.Number = &number
+	}
+	return next, nil
+}
+
+// nextAnimeEpisode follows the next-episode link of the current episode page.
+func (s *PlaybackService) nextAnimeEpisode(ctx context.Context, item *entity.PlaybackProgress) (*playbackNextEpisode, error) {
+	if item.EpisodeURL == nil {
+		return nil, nil
+	}
+	episode, err := s.animes.GetEpisode(ctx, *item.EpisodeURL)
+	if err != nil {
+		return nil, err
+	}
+	if episode == nil || episode.NextEpisodeURL == nil || strings.TrimSpace(*episode.NextEpisodeURL) == "" {
+		return nil, nil
+	}
+
+	next := &playbackNextEpisode{URL: episode.NextEpisodeURL}
+	if item.EpisodeNumber != nil {
+		number := *item.EpisodeNumber + 1
+		next.Number = &number
+	}
+	return next, nil
+}
+
+func (s *PlaybackService) decodeProgress(raw string) *entity.PlaybackProgress {
+	var progress entity.PlaybackProgress
+	if err := json.Unmarshal([]byte(raw), &progress); err != nil {
+		s.logger.Warn("[PlaybackService.decodeProgress] dropping unreadable progress", zap.Error(err))
+		return nil
+	}
+	if progress.UserID == uuid.Nil || progress.Slug == "" {
+		return nil
+	}
+	return &progress
+}
+
+func (s *PlaybackService) progressTTL() time.Duration {
+	if s.cfg.ProgressTTL > 0 {
+		return s.cfg.ProgressTTL
+	}
+	return 24 * time.Hour
+}
+
+func playbackField(contentType, slug string) string {
+	return contentType + ":" + slug
+}
+
+// mergePlaybackProgress lays a pending heartbeat over the stored row. The stored row keeps its
+// identity and, while the episode is unchanged, its resolved next episode.
+func mergePlaybackProgress(stored, pending *entity.PlaybackProgress) *entity.PlaybackProgress {
+	if pending == nil {
+		return stored
+	}
+	if stored == nil {
+		return pending
+	}
+	if pending.WatchedAt.Before(stored.WatchedAt) {
+		return stored
+	}
+
+	merged := *pending
+	merged.ID = stored.ID
+	merged.CreatedAt = stored.CreatedAt
+	merged.UpdatedAt = stored.UpdatedAt
+	if merged.Name == nil {
+		merged.Name = stored.Name
+	}
+	if merged.NextEpisodeNumber == nil && merged.NextEpisodeURL == nil && stored.SameEpisode(pending) {
+		merged.NextEpisodeNumber = stored.NextEpisodeNumber
+		merged.NextEpisodeURL = stored.NextEpisodeURL
+	}
+	return &merged
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/centrifugo"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+	"tubexxi/video-api/internal/infrastructure/repository"
+	"tubexxi/video-api/pkg/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type RealtimeService struct {
+	centrifugo   *centrifugo.CentrifugoClient
+	chatRoomRepo repository.ChatRoomRepository
+	chat         *ChatService
+	logger       *zap.Logger
+}
+
+func NewRealtimeService(
+	centrifugo *centrifugo.CentrifugoClient,
+	chatRoomRepo repository.ChatRoomRepository,
+	chat *ChatService,
+	logger *zap.Logger,
+) *RealtimeService {
+	return &RealtimeService{
+		centrifugo:   centrifugo,
+		chatRoomRepo: chatRoomRepo,
+		chat:         chat,
+		logger:       logger,
+	}
+}
+
+func (s *RealtimeService) IssueConnectionToken(ctx context.Context, userID string) (*dto.RealtimeTokenResponse, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	token, expiresAt, err := s.centrifugo.GenerateConnectionToken(userID, nil)
+	if err != nil {
+		s.logger.Error("[RealtimeService.IssueConnectionToken]", zap.Error(err))
+		return nil, fmt.Errorf("failed to issue connection token: %w", err)
+	}
+
+	return &dto.RealtimeTokenResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *RealtimeService) IssueSubscriptionToken(ctx context.Context, userID string, channel string) (*dto.RealtimeTokenResponse, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if err := s.AuthorizeChannel(subCtx, userID, channel); err != nil {
+		return nil, err
+	}
+
+	token, expiresAt, err := s.centrifugo.GenerateSubscriptionToken(userID, channel)
+	if err != nil {
+		s.logger.Error("[RealtimeService.IssueSubscriptionToken]", zap.Error(err))
+		return nil, fmt.Errorf("failed to issue subscription token: %w", err)
+	}
+
+	return &dto.RealtimeTokenResponse{
+		Token:     token,
+		Channel:   channel,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// AuthorizeChannel reports whether the user may subscribe to the channel.
+func (s *RealtimeService) AuthorizeChannel(ctx context.Context, userID string, channel string) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userIDUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	namespace, id := centrifugo.ParseChannel(channel)
+	if id == "" {
+		return dto.ErrInvalidChannel
+	}
+
+	switch namespace {
+	case centrifugo.NamespaceUser:
+		if id != userIDUUID.String() {
+			return dto.ErrPermissionDenied
+		}
+		return nil
+	case centrifugo.NamespaceConversation, centrifugo.NamespaceWatchParty:
+		roomID, err := uuid.Parse(id)
+		if err != nil {
+			return dto.ErrInvalidChannel
+		}
+		isMember, err := s.chatRoomRepo.IsParticipant(subCtx, roomID, userIDUUID)
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			return dto.ErrPermissionDenied
+		}
+		return nil
+	case centrifugo.NamespaceComments:
+		// Comment streams mirror public listings; any signed-in viewer may follow them.
+		if !centrifugo.IsCommentsChannelID(id) {
+			return dto.ErrInvalidChannel
+		}
+		return nil
+	default:
+		return dto.ErrInvalidChannel
+	}
+}
+
+func (s *RealtimeService) ConnectResult(ctx context.Context, user *entity.User) (*dto.CentrifugoConnectResult, error) {
+	info, err := json.Marshal(map[string]interface{}{
+		"name":   user.FullName,
+		"avatar": user.AvatarURL.String,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal connection info: %w", err)
+	}
+
+	return &dto.CentrifugoConnectResult{
+		User:     user.ID.String(),
+		Info:     info,
+		Channels: []string{centrifugo.UserChannel(user.ID.String())},
+	}, nil
+}
+
+// HandlePublish validates a client-side publication and returns the data Centrifugo should broadcast.
+func (s *RealtimeService) HandlePublish(ctx context.Context, userID string, channel string, data json.RawMessage) (json.RawMessage, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	namespace, id := centrifugo.ParseChannel(channel)
+	switch namespace {
+	case centrifugo.NamespaceConversation:
+		if err := s.AuthorizeChannel(subCtx, userID, channel); err != nil {
+			return nil, err
+		}
+
+		var req dto.ConversationPublishRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return nil, fmt.Errorf("%w: %v", dto.ErrInvalidPayload, err)
+		}
+		if errs := utils.ValidateStruct(req); len(errs) > 0 {
+			return nil, fmt.Errorf("%w: %v", dto.ErrInvalidPayload, errs)
+		}
+
+		roomID, _ := uuid.Parse(id)
+		message, err := s.chat.CreateMessage(subCtx, userID, &entity.ChatCreateRequest{
+			GroupID:   &roomID,
+			Message:   &req.Message,
+			Type:      entity.ChatMessageTypeText,
+			ReplyToID: req.ReplyToID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		payload, err := json.Marshal(map[string]interface{}{
+			"type":    "new_message",
+			"message": chatMessagePayload(message),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal publication: %w", err)
+		}
+		return payload, nil
+	default:
+		return nil, dto.ErrPublishForbidden
+	}
+}
+
+func (s *RealtimeService) HandleRPC(ctx context.Context, userID string, method string, data json.RawMessage) (interface{}, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	switch method {
+	case "subscription_token", "presence":
+		var req dto.ChannelRPCRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return nil, fmt.Errorf("%w: %v", dto.ErrInvalidPayload, err)
+		}
+		if errs := utils.ValidateStruct(req); len(errs) > 0 {
+			return nil, fmt.Errorf("%w: %v", dto.ErrInvalidPayload, errs)
+		}
+
+		if method == "subscription_token" {
+			return s.IssueSubscriptionToken(subCtx, userID, req.Channel)
+		}
+
+		if err := s.AuthorizeChannel(subCtx, userID, req.Channel); err != nil {
+			return nil, err
+		}
+		presence, err := s.centrifugo.Presence(subCtx, req.Channel)
+		if err != nil {
+			return nil, err
+		}
+		users := make(map[string]struct{}, len(presence.Presence))
+		for _, client := range presence.Presence {
+			users[client.User] = struct{}{}
+		}
+		online := make([]string, 0, len(users))
+		for user := range users {
+			online = append(online, user)
+		}
+		return map[string]interface{}{
+			"channel": req.Channel,
+			"users":   online,
+		}, nil
+	default:
+		return nil, dto.ErrUnknownRPCMethod
+	}
+}
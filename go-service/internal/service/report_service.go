@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"tubexxi/video-api/config"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/centrifugo"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+	"tubexxi/video-api/internal/infrastructure/repository"
+	"tubexxi/video-api/pkg/telegram"
+
+	"github.com/centrifugal/gocent/v3"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ReportService struct {
+	reportRepo   repository.ReportRepository
+	commentRepo  repository.CommentRepository
+	chatRoomRepo repository.ChatRoomRepository
+	userRepo     repository.UserRepository
+	centrifugo   *centrifugo.CentrifugoClient
+	notifier     telegram.Notifier
+	cfg          *config.ReportConfig
+	logger       *zap.Logger
+}
+
+func NewReportService(
+	reportRepo repository.ReportRepository,
+	commentRepo repository.CommentRepository,
+	chatRoomRepo repository.ChatRoomRepository,
+	userRepo repository.UserRepository,
+	centrifugo *centrifugo.CentrifugoClient,
+	notifier telegram.Notifier,
+	cfg *config.ReportConfig,
+	logger *zap.Logger,
+) *ReportService {
+	return &ReportService{
+		reportRepo:   reportRepo,
+		commentRepo:  commentRepo,
+		chatRoomRepo: chatRoomRepo,
+		userRepo:     userRepo,
+		centrifugo:   centrifugo,
+		notifier:     notifier,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+// reportTarget is the reported content as the report service needs it.
+type reportTarget struct {
+	authorID *uuid.UUID
+	comment  *entity.Comment
+	message  *entity.Chat
+}
+
+// CreateReport files a report and hides the content once enough distinct users reported it.
+func (s *ReportService) CreateReport(ctx context.Context, reporterID string, req *dto.ReportRequest) (*entity.Report, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	reporterUUID, err := uuid.Parse(reporterID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	target, err := s.loadTarget(subCtx, req.TargetType, req.TargetID, &reporterUUID)
+	if err != nil {
+		return nil, err
+	}
+	if target.authorID != nil && *target.authorID == reporterUUID {
+		return nil, dto.ErrReportOwnContent
+	}
+
+	report := &entity.Report{
+		TargetType:     req.TargetType,
+		TargetID:       req.TargetID,
+		TargetAuthorID: target.authorID,
+		ReporterID:     reporterUUID,
+		Reason:         req.Reason,
+	}
+	if details := strings.TrimSpace(req.Details); details != "" {
+		report.Details = &details
+	}
+	if err := s.reportRepo.Create(subCtx, report); err != nil {
+		return nil, err
+	}
+
+	if report.Reason == entity.ReportReasonIllegal {
+		s.notifier.SendAlert(telegram.AlertRequest{
+			Subject: "Content reported as illegal",
+			Message: fmt.Sprintf("%s %s was reported as illegal", report.TargetType, report.TargetID),
+			Metadata: map[string]interface{}{
+				"target_type": report.TargetType,
+				"target_id":   report.TargetID.String(),
+				"reporter_id": reporterID,
+				"timestamp":   time.Now(),
+			},
+		})
+	}
+
+	s.hideIfThresholdReached(subCtx, target, report)
+	return report, nil
+}
+
+func (s *ReportService) hideIfThresholdReached(ctx context.Context, target *reportTarget, report *entity.Report) {
+	threshold := s.cfg.AutoHideThreshold
+	if threshold <= 0 {
+		return
+	}
+
+	reporters, err := s.reportRepo.CountOpenReporters(ctx, report.TargetType, report.TargetID)
+	if err != nil || reporters < int64(threshold) {
+		return
+	}
+
+	hidden, err := s.reportRepo.HideTarget(ctx, report.TargetType, report.TargetID, fmt.Sprintf("hidden after %d reports", reporters))
+	if err != nil {
+		s.logger.Warn("[ReportService.hideIfThresholdReached] failed to hide reported content",
+			zap.String("target_type", report.TargetType),
+			zap.String("target_id", report.TargetID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	if !hidden {
+		return
+	}
+
+	s.logger.Info("[ReportService.hideIfThresholdReached] content hidden",
+		zap.String("target_type", report.TargetType),
+		zap.String("target_id", report.TargetID.String()),
+		zap.Int64("reporters", reporters),
+	)
+	if target.comment != nil {
+		s.publish(ctx, centrifugo.CommentsChannel(target.comment.PageURL), newCommentEvent(dto.CommentEventDeleted, target.comment, false))
+	} else {
+		s.publishMessageEvent(ctx, target.message, dto.ReportEventMessageHidden)
+	}
+}
+
+// ListReports returns report cases grouped by the reported content.
+func (s *ReportService) ListReports(ctx context.Context, filter entity.ReportFilter) ([]*entity.ReportCase, dto.Pagination, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	filter.SetDefaults()
+
+	cases, total, err := s.reportRepo.ListCases(subCtx, filter)
+	if err != nil {
+		return nil, dto.Pagination{}, err
+	}
+	return cases, commentPagination(total, filter.Limit, filter.Offset), nil
+}
+
+// GetReportCase returns one report case with every individual report filed against it.
+func (s *ReportService) GetReportCase(ctx context.Context, targetType string, targetID uuid.UUID) (*entity.ReportCase, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	reportCase, err := s.reportRepo.FindCase(subCtx, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	reportCase.Reports, err = s.reportRepo.FindByTarget(subCtx, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	return reportCase, nil
+}
+
+// ResolveReports closes every open report on a piece of content and applies the admin's action.
+// Dismissing restores content the threshold hid; the other actions keep it hidden.
+func (s *ReportService) ResolveReports(ctx context.Context, adminID string, req *dto.ReportResolveRequest) (*dto.ReportResolveResult, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	reports, err := s.reportRepo.FindByTarget(subCtx, req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	open := openReports(reports)
+	if len(open) == 0 {
+		return nil, dto.ErrReportNotFound
+	}
+
+	target, err := s.loadTarget(subCtx, req.TargetType, req.TargetID, nil)
+	if err != nil && !errors.Is(err, dto.ErrCommentNotFound) && !errors.Is(err, dto.ErrChatMessageNotFound) {
+		return nil, err
+	}
+	if target == nil {
+		target = &reportTarget{authorID: open[0].TargetAuthorID}
+	}
+
+	var note *string
+	if text := strings.TrimSpace(req.Note); text != "" {
+		note = &text
+	}
+
+	switch req.Action {
+	case entity.ReportActionDismiss:
+		err = s.restoreTarget(subCtx, target, req, adminUUID)
+	case entity.ReportActionDelete:
+		err = s.deleteTarget(subCtx, target)
+	case entity.ReportActionWarn:
+		err = s.warnAuthor(subCtx, target, req, open, note)
+	case entity.ReportActionSuspend:
+		err = s.suspendAuthor(subCtx, target, adminUUID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := entity.ReportStatusResolved
+	if req.Action == entity.ReportActionDismiss {
+		status = entity.ReportStatusDismissed
+	}
+	resolved, err := s.reportRepo.Resolve(subCtx, req.TargetType, req.TargetID, status, req.Action, note, adminUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("[ReportService.ResolveReports] reports resolved",
+		zap.String("admin_id", adminID),
+		zap.String("target_type", req.TargetType),
+		zap.String("target_id", req.TargetID.String()),
+		zap.String("action", req.Action),
+		zap.Int64("resolved", resolved),
+	)
+	return &dto.ReportResolveResult{
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Action:     req.Action,
+		Status:     status,
+		Resolved:   resolved,
+	}, nil
+}
+
+func (s *ReportService) restoreTarget(ctx context.Context, target *reportTarget, req *dto.ReportResolveRequest, adminID uuid.UUID) error {
+	restored, err := s.reportRepo.RestoreTarget(ctx, req.TargetType, req.TargetID, adminID)
+	if err != nil || !restored {
+		return err
+	}
+
+	switch {
+	case target.comment != nil:
+		comment, err := s.commentRepo.FindByID(ctx, target.comment.ID)
+		if err != nil {
+			return nil
+		}
+		if !comment.IsGuest() {
+			reputation, err := s.commentRepo.GetReputation(ctx, *comment.UserID)
+			if err != nil || reputation.IsShadowBanned {
+				return nil
+			}
+		}
+		s.publish(ctx, centrifugo.CommentsChannel(comment.PageURL), newCommentEvent(dto.CommentEventCreated, comment, true))
+	case target.message != nil:
+		s.publishMessageEvent(ctx, target.message, dto.ReportEventMessageRestored)
+	}
+	return nil
+}
+
+// deleteTarget soft-deletes the content. Content that no longer exists needs no action.
+func (s *ReportService) deleteTarget(ctx context.Context, target *reportTarget) error {
+	switch {
+	case target.comment != nil:
+		if err := s.commentRepo.SoftDelete(ctx, target.comment.ID); err != nil && !errors.Is(err, dto.ErrCommentNotFound) {
+			return err
+		}
+		s.publish(ctx, centrifugo.CommentsChannel(target.comment.PageURL), newCommentEvent(dto.CommentEventDeleted, target.comment, false))
+	case target.message != nil:
+		if err := s.chatRoomRepo.SoftDeleteMessage(ctx, target.message.ID); err != nil && !errors.Is(err, dto.ErrChatMessageNotFound) {
+			return err
+		}
+		s.publishMessageEvent(ctx, target.message, dto.ChatEventMessageDeleted)
+	}
+	return nil
+}
+
+func (s *ReportService) warnAuthor(ctx context.Context, target *reportTarget, req *dto.ReportResolveRequest, open []*entity.Report, note *string) error {
+	if target.authorID == nil {
+		return dto.ErrReportNoAuthor
+	}
+
+	seen := make(map[string]bool, len(open))
+	reasons := make([]string, 0, len(open))
+	for _, report := range open {
+		if !seen[report.Reason] {
+			seen[report.Reason] = true
+			reasons = append(reasons, report.Reason)
+		}
+	}
+	sort.Strings(reasons)
+
+	warning := &dto.ReportWarning{
+		Type:       dto.ReportEventWarning,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reasons:    reasons,
+		Note:       note,
+		OccurredAt: time.Now().UTC(),
+	}
+	s.publish(ctx, centrifugo.UserChannel(target.authorID.String()), warning)
+	return nil
+}
+
+// suspendAuthor deactivates the author's account and drops their realtime connections.
+// AuthMiddleware rejects inactive users on their next request.
+func (s *ReportService) suspendAuthor(ctx context.Context, target *reportTarget, adminID uuid.UUID) error {
+	if target.authorID == nil {
+		return dto.ErrReportNoAuthor
+	}
+	if *target.authorID == adminID {
+		return dto.ErrPermissionDenied
+	}
+
+	user, err := s.userRepo.FindByID(ctx, *target.authorID)
+	if err != nil {
+		return dto.ErrUserNotFound
+	}
+	if user.Role != nil && (user.Role.IsAdmin() || user.Role.IsSuperAdmin()) {
+		return dto.ErrPermissionDenied
+	}
+	if !user.IsActive {
+		return nil
+	}
+
+	user.IsActive = false
+	if _, err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if err := s.centrifugo.Disconnect(ctx, user.ID.String(), gocent.WithDisconnect(&gocent.Disconnect{
+		Code:   3500,
+		Reason: "account suspended",
+	})); err != nil {
+		s.logger.Warn("[ReportService.suspendAuthor] failed to disconnect suspended user",
+			zap.String("user_id", user.ID.String()),
+			zap.Error(err),
+		)
+	}
+	return nil
+}
+
+// loadTarget resolves reported content. With a viewer it also enforces that the viewer can
+// see the content: published comments, or messages in rooms the viewer belongs to.
+func (s *ReportService) loadTarget(ctx context.Context, targetType string, targetID uuid.UUID, viewerID *uuid.UUID) (*reportTarget, error) {
+	if targetType == entity.ReportTargetChatMessage {
+		message, err := s.chatRoomRepo.FindMessageByID(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if viewerID != nil {
+			if message.IsDeleted {
+				return nil, dto.ErrReportTargetDeleted
+			}
+			isMember, err := s.chatRoomRepo.IsParticipant(ctx, message.RoomID, *viewerID)
+			if err != nil {
+				return nil, err
+			}
+			if !isMember {
+				return nil, dto.ErrChatMessageNotFound
+			}
+		}
+		senderID := message.SenderID
+		return &reportTarget{authorID: &senderID, message: message}, nil
+	}
+
+	comment, err := s.commentRepo.FindByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if viewerID != nil {
+		if comment.IsDeleted {
+			return nil, dto.ErrReportTargetDeleted
+		}
+		if !comment.IsVisibleTo(viewerID) {
+			return nil, dto.ErrCommentNotFound
+		}
+	}
+	return &reportTarget{authorID: comment.UserID, comment: comment}, nil
+}
+
+func (s *ReportService) publishMessageEvent(ctx context.Context, message *entity.Chat, eventType string) {
+	s.publish(ctx, centrifugo.ConversationChannel(message.RoomID.String()), map[string]interface{}{
+		"type":       eventType,
+		"message_id": message.ID.String(),
+	})
+}
+
+// publish pushes a realtime event. Failures are logged only.
+func (s *ReportService) publish(ctx context.Context, channel string, data interface{}) {
+	if err := s.centrifugo.PublishMessage(ctx, channel, data); err != nil {
+		s.logger.Warn("[ReportService.publish] failed to publish report event",
+			zap.String("channel", channel),
+			zap.Error(err),
+		)
+	}
+}
+
+func openReports(reports []*entity.Report) []*entity.Report {
+	open := make([]*entity.Report, 0, len(reports))
+	for _, report := range reports {
+		if report.Status == entity.ReportStatusOpen {
+			open = append(open, report)
+		}
+	}
+	return open
+}
@@ -0,0 +1,755 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+	"tubexxi/video-api/config"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	helpers "tubexxi/video-api/internal/helper"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+	minioclient "tubexxi/video-api/internal/infrastructure/minio-client"
+	redisclient "tubexxi/video-api/internal/infrastructure/redis-client"
+	"tubexxi/video-api/internal/infrastructure/repository"
+	"tubexxi/video-api/pkg/telegram"
+	"tubexxi/video-api/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// contactEmailRateKey counts contact form messages per sender address, which is also where the
+// acknowledgement goes.
+const contactEmailRateKey = "ticket:contact:rate:email:%s"
+
+type TicketService struct {
+	ticketRepo repository.TicketRepository
+	userRepo   repository.UserRepository
+	minio      *minioclient.MinioClient
+	redis      *redisclient.RedisClient
+	mail       *helpers.MailHelper
+	notifier   telegram.Notifier
+	cfg        *config.Config
+	logger     *zap.Logger
+}
+
+func NewTicketService(
+	ticketRepo repository.TicketRepository,
+	userRepo repository.UserRepository,
+	minio *minioclient.MinioClient,
+	redis *redisclient.RedisClient,
+	mail *helpers.MailHelper,
+	notifier telegram.Notifier,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *TicketService {
+	return &TicketService{
+		ticketRepo: ticketRepo,
+		userRepo:   userRepo,
+		minio:      minio,
+		redis:      redis,
+		mail:       mail,
+		notifier:   notifier,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// CreateTicket opens a ticket with its attachments and alerts staff on Telegram.
+func (s *TicketService) CreateTicket(ctx context.Context, userID string, req *dto.TicketCreateRequest, files []*multipart.FileHeader) (*entity.Ticket, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 30*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	ticket := &entity.Ticket{
+		Number:      utils.GenerateTaskNumber(userUUID),
+		UserID:      &userUUID,
+		Source:      entity.TicketSourceApp,
+		ReplyToken:  newReplyToken(),
+		Title:       strings.TrimSpace(req.Title),
+		Description: strings.TrimSpace(req.Description),
+		Category:    req.Category,
+		Priority:    req.Priority,
+	}
+	if ticket.Category == "" {
+		ticket.Category = entity.TicketCategoryGeneral
+	}
+	if ticket.Priority == "" {
+		ticket.Priority = entity.TicketPriorityNormal
+	}
+
+	ticket.Attachments, err = s.storeAttachments(subCtx, userUUID, files)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ticketRepo.Create(subCtx, ticket); err != nil {
+		s.removeAttachments(subCtx, ticket.Attachments)
+		return nil, err
+	}
+
+	s.alertStaff("New support ticket", ticket, map[string]interface{}{
+		"user_id": userID,
+		"title":   ticket.Title,
+	})
+	return ticket, nil
+}
+
+// SubmitContact files a contact form message as a ticket. Visitors without an account are
+// tracked by their email address; signed-in visitors also get the ticket in their own list.
+// The sender is emailed an acknowledgement carrying the reference number; messages per sender
+// address are rate limited, since the form is public and the address is not verified.
+func (s *TicketService) SubmitContact(ctx context.Context, userID string, req *dto.ContactRequest) (*entity.Ticket, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	name := strings.TrimSpace(req.Name)
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if err := s.allowContact(subCtx, email); err != nil {
+		return nil, err
+	}
+	ticket := &entity.Ticket{
+		Number:       utils.GenerateTaskNumber(uuid.New()),
+		Source:       entity.TicketSourceContact,
+		ContactName:  &name,
+		ContactEmail: &email,
+		ReplyToken:   newReplyToken(),
+		Title:        strings.TrimSpace(req.Subject),
+		Description:  strings.TrimSpace(req.Message),
+		Category:     entity.TicketCategoryGeneral,
+		Priority:     entity.TicketPriorityNormal,
+	}
+	if userUUID, err := uuid.Parse(userID); err == nil {
+		ticket.UserID = &userUUID
+	}
+
+	if err := s.ticketRepo.Create(subCtx, ticket); err != nil {
+		return nil, err
+	}
+
+	s.alertStaff("New contact form message", ticket, map[string]interface{}{
+		"name":  name,
+		"email": email,
+	})
+	s.emailRequester(ctx, dto.TicketAcknowledgement, ticket, nil)
+	return ticket, nil
+}
+
+// ListMyTickets pages through the caller's own tickets.
+func (s *TicketService) ListMyTickets(ctx context.Context, userID string, filter entity.TicketFilter) ([]*entity.Ticket, dto.Pagination, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, dto.Pagination{}, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	filter.UserID = &userUUID
+	filter.AssigneeID = ""
+	filter.Unassigned = false
+	return s.ListTickets(ctx, filter)
+}
+
+// ListTickets pages through every ticket for the admin queue.
+func (s *TicketService) ListTickets(ctx context.Context, filter entity.TicketFilter) ([]*entity.Ticket, dto.Pagination, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	filter.SetDefaults()
+	filter.Search = strings.TrimSpace(filter.Search)
+
+	tickets, total, err := s.ticketRepo.List(subCtx, filter)
+	if err != nil {
+		return nil, dto.Pagination{}, err
+	}
+	return tickets, commentPagination(total, filter.Limit, filter.Offset), nil
+}
+
+// GetMyTicket returns one of the caller's tickets with its thread. Tickets of other users are
+// reported as not found.
+func (s *TicketService) GetMyTicket(ctx context.Context, userID string, id uuid.UUID) (*entity.Ticket, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	ticket, err := s.ownTicket(subCtx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return ticket, s.loadThread(subCtx, ticket)
+}
+
+// GetTicket returns any ticket with its thread.
+func (s *TicketService) GetTicket(ctx context.Context, id uuid.UUID) (*entity.Ticket, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	ticket, err := s.ticketRepo.FindByID(subCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ticket, s.loadThread(subCtx, ticket)
+}
+
+// Reply adds the owner's reply to the thread. Replying to a resolved ticket reopens it; closed
+// tickets take no more replies. Staff hear about it on Telegram and the assignee by email.
+func (s *TicketService) Reply(ctx context.Context, userID string, id uuid.UUID, req *dto.TicketReplyRequest, files []*multipart.FileHeader) (*entity.TicketReply, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 30*time.Second)
+	defer cancel()
+
+	ticket, err := s.ownTicket(subCtx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := s.createReply(subCtx, ticket, *ticket.UserID, false, req.Body, files)
+	if err != nil {
+		return nil, err
+	}
+
+	s.alertStaff("Customer replied to ticket", ticket, map[string]interface{}{
+		"user_id": userID,
+		"status":  ticket.Status.String(),
+	})
+	if ticket.AssigneeID != nil {
+		s.emailUser(ctx, *ticket.AssigneeID, dto.TicketReplyNotice, ticket, reply, s.adminTicketLink(ticket), "")
+	}
+	return reply, nil
+}
+
+// StaffReply adds a staff reply to the thread and emails the requester.
+func (s *TicketService) StaffReply(ctx context.Context, adminID string, id uuid.UUID, req *dto.TicketReplyRequest, files []*multipart.FileHeader) (*entity.TicketReply, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 30*time.Second)
+	defer cancel()
+
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	ticket, err := s.ticketRepo.FindByID(subCtx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := s.createReply(subCtx, ticket, adminUUID, true, req.Body, files)
+	if err != nil {
+		return nil, err
+	}
+
+	s.emailRequester(ctx, dto.TicketReplyNotice, ticket, reply)
+	return reply, nil
+}
+
+// AssignTicket sets or clears the staff member responsible for a ticket. Only active admins
+// can be assigned.
+func (s *TicketService) AssignTicket(ctx context.Context, adminID string, id uuid.UUID, req *dto.TicketAssignRequest) (*entity.Ticket, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if req.AssigneeID != nil {
+		assignee, err := s.userRepo.FindByID(subCtx, *req.AssigneeID)
+		if err != nil || !assignee.IsActive || assignee.Role == nil || !(assignee.Role.IsAdmin() || assignee.Role.IsSuperAdmin()) {
+			return nil, dto.ErrTicketAssigneeInvalid
+		}
+	}
+	if err := s.ticketRepo.Assign(subCtx, id, req.AssigneeID); err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.ticketRepo.FindByID(subCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.AssigneeID != nil {
+		s.alertStaff("Ticket assigned", ticket, map[string]interface{}{
+			"assigned_by": adminID,
+			"assignee":    assigneeLabel(ticket),
+		})
+		if req.AssigneeID.String() != adminID {
+			s.emailUser(ctx, *req.AssigneeID, dto.TicketAssignedNotice, ticket, nil, s.adminTicketLink(ticket), "")
+		}
+	}
+	return ticket, nil
+}
+
+// UpdatePriority changes a ticket's priority. Raising it to urgent alerts staff on Telegram.
+func (s *TicketService) UpdatePriority(ctx context.Context, adminID string, id uuid.UUID, req *dto.TicketPriorityRequest) (*entity.Ticket, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if err := s.ticketRepo.UpdatePriority(subCtx, id, req.Priority); err != nil {
+		return nil, err
+	}
+	ticket, err := s.ticketRepo.FindByID(subCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Priority == entity.TicketPriorityUrgent {
+		s.alertStaff("Ticket escalated to urgent", ticket, map[string]interface{}{
+			"changed_by": adminID,
+			"assignee":   assigneeLabel(ticket),
+		})
+	}
+	return ticket, nil
+}
+
+// UpdateCategory files a ticket under another category, which may change its SLA targets.
+func (s *TicketService) UpdateCategory(ctx context.Context, id uuid.UUID, req *dto.TicketCategoryRequest) (*entity.Ticket, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	if err := s.ticketRepo.UpdateCategory(subCtx, id, req.Category); err != nil {
+		return nil, err
+	}
+	return s.ticketRepo.FindByID(subCtx, id)
+}
+
+// UpdateStatus moves a ticket along open → resolved → closed, or reopens a resolved ticket.
+// An optional note is posted as a staff reply first, and the requester is emailed about the change.
+func (s *TicketService) UpdateStatus(ctx context.Context, adminID string, id uuid.UUID, req *dto.TicketStatusRequest) (*entity.Ticket, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	ticket, err := s.ticketRepo.FindByID(subCtx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	next := entity.TicketStatus(req.Status)
+	if !ticket.Status.CanTransitionTo(next) {
+		return nil, dto.ErrTicketTransition
+	}
+
+	var reply *entity.TicketReply
+	if note := strings.TrimSpace(req.Note); note != "" {
+		reply = &entity.TicketReply{TicketID: ticket.ID, AuthorID: &adminUUID, Body: note, IsStaff: true, Via: entity.TicketReplyViaApp}
+		if err := s.ticketRepo.CreateReply(subCtx, reply, false); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.ticketRepo.UpdateStatus(subCtx, ticket.ID, ticket.Status, next); err != nil {
+		return nil, err
+	}
+
+	ticket, err = s.ticketRepo.FindByID(subCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.emailRequester(ctx, dto.TicketStatusNotice, ticket, reply)
+	return ticket, nil
+}
+
+// HandleInboundEmail threads an emailed reply into the ticket named by the reply token in the
+// recipient address. Only the requester is mailed that address, so only mail from the
+// requester is accepted; staff answer from the admin panel, since a From header alone cannot
+// prove who sent a message. Anything else, and replies to closed tickets, is rejected with
+// dto.ErrInboundMailRejected. Redelivered messages are recognised by their Message-ID and
+// accepted without a second reply.
+func (s *TicketService) HandleInboundEmail(ctx context.Context, sender string, recipients []string, raw []byte) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 30*time.Second)
+	defer cancel()
+
+	token := s.inboundToken(recipients)
+	if token == "" {
+		return fmt.Errorf("%w: no reply address", dto.ErrInboundMailRejected)
+	}
+	email, err := utils.ParseInboundEmail(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %v", dto.ErrInboundMailRejected, err)
+	}
+	if email.From == "" {
+		email.From = strings.ToLower(strings.TrimSpace(sender))
+	}
+	body := []rune(email.Text)
+	if len(body) == 0 {
+		return fmt.Errorf("%w: empty message", dto.ErrInboundMailRejected)
+	}
+	if len(body) > 10000 {
+		body = body[:10000]
+	}
+
+	ticket, err := s.ticketRepo.FindByReplyToken(subCtx, token)
+	if errors.Is(err, dto.ErrTicketNotFound) {
+		return fmt.Errorf("%w: unknown reply address", dto.ErrInboundMailRejected)
+	}
+	if err != nil {
+		return err
+	}
+	if ticket.Status.IsClosed() {
+		return fmt.Errorf("%w: %v", dto.ErrInboundMailRejected, dto.ErrTicketClosed)
+	}
+
+	reply := &entity.TicketReply{
+		TicketID: ticket.ID,
+		Body:     strings.TrimSpace(string(body)),
+		Via:      entity.TicketReplyViaEmail,
+	}
+	if email.MessageID != "" {
+		reply.MessageID = &email.MessageID
+	}
+
+	if !s.isRequester(subCtx, ticket, email.From) {
+		return fmt.Errorf("%w: sender is not the requester", dto.ErrInboundMailRejected)
+	}
+	reply.AuthorID = ticket.UserID
+
+	if err := s.ticketRepo.CreateReply(subCtx, reply, true); err != nil {
+		if errors.Is(err, dto.ErrTicketReplyDuplicate) {
+			return nil
+		}
+		return err
+	}
+	applyReply(ticket, reply)
+
+	s.alertStaff("Customer replied to ticket by email", ticket, map[string]interface{}{
+		"from":   email.From,
+		"status": ticket.Status.String(),
+	})
+	if ticket.AssigneeID != nil {
+		s.emailUser(ctx, *ticket.AssigneeID, dto.TicketReplyNotice, ticket, reply, s.adminTicketLink(ticket), "")
+	}
+	return nil
+}
+
+// inboundToken finds the reply token in the first recipient addressed to the support mailbox.
+func (s *TicketService) inboundToken(recipients []string) string {
+	local, domain, ok := splitMailbox(s.cfg.Ticket.InboundAddress)
+	if !ok {
+		return ""
+	}
+	for _, recipient := range recipients {
+		rcptLocal, rcptDomain, ok := splitMailbox(recipient)
+		if !ok || rcptDomain != domain {
+			continue
+		}
+		base, token, found := strings.Cut(rcptLocal, "+")
+		if found && base == local && token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// isRequester reports whether the address belongs to whoever opened the ticket.
+func (s *TicketService) isRequester(ctx context.Context, ticket *entity.Ticket, address string) bool {
+	if address == "" {
+		return false
+	}
+	if ticket.ContactEmail != nil && strings.EqualFold(*ticket.ContactEmail, address) {
+		return true
+	}
+	if ticket.UserID == nil {
+		return false
+	}
+	user, err := s.userRepo.FindByID(ctx, *ticket.UserID)
+	return err == nil && strings.EqualFold(user.Email, address)
+}
+
+func (s *TicketService) ownTicket(ctx context.Context, userID string, id uuid.UUID) (*entity.Ticket, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	ticket, err := s.ticketRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.UserID == nil || *ticket.UserID != userUUID {
+		return nil, dto.ErrTicketNotFound
+	}
+	return ticket, nil
+}
+
+func (s *TicketService) createReply(ctx context.Context, ticket *entity.Ticket, authorID uuid.UUID, isStaff bool, body string, files []*multipart.FileHeader) (*entity.TicketReply, error) {
+	if ticket.Status.IsClosed() {
+		return nil, dto.ErrTicketClosed
+	}
+
+	attachments, err := s.storeAttachments(ctx, authorID, files)
+	if err != nil {
+		return nil, err
+	}
+	reply := &entity.TicketReply{
+		TicketID:    ticket.ID,
+		AuthorID:    &authorID,
+		Body:        strings.TrimSpace(body),
+		IsStaff:     isStaff,
+		Via:         entity.TicketReplyViaApp,
+		Attachments: attachments,
+	}
+	if err := s.ticketRepo.CreateReply(ctx, reply, !isStaff); err != nil {
+		s.removeAttachments(ctx, attachments)
+		return nil, err
+	}
+	applyReply(ticket, reply)
+	return reply, nil
+}
+
+// applyReply mirrors on the loaded ticket what CreateReply did in the database: a customer
+// reply reopens a resolved ticket.
+func applyReply(ticket *entity.Ticket, reply *entity.TicketReply) {
+	if !reply.IsStaff && ticket.Status.IsResolved() {
+		ticket.Status = entity.TicketStatusOpen
+		ticket.ResolvedAt = nil
+	}
+	ticket.LastReplyAt = &reply.CreatedAt
+}
+
+// loadThread attaches the replies to the ticket and sorts the attachments onto the opening
+// message or the reply they came with.
+func (s *TicketService) loadThread(ctx context.Context, ticket *entity.Ticket) error {
+	replies, err := s.ticketRepo.ListReplies(ctx, ticket.ID)
+	if err != nil {
+		return err
+	}
+	attachments, err := s.ticketRepo.ListAttachments(ctx, ticket.ID)
+	if err != nil {
+		return err
+	}
+
+	byReply := make(map[uuid.UUID]*entity.TicketReply, len(replies))
+	for _, reply := range replies {
+		byReply[reply.ID] = reply
+	}
+	for _, attachment := range attachments {
+		if attachment.ReplyID == nil {
+			ticket.Attachments = append(ticket.Attachments, attachment)
+			continue
+		}
+		if reply, ok := byReply[*attachment.ReplyID]; ok {
+			reply.Attachments = append(reply.Attachments, attachment)
+		}
+	}
+	ticket.Replies = replies
+	return nil
+}
+
+// storeAttachments uploads the files to object storage. Nothing is kept if any file is
+// rejected or fails to upload.
+func (s *TicketService) storeAttachments(ctx context.Context, uploaderID uuid.UUID, files []*multipart.FileHeader) ([]*entity.TicketAttachment, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	limits := &s.cfg.Ticket
+	if len(files) > limits.MaxAttachments {
+		return nil, dto.ErrTicketTooManyAttachments
+	}
+
+	attachments := make([]*entity.TicketAttachment, 0, len(files))
+	for _, file := range files {
+		attachment, err := s.storeAttachment(ctx, uploaderID, file, limits.MaxAttachmentSize)
+		if err != nil {
+			s.removeAttachments(ctx, attachments)
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+func (s *TicketService) storeAttachment(ctx context.Context, uploaderID uuid.UUID, file *multipart.FileHeader, maxSize int64) (*entity.TicketAttachment, error) {
+	if file.Size > maxSize {
+		return nil, dto.ErrTicketAttachmentTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload: %w", err)
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, dto.ErrTicketAttachmentEmpty
+	}
+	if int64(len(data)) > maxSize {
+		return nil, dto.ErrTicketAttachmentTooLarge
+	}
+
+	name := chatMediaName(file.Filename)
+	mimeType := http.DetectContentType(data)
+	attachment := &entity.TicketAttachment{
+		UploaderID: &uploaderID,
+		ObjectKey:  fmt.Sprintf("tickets/%s/%s%s", uploaderID, uuid.New(), chatMediaExtension(name)),
+		Name:       name,
+		Size:       int64(len(data)),
+		MimeType:   mimeType,
+	}
+
+	attachment.URL, err = s.minio.UploadFile(ctx, s.cfg.MinIO.MinioBucketName, attachment.ObjectKey, bytes.NewReader(data), attachment.Size, chatStoredContentType(mimeType))
+	if err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (s *TicketService) removeAttachments(ctx context.Context, attachments []*entity.TicketAttachment) {
+	for _, attachment := range attachments {
+		if err := s.minio.DeleteFile(ctx, s.cfg.MinIO.MinioBucketName, attachment.ObjectKey); err != nil {
+			s.logger.Warn("[TicketService.removeAttachments] failed to delete object", zap.String("object", attachment.ObjectKey), zap.Error(err))
+		}
+	}
+}
+
+func (s *TicketService) alertStaff(subject string, ticket *entity.Ticket, metadata map[string]interface{}) {
+	metadata["ticket_id"] = ticket.ID.String()
+	metadata["number"] = ticket.Number
+	metadata["priority"] = ticket.Priority
+	metadata["timestamp"] = time.Now()
+
+	s.notifier.SendAlert(telegram.AlertRequest{
+		Subject:  subject,
+		Message:  fmt.Sprintf("%s: %s", ticket.Number, ticket.Title),
+		Metadata: metadata,
+	})
+}
+
+// emailUser sends a ticket email in the background so SMTP latency never holds up the request.
+// replyTo is left empty for staff, whose emailed replies would not be accepted.
+func (s *TicketService) emailUser(ctx context.Context, userID uuid.UUID, mailType dto.TypeVerify, ticket *entity.Ticket, reply *entity.TicketReply, link, replyTo string) {
+	s.sendTicketEmail(ctx, mailType, ticket, reply, link, replyTo, func(ctx context.Context) (*entity.User, error) {
+		return s.userRepo.FindByID(ctx, userID)
+	})
+}
+
+// allowContact counts contact form messages per sender address and refuses the ones over
+// TICKET_CONTACT_LIMIT_PER_EMAIL within the window. A Redis failure lets the message through.
+func (s *TicketService) allowContact(ctx context.Context, email string) error {
+	limit := s.cfg.Ticket.ContactLimitPerEmail
+	if limit <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf(contactEmailRateKey, hashGuestKey(email))
+	var count *redis.IntCmd
+	_, err := s.redis.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		count = pipe.Incr(ctx, key)
+		pipe.ExpireNX(ctx, key, s.cfg.Ticket.ContactRateWindow)
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("[TicketService.allowContact] rate limit check failed", zap.Error(err))
+		return nil
+	}
+	if count.Val() > int64(limit) {
+		return dto.ErrContactRateLimited
+	}
+	return nil
+}
+
+// emailRequester emails whoever opened the ticket: the account holder, or the address given on
+// the contact form. Anonymous requesters have no ticket page to link to and follow the thread
+// by replying to the email instead. Only the requester's mail carries the reply address.
+func (s *TicketService) emailRequester(ctx context.Context, mailType dto.TypeVerify, ticket *entity.Ticket, reply *entity.TicketReply) {
+	if ticket.UserID != nil {
+		s.emailUser(ctx, *ticket.UserID, mailType, ticket, reply, s.ticketLink(ticket), s.replyAddress(ticket))
+		return
+	}
+	if ticket.ContactEmail == nil {
+		return
+	}
+
+	requester := &entity.User{Email: *ticket.ContactEmail}
+	if ticket.ContactName != nil {
+		requester.FullName = *ticket.ContactName
+	}
+	s.sendTicketEmail(ctx, mailType, ticket, reply, "", s.replyAddress(ticket), func(context.Context) (*entity.User, error) {
+		return requester, nil
+	})
+}
+
+func (s *TicketService) sendTicketEmail(ctx context.Context, mailType dto.TypeVerify, ticket *entity.Ticket, reply *entity.TicketReply, link, replyTo string, recipient func(context.Context) (*entity.User, error)) {
+	snapshot := *ticket
+	var replySnapshot *entity.TicketReply
+	if reply != nil {
+		copied := *reply
+		replySnapshot = &copied
+	}
+
+	go func() {
+		subCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+
+		user, err := recipient(subCtx)
+		if err != nil {
+			return
+		}
+		err = s.mail.SendEmail(&dto.SendMailMetaData{
+			Type:        mailType,
+			To:          user.Email,
+			User:        user,
+			Ticket:      &snapshot,
+			TicketReply: replySnapshot,
+			Link:        link,
+			ReplyTo:     replyTo,
+		}, s.cfg.App.ClientUrl)
+		if err != nil {
+			s.logger.Warn("[TicketService.sendTicketEmail] failed to send email",
+				zap.String("to", user.Email),
+				zap.String("ticket_id", snapshot.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// replyAddress is the plus-address of the support mailbox that threads replies into the
+// ticket, or empty when inbound mail is not configured.
+func (s *TicketService) replyAddress(ticket *entity.Ticket) string {
+	local, domain, ok := splitMailbox(s.cfg.Ticket.InboundAddress)
+	if !ok || ticket.ReplyToken == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s+%s@%s", local, ticket.ReplyToken, domain)
+}
+
+func (s *TicketService) ticketLink(ticket *entity.Ticket) string {
+	return fmt.Sprintf("%s/support/tickets/%s", strings.TrimRight(s.cfg.App.ClientUrl, "/"), ticket.ID)
+}
+
+func (s *TicketService) adminTicketLink(ticket *entity.Ticket) string {
+	return fmt.Sprintf("%s/admin/tickets/%s", strings.TrimRight(s.cfg.App.ClientUrl, "/"), ticket.ID)
+}
+
+func assigneeLabel(ticket *entity.Ticket) string {
+	if ticket.AssigneeName != nil && *ticket.AssigneeName != "" {
+		return *ticket.AssigneeName
+	}
+	if ticket.AssigneeID != nil {
+		return ticket.AssigneeID.String()
+	}
+	return "unassigned"
+}
+
+// newReplyToken returns the unguessable token that addresses a ticket's reply mailbox.
+func newReplyToken() string {
+	return strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+// splitMailbox returns the lowercased local part and domain of an address.
+func splitMailbox(address string) (string, string, bool) {
+	parsed, err := mail.ParseAddress(strings.TrimSpace(address))
+	if err != nil {
+		return "", "", false
+	}
+	at := strings.LastIndexByte(parsed.Address, '@')
+	if at <= 0 || at == len(parsed.Address)-1 {
+		return "", "", false
+	}
+	return strings.ToLower(parsed.Address[:at]), strings.ToLower(parsed.Address[at+1:]), true
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"tubexxi/video-api/config"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	helpers "tubexxi/video-api/internal/helper"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+	"tubexxi/video-api/internal/infrastructure/repository"
+	"tubexxi/video-api/pkg/telegram"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ticketSLABatch caps how many breaches one check escalates; the rest wait for the next run.
+const ticketSLABatch = 100
+
+type TicketSLAService struct {
+	slaRepo  repository.TicketSLARepository
+	userRepo repository.UserRepository
+	mail     *helpers.MailHelper
+	notifier telegram.Notifier
+	cfg      *config.Config
+	logger   *zap.Logger
+}
+
+func NewTicketSLAService(
+	slaRepo repository.TicketSLARepository,
+	userRepo repository.UserRepository,
+	mail *helpers.MailHelper,
+	notifier telegram.Notifier,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *TicketSLAService {
+	return &TicketSLAService{
+		slaRepo:  slaRepo,
+		userRepo: userRepo,
+		mail:     mail,
+		notifier: notifier,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+func (s *TicketSLAService) ListPolicies(ctx context.Context) ([]*entity.TicketSLAPolicy, error) {
+	return s.slaRepo.ListPolicies(ctx)
+}
+
+// SavePolicy creates or replaces the policy for the request's category and priority.
+func (s *TicketSLAService) SavePolicy(ctx context.Context, req *dto.TicketSLAPolicyRequest) (*entity.TicketSLAPolicy, error) {
+	if req.ResolutionMinutes < req.FirstResponseMinutes {
+		return nil, dto.ErrTicketSLAPolicyInvalid
+	}
+
+	policy := &entity.TicketSLAPolicy{
+		Category:             req.Category,
+		Priority:             req.Priority,
+		FirstResponseMinutes: req.FirstResponseMinutes,
+		ResolutionMinutes:    req.ResolutionMinutes,
+		IsActive:             req.IsActive == nil || *req.IsActive,
+	}
+	if policy.Category != nil && *policy.Category == "" {
+		policy.Category = nil
+	}
+	if err := s.slaRepo.UpsertPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *TicketSLAService) DeletePolicy(ctx context.Context, id uuid.UUID) error {
+	return s.slaRepo.DeletePolicy(ctx, id)
+}
+
+// Report returns SLA compliance and response medians for tickets opened in the last
+// query.Days days (30 by default), with the current backlog.
+func (s *TicketSLAService) Report(ctx context.Context, query dto.TicketSLAReportQuery) (*entity.TicketSLAReport, error) {
+	if query.Days <= 0 {
+		query.Days = 30
+	}
+	since := time.Now().UTC().AddDate(0, 0, -query.Days)
+
+	report, err := s.slaRepo.Report(ctx, since, query.Category)
+	if err != nil {
+		return nil, err
+	}
+	report.FirstResponse.ComplianceRate = complianceRate(report.FirstResponse)
+	report.Resolution.ComplianceRate = complianceRate(report.Resolution)
+	report.FirstResponse.MedianMinutes = roundedMetric(report.FirstResponse.MedianMinutes)
+	report.Resolution.MedianMinutes = roundedMetric(report.Resolution.MedianMinutes)
+	report.Backlog.OldestAgeHours = roundedMetric(report.Backlog.OldestAgeHours)
+	report.Backlog.MedianAgeHours = roundedMetric(report.Backlog.MedianAgeHours)
+	report.Backlog.AverageAgeHours = roundedMetric(report.Backlog.AverageAgeHours)
+	return report, nil
+}
+
+// EscalateBreaches alerts staff on Telegram about every open ticket that missed a target and
+// emails the assignee, or the escalation address when nobody is assigned. Each breach is
+// escalated once. It returns how many breaches were escalated.
+func (s *TicketSLAService) EscalateBreaches(ctx context.Context) (int, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 2*time.Minute)
+	defer cancel()
+
+	breaches, err := s.slaRepo.ListBreaches(subCtx, ticketSLABatch)
+	if err != nil {
+		return 0, err
+	}
+
+	escalated := 0
+	for _, breach := range breaches {
+		claimed, err := s.slaRepo.ClaimEscalation(subCtx, breach.TicketID, breach.Kind)
+		if err != nil {
+			return escalated, err
+		}
+		if !claimed {
+			continue
+		}
+		escalated++
+
+		assignee := "unassigned"
+		if breach.AssigneeName != nil && *breach.AssigneeName != "" {
+			assignee = *breach.AssigneeName
+		}
+		s.notifier.SendAlert(telegram.AlertRequest{
+			Subject: "Ticket SLA breached",
+			Message: fmt.Sprintf("%s: %s", breach.Number, breach.Title),
+			Metadata: map[string]interface{}{
+				"ticket_id": breach.TicketID.String(),
+				"target":    breach.Kind,
+				"due_at":    breach.DueAt,
+				"category":  breach.Category,
+				"priority":  breach.Priority,
+				"assignee":  assignee,
+				"timestamp": time.Now(),
+			},
+		})
+		s.emailBreach(subCtx, breach)
+	}
+
+	if escalated > 0 {
+		s.logger.Info("[TicketSLAService.EscalateBreaches] escalated SLA breaches", zap.Int("count", escalated))
+	}
+	return escalated, nil
+}
+
+func (s *TicketSLAService) emailBreach(ctx context.Context, breach *entity.TicketSLABreach) {
+	var recipient *entity.User
+	if breach.AssigneeID != nil {
+		if user, err := s.userRepo.FindByID(ctx, *breach.AssigneeID); err == nil && user.IsActive {
+			recipient = user
+		}
+	}
+	if recipient == nil {
+		if s.cfg.Ticket.SLAEscalationEmail == "" {
+			return
+		}
+		recipient = &entity.User{Email: s.cfg.Ticket.SLAEscalationEmail, FullName: "Support team"}
+	}
+
+	ticket := &entity.Ticket{
+		ID:        breach.TicketID,
+		Number:    breach.Number,
+		Title:     breach.Title,
+		Category:  breach.Category,
+		Status:    entity.TicketStatusOpen,
+		Priority:  breach.Priority,
+		CreatedAt: breach.CreatedAt,
+	}
+	err := s.mail.SendEmail(&dto.SendMailMetaData{
+		Type:      dto.TicketSLABreachNotice,
+		To:        recipient.Email,
+		User:      recipient,
+		Ticket:    ticket,
+		SLABreach: breach,
+		Link:      fmt.Sprintf("%s/admin/tickets/%s", strings.TrimRight(s.cfg.App.ClientUrl, "/"), breach.TicketID),
+	}, s.cfg.App.ClientUrl)
+	if err != nil {
+		s.logger.Warn("[TicketSLAService.emailBreach] failed to send email",
+			zap.String("to", recipient.Email),
+			zap.String("ticket_id", breach.TicketID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// complianceRate is the percentage of decided tickets that met the target, or nil before any
+// ticket has met or missed it.
+func complianceRate(metric entity.TicketSLAMetric) *float64 {
+	decided := metric.Met + metric.Breached
+	if decided == 0 {
+		return nil
+	}
+	rate := math.Round(float64(metric.Met)/float64(decided)*10000) / 100
+	return &rate
+}
+
+func roundedMetric(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	rounded := math.Round(*value*100) / 100
+	return &rounded
+}
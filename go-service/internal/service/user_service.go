@@ -211,6 +211,11 @@ func (s *UserService) UpdateProfile(ctx context.Context, userID string, req *dto
 		IsVerified:      user.IsVerified,
 		EmailVerifiedAt: user.EmailVerifiedAt,
 	}
+	if req.Username != nil && *req.Username != user.Username.String {
+		if err := s.userRepo.UpdateUsername(subCtx, user.ID, *req.Username); err != nil {
+			return nil, err
+		}
+	}
 	userUpdate, err := s.userRepo.Update(subCtx, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user profile: %w", err)
@@ -347,3 +352,53 @@ func (s *UserService) ActivateTwoFactor(ctx context.Context, userID string, req
 	}
 	return nil
 }
+func (s *UserService) BlockUser(ctx context.Context, userID string, targetID string) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userIDUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+	targetIDUUID, err := uuid.Parse(targetID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+	if userIDUUID == targetIDUUID {
+		return dto.ErrCannotBlockSelf
+	}
+	if _, err := s.userRepo.FindByID(subCtx, targetIDUUID); err != nil {
+		return dto.ErrUserNotFound
+	}
+	return s.userRepo.Block(subCtx, userIDUUID, targetIDUUID)
+}
+func (s *UserService) UnblockUser(ctx context.Context, userID string, targetID string) error {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userIDUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+	targetIDUUID, err := uuid.Parse(targetID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+	return s.userRepo.Unblock(subCtx, userIDUUID, targetIDUUID)
+}
+func (s *UserService) ListBlockedUsers(ctx context.Context, userID string, filter entity.UserBlockFilter) ([]*entity.UserBlock, dto.Pagination, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userIDUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, dto.Pagination{}, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	filter.SetDefaults()
+
+	blocks, total, err := s.userRepo.ListBlocked(subCtx, userIDUUID, filter)
+	if err != nil {
+		return nil, dto.Pagination{}, err
+	}
+	return blocks, commentPagination(total, filter.Limit, filter.Offset), nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"tubexxi/video-api/config"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	asynqclient "tubexxi/video-api/internal/infrastructure/asynq-client"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+	redisclient "tubexxi/video-api/internal/infrastructure/redis-client"
+	"tubexxi/video-api/internal/infrastructure/repository"
+	"tubexxi/video-api/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// viewHistoryUserAgentMax bounds the stored User-Agent; anything longer is not a real browser.
+const viewHistoryUserAgentMax = 512
+
+type ViewHistoryService struct {
+	viewHistoryRepo repository.ViewHistoryRepository
+	redis           *redisclient.RedisClient
+	asynq           *asynqclient.AsynqClientWrapper
+	cfg             *config.ViewHistoryConfig
+	logger          *zap.Logger
+}
+
+func NewViewHistoryService(
+	viewHistoryRepo repository.ViewHistoryRepository,
+	redis *redisclient.RedisClient,
+	asynq *asynqclient.AsynqClientWrapper,
+	cfg *config.ViewHistoryConfig,
+	logger *zap.Logger,
+) *ViewHistoryService {
+	return &ViewHistoryService{
+		viewHistoryRepo: viewHistoryRepo,
+		redis:           redis,
+		asynq:           asynq,
+		cfg:             cfg,
+		logger:          logger,
+	}
+}
+
+// Record queues a view of a title for the worker to write. A view of a title the user already
+// viewed within the dedup window is dropped, and Record reports false.
+func (s *ViewHistoryService) Record(ctx context.Context, userID string, req *dto.ViewHistoryRecordRequest) (bool, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 5*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return false, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	key := fmt.Sprintf("view_history:%s:%s:%s", userUUID, req.Type, slug)
+	acquired, err := s.redis.Client().SetNX(subCtx, key, 1, s.dedupWindow()).Result()
+	if err != nil {
+		// The worker repeats the check against the table, so a Redis outage only costs the
+		// early exit.
+		s.logger.Warn("[ViewHistoryService.Record] failed to check view dedup", zap.Error(err))
+	} else if !acquired {
+		return false, nil
+	}
+
+	view := &entity.ViewHistory{
+		UserID:   userUUID,
+		Type:     entity.ViewHistoryType(req.Type),
+		Slug:     &slug,
+		PageURL:  &req.PageURL,
+		Platform: req.Platform,
+		ViewTime: time.Now().UTC(),
+	}
+	if view.Platform == "" {
+		view.Platform = "web"
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		view.Name = &name
+	}
+	if req.IPAddress != "" {
+		view.IPAddress = &req.IPAddress
+	}
+	if userAgent := strings.TrimSpace(req.UserAgent); userAgent != "" {
+		if len(userAgent) > viewHistoryUserAgentMax {
+			userAgent = userAgent[:viewHistoryUserAgentMax]
+		}
+		view.UserAgent = &userAgent
+	}
+
+	agent := utils.ParseUserAgent(req.UserAgent)
+	view.DeviceType = &agent.DeviceType
+	if agent.Browser != "" {
+		browser := agent.Browser
+		if agent.BrowserVersion != "" {
+			browser += " " + agent.BrowserVersion
+		}
+		view.Browser = &browser
+	}
+	if language := utils.PreferredLanguage(req.AcceptLanguage); language != "" {
+		view.BrowserLanguage = &language
+	}
+
+	err = s.asynq.EnqueueTask(
+		dto.TaskViewHistoryRecord,
+		view,
+		asynq.Queue("low"),
+		asynq.Group(dto.TaskViewHistoryBatch),
+	)
+	if err != nil {
+		// Let the next ping try again rather than swallowing the view for a whole window.
+		if delErr := s.redis.Client().Del(subCtx, key).Err(); delErr != nil {
+			s.logger.Warn("[ViewHistoryService.Record] failed to release view dedup", zap.Error(delErr))
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// FlushBatch writes a batch of queued views, dropping any that are incomplete.
+func (s *ViewHistoryService) FlushBatch(ctx context.Context, views []*entity.ViewHistory) (int64, error) {
+	valid := make([]*entity.ViewHistory, 0, len(views))
+	for _, view := range views {
+		if view == nil || view.UserID == uuid.Nil || !view.IsValid() || view.Slug == nil || view.PageURL == nil {
+			continue
+		}
+		valid = append(valid, view)
+	}
+
+	inserted, err := s.viewHistoryRepo.InsertBatch(ctx, valid, s.dedupWindow())
+	if err != nil {
+		return 0, err
+	}
+	if skipped := int64(len(views)) - inserted; skipped > 0 {
+		s.logger.Debug("[ViewHistoryService.FlushBatch] skipped views",
+			zap.Int64("inserted", inserted),
+			zap.Int64("skipped", skipped),
+		)
+	}
+	return inserted, nil
+}
+
+func (s *ViewHistoryService) dedupWindow() time.Duration {
+	if s.cfg.DedupWindow > 0 {
+		return s.cfg.DedupWindow
+	}
+	return 30 * time.Minute
+}
@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/infrastructure/centrifugo"
+	"tubexxi/video-api/internal/infrastructure/contextpool"
+	redisclient "tubexxi/video-api/internal/infrastructure/redis-client"
+	"tubexxi/video-api/internal/infrastructure/repository"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	watchPartyStateKey = "watch_party:state:%s"
+	// watchPartyStateTTL is refreshed on every control, so idle parties eventually reset to
+	// their initial state.
+	watchPartyStateTTL        = 12 * time.Hour
+	maxWatchPartyParticipants = 50
+	watchPartyStateRetries    = 3
+)
+
+type WatchPartyService struct {
+	chatRoomRepo repository.ChatRoomRepository
+	chat         *ChatService
+	movies       *MovieService
+	centrifugo   *centrifugo.CentrifugoClient
+	redis        *redisclient.RedisClient
+	logger       *zap.Logger
+}
+
+func NewWatchPartyService(
+	chatRoomRepo repository.ChatRoomRepository,
+	chat *ChatService,
+	movies *MovieService,
+	centrifugo *centrifugo.CentrifugoClient,
+	redis *redisclient.RedisClient,
+	logger *zap.Logger,
+) *WatchPartyService {
+	return &WatchPartyService{
+		chatRoomRepo: chatRoomRepo,
+		chat:         chat,
+		movies:       movies,
+		centrifugo:   centrifugo,
+		redis:        redis,
+		logger:       logger,
+	}
+}
+
+// Create opens a watch party room hosted by the caller. The content's player URLs are resolved
+// through the scraper once and kept in the room metadata.
+func (s *WatchPartyService) Create(ctx context.Context, userID string, req *dto.WatchPartyCreateRequest) (*entity.WatchParty, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 30*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	content, err := s.resolveContent(subCtx, req)
+	if err != nil {
+		return nil, err
+	}
+	playerIndex := 0
+	if req.PlayerIndex != nil {
+		playerIndex = *req.PlayerIndex
+	}
+	if playerIndex >= len(content.PlayerUrls) {
+		return nil, dto.ErrWatchPartyInvalidPlayer
+	}
+
+	metadata, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal watch party content: %w", err)
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = content.Title
+	}
+	room := &entity.ChatRoom{
+		Name:      &name,
+		Type:      entity.ChatRoomTypeWatchParty,
+		CreatedBy: userUUID,
+		AvatarURL: content.Thumbnail,
+		Metadata:  metadata,
+	}
+
+	participants := []*entity.ChatParticipant{{UserID: userUUID, Role: entity.ChatRoleAdmin}}
+	seen := map[uuid.UUID]bool{userUUID: true}
+	for _, id := range req.ParticipantIDs {
+		if !seen[id] {
+			seen[id] = true
+			participants = append(participants, &entity.ChatParticipant{UserID: id, Role: entity.ChatRoleMember})
+		}
+	}
+	if len(participants) > maxWatchPartyParticipants {
+		return nil, dto.ErrWatchPartyFull
+	}
+	if err := s.chatRoomRepo.CreateRoom(subCtx, room, participants); err != nil {
+		return nil, err
+	}
+
+	state := &entity.WatchPartyState{
+		PlayerIndex: playerIndex,
+		PlayerUrl:   &content.PlayerUrls[playerIndex],
+		UpdatedBy:   &userUUID,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal watch party state: %w", err)
+	}
+	if err := s.redis.Client().Set(subCtx, fmt.Sprintf(watchPartyStateKey, room.ID), raw, watchPartyStateTTL).Err(); err != nil {
+		s.logger.Warn("[WatchPartyService.Create] failed to store initial state", zap.String("room_id", room.ID.String()), zap.Error(err))
+	}
+
+	for _, participant := range participants[1:] {
+		if participant.ID != uuid.Nil {
+			s.chat.publishToUser(subCtx, participant.UserID, dto.ChatEventRoomJoined, room.ID)
+		}
+	}
+	return s.load(subCtx, room.ID)
+}
+
+// Get returns a party with its content and current playback state to one of its members.
+func (s *WatchPartyService) Get(ctx context.Context, userID string, roomID uuid.UUID) (*entity.WatchParty, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	if _, err := s.chatRoomRepo.FindParticipant(subCtx, roomID, userUUID); err != nil {
+		return nil, err
+	}
+	return s.load(subCtx, roomID)
+}
+
+// Join adds the caller to a party as a member, so party links can be shared.
+func (s *WatchPartyService) Join(ctx context.Context, userID string, roomID uuid.UUID) (*entity.WatchParty, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	room, err := s.chatRoomRepo.FindRoomByID(subCtx, roomID)
+	if err != nil {
+		if errors.Is(err, dto.ErrChatRoomNotFound) {
+			return nil, dto.ErrWatchPartyNotFound
+		}
+		return nil, err
+	}
+	if !room.IsWatchParty() {
+		return nil, dto.ErrWatchPartyNotFound
+	}
+
+	isMember, err := s.chatRoomRepo.IsParticipant(subCtx, roomID, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		banned, err := s.chatRoomRepo.IsBanned(subCtx, roomID, userUUID)
+		if err != nil {
+			return nil, err
+		}
+		if banned {
+			return nil, dto.ErrChatBanned
+		}
+		participants, err := s.chatRoomRepo.ListParticipants(subCtx, roomID)
+		if err != nil {
+			return nil, err
+		}
+		if len(participants) >= maxWatchPartyParticipants {
+			return nil, dto.ErrWatchPartyFull
+		}
+
+		added, err := s.chatRoomRepo.AddParticipants(subCtx, roomID, []uuid.UUID{userUUID}, entity.ChatRoleMember)
+		if err != nil {
+			return nil, err
+		}
+		if len(added) > 0 {
+			names := s.chat.participantNames(subCtx, roomID, added)
+			s.chat.postSystemMessage(subCtx, roomID, userUUID,
+				fmt.Sprintf("%s joined the watch party", strings.Join(names, ", ")),
+				map[string]interface{}{"action": dto.ChatEventParticipantsAdded, "user_ids": added},
+			)
+			s.chat.publishToUser(subCtx, userUUID, dto.ChatEventRoomJoined, roomID)
+		}
+	}
+	return s.load(subCtx, roomID)
+}
+
+// Sync returns the playback state with the server clock so clients can correct drift.
+func (s *WatchPartyService) Sync(ctx context.Context, userID string, roomID uuid.UUID) (*dto.WatchPartySyncResponse, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	if _, err := s.chatRoomRepo.FindParticipant(subCtx, roomID, userUUID); err != nil {
+		return nil, err
+	}
+	room, content, err := s.partyRoom(subCtx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.readState(subCtx, room.ID, content)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &dto.WatchPartySyncResponse{State: state, Position: state.PositionAt(now), ServerTime: now}, nil
+}
+
+// Control applies a host's play, pause, seek or source change and relays it to the party
+// channel stamped with the server time.
+func (s *WatchPartyService) Control(ctx context.Context, userID string, roomID uuid.UUID, req *dto.WatchPartyPlaybackRequest) (*dto.WatchPartySyncResponse, error) {
+	subCtx, cancel := contextpool.WithTimeoutIfNone(ctx, 15*time.Second)
+	defer cancel()
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	participant, err := s.chatRoomRepo.FindParticipant(subCtx, roomID, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	_, content, err := s.partyRoom(subCtx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if !participant.IsAdmin() {
+		return nil, dto.ErrWatchPartyHostOnly
+	}
+
+	switch req.Action {
+	case entity.WatchPartyActionSeek:
+		if req.Position == nil {
+			return nil, dto.ErrWatchPartyPositionRequired
+		}
+	case entity.WatchPartyActionSource:
+		if req.PlayerIndex == nil {
+			return nil, dto.ErrWatchPartyPlayerRequired
+		}
+		if *req.PlayerIndex >= len(content.PlayerUrls) {
+			return nil, dto.ErrWatchPartyInvalidPlayer
+		}
+	}
+
+	state, err := s.updateState(subCtx, roomID, content, func(state *entity.WatchPartyState, now time.Time) {
+		position := state.PositionAt(now)
+		if req.Position != nil {
+			position = *req.Position
+		}
+		switch req.Action {
+		case entity.WatchPartyActionPlay:
+			state.Playing = true
+		case entity.WatchPartyActionPause:
+			state.Playing = false
+		case entity.WatchPartyActionSource:
+			state.PlayerIndex = *req.PlayerIndex
+			state.PlayerUrl = &content.PlayerUrls[state.PlayerIndex]
+		}
+		state.Position = position
+		state.Seq++
+		state.UpdatedBy = &userUUID
+		state.UpdatedAt = now
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if err := s.centrifugo.PublishToWatchParty(subCtx, roomID.String(), map[string]interface{}{
+		"type":        dto.WatchPartyEventPlayback,
+		"action":      req.Action,
+		"state":       state,
+		"server_time": now,
+	}); err != nil {
+		s.logger.Warn("[WatchPartyService.Control] failed to relay playback",
+			zap.String("room_id", roomID.String()),
+			zap.String("action", req.Action),
+			zap.Error(err),
+		)
+	}
+	return &dto.WatchPartySyncResponse{State: state, Position: state.PositionAt(now), ServerTime: now}, nil
+}
+
+func (s *WatchPartyService) load(ctx context.Context, roomID uuid.UUID) (*entity.WatchParty, error) {
+	room, content, err := s.partyRoom(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	room.Participants, err = s.chatRoomRepo.ListParticipants(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.readState(ctx, roomID, content)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.WatchParty{Room: room, Content: content, State: state, ServerTime: time.Now().UTC()}, nil
+}
+
+// partyRoom loads a room and decodes its watch party content, treating any other room type as
+// missing.
+func (s *WatchPartyService) partyRoom(ctx context.Context, roomID uuid.UUID) (*entity.ChatRoom, *entity.WatchPartyContent, error) {
+	room, err := s.chatRoomRepo.FindRoomByID(ctx, roomID)
+	if err != nil {
+		if errors.Is(err, dto.ErrChatRoomNotFound) {
+			return nil, nil, dto.ErrWatchPartyNotFound
+		}
+		return nil, nil, err
+	}
+	if !room.IsWatchParty() {
+		return nil, nil, dto.ErrWatchPartyNotFound
+	}
+
+	var content entity.WatchPartyContent
+	if err := json.Unmarshal(room.Metadata, &content); err != nil || len(content.PlayerUrls) == 0 {
+		s.logger.Error("[WatchPartyService.partyRoom] invalid watch party metadata", zap.String("room_id", roomID.String()), zap.Error(err))
+		return nil, nil, dto.ErrWatchPartyContentUnavailable
+	}
+	return room, &content, nil
+}
+
+// readState returns the stored playback state, or a paused state at the start of the first
+// player when none is stored.
+func (s *WatchPartyService) readState(ctx context.Context, roomID uuid.UUID, content *entity.WatchPartyContent) (*entity.WatchPartyState, error) {
+	raw, err := s.redis.Client().Get(ctx, fmt.Sprintf(watchPartyStateKey, roomID)).Bytes()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		s.logger.Error("[WatchPartyService.readState]", zap.Error(err))
+		return nil, fmt.Errorf("failed to read watch party state: %w", err)
+	}
+	return decodeWatchPartyState(raw, content), nil
+}
+
+// updateState applies a change to the stored state inside an optimistic transaction, so
+// concurrent controls from co-hosts never lose a sequence number.
+func (s *WatchPartyService) updateState(ctx context.Context, roomID uuid.UUID, content *entity.WatchPartyContent, apply func(*entity.WatchPartyState, time.Time)) (*entity.WatchPartyState, error) {
+	key := fmt.Sprintf(watchPartyStateKey, roomID)
+
+	var state *entity.WatchPartyState
+	txn := func(tx *redis.Tx) error {
+		raw, err := tx.Get(ctx, key).Bytes()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		state = decodeWatchPartyState(raw, content)
+		apply(state, time.Now().UTC())
+
+		encoded, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, encoded, watchPartyStateTTL)
+			return nil
+		})
+		return err
+	}
+
+	for attempt := 0; attempt < watchPartyStateRetries; attempt++ {
+		err := s.redis.Client().Watch(ctx, txn, key)
+		if err == nil {
+			return state, nil
+		}
+		if !errors.Is(err, redis.TxFailedErr) {
+			s.logger.Error("[WatchPartyService.updateState]", zap.Error(err))
+			return nil, fmt.Errorf("failed to update watch party state: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("failed to update watch party state: %w", redis.TxFailedErr)
+}
+
+// resolveContent looks the movie or episode up through the scraper and keeps its playable
+// sources.
+func (s *WatchPartyService) resolveContent(ctx context.Context, req *dto.WatchPartyCreateRequest) (*entity.WatchPartyContent, error) {
+	source := strings.TrimSpace(req.Source)
+	content := &entity.WatchPartyContent{
+		ContentType: req.ContentType,
+		Source:      source,
+		Title:       strings.TrimSpace(req.Title),
+	}
+
+	var players *[]entity.PlayerUrl
+	switch req.ContentType {
+	case entity.WatchPartyContentMovie:
+		detail, err := s.movies.GetMovieDetail(ctx, source)
+		if err != nil {
+			return nil, dto.ErrWatchPartyContentUnavailable
+		}
+		if content.Title == "" {
+			content.Title = detail.Title
+		}
+		content.Thumbnail = detail.Thumbnail
+		players = detail.PlayerUrl
+	case entity.WatchPartyContentEpisode:
+		episode, err := s.movies.GetSeriesEpisode(ctx, source)
+		if err != nil {
+			return nil, dto.ErrWatchPartyContentUnavailable
+		}
+		if content.Title == "" && episode.EpisodeNumber != nil {
+			content.Title = fmt.Sprintf("Episode %d", *episode.EpisodeNumber)
+		}
+		players = episode.PlayerUrl
+	}
+
+	if players != nil {
+		for _, player := range *players {
+			if player.URL != nil && strings.TrimSpace(*player.URL) != "" {
+				content.PlayerUrls = append(content.PlayerUrls, player)
+			}
+		}
+	}
+	if len(content.PlayerUrls) == 0 {
+		return nil, dto.ErrWatchPartyContentUnavailable
+	}
+	if content.Title == "" {
+		content.Title = "Watch party"
+	}
+	return content, nil
+}
+
+// decodeWatchPartyState parses a stored state, falling back to the initial state. A player index
+// that no longer exists is reset to the first player.
+func decodeWatchPartyState(raw []byte, content *entity.WatchPartyContent) *entity.WatchPartyState {
+	state := &entity.WatchPartyState{}
+	if len(raw) == 0 || json.Unmarshal(raw, state) != nil {
+		state = &entity.WatchPartyState{UpdatedAt: time.Now().UTC()}
+	}
+	if state.PlayerIndex < 0 || state.PlayerIndex >= len(content.PlayerUrls) {
+		state.PlayerIndex = 0
+	}
+	state.PlayerUrl = &content.PlayerUrls[state.PlayerIndex]
+	return state
+}
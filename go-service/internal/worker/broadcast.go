@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+type BroadcastWorker struct {
+	broadcastService *service.BroadcastService
+	logger           *zap.Logger
+}
+
+func NewBroadcastWorker(broadcastService *service.BroadcastService, logger *zap.Logger) *BroadcastWorker {
+	return &BroadcastWorker{
+		broadcastService: broadcastService,
+		logger:           logger,
+	}
+}
+
+// HandleAnnouncement delivers a scheduled announcement. On the final retry a failing delivery
+// is recorded on the announcement instead of being released for another attempt.
+func (w *BroadcastWorker) HandleAnnouncement(ctx context.Context, task *asynq.Task) error {
+	var payload dto.BroadcastAnnouncementTask
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		w.logger.Error("[BroadcastWorker.HandleAnnouncement] invalid payload", zap.Error(err))
+		return fmt.Errorf("invalid announcement payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+
+	return w.broadcastService.DeliverAnnouncement(ctx, payload.AnnouncementID, retried >= maxRetry)
+}
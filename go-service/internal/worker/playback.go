@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"context"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+type PlaybackWorker struct {
+	playbackService *service.PlaybackService
+	logger          *zap.Logger
+}
+
+func NewPlaybackWorker(playbackService *service.PlaybackService, logger *zap.Logger) *PlaybackWorker {
+	return &PlaybackWorker{
+		playbackService: playbackService,
+		logger:          logger,
+	}
+}
+
+// HandleFlush writes the playback progress reported since the previous run. Users whose
+// progress fails to write stay marked, so the next run picks them up.
+func (w *PlaybackWorker) HandleFlush(ctx context.Context, task *asynq.Task) error {
+	if _, err := w.playbackService.Flush(ctx); err != nil {
+		w.logger.Error("[PlaybackWorker.HandleFlush] failed to flush playback progress", zap.Error(err))
+		return err
+	}
+	return nil
+}
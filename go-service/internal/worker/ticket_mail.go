@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"tubexxi/video-api/internal/dependencies"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/service"
+	"tubexxi/video-api/pkg/smtpd"
+
+	"go.uber.org/zap"
+)
+
+type TicketMailWorker struct {
+	ticketService *service.TicketService
+	logger        *zap.Logger
+}
+
+func NewTicketMailWorker(ticketService *service.TicketService, logger *zap.Logger) *TicketMailWorker {
+	return &TicketMailWorker{
+		ticketService: ticketService,
+		logger:        logger,
+	}
+}
+
+// HandleMessage threads one received email into its ticket. Mail that can never be threaded is
+// refused permanently so the sending server stops retrying it.
+func (w *TicketMailWorker) HandleMessage(ctx context.Context, envelope *smtpd.Envelope) error {
+	err := w.ticketService.HandleInboundEmail(ctx, envelope.From, envelope.Recipients, envelope.Data)
+	if errors.Is(err, dto.ErrInboundMailRejected) {
+		return errors.Join(smtpd.ErrRejected, err)
+	}
+	return err
+}
+
+// NewInboundMailServer returns the SMTP receiver for ticket replies, or nil when
+// TICKET_INBOUND_SMTP_ADDR is not set.
+func NewInboundMailServer(cont *dependencies.Container) *smtpd.Server {
+	cfg := cont.AppConfig.Ticket
+	if cfg.InboundSMTPAddr == "" {
+		return nil
+	}
+
+	ticketService := service.NewTicketService(
+		cont.TicketRepo,
+		cont.UserRepo,
+		cont.MinioClient,
+		cont.RedisClient,
+		cont.EmailHelper,
+		cont.Notifier,
+		cont.AppConfig,
+		cont.Logger,
+	)
+	mailWorker := NewTicketMailWorker(ticketService, cont.Logger)
+
+	domain := ""
+	if at := strings.LastIndexByte(cfg.InboundAddress, '@'); at >= 0 {
+		domain = strings.Trim(cfg.InboundAddress[at+1:], "> ")
+	}
+	return &smtpd.Server{
+		Addr:    cfg.InboundSMTPAddr,
+		Domain:  domain,
+		MaxSize: cfg.InboundMaxSize,
+		Handler: mailWorker.HandleMessage,
+		Logger:  cont.Logger,
+	}
+}
@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"context"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+type TicketSLAWorker struct {
+	slaService *service.TicketSLAService
+	logger     *zap.Logger
+}
+
+func NewTicketSLAWorker(slaService *service.TicketSLAService, logger *zap.Logger) *TicketSLAWorker {
+	return &TicketSLAWorker{
+		slaService: slaService,
+		logger:     logger,
+	}
+}
+
+// HandleCheck escalates the SLA breaches found since the previous run. Breaches are claimed
+// one by one, so a failed run is simply picked up by the next one.
+func (w *TicketSLAWorker) HandleCheck(ctx context.Context, task *asynq.Task) error {
+	if _, err := w.slaService.EscalateBreaches(ctx); err != nil {
+		w.logger.Error("[TicketSLAWorker.HandleCheck] failed to escalate SLA breaches", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"tubexxi/video-api/internal/entity"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+type ViewHistoryWorker struct {
+	viewHistoryService *service.ViewHistoryService
+	logger             *zap.Logger
+}
+
+func NewViewHistoryWorker(viewHistoryService *service.ViewHistoryService, logger *zap.Logger) *ViewHistoryWorker {
+	return &ViewHistoryWorker{
+		viewHistoryService: viewHistoryService,
+		logger:             logger,
+	}
+}
+
+// HandleBatch writes a batch of recorded views, which arrives as the JSON array asynq builds
+// from the grouped record tasks. Views already in the table are skipped, so a retried batch
+// does not count anything twice.
+func (w *ViewHistoryWorker) HandleBatch(ctx context.Context, task *asynq.Task) error {
+	var views []*entity.ViewHistory
+	if err := json.Unmarshal(task.Payload(), &views); err != nil {
+		w.logger.Error("[ViewHistoryWorker.HandleBatch] invalid payload", zap.Error(err))
+		return fmt.Errorf("invalid view history batch: %v: %w", err, asynq.SkipRetry)
+	}
+
+	if _, err := w.viewHistoryService.FlushBatch(ctx, views); err != nil {
+		w.logger.Error("[ViewHistoryWorker.HandleBatch] failed to write views", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"fmt"
+	"time"
+	"tubexxi/video-api/internal/dependencies"
+	"tubexxi/video-api/internal/dto"
+	"tubexxi/video-api/internal/service"
+
+	"github.com/hibiken/asynq"
+)
+
+// RegisterHandlers wires every background task handler onto the asynq server mux.
+func RegisterHandlers(cont *dependencies.Container) {
+	chatService := service.NewChatService(
+		cont.ChatRoomRepo,
+		cont.UserRepo,
+		cont.CentrifugoClient,
+		cont.RedisClient,
+		cont.MinioClient,
+		cont.AppConfig,
+		cont.Logger,
+	)
+	broadcastService := service.NewBroadcastService(
+		cont.BroadcastRepo,
+		cont.ChatRoomRepo,
+		chatService,
+		cont.AsynqClient,
+		cont.FirebaseClient,
+		cont.EmailHelper,
+		&cont.AppConfig.App,
+		cont.Logger,
+	)
+
+	slaService := service.NewTicketSLAService(
+		cont.TicketSLARepo,
+		cont.UserRepo,
+		cont.EmailHelper,
+		cont.Notifier,
+		cont.AppConfig,
+		cont.Logger,
+	)
+
+	viewHistoryService := service.NewViewHistoryService(
+		cont.ViewHistoryRepo,
+		cont.RedisClient,
+		cont.AsynqClient,
+		&cont.AppConfig.ViewHistory,
+		cont.Logger,
+	)
+
+	playbackService := service.NewPlaybackService(
+		cont.PlaybackRepo,
+		service.NewMovieService(cont.Logger, cont.ScraperClient),
+		service.NewAnimeService(cont.Logger, cont.ScraperClient),
+		cont.RedisClient,
+		&cont.AppConfig.Playback,
+		cont.Logger,
+	)
+
+	broadcastWorker := NewBroadcastWorker(broadcastService, cont.Logger)
+	cont.AsynqClient.RegisterHandlerFunc(dto.TaskBroadcastAnnouncement, broadcastWorker.HandleAnnouncement)
+
+	slaWorker := NewTicketSLAWorker(slaService, cont.Logger)
+	cont.AsynqClient.RegisterHandlerFunc(dto.TaskTicketSLACheck, slaWorker.HandleCheck)
+
+	viewHistoryWorker := NewViewHistoryWorker(viewHistoryService, cont.Logger)
+	cont.AsynqClient.RegisterHandlerFunc(dto.TaskViewHistoryBatch, viewHistoryWorker.HandleBatch)
+
+	playbackWorker := NewPlaybackWorker(playbackService, cont.Logger)
+	cont.AsynqClient.RegisterHandlerFunc(dto.TaskPlaybackFlush, playbackWorker.HandleFlush)
+}
+
+// RegisterSchedules adds the periodic tasks to the asynq scheduler.
+func RegisterSchedules(cont *dependencies.Container) error {
+	slaInterval := cont.AppConfig.Ticket.SLACheckInterval
+	if slaInterval <= 0 {
+		slaInterval = 5 * time.Minute
+	}
+	err := cont.AsynqClient.RegisterPeriodicTask(
+		fmt.Sprintf("@every %s", slaInterval),
+		dto.TaskTicketSLACheck,
+		struct{}{},
+		asynq.Queue("default"),
+		asynq.MaxRetry(0),
+		asynq.Unique(slaInterval),
+	)
+	if err != nil {
+		return err
+	}
+
+	flushInterval := cont.AppConfig.Playback.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = 30 * time.Second
+	}
+	return cont.AsynqClient.RegisterPeriodicTask(
+		fmt.Sprintf("@every %s", flushInterval),
+		dto.TaskPlaybackFlush,
+		struct{}{},
+		asynq.Queue("low"),
+		asynq.MaxRetry(0),
+		asynq.Unique(flushInterval),
+	)
+}
@@ -0,0 +1,367 @@
+// Package smtpd is a small SMTP receiver for inbound support mail. It accepts messages for
+// whatever recipients the handler is willing to take and hands each one over whole; it does
+// no relaying, authentication or TLS, so it belongs behind a mail gateway or, in development,
+// it stands in for one.
+package smtpd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrRejected tells the client the message is permanently refused (550) rather than
+// temporarily failed (451).
+var ErrRejected = errors.New("message rejected")
+
+var ErrServerClosed = errors.New("smtpd: server closed")
+
+// Envelope is one accepted message.
+type Envelope struct {
+	From       string
+	Recipients []string
+	Data       []byte
+}
+
+// Handler processes a message after DATA completes.
+type Handler func(ctx context.Context, envelope *Envelope) error
+
+type Server struct {
+	Addr        string
+	Domain      string
+	MaxSize     int64
+	MaxRcpt     int
+	ReadTimeout time.Duration
+	Handler     Handler
+	Logger      *zap.Logger
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Addr, err)
+	}
+	return s.Serve(listener)
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		listener.Close()
+		return ErrServerClosed
+	}
+	s.listener = listener
+	s.conns = make(map[net.Conn]struct{})
+	s.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				time.Sleep(50 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+				conn.Close()
+			}()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// Shutdown stops accepting connections and waits for open sessions to finish, closing them
+// once ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+		<-done
+		return ctx.Err()
+	}
+}
+
+type session struct {
+	server     *Server
+	conn       net.Conn
+	text       *textproto.Conn
+	greeted    bool
+	from       string
+	hasFrom    bool
+	recipients []string
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	sess := &session{server: s, conn: conn, text: textproto.NewConn(conn)}
+	sess.reply(220, "%s ESMTP ready", s.domain())
+
+	for {
+		sess.deadline()
+		line, err := sess.text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			verb, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			sess.greeted = true
+			sess.reset()
+			sess.reply(250, "%s", s.domain())
+		case "EHLO":
+			sess.greeted = true
+			sess.reset()
+			sess.replyLines(250, s.domain(), "8BITMIME", "PIPELINING", fmt.Sprintf("SIZE %d", s.MaxSize))
+		case "MAIL":
+			sess.mail(arg)
+		case "RCPT":
+			sess.rcpt(arg)
+		case "DATA":
+			if !sess.data() {
+				return
+			}
+		case "RSET":
+			sess.reset()
+			sess.reply(250, "OK")
+		case "NOOP":
+			sess.reply(250, "OK")
+		case "VRFY":
+			sess.reply(252, "Cannot verify user")
+		case "QUIT":
+			sess.reply(221, "Bye")
+			return
+		default:
+			sess.reply(502, "Command not implemented")
+		}
+	}
+}
+
+func (s *Server) domain() string {
+	if s.Domain != "" {
+		return s.Domain
+	}
+	return "localhost"
+}
+
+func (s *session) mail(arg string) {
+	if !s.greeted {
+		s.reply(503, "Send HELO or EHLO first")
+		return
+	}
+	if s.hasFrom {
+		s.reply(503, "Sender already given")
+		return
+	}
+	address, params, ok := pathArg(arg, "FROM:")
+	if !ok {
+		s.reply(501, "Syntax: MAIL FROM:<address>")
+		return
+	}
+	for _, param := range params {
+		key, value, _ := strings.Cut(param, "=")
+		if strings.EqualFold(key, "SIZE") {
+			if size, err := strconv.ParseInt(value, 10, 64); err == nil && s.server.MaxSize > 0 && size > s.server.MaxSize {
+				s.reply(552, "Message exceeds the size limit")
+				return
+			}
+		}
+	}
+	s.from = address
+	s.hasFrom = true
+	s.reply(250, "OK")
+}
+
+func (s *session) rcpt(arg string) {
+	if !s.hasFrom {
+		s.reply(503, "Send MAIL first")
+		return
+	}
+	address, _, ok := pathArg(arg, "TO:")
+	if !ok || address == "" {
+		s.reply(501, "Syntax: RCPT TO:<address>")
+		return
+	}
+	if _, err := mail.ParseAddress(address); err != nil {
+		s.reply(553, "Invalid recipient")
+		return
+	}
+	maxRcpt := s.server.MaxRcpt
+	if maxRcpt <= 0 {
+		maxRcpt = 50
+	}
+	if len(s.recipients) >= maxRcpt {
+		s.reply(452, "Too many recipients")
+		return
+	}
+	s.recipients = append(s.recipients, address)
+	s.reply(250, "OK")
+}
+
+// data reads the message body. It reports false when the connection can no longer be used.
+func (s *session) data() bool {
+	if len(s.recipients) == 0 {
+		s.reply(503, "Send RCPT first")
+		return true
+	}
+	s.reply(354, "End data with <CR><LF>.<CR><LF>")
+
+	dot := s.text.DotReader()
+	reader := dot
+	if s.server.MaxSize > 0 {
+		reader = io.LimitReader(dot, s.server.MaxSize+1)
+	}
+	s.deadline()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return false
+	}
+	if s.server.MaxSize > 0 && int64(len(data)) > s.server.MaxSize {
+		// Drain the rest of the message so the session stays in sync.
+		if _, err := io.Copy(io.Discard, dot); err != nil {
+			return false
+		}
+		s.reset()
+		s.reply(552, "Message exceeds the size limit")
+		return true
+	}
+
+	envelope := &Envelope{From: s.from, Recipients: s.recipients, Data: data}
+	s.reset()
+
+	if err := s.server.handle(envelope); err != nil {
+		if errors.Is(err, ErrRejected) {
+			s.reply(550, "Message rejected")
+		} else {
+			s.reply(451, "Temporary failure, try again later")
+		}
+		return true
+	}
+	s.reply(250, "OK: queued")
+	return true
+}
+
+func (s *Server) handle(envelope *Envelope) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+		if err != nil && s.Logger != nil {
+			s.Logger.Warn("[smtpd] message not accepted",
+				zap.String("from", envelope.From),
+				zap.Strings("recipients", envelope.Recipients),
+				zap.Error(err),
+			)
+		}
+	}()
+
+	if s.Handler == nil {
+		return ErrRejected
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	return s.Handler(ctx, envelope)
+}
+
+func (s *session) reset() {
+	s.from = ""
+	s.hasFrom = false
+	s.recipients = nil
+}
+
+func (s *session) deadline() {
+	timeout := s.server.ReadTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	s.conn.SetDeadline(time.Now().Add(timeout))
+}
+
+func (s *session) reply(code int, format string, args ...interface{}) {
+	s.text.PrintfLine("%d %s", code, fmt.Sprintf(format, args...))
+}
+
+func (s *session) replyLines(code int, lines ...string) {
+	for i, line := range lines {
+		sep := "-"
+		if i == len(lines)-1 {
+			sep = " "
+		}
+		s.text.PrintfLine("%d%s%s", code, sep, line)
+	}
+}
+
+// pathArg parses "FROM:<address> PARAM=value" style arguments. The null sender "<>" is
+// returned as an empty address.
+func pathArg(arg, prefix string) (string, []string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+	fields := strings.Fields(strings.TrimSpace(arg[len(prefix):]))
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+	path := fields[0]
+	if !strings.HasPrefix(path, "<") || !strings.HasSuffix(path, ">") {
+		return "", nil, false
+	}
+	return strings.TrimSpace(path[1 : len(path)-1]), fields[1:], true
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+// maxThumbnailPixels bounds the images JPEGThumbnail will decode, guarding against
+// decompression bombs.
+const maxThumbnailPixels = 40_000_000
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image format")
+	ErrImageTooLarge    = errors.New("image is too large to process")
+)
+
+// ImageDimensions reads the pixel size of a JPEG, PNG, GIF or WebP image from its header
+// without decoding the pixel data.
+func ImageDimensions(data []byte) (int, int, error) {
+	if width, height, ok := webpDimensions(data); ok {
+		return width, height, nil
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, ErrUnsupportedImage
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// JPEGThumbnail decodes a JPEG, PNG or GIF image and re-encodes it as a JPEG that fits within
+// maxSide pixels on its longest side. Transparent areas are flattened onto white.
+func JPEGThumbnail(data []byte, maxSide int) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > maxThumbnailPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	thumbWidth, thumbHeight := width, height
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			thumbWidth, thumbHeight = maxSide, max(1, height*maxSide/width)
+		} else {
+			thumbWidth, thumbHeight = max(1, width*maxSide/height), maxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, thumbWidth, thumbHeight))
+	for y := 0; y < thumbHeight; y++ {
+		y0 := bounds.Min.Y + y*height/thumbHeight
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*height/thumbHeight)
+		for x := 0; x < thumbWidth; x++ {
+			x0 := bounds.Min.X + x*width/thumbWidth
+			x1 := max(x0+1, bounds.Min.X+(x+1)*width/thumbWidth)
+			dst.SetRGBA(x, y, averageOverWhite(src, x0, y0, x1, y1))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// averageOverWhite box-filters the source rectangle, sampling at most a 4x4 grid, and
+// composites the result over a white background.
+func averageOverWhite(src image.Image, x0, y0, x1, y1 int) color.RGBA {
+	stepX := max(1, (x1-x0)/4)
+	stepY := max(1, (y1-y0)/4)
+
+	var r, g, b, a, n uint64
+	for sy := y0; sy < y1; sy += stepY {
+		for sx := x0; sx < x1; sx += stepX {
+			pr, pg, pb, pa := src.At(sx, sy).RGBA()
+			r += uint64(pr)
+			g += uint64(pg)
+			b += uint64(pb)
+			a += uint64(pa)
+			n++
+		}
+	}
+	r, g, b, a = r/n, g/n, b/n, a/n
+
+	// Colours are alpha-premultiplied, so compositing over white adds the uncovered share.
+	return color.RGBA{
+		R: uint8((r + 0xffff - a) >> 8),
+		G: uint8((g + 0xffff - a) >> 8),
+		B: uint8((b + 0xffff - a) >> 8),
+		A: 0xff,
+	}
+}
+
+// webpDimensions parses the canvas size from the lossy, lossless or extended WebP headers.
+func webpDimensions(data []byte) (int, int, bool) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, false
+	}
+	switch string(data[12:16]) {
+	case "VP8 ":
+		if data[23] != 0x9d || data[24] != 0x01 || data[25] != 0x2a {
+			return 0, 0, false
+		}
+		width := int(binary.LittleEndian.Uint16(data[26:28]) & 0x3fff)
+		height := int(binary.LittleEndian.Uint16(data[28:30]) & 0x3fff)
+		return width, height, true
+	case "VP8L":
+		if data[20] != 0x2f {
+			return 0, 0, false
+		}
+		bits := binary.LittleEndian.Uint32(data[21:25])
+		return int(bits&0x3fff) + 1, int((bits>>14)&0x3fff) + 1, true
+	case "VP8X":
+		width := 1 + (int(data[24]) | int(data[25])<<8 | int(data[26])<<16)
+		height := 1 + (int(data[27]) | int(data[28])<<8 | int(data[29])<<16)
+		return width, height, true
+	default:
+		return 0, 0, false
+	}
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// InboundEmail is the part of a received email needed to thread it as a reply.
+type InboundEmail struct {
+	From      string
+	Subject   string
+	MessageID string
+	Text      string
+}
+
+var (
+	htmlTagRegex    = regexp.MustCompile(`(?s)<[^>]*>`)
+	quoteLeadRegex  = regexp.MustCompile(`(?i)^on .+ wrote:$`)
+	quoteSplitRegex = regexp.MustCompile(`(?i)^-{2,}\s*(original message|forwarded message)\s*-{2,}$`)
+)
+
+// ParseInboundEmail reads a raw RFC 5322 message and returns the sender, the Message-ID and
+// the plain-text body with the quoted history of earlier messages removed.
+func ParseInboundEmail(raw []byte) (*InboundEmail, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	decoder := new(mime.WordDecoder)
+	email := &InboundEmail{
+		MessageID: strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>"),
+	}
+	if subject, err := decoder.DecodeHeader(msg.Header.Get("Subject")); err == nil {
+		email.Subject = subject
+	}
+	if from, err := mail.ParseAddress(msg.Header.Get("From")); err == nil {
+		email.From = strings.ToLower(from.Address)
+	}
+
+	text, err := emailText(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return nil, err
+	}
+	email.Text = StripQuotedReply(text)
+	return email, nil
+}
+
+// StripQuotedReply drops the quoted history a mail client appends below a reply, along with
+// any trailing signature.
+func StripQuotedReply(text string) string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(strings.ReplaceAll(text, "\r\n", "\n")))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		trimmed := strings.TrimSpace(line)
+		if line == "--" || quoteLeadRegex.MatchString(trimmed) || quoteSplitRegex.MatchString(trimmed) {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// emailText walks the MIME tree and returns the first text/plain part, falling back to a
+// text/html part with its tags removed.
+func emailText(contentType, encoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		var fallback string
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to read message part: %w", err)
+			}
+			if strings.HasPrefix(part.Header.Get("Content-Disposition"), "attachment") {
+				continue
+			}
+			partType := part.Header.Get("Content-Type")
+			text, err := emailText(partType, part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return "", err
+			}
+			if text == "" {
+				continue
+			}
+			if partType == "" || strings.HasPrefix(strings.ToLower(partType), "text/plain") || strings.HasPrefix(strings.ToLower(partType), "multipart/") {
+				return text, nil
+			}
+			if fallback == "" {
+				fallback = text
+			}
+		}
+		return fallback, nil
+	}
+
+	if !strings.HasPrefix(mediaType, "text/") {
+		return "", nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode message body: %w", err)
+	}
+
+	text := string(data)
+	if mediaType == "text/html" {
+		text = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n").Replace(text)
+		text = html.UnescapeString(htmlTagRegex.ReplaceAllString(text, ""))
+	}
+	return text, nil
+}
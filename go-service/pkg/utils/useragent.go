@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DeviceTypeDesktop = "desktop"
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+	DeviceTypeTV      = "tv"
+	DeviceTypeBot     = "bot"
+	DeviceTypeUnknown = "unknown"
+)
+
+// UserAgentInfo is what ParseUserAgent could tell about a client. Unrecognised parts are empty,
+// except DeviceType which falls back to DeviceTypeUnknown.
+type UserAgentInfo struct {
+	Browser        string
+	BrowserVersion string
+	OS             string
+	DeviceType     string
+}
+
+// uaBrowsers is checked in order: most browsers also carry the tokens of the engines they are
+// built on, so the more specific ones come first.
+var uaBrowsers = []struct {
+	name   string
+	tokens []string
+}{
+	{"Facebook", []string{"FBAV/", "FBAN/"}},
+	{"Instagram", []string{"Instagram "}},
+	{"Edge", []string{"Edg/", "EdgA/", "EdgiOS/", "Edge/"}},
+	{"Opera", []string{"OPR/", "OPT/", "Opera/"}},
+	{"Samsung Internet", []string{"SamsungBrowser/"}},
+	{"UC Browser", []string{"UCBrowser/"}},
+	{"Yandex", []string{"YaBrowser/"}},
+	{"Vivaldi", []string{"Vivaldi/"}},
+	{"Firefox", []string{"Firefox/", "FxiOS/"}},
+	{"Chrome", []string{"CriOS/", "Chrome/"}},
+	{"Safari", []string{"Version/"}},
+	{"Internet Explorer", []string{"MSIE ", "rv:"}},
+}
+
+var uaBotTokens = []string{
+	"bot", "crawler", "spider", "slurp", "curl/", "wget/", "python-requests", "go-http-client",
+	"headlesschrome", "lighthouse", "facebookexternalhit",
+}
+
+var uaTVTokens = []string{
+	"smart-tv", "smarttv", "googletv", "android tv", "appletv", "crkey", "web0s", "webos",
+	"tizen", "bravia", "hbbtv", "roku", "aftb", "aftm", "afts", "aftt",
+}
+
+// ParseUserAgent extracts the browser, operating system and device class from a User-Agent
+// header. It relies on the tokens browsers commonly send and does not try to be exhaustive.
+func ParseUserAgent(ua string) UserAgentInfo {
+	info := UserAgentInfo{DeviceType: DeviceTypeUnknown}
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		return info
+	}
+	lower := strings.ToLower(ua)
+
+	info.OS = userAgentOS(ua, lower)
+	info.Browser, info.BrowserVersion = userAgentBrowser(ua)
+
+	switch {
+	case containsAny(lower, uaBotTokens):
+		info.DeviceType = DeviceTypeBot
+	case containsAny(lower, uaTVTokens):
+		info.DeviceType = DeviceTypeTV
+	case strings.Contains(lower, "ipad") || strings.Contains(lower, "tablet") || strings.Contains(lower, "kindle") ||
+		strings.Contains(lower, "silk/") || (info.OS == "Android" && !strings.Contains(lower, "mobile")):
+		info.DeviceType = DeviceTypeTablet
+	case strings.Contains(lower, "mobi") || strings.Contains(lower, "iphone") || strings.Contains(lower, "ipod") ||
+		strings.Contains(lower, "windows phone") || info.OS == "Android" || info.OS == "iOS":
+		info.DeviceType = DeviceTypeMobile
+	case info.OS == "Windows" || info.OS == "macOS" || info.OS == "Linux" || info.OS == "ChromeOS":
+		info.DeviceType = DeviceTypeDesktop
+	}
+	return info
+}
+
+func userAgentOS(ua, lower string) string {
+	switch {
+	case strings.Contains(lower, "windows phone"):
+		return "Windows Phone"
+	case strings.Contains(lower, "windows"):
+		return "Windows"
+	case strings.Contains(lower, "android"):
+		return "Android"
+	case strings.Contains(lower, "iphone") || strings.Contains(lower, "ipad") || strings.Contains(lower, "ipod"):
+		return "iOS"
+	case strings.Contains(lower, "appletv"):
+		return "tvOS"
+	case strings.Contains(ua, "Mac OS X") || strings.Contains(ua, "Macintosh"):
+		return "macOS"
+	case strings.Contains(ua, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(lower, "tizen"):
+		return "Tizen"
+	case strings.Contains(lower, "web0s") || strings.Contains(lower, "webos"):
+		return "webOS"
+	case strings.Contains(lower, "linux") || strings.Contains(lower, "x11"):
+		return "Linux"
+	}
+	return ""
+}
+
+func userAgentBrowser(ua string) (string, string) {
+	for _, browser := range uaBrowsers {
+		for _, token := range browser.tokens {
+			i := strings.Index(ua, token)
+			if i < 0 {
+				continue
+			}
+			// "rv:" alone also appears in Firefox; it only means IE next to Trident.
+			if token == "rv:" && !strings.Contains(ua, "Trident/") {
+				continue
+			}
+			// Version/ is how Safari reports itself, but other WebKit browsers send it too.
+			if token == "Version/" && !strings.Contains(ua, "Safari/") {
+				continue
+			}
+			return browser.name, userAgentVersion(ua[i+len(token):])
+		}
+	}
+	return "", ""
+}
+
+func userAgentVersion(rest string) string {
+	end := 0
+	for end < len(rest) && (rest[end] == '.' || (rest[end] >= '0' && rest[end] <= '9')) {
+		end++
+	}
+	return strings.Trim(rest[:end], ".")
+}
+
+func containsAny(s string, tokens []string) bool {
+	for _, token := range tokens {
+		if strings.Contains(s, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// PreferredLanguage returns the language tag with the highest weight in an Accept-Language
+// header, such as "en-US", or an empty string when the header names none.
+func PreferredLanguage(header string) string {
+	type weighted struct {
+		tag    string
+		weight float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" || len(tag) > 35 || !isLanguageTag(tag) {
+			continue
+		}
+		weight := 1.0
+		for _, param := range fields[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(key) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				weight = q
+			}
+		}
+		if weight <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, weight: weight})
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(tags, func(a, b int) bool {
+		return tags[a].weight > tags[b].weight
+	})
+	return normalizeLanguageTag(tags[0].tag)
+}
+
+func isLanguageTag(tag string) bool {
+	for _, r := range tag {
+		if !(r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeLanguageTag writes the language in lower case and a two-letter region in upper
+// case: "EN_us" becomes "en-US".
+func normalizeLanguageTag(tag string) string {
+	parts := strings.Split(strings.ReplaceAll(tag, "_", "-"), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
@@ -18,6 +18,7 @@ import (
 )
 
 var validate = validator.New()
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,32}$`)
 var validTLDs = map[string]bool{
 	"com": true,
 	"net": true,
@@ -28,6 +29,9 @@ func init() {
 	validate.RegisterValidation("domain", validateDomain)
 	validate.RegisterValidation("timestamp", validateTimestamp)
 	validate.RegisterValidation("file", FileImagesValidation)
+	validate.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		return IsValidUsername(fl.Field().String())
+	})
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
 		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
 		if name == "-" {
@@ -95,6 +99,8 @@ func msgForTag(tag string, e validator.FieldError) string {
 		return "Must be a valid datetime format"
 	case "future":
 		return "Must be a future timestamp in format: YYYY-MM-DD HH:MM:SS[.SSSSSS] (min 1 hour from now)"
+	case "username":
+		return "Username must be 3-32 letters, digits or underscores"
 	default:
 		return "Invalid value"
 	}
@@ -184,6 +190,9 @@ func FileImagesValidation(fl validator.FieldLevel) bool {
 
 	return allowedTypes[contentType] && validExtension[fileExt]
 }
+func IsValidUsername(username string) bool {
+	return usernamePattern.MatchString(username)
+}
 func IsValidEmail(email string) bool {
 	email = strings.TrimSpace(email)
 	if email == "" {